@@ -94,6 +94,12 @@ func main() {
 	defer downloadMgr.Stop()
 	utils.Info("download manager started")
 
+	if resumed, err := downloader.NewReorganizer(cfg, db).Resume(); err != nil {
+		utils.Warn("resume library reorganize failed: %v", err)
+	} else if resumed > 0 {
+		utils.Info("resumed library reorganize for %d videos", resumed)
+	}
+
 	urlDownloadService := service.NewURLDownloadService(cfg, db, biliClient, downloadMgr)
 	telegramService := telegram.NewBotService(cfg, db, urlDownloadService)
 
@@ -0,0 +1,166 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bili-download/internal/bilibili"
+)
+
+// BangumiAdapter 番剧/影视（PGC）适配器，每一集作为一个视频
+type BangumiAdapter struct {
+	client *bilibili.Client
+	config *BangumiConfig
+}
+
+// NewBangumiAdapter 创建番剧适配器
+func NewBangumiAdapter(client *bilibili.Client, config *BangumiConfig) *BangumiAdapter {
+	return &BangumiAdapter{
+		client: client,
+		config: config,
+	}
+}
+
+// GetType 获取视频源类型
+func (a *BangumiAdapter) GetType() VideoSourceType {
+	return SourceTypeBangumi
+}
+
+// GetID 获取视频源唯一标识
+func (a *BangumiAdapter) GetID() string {
+	return fmt.Sprintf("bgm_%s", a.config.SeasonID)
+}
+
+// GetName 获取视频源名称
+func (a *BangumiAdapter) GetName() string {
+	if a.config.Name != "" {
+		return a.config.Name
+	}
+
+	season, err := a.fetchSeason(context.Background())
+	if err != nil {
+		return fmt.Sprintf("番剧_%s", a.config.SeasonID)
+	}
+	return season.Title
+}
+
+// Scan 扫描视频源
+// 季度接口一次返回全部正片，已入库的剧集由同步任务跳过；尚未上线或没有 bvid 的剧集不返回
+func (a *BangumiAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]VideoInfo, error) {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+
+	season, err := a.fetchSeason(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.episodesToVideoInfos(season, opts, time.Now()), nil
+}
+
+// GetVideoCount 获取视频总数
+func (a *BangumiAdapter) GetVideoCount(ctx context.Context) (int, error) {
+	season, err := a.fetchSeason(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(season.Episodes), nil
+}
+
+// Validate 验证配置
+func (a *BangumiAdapter) Validate(ctx context.Context) error {
+	if a.config.SeasonID == "" {
+		return fmt.Errorf("番剧季度ID不能为空")
+	}
+
+	if _, err := a.fetchSeason(ctx); err != nil {
+		return fmt.Errorf("番剧验证失败: %w", err)
+	}
+	return nil
+}
+
+// fetchSeason 获取季度信息
+func (a *BangumiAdapter) fetchSeason(ctx context.Context) (*bilibili.BangumiSeason, error) {
+	seasonID, err := strconv.ParseInt(a.config.SeasonID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的番剧季度ID: %s", a.config.SeasonID)
+	}
+
+	season, err := a.client.GetBangumiSeason(ctx, seasonID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取番剧信息失败: %w", err)
+	}
+	return season, nil
+}
+
+// episodesToVideoInfos 将季度的正片剧集转换为统一的VideoInfo格式
+func (a *BangumiAdapter) episodesToVideoInfos(season *bilibili.BangumiSeason, opts *ScanOptions, now time.Time) []VideoInfo {
+	seasonNumber := a.config.SeasonNumber
+	if seasonNumber <= 0 {
+		seasonNumber = season.SeasonNumber()
+	}
+
+	videos := make([]VideoInfo, 0, len(season.Episodes))
+	for i, ep := range season.Episodes {
+		// 集序号按正片列表中的位置计算，保证 SP、半集等非数字标题也有稳定的编号
+		episodeNumber := i + 1
+
+		if ep.BVid == "" {
+			continue
+		}
+		pubTime := time.Unix(ep.PubTime, 0)
+		if ep.PubTime > 0 && pubTime.After(now) {
+			continue
+		}
+		if opts.reachedKnown(ep.BVid) {
+			continue
+		}
+
+		videos = append(videos, a.convertToVideoInfo(season, ep, seasonNumber, episodeNumber))
+		if opts.Limit > 0 && len(videos) >= opts.Limit {
+			break
+		}
+	}
+	return videos
+}
+
+// convertToVideoInfo 转换为统一的VideoInfo格式
+func (a *BangumiAdapter) convertToVideoInfo(season *bilibili.BangumiSeason, ep bilibili.BangumiEpisode, seasonNumber, episodeNumber int) VideoInfo {
+	title := ep.DisplayTitle()
+	pubTime := time.Unix(ep.PubTime, 0)
+
+	return VideoInfo{
+		BVid:        ep.BVid,
+		Aid:         ep.Aid,
+		Title:       title,
+		Description: season.Evaluate,
+		Duration:    ep.DurationSeconds(),
+		PubDate:     pubTime,
+		Owner: OwnerInfo{
+			Mid:  season.UpInfo.Mid,
+			Name: season.UpInfo.Uname,
+			Face: season.UpInfo.Avatar,
+		},
+		Cover: ep.Cover,
+		Pages: []PageInfo{{
+			CID:      ep.CID,
+			Page:     1,
+			Part:     title,
+			Duration: ep.DurationSeconds(),
+			Width:    ep.Dimension.Width,
+			Height:   ep.Dimension.Height,
+		}},
+		Tags:       season.Styles,
+		SourceType: SourceTypeBangumi,
+		SourceID:   a.GetID(),
+		AddTime:    pubTime,
+		Episode: &EpisodeInfo{
+			EpID:          ep.ID,
+			SeasonNumber:  seasonNumber,
+			EpisodeNumber: episodeNumber,
+		},
+	}
+}
@@ -45,7 +45,7 @@ func (a *CollectionAdapter) GetName() string {
 
 	// 从API获取名称
 	params := a.buildListParams()
-	info, err := a.client.GetCollectionInfo(params)
+	info, err := a.client.GetCollectionInfo(context.Background(), params)
 	if err != nil {
 		if a.config.CollectionType == "season" {
 			return fmt.Sprintf("合集_%s", a.config.SeasonID)
@@ -65,6 +65,7 @@ func (a *CollectionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 	var allVideos []VideoInfo
 	page := 1
 	pageSize := 30
+	incremental := opts.OnlyNew && opts.IsKnown != nil
 
 	// 如果设置了偏移量和限制，计算起始页
 	if opts.Offset > 0 {
@@ -80,16 +81,24 @@ func (a *CollectionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 
 		// 构建请求参数
 		params := a.buildListParams()
+		if incremental {
+			// 增量扫描按最新加入在前的顺序翻页，遇到已入库的视频即停止
+			params.SortReverse = true
+		}
 		params.PageNum = page
 		params.PageSize = pageSize
 
-		resp, err := a.client.GetCollectionList(params)
+		resp, err := a.client.GetCollectionList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取合集列表失败: %w", err)
 		}
 
 		// 转换为统一的VideoInfo格式
 		for _, archive := range resp.Archives {
+			if incremental && opts.reachedKnown(archive.BVid) {
+				return allVideos, nil
+			}
+
 			// 应用过滤条件
 			if !a.matchFilter(archive, opts) {
 				continue
@@ -126,9 +135,6 @@ func (a *CollectionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 		}
 
 		page++
-
-		// 翻页间隔，防止触发B站风控
-		time.Sleep(300 * time.Millisecond)
 	}
 
 	return allVideos, nil
@@ -137,7 +143,7 @@ func (a *CollectionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 // GetVideoCount 获取视频总数
 func (a *CollectionAdapter) GetVideoCount(ctx context.Context) (int, error) {
 	params := a.buildListParams()
-	info, err := a.client.GetCollectionInfo(params)
+	info, err := a.client.GetCollectionInfo(ctx, params)
 	if err != nil {
 		return 0, fmt.Errorf("获取合集信息失败: %w", err)
 	}
@@ -164,7 +170,7 @@ func (a *CollectionAdapter) Validate(ctx context.Context) error {
 
 	// 尝试获取合集信息
 	params := a.buildListParams()
-	_, err := a.client.GetCollectionInfo(params)
+	_, err := a.client.GetCollectionInfo(ctx, params)
 	if err != nil {
 		return fmt.Errorf("合集验证失败: %w", err)
 	}
@@ -39,7 +39,7 @@ func (a *FavoriteAdapter) GetName() string {
 		return a.config.Name
 	}
 	// 如果没有设置名称，从API获取
-	info, err := a.client.GetFavoriteInfo(a.config.MediaID)
+	info, err := a.client.GetFavoriteInfo(context.Background(), a.config.MediaID)
 	if err != nil {
 		return fmt.Sprintf("收藏夹_%s", a.config.MediaID)
 	}
@@ -80,17 +80,19 @@ func (a *FavoriteAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]VideoI
 			params.Order = "mtime" // 默认按收藏时间排序
 		}
 
-		resp, err := a.client.GetFavoriteList(params)
+		resp, err := a.client.GetFavoriteList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取收藏夹列表失败: %w", err)
 		}
 
 		// 转换为统一的VideoInfo格式
 		for _, media := range resp.Medias {
-			// 按收藏时间排序时，遇到早于上次扫描时间的视频直接终止翻页
-			if opts.OnlyNew && !opts.LastScanTime.IsZero() && (params.Order == "mtime" || params.Order == "") {
-				favTime := time.Unix(media.FavTime, 0)
-				if !favTime.After(opts.LastScanTime) {
+			// 按收藏时间排序时，遇到早于上次扫描时间的视频或已入库的视频直接终止翻页
+			if params.Order == "mtime" || params.Order == "" {
+				if opts.OnlyNew && !opts.LastScanTime.IsZero() && !time.Unix(media.FavTime, 0).After(opts.LastScanTime) {
+					return allVideos, nil
+				}
+				if opts.reachedKnown(media.BVid) {
 					return allVideos, nil
 				}
 			}
@@ -115,9 +117,6 @@ func (a *FavoriteAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]VideoI
 		}
 
 		page++
-
-		// 翻页间隔，防止触发B站风控
-		time.Sleep(300 * time.Millisecond)
 	}
 
 	return allVideos, nil
@@ -125,7 +124,7 @@ func (a *FavoriteAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]VideoI
 
 // GetVideoCount 获取视频总数
 func (a *FavoriteAdapter) GetVideoCount(ctx context.Context) (int, error) {
-	info, err := a.client.GetFavoriteInfo(a.config.MediaID)
+	info, err := a.client.GetFavoriteInfo(ctx, a.config.MediaID)
 	if err != nil {
 		return 0, fmt.Errorf("获取收藏夹信息失败: %w", err)
 	}
@@ -139,7 +138,7 @@ func (a *FavoriteAdapter) Validate(ctx context.Context) error {
 	}
 
 	// 尝试获取收藏夹信息
-	_, err := a.client.GetFavoriteInfo(a.config.MediaID)
+	_, err := a.client.GetFavoriteInfo(ctx, a.config.MediaID)
 	if err != nil {
 		return fmt.Errorf("收藏夹验证失败: %w", err)
 	}
@@ -237,6 +236,7 @@ func (a *FavoriteAdapter) convertToVideoInfo(media bilibili.FavoriteMedia) Video
 		SourceType: SourceTypeFavorite,
 		SourceID:   a.config.MediaID,
 		AddTime:    time.Unix(media.FavTime, 0),
+		Invalid:    media.Attr != 0,
 	}
 
 	return videoInfo
@@ -17,6 +17,8 @@ const (
 	SourceTypeCollection VideoSourceType = "collection"
 	// SourceTypeSubmission UP主投稿
 	SourceTypeSubmission VideoSourceType = "submission"
+	// SourceTypeBangumi 番剧/影视（PGC）
+	SourceTypeBangumi VideoSourceType = "bangumi"
 )
 
 // VideoSource 视频源适配器接口
@@ -52,6 +54,13 @@ type ScanOptions struct {
 	Offset int
 	// Filter 过滤条件
 	Filter *VideoFilter
+	// IsKnown 判断视频是否已在当前视频源中；增量扫描时，按时间倒序的列表遇到已知视频即停止翻页
+	IsKnown func(bvid string) bool
+}
+
+// reachedKnown 增量扫描时是否已到达上次扫描过的视频
+func (o *ScanOptions) reachedKnown(bvid string) bool {
+	return o.OnlyNew && o.IsKnown != nil && o.IsKnown(bvid)
 }
 
 // VideoFilter 视频过滤条件
@@ -100,6 +109,19 @@ type VideoInfo struct {
 	SourceID string
 	// AddTime 添加到源的时间（收藏时间、投稿时间等）
 	AddTime time.Time
+	// Copyright 版权类型：1-原创，2-转载，0-未知（列表接口未返回）
+	Copyright int
+	// Episode 番剧剧集信息（仅番剧视频源），存在时 Pages 已由适配器填充
+	Episode *EpisodeInfo
+	// Invalid 视频源中仍有条目但视频已失效（如收藏夹中已被删除的视频）
+	Invalid bool
+}
+
+// EpisodeInfo 番剧剧集信息
+type EpisodeInfo struct {
+	EpID          int64 // 剧集ID（ep）
+	SeasonNumber  int   // 季序号
+	EpisodeNumber int   // 集序号
 }
 
 // OwnerInfo UP主信息
@@ -177,4 +199,13 @@ type SubmissionConfig struct {
 	Mid   string // UP主ID
 	Order string // 排序方式：pubdate, click, stow
 	Tid   int    // 分区筛选（0表示不筛选）
+	// UseDynamicAPI 通过空间动态接口扫描（按发布时间倒序，忽略 Order 和 Tid）
+	UseDynamicAPI bool
+}
+
+// BangumiConfig 番剧/影视配置
+type BangumiConfig struct {
+	SourceConfig
+	SeasonID     string // 季度ID（ss）
+	SeasonNumber int    // 季序号（0 表示按同系列各季的顺序推断）
 }
@@ -41,7 +41,7 @@ func (a *SubmissionAdapter) GetName() string {
 	}
 
 	// 从API获取UP主名称
-	card, err := a.client.GetUpperCard(a.config.Mid)
+	card, err := a.client.GetUpperCard(context.Background(), a.config.Mid)
 	if err != nil {
 		return fmt.Sprintf("UP主_%s", a.config.Mid)
 	}
@@ -54,6 +54,9 @@ func (a *SubmissionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 	if opts == nil {
 		opts = &ScanOptions{}
 	}
+	if a.config.UseDynamicAPI {
+		return a.scanDynamic(ctx, opts)
+	}
 
 	var allVideos []VideoInfo
 	page := 1
@@ -84,17 +87,19 @@ func (a *SubmissionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 			params.Order = "pubdate" // 默认按发布时间排序
 		}
 
-		resp, err := a.client.GetSubmissionList(params)
+		resp, err := a.client.GetSubmissionList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取UP主投稿列表失败: %w", err)
 		}
 
 		// 转换为统一的VideoInfo格式
 		for _, video := range resp.List.Vlist {
-			// 按pubdate排序时，遇到早于上次扫描时间的视频直接终止翻页
-			if opts.OnlyNew && !opts.LastScanTime.IsZero() && (params.Order == "pubdate" || params.Order == "") {
-				pubTime := time.Unix(video.Created, 0)
-				if !pubTime.After(opts.LastScanTime) {
+			// 按pubdate排序时，遇到早于上次扫描时间的视频或已入库的视频直接终止翻页
+			if params.Order == "pubdate" || params.Order == "" {
+				if opts.OnlyNew && !opts.LastScanTime.IsZero() && !time.Unix(video.Created, 0).After(opts.LastScanTime) {
+					return allVideos, nil
+				}
+				if opts.reachedKnown(video.BVid) {
 					return allVideos, nil
 				}
 			}
@@ -124,14 +129,95 @@ func (a *SubmissionAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 		}
 
 		page++
+	}
+
+	return allVideos, nil
+}
+
+// scanDynamic 通过空间动态接口扫描UP主投稿
+// 动态按发布时间倒序返回并以游标翻页，增量扫描遇到早于上次扫描时间的动态即停止；
+// 与投稿列表相比，还能发现仅动态可见的视频
+func (a *SubmissionAdapter) scanDynamic(ctx context.Context, opts *ScanOptions) ([]VideoInfo, error) {
+	var allVideos []VideoInfo
+	seen := make(map[string]bool)
+	offset := ""
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		resp, err := a.client.GetSpaceDynamics(ctx, a.config.Mid, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取UP主动态失败: %w", err)
+		}
+
+		for i := range resp.Items {
+			item := &resp.Items[i]
+
+			// 置顶动态不在时间顺序中，不能作为停止翻页的依据
+			if !item.IsPinned() && opts.OnlyNew && !opts.LastScanTime.IsZero() {
+				pubTime := time.Unix(item.Modules.Author.PubTS, 0)
+				if !pubTime.After(opts.LastScanTime) {
+					return allVideos, nil
+				}
+			}
+
+			archive := item.VideoArchive()
+			if archive == nil || seen[archive.BVid] {
+				continue
+			}
+			if opts.reachedKnown(archive.BVid) {
+				if item.IsPinned() {
+					continue
+				}
+				return allVideos, nil
+			}
+			seen[archive.BVid] = true
+
+			video := dynamicToSubmissionVideo(item, archive)
+			if !a.matchFilter(video, opts) {
+				continue
+			}
 
-		// 翻页间隔，防止触发B站风控
-		time.Sleep(300 * time.Millisecond)
+			allVideos = append(allVideos, a.convertToVideoInfo(video))
+
+			// 如果设置了限制且已达到，返回
+			if opts.Limit > 0 && len(allVideos) >= opts.Limit {
+				return allVideos, nil
+			}
+		}
+
+		if !resp.HasMore || resp.Offset == "" || len(resp.Items) == 0 {
+			break
+		}
+		offset = resp.Offset
 	}
 
 	return allVideos, nil
 }
 
+// dynamicToSubmissionVideo 将视频动态转换为投稿视频格式，以复用过滤与转换逻辑
+// 动态接口不返回版权信息，由同步任务获取详情时补全
+func dynamicToSubmissionVideo(item *bilibili.DynamicItem, archive *bilibili.DynamicArchive) bilibili.SubmissionVideo {
+	aid, _ := strconv.ParseInt(archive.Aid, 10, 64)
+	return bilibili.SubmissionVideo{
+		Aid:         aid,
+		BVid:        archive.BVid,
+		Title:       archive.Title,
+		Description: archive.Desc,
+		Pic:         archive.Cover,
+		Author:      item.Modules.Author.Name,
+		Mid:         item.Modules.Author.Mid,
+		Created:     item.Modules.Author.PubTS,
+		Length:      archive.DurationText,
+		Play:        bilibili.ParseCountText(archive.Stat.Play),
+		VideoReview: bilibili.ParseCountText(archive.Stat.Danmaku),
+	}
+}
+
 // GetVideoCount 获取视频总数
 func (a *SubmissionAdapter) GetVideoCount(ctx context.Context) (int, error) {
 	params := bilibili.SubmissionListParams{
@@ -142,7 +228,7 @@ func (a *SubmissionAdapter) GetVideoCount(ctx context.Context) (int, error) {
 		Tid:   a.config.Tid,
 	}
 
-	resp, err := a.client.GetSubmissionList(params)
+	resp, err := a.client.GetSubmissionList(ctx, params)
 	if err != nil {
 		return 0, fmt.Errorf("获取UP主投稿信息失败: %w", err)
 	}
@@ -157,7 +243,7 @@ func (a *SubmissionAdapter) Validate(ctx context.Context) error {
 	}
 
 	// 尝试获取UP主信息
-	_, err := a.client.GetUpperCard(a.config.Mid)
+	_, err := a.client.GetUpperCard(ctx, a.config.Mid)
 	if err != nil {
 		return fmt.Errorf("UP主验证失败: %w", err)
 	}
@@ -236,6 +322,7 @@ func (a *SubmissionAdapter) matchFilter(video bilibili.SubmissionVideo, opts *Sc
 // convertToVideoInfo 转换为统一的VideoInfo格式
 func (a *SubmissionAdapter) convertToVideoInfo(video bilibili.SubmissionVideo) VideoInfo {
 	duration := parseDuration(video.Length)
+	copyright, _ := strconv.Atoi(video.Copyright)
 
 	videoInfo := VideoInfo{
 		BVid:        video.BVid,
@@ -258,6 +345,7 @@ func (a *SubmissionAdapter) convertToVideoInfo(video bilibili.SubmissionVideo) V
 		SourceType: SourceTypeSubmission,
 		SourceID:   a.config.Mid,
 		AddTime:    time.Unix(video.Created, 0),
+		Copyright:  copyright,
 	}
 
 	return videoInfo
@@ -54,7 +54,7 @@ func (a *WatchLaterAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 	}
 
 	// 获取稍后再看列表
-	resp, err := a.client.GetWatchLaterList()
+	resp, err := a.client.GetWatchLaterList(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("获取稍后再看列表失败: %w", err)
 	}
@@ -87,7 +87,7 @@ func (a *WatchLaterAdapter) Scan(ctx context.Context, opts *ScanOptions) ([]Vide
 
 // GetVideoCount 获取视频总数
 func (a *WatchLaterAdapter) GetVideoCount(ctx context.Context) (int, error) {
-	resp, err := a.client.GetWatchLaterList()
+	resp, err := a.client.GetWatchLaterList(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取稍后再看列表失败: %w", err)
 	}
@@ -97,7 +97,7 @@ func (a *WatchLaterAdapter) GetVideoCount(ctx context.Context) (int, error) {
 // Validate 验证配置
 func (a *WatchLaterAdapter) Validate(ctx context.Context) error {
 	// 尝试获取稍后再看列表
-	_, err := a.client.GetWatchLaterList()
+	_, err := a.client.GetWatchLaterList(ctx)
 	if err != nil {
 		return fmt.Errorf("稍后再看验证失败: %w", err)
 	}
@@ -196,5 +196,6 @@ func (a *WatchLaterAdapter) convertToVideoInfo(video bilibili.WatchLaterVideo) V
 		SourceType: SourceTypeWatchLater,
 		SourceID:   "watch_later",
 		AddTime:    time.Unix(video.AddAt, 0),
+		Copyright:  video.Copyright,
 	}
 }
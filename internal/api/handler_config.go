@@ -167,6 +167,33 @@ func mergeConfigFromMap(cfg *config.Config, configMap map[string]interface{}) {
 				cfg.Sync.ScanOnly = v
 			}
 		}
+		if cronExpr, exists := syncMap["cron"]; exists {
+			if v, ok := cronExpr.(string); ok {
+				cfg.Sync.Cron = v
+			}
+		}
+		if fullScanDays, exists := syncMap["full_scan_days"]; exists {
+			if v, ok := fullScanDays.(float64); ok {
+				cfg.Sync.FullScanDays = int(v)
+			}
+		}
+		if quietHoursMap, ok := syncMap["quiet_hours"].(map[string]interface{}); ok {
+			if enabled, exists := quietHoursMap["enabled"]; exists {
+				if v, ok := enabled.(bool); ok {
+					cfg.Sync.QuietHours.Enabled = v
+				}
+			}
+			if start, exists := quietHoursMap["start"]; exists {
+				if v, ok := start.(string); ok {
+					cfg.Sync.QuietHours.Start = v
+				}
+			}
+			if end, exists := quietHoursMap["end"]; exists {
+				if v, ok := end.(string); ok {
+					cfg.Sync.QuietHours.End = v
+				}
+			}
+		}
 	}
 
 	// 处理 paths 配置
@@ -283,6 +310,21 @@ func mergeConfigFromMap(cfg *config.Config, configMap map[string]interface{}) {
 
 	// 处理 download 配置
 	if downloadMap, ok := configMap["download"].(map[string]interface{}); ok {
+		if engine, exists := downloadMap["engine"]; exists {
+			if v, ok := engine.(string); ok {
+				cfg.Download.Engine = v
+			}
+		}
+		if postProcess, exists := downloadMap["post_process"]; exists {
+			if v, ok := postProcess.(string); ok {
+				cfg.Download.PostProcess = v
+			}
+		}
+		if workers, exists := downloadMap["post_process_workers"]; exists {
+			if v, ok := workers.(float64); ok {
+				cfg.Download.PostProcessWorkers = int(v)
+			}
+		}
 		if skipPoster, exists := downloadMap["skip_poster"]; exists {
 			if v, ok := skipPoster.(bool); ok {
 				cfg.Download.SkipPoster = v
@@ -308,6 +350,28 @@ func mergeConfigFromMap(cfg *config.Config, configMap map[string]interface{}) {
 				cfg.Download.SkipSubtitle = v
 			}
 		}
+		if subtitleLangs, exists := downloadMap["subtitle_langs"]; exists {
+			if v, ok := toStringSlice(subtitleLangs); ok {
+				cfg.Download.SubtitleLangs = v
+			}
+		}
+		if subtitleFormats, exists := downloadMap["subtitle_formats"]; exists {
+			if v, ok := toStringSlice(subtitleFormats); ok {
+				cfg.Download.SubtitleFormats = v
+			}
+		}
+		if bandwidthMap, ok := downloadMap["bandwidth"].(map[string]interface{}); ok {
+			if limit, exists := bandwidthMap["limit"]; exists {
+				if v, ok := limit.(float64); ok {
+					cfg.Download.Bandwidth.Limit = int(v)
+				}
+			}
+			if schedule, exists := bandwidthMap["schedule"]; exists {
+				if v, ok := toBandwidthSchedule(schedule); ok {
+					cfg.Download.Bandwidth.Schedule = v
+				}
+			}
+		}
 	}
 
 	// 处理 danmaku 配置
@@ -409,6 +473,20 @@ func mergeConfigFromMap(cfg *config.Config, configMap map[string]interface{}) {
 					cfg.Advanced.RateLimit.Limit = int(v)
 				}
 			}
+			for key, rule := range map[string]*config.RateLimitRule{
+				"wbi":     &cfg.Advanced.RateLimit.WBI,
+				"playurl": &cfg.Advanced.RateLimit.PlayURL,
+				"danmaku": &cfg.Advanced.RateLimit.Danmaku,
+			} {
+				if ruleMap, ok := rateLimitMap[key].(map[string]interface{}); ok {
+					if v, ok := ruleMap["duration_ms"].(float64); ok {
+						rule.DurationMS = int(v)
+					}
+					if v, ok := ruleMap["limit"].(float64); ok {
+						rule.Limit = int(v)
+					}
+				}
+			}
 		}
 		if nfoTimeType, exists := advancedMap["nfo_time_type"]; exists {
 			if v, ok := nfoTimeType.(string); ok {
@@ -431,6 +509,14 @@ func mergeConfigFromMap(cfg *config.Config, configMap map[string]interface{}) {
 				cfg.Advanced.MaxRetryCount = int(v)
 			}
 		}
+		if apiRetryMap, ok := advancedMap["api_retry"].(map[string]interface{}); ok {
+			if v, ok := apiRetryMap["max_retries"].(float64); ok {
+				cfg.Advanced.APIRetry.MaxRetries = int(v)
+			}
+			if v, ok := apiRetryMap["backoff_ms"].(float64); ok {
+				cfg.Advanced.APIRetry.BackoffMS = int(v)
+			}
+		}
 	}
 
 	if telegramMap, ok := configMap["telegram"].(map[string]interface{}); ok {
@@ -545,6 +631,34 @@ func toStringSlice(value interface{}) ([]string, bool) {
 	return result, true
 }
 
+func toBandwidthSchedule(value interface{}) ([]config.BandwidthScheduleRule, bool) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	result := make([]config.BandwidthScheduleRule, 0, len(items))
+	for _, item := range items {
+		ruleMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		var rule config.BandwidthScheduleRule
+		if v, ok := ruleMap["start"].(string); ok {
+			rule.Start = v
+		}
+		if v, ok := ruleMap["end"].(string); ok {
+			rule.End = v
+		}
+		if v, ok := ruleMap["limit"].(float64); ok {
+			rule.Limit = int(v)
+		}
+		result = append(result, rule)
+	}
+
+	return result, true
+}
+
 // ConfigValidationRequest 配置验证请求
 type ConfigValidationRequest struct {
 	Config map[string]interface{} `json:"config"`
@@ -598,13 +712,13 @@ func (s *Server) handleValidateBilibiliCredential(c *gin.Context) {
 	}
 
 	// 验证当前配置的B站凭证
-	if err := s.biliClient.ValidateCredential(); err != nil {
+	if err := s.biliClient.ValidateCredential(c.Request.Context()); err != nil {
 		respondError(c, 400, fmt.Sprintf("认证验证失败: %v", err))
 		return
 	}
 
 	// 获取用户信息
-	userInfo, err := s.biliClient.GetMe()
+	userInfo, err := s.biliClient.GetMe(c.Request.Context())
 	if err != nil {
 		respondError(c, 500, fmt.Sprintf("获取用户信息失败: %v", err))
 		return
@@ -16,6 +16,7 @@ type DashboardStats struct {
 	WatchLaterCount int `json:"watch_later_count"`
 	CollectionCount int `json:"collection_count"`
 	SubmissionCount int `json:"submission_count"`
+	BangumiCount    int `json:"bangumi_count"`
 
 	// 视频统计
 	TotalVideos      int `json:"total_videos"`
@@ -45,25 +46,28 @@ func (s *Server) handleDashboard(c *gin.Context) {
 	stats := DashboardStats{}
 
 	// 统计视频源
-	var favoriteCount, watchLaterCount, collectionCount, submissionCount int64
+	var favoriteCount, watchLaterCount, collectionCount, submissionCount, bangumiCount int64
 	s.db.Model(&models.Favorite{}).Count(&favoriteCount)
 	s.db.Model(&models.WatchLater{}).Count(&watchLaterCount)
 	s.db.Model(&models.Collection{}).Count(&collectionCount)
 	s.db.Model(&models.Submission{}).Count(&submissionCount)
+	s.db.Model(&models.Bangumi{}).Count(&bangumiCount)
 
 	stats.FavoriteCount = int(favoriteCount)
 	stats.WatchLaterCount = int(watchLaterCount)
 	stats.CollectionCount = int(collectionCount)
 	stats.SubmissionCount = int(submissionCount)
-	stats.TotalSources = stats.FavoriteCount + stats.WatchLaterCount + stats.CollectionCount + stats.SubmissionCount
+	stats.BangumiCount = int(bangumiCount)
+	stats.TotalSources = stats.FavoriteCount + stats.WatchLaterCount + stats.CollectionCount + stats.SubmissionCount + stats.BangumiCount
 
 	// 统计启用的视频源
-	var activeFavorite, activeWatchLater, activeCollection, activeSubmission int64
+	var activeFavorite, activeWatchLater, activeCollection, activeSubmission, activeBangumi int64
 	s.db.Model(&models.Favorite{}).Where("enabled = ?", true).Count(&activeFavorite)
 	s.db.Model(&models.WatchLater{}).Where("enabled = ?", true).Count(&activeWatchLater)
 	s.db.Model(&models.Collection{}).Where("enabled = ?", true).Count(&activeCollection)
 	s.db.Model(&models.Submission{}).Where("enabled = ?", true).Count(&activeSubmission)
-	stats.ActiveSources = int(activeFavorite + activeWatchLater + activeCollection + activeSubmission)
+	s.db.Model(&models.Bangumi{}).Where("enabled = ?", true).Count(&activeBangumi)
+	stats.ActiveSources = int(activeFavorite + activeWatchLater + activeCollection + activeSubmission + activeBangumi)
 
 	// 统计视频
 	var totalVideos int64
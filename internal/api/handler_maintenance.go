@@ -42,10 +42,19 @@ func buildBackfillQualityQueryParts() backfillQualityQueryParts {
 		videoTable:   videoTable,
 		selectClause: fmt.Sprintf("%s.*, %s.name as video_name, %s.single_page as single_page, %s.path as video_path", pageTable, videoTable, videoTable, videoTable),
 		joinClause:   fmt.Sprintf("JOIN %s ON %s.id = %s.video_id", videoTable, videoTable, pageTable),
-		whereClause:  fmt.Sprintf("%s.download_status = ? AND (%s.quality = 0 OR %s.width = 0)", pageTable, pageTable, pageTable),
+		whereClause:  fmt.Sprintf("(%s.download_status & ?) <> 0 AND (%s.quality = 0 OR %s.width = 0 OR %s.video_codec = '')", pageTable, pageTable, pageTable, pageTable),
 	}
 }
 
+// rowPageFileBaseName 按命名模板计算分P文件名前缀，视频加载失败时按视频标题推算
+func (s *Server) rowPageFileBaseName(r pageWithVideo) string {
+	var video models.Video
+	if err := s.db.First(&video, r.Page.VideoID).Error; err != nil {
+		video = models.Video{Name: r.VideoName, SinglePage: r.SinglePage}
+	}
+	return s.pageFileBaseName(&video, &r.Page)
+}
+
 func (s *Server) queryPagesForMetadataBackfill(whereClause string, args ...interface{}) ([]pageWithVideo, error) {
 	queryParts := buildBackfillQualityQueryParts()
 
@@ -74,12 +83,7 @@ func (s *Server) backfillPageMetadata(rows []pageWithVideo, taskName string) {
 			outputDir = filepath.Join(s.config.Paths.DownloadBase, outputDir)
 		}
 
-		var baseName string
-		if r.SinglePage {
-			baseName = utils.Filenamify(r.VideoName)
-		} else {
-			baseName = fmt.Sprintf("%s-%s", utils.Filenamify(r.VideoName), utils.Filenamify(r.Page.Name))
-		}
+		baseName := s.rowPageFileBaseName(r)
 
 		filePath := ""
 		entries, _ := os.ReadDir(outputDir)
@@ -116,13 +120,7 @@ func (s *Server) backfillPageMetadata(rows []pageWithVideo, taskName string) {
 			continue
 		}
 
-		updates := map[string]interface{}{
-			"width":       probe.Width,
-			"height":      probe.Height,
-			"frame_rate":  probe.FrameRate,
-			"quality":     models.CalcQuality(probe.Width, probe.Height, probe.FrameRate),
-			"orientation": models.CalcOrientation(probe.Width, probe.Height),
-		}
+		updates := probe.PageUpdates()
 		if err := s.db.Model(&models.Page{}).Where("id = ?", r.Page.ID).Updates(updates).Error; err != nil {
 			utils.Warn("%s: 更新失败 page=%d: %v", taskName, r.Page.ID, err)
 			failed++
@@ -172,7 +170,7 @@ func (s *Server) doRefreshViewCounts() {
 	for i := range videos {
 		video := &videos[i]
 
-		detail, err := s.biliClient.GetVideoDetail(video.BVid)
+		detail, err := s.biliClient.GetVideoDetail(context.Background(), video.BVid)
 		if err != nil {
 			utils.Warn("获取视频 %s 播放量失败: %v", video.BVid, err)
 			failed++
@@ -237,7 +235,7 @@ func (s *Server) doRefreshUpperFaces() {
 	failed := 0
 
 	for _, sub := range submissions {
-		info, err := s.biliClient.GetUpperInfo(sub.UpperID)
+		info, err := s.biliClient.GetUpperInfo(context.Background(), sub.UpperID)
 		if err != nil {
 			utils.Warn("获取UP主 %s (ID:%d) 信息失败: %v", sub.Name, sub.UpperID, err)
 			failed++
@@ -253,6 +251,17 @@ func (s *Server) doRefreshUpperFaces() {
 			updated++
 		}
 
+		if !s.config.Download.SkipUpper {
+			name := info.Uname
+			if name == "" {
+				name = sub.Name
+			}
+			upper := downloader.UpperMetadata{Mid: sub.UpperID, Name: name, Face: info.Face, Sign: info.Sign}
+			if err := downloader.WriteUpperMetadata(context.Background(), s.biliClient, s.config.Paths.UpperPath, upper, true); err != nil {
+				utils.Warn("写入UP主 %s 人物信息失败: %v", name, err)
+			}
+		}
+
 		time.Sleep(200 * time.Millisecond)
 	}
 
@@ -263,7 +272,7 @@ func (s *Server) doRefreshUpperFaces() {
 var backfillQualityRunning atomic.Bool
 var reparsePageMetadataRunning atomic.Bool
 
-// handleBackfillQuality 扫描已下载文件回填 width/height/frame_rate/quality/orientation
+// handleBackfillQuality 扫描已下载文件回填 width/height/frame_rate/quality/orientation 及编码、码率、动态范围、音频信息
 func (s *Server) handleBackfillQuality(c *gin.Context) {
 	if !backfillQualityRunning.CompareAndSwap(false, true) {
 		respondError(c, 409, "回填画质任务正在执行中，请稍后再试")
@@ -271,7 +280,7 @@ func (s *Server) handleBackfillQuality(c *gin.Context) {
 	}
 
 	var count int64
-	s.db.Model(&models.Page{}).Where("download_status = ? AND (quality = 0 OR width = 0)", 1).Count(&count)
+	s.db.Model(&models.Page{}).Where("(download_status & ?) <> 0 AND (quality = 0 OR width = 0 OR video_codec = '')", models.PageStatusVideo).Count(&count)
 
 	go s.doBackfillQuality()
 
@@ -286,17 +295,11 @@ func (s *Server) doBackfillQuality() {
 
 	queryParts := buildBackfillQualityQueryParts()
 
-	type pageWithVideo struct {
-		models.Page
-		VideoName  string
-		SinglePage bool
-		VideoPath  string
-	}
 	var rows []pageWithVideo
 	err := s.db.Table(queryParts.pageTable).
 		Select(queryParts.selectClause).
 		Joins(queryParts.joinClause).
-		Where(queryParts.whereClause, 1).
+		Where(queryParts.whereClause, models.PageStatusVideo).
 		Scan(&rows).Error
 	if err != nil {
 		utils.Error("回填画质查询失败: %v", err)
@@ -316,12 +319,7 @@ func (s *Server) doBackfillQuality() {
 			outputDir = filepath.Join(s.config.Paths.DownloadBase, outputDir)
 		}
 
-		var baseName string
-		if r.SinglePage {
-			baseName = utils.Filenamify(r.VideoName)
-		} else {
-			baseName = fmt.Sprintf("%s-%s", utils.Filenamify(r.VideoName), utils.Filenamify(r.Page.Name))
-		}
+		baseName := s.rowPageFileBaseName(r)
 
 		filePath := ""
 		entries, _ := os.ReadDir(outputDir)
@@ -357,13 +355,7 @@ func (s *Server) doBackfillQuality() {
 			continue
 		}
 
-		updates := map[string]interface{}{
-			"width":       probe.Width,
-			"height":      probe.Height,
-			"frame_rate":  probe.FrameRate,
-			"quality":     models.CalcQuality(probe.Width, probe.Height, probe.FrameRate),
-			"orientation": models.CalcOrientation(probe.Width, probe.Height),
-		}
+		updates := probe.PageUpdates()
 		if err := s.db.Model(&models.Page{}).Where("id = ?", r.Page.ID).Updates(updates).Error; err != nil {
 			utils.Warn("回填画质: 更新失败 page=%d: %v", r.Page.ID, err)
 			failed++
@@ -384,7 +376,7 @@ func (s *Server) handleReparsePageMetadata(c *gin.Context) {
 
 	var count int64
 	s.db.Model(&models.Page{}).
-		Where("download_status = ? AND (quality = 0 OR quality IS NULL) AND (frame_rate = 0 OR frame_rate IS NULL) AND (orientation = 0 OR orientation IS NULL)", 1).
+		Where("(download_status & ?) <> 0 AND (quality = 0 OR quality IS NULL) AND (frame_rate = 0 OR frame_rate IS NULL) AND (orientation = 0 OR orientation IS NULL)", models.PageStatusVideo).
 		Count(&count)
 
 	go s.doReparsePageMetadata()
@@ -399,14 +391,14 @@ func (s *Server) doReparsePageMetadata() {
 	defer reparsePageMetadataRunning.Store(false)
 
 	queryParts := buildBackfillQualityQueryParts()
-	whereClause := fmt.Sprintf("%s.download_status = ? AND (%s.quality = 0 OR %s.quality IS NULL) AND (%s.frame_rate = 0 OR %s.frame_rate IS NULL) AND (%s.orientation = 0 OR %s.orientation IS NULL)",
+	whereClause := fmt.Sprintf("(%s.download_status & ?) <> 0 AND (%s.quality = 0 OR %s.quality IS NULL) AND (%s.frame_rate = 0 OR %s.frame_rate IS NULL) AND (%s.orientation = 0 OR %s.orientation IS NULL)",
 		queryParts.pageTable,
 		queryParts.pageTable, queryParts.pageTable,
 		queryParts.pageTable, queryParts.pageTable,
 		queryParts.pageTable, queryParts.pageTable,
 	)
 
-	rows, err := s.queryPagesForMetadataBackfill(whereClause, 1)
+	rows, err := s.queryPagesForMetadataBackfill(whereClause, models.PageStatusVideo)
 	if err != nil {
 		utils.Error("重新解析视频信息查询失败: %v", err)
 		return
@@ -417,14 +409,7 @@ func (s *Server) doReparsePageMetadata() {
 
 func (s *Server) updateNFOViewCount(video *models.Video, outputDir string) {
 	for _, page := range video.Pages {
-		var nfoFile string
-		if video.SinglePage {
-			nfoFile = fmt.Sprintf("%s.nfo", utils.Filenamify(video.Name))
-		} else {
-			nfoFile = fmt.Sprintf("%s-%s.nfo",
-				utils.Filenamify(video.Name),
-				utils.Filenamify(page.Name))
-		}
+		nfoFile := s.pageFileBaseName(video, &page) + ".nfo"
 		nfoPath := filepath.Join(outputDir, nfoFile)
 
 		if _, err := os.Stat(nfoPath); os.IsNotExist(err) {
@@ -454,10 +439,13 @@ func (s *Server) updateNFOViewCount(video *models.Video, outputDir string) {
 				AddUniqueID("bvid", video.BVid, true).
 				AddTags(video.Tags)
 
-			if page.Width > 0 && page.Height > 0 {
-				generator.SetVideoInfo("h264", page.Width, page.Height, page.Duration)
+			videoStream, audioStream := downloader.PageStreamDetails(&page)
+			if videoStream != nil {
+				generator.AddVideoStream(*videoStream)
+			}
+			if audioStream != nil {
+				generator.AddAudioStream(*audioStream)
 			}
-			generator.SetAudioInfo("aac", "zh", 2)
 			if video.Cover != "" {
 				generator.AddThumb(video.Cover, "poster")
 			}
@@ -482,10 +470,13 @@ func (s *Server) updateNFOViewCount(video *models.Video, outputDir string) {
 				AddUniqueID("bvid", video.BVid, true).
 				AddTags(video.Tags)
 
-			if page.Width > 0 && page.Height > 0 {
-				generator.SetVideoInfo("h264", page.Width, page.Height, page.Duration)
+			videoStream, audioStream := downloader.PageStreamDetails(&page)
+			if videoStream != nil {
+				generator.AddVideoStream(*videoStream)
+			}
+			if audioStream != nil {
+				generator.AddAudioStream(*audioStream)
 			}
-			generator.SetAudioInfo("aac", "zh", 2)
 			if page.Image != "" {
 				generator.AddThumb(page.Image, "poster")
 			} else if video.Cover != "" {
@@ -498,3 +489,50 @@ func (s *Server) updateNFOViewCount(video *models.Video, outputDir string) {
 		}
 	}
 }
+
+// reorganizeLibraryRunning 防止重复执行
+var reorganizeLibraryRunning atomic.Bool
+
+// handleReorganizePreview 试运行媒体库重整，返回按当前命名模板需要移动的文件
+func (s *Server) handleReorganizePreview(c *gin.Context) {
+	preview, err := downloader.NewReorganizer(s.config, s.db).Preview()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	respondSuccess(c, preview)
+}
+
+// handleReorganizeLibrary 按当前命名模板移动已下载视频的文件并更新数据库
+func (s *Server) handleReorganizeLibrary(c *gin.Context) {
+	if !reorganizeLibraryRunning.CompareAndSwap(false, true) {
+		respondError(c, 409, "媒体库重整任务正在执行中，请稍后再试")
+		return
+	}
+
+	preview, err := downloader.NewReorganizer(s.config, s.db).Preview()
+	if err != nil {
+		reorganizeLibraryRunning.Store(false)
+		respondInternalError(c, err)
+		return
+	}
+
+	go s.doReorganizeLibrary()
+
+	respondSuccess(c, gin.H{
+		"total":   preview.Changed,
+		"pending": preview.Pending,
+		"message": fmt.Sprintf("已开始重整 %d 个视频的文件，请查看日志了解进度", preview.Changed),
+	})
+}
+
+func (s *Server) doReorganizeLibrary() {
+	defer reorganizeLibraryRunning.Store(false)
+
+	result, err := downloader.NewReorganizer(s.config, s.db).Run(context.Background())
+	if err != nil {
+		utils.Error("媒体库重整失败: %v", err)
+	}
+	utils.Info("媒体库重整完成: 继续完成 %d 个，重整 %d 个，跳过 %d 个，失败 %d 个",
+		result.Resumed, result.Moved, result.Skipped, result.Failed)
+}
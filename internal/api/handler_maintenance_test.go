@@ -28,7 +28,7 @@ func TestBuildBackfillQualityQueryPartsUseSingularTableNames(t *testing.T) {
 	if !strings.Contains(parts.joinClause, "JOIN video ON video.id = page.video_id") {
 		t.Fatalf("expected join clause to use singular table names, got %q", parts.joinClause)
 	}
-	if parts.whereClause != "page.download_status = ? AND (page.quality = 0 OR page.width = 0)" {
+	if parts.whereClause != "(page.download_status & ?) <> 0 AND (page.quality = 0 OR page.width = 0 OR page.video_codec = '')" {
 		t.Fatalf("unexpected where clause: %q", parts.whereClause)
 	}
 }
@@ -22,6 +22,27 @@ func (s *Server) handleSchedulerStatus(c *gin.Context) {
 	})
 }
 
+// handleSchedulerSources 获取各视频源的同步计划
+func (s *Server) handleSchedulerSources(c *gin.Context) {
+	schedules, err := s.scheduler.GetSourceSchedules()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"code":    500,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"items": schedules,
+			"total": len(schedules),
+		},
+	})
+}
+
 // handleSchedulerStart 启动调度器
 func (s *Server) handleSchedulerStart(c *gin.Context) {
 	if err := s.scheduler.Start(); err != nil {
@@ -248,12 +269,15 @@ func (s *Server) handleGetTasksSummary(c *gin.Context) {
 		"code":    0,
 		"message": "success",
 		"data": gin.H{
-			"pending":   0,                    // 暂未实现 pending 状态
-			"queued":    stats.QueuedTasks,    // 排队中
-			"running":   stats.RunningTasks,   // 运行中
-			"completed": stats.CompletedTasks, // 已完成
-			"failed":    stats.FailedTasks,    // 失败
-			"total":     stats.TotalTasks,     // 总计
+			"pending":     0,                    // 暂未实现 pending 状态
+			"queued":      stats.QueuedTasks,    // 排队中
+			"running":     stats.RunningTasks,   // 运行中
+			"paused":      stats.PausedTasks,    // 已暂停
+			"completed":   stats.CompletedTasks, // 已完成
+			"failed":      stats.FailedTasks,    // 失败
+			"total":       stats.TotalTasks,     // 总计
+			"paused_all":  stats.PausedAll,      // 全局暂停开关
+			"quiet_hours": stats.QuietHours,     // 是否处于静默时段
 		},
 	})
 }
@@ -272,7 +296,9 @@ func (s *Server) handleListTasks(c *gin.Context) {
 		allTasks = s.downloadMgr.GetQueuedTasks()
 	case "running":
 		allTasks = s.downloadMgr.GetRunningTasks()
-	case "completed", "failed", "cancelled", "paused":
+	case "paused":
+		allTasks = s.downloadMgr.GetPausedTasks()
+	case "completed", "failed", "cancelled":
 		completedTasks := s.downloadMgr.GetCompletedTasks()
 		for _, task := range completedTasks {
 			if string(task.GetStatus()) == statusFilter {
@@ -283,6 +309,7 @@ func (s *Server) handleListTasks(c *gin.Context) {
 		// 获取所有任务
 		allTasks = append(allTasks, s.downloadMgr.GetQueuedTasks()...)
 		allTasks = append(allTasks, s.downloadMgr.GetRunningTasks()...)
+		allTasks = append(allTasks, s.downloadMgr.GetPausedTasks()...)
 		allTasks = append(allTasks, s.downloadMgr.GetCompletedTasks()...)
 	}
 
@@ -293,12 +320,73 @@ func (s *Server) handleListTasks(c *gin.Context) {
 	})
 }
 
+// handlePauseTask 暂停任务
+func (s *Server) handlePauseTask(c *gin.Context) {
+	if err := s.downloadMgr.PauseTask(c.Param("id")); err != nil {
+		c.JSON(400, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    0,
+		"message": "任务已暂停",
+	})
+}
+
+// handleResumeTask 恢复任务
+func (s *Server) handleResumeTask(c *gin.Context) {
+	if err := s.downloadMgr.ResumeTask(c.Param("id")); err != nil {
+		c.JSON(400, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code":    0,
+		"message": "任务已恢复",
+	})
+}
+
+// handlePauseAllTasks 打开全局暂停开关
+func (s *Server) handlePauseAllTasks(c *gin.Context) {
+	count := s.downloadMgr.PauseAll()
+
+	c.JSON(200, gin.H{
+		"code":    0,
+		"message": "已暂停全部下载",
+		"data": gin.H{
+			"paused_all":   true,
+			"paused_count": count,
+		},
+	})
+}
+
+// handleResumeAllTasks 关闭全局暂停开关
+func (s *Server) handleResumeAllTasks(c *gin.Context) {
+	count := s.downloadMgr.ResumeAll()
+
+	c.JSON(200, gin.H{
+		"code":    0,
+		"message": "已恢复全部下载",
+		"data": gin.H{
+			"paused_all":    false,
+			"resumed_count": count,
+		},
+	})
+}
+
 // registerSchedulerRoutes 注册调度器相关路由
 func (s *Server) registerSchedulerRoutes(r *gin.RouterGroup) {
 	scheduler := r.Group("/scheduler")
 	{
 		// 调度器控制
 		scheduler.GET("/status", s.handleSchedulerStatus)
+		scheduler.GET("/sources", s.handleSchedulerSources)
 		scheduler.POST("/start", s.handleSchedulerStart)
 		scheduler.POST("/stop", s.handleSchedulerStop)
 		scheduler.POST("/trigger", s.handleSchedulerTrigger)
@@ -311,5 +399,9 @@ func (s *Server) registerSchedulerRoutes(r *gin.RouterGroup) {
 		// 任务管理
 		scheduler.GET("/tasks/summary", s.handleGetTasksSummary)
 		scheduler.GET("/tasks", s.handleListTasks)
+		scheduler.POST("/tasks/pause-all", s.handlePauseAllTasks)
+		scheduler.POST("/tasks/resume-all", s.handleResumeAllTasks)
+		scheduler.POST("/tasks/:id/pause", s.handlePauseTask)
+		scheduler.POST("/tasks/:id/resume", s.handleResumeTask)
 	}
 }
@@ -3,16 +3,20 @@ package api
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"bili-download/internal/bilibili"
+	"bili-download/internal/config"
 	"bili-download/internal/database/models"
+	"bili-download/internal/scheduler"
+	"bili-download/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SourceRequest 添加视频源请求
 type SourceRequest struct {
-	Type string `json:"type" binding:"required"` // favorite, watch_later, collection, submission
+	Type string `json:"type" binding:"required"` // favorite, watch_later, collection, submission, bangumi
 	URL  string `json:"url" binding:"required"`
 	Name string `json:"name"`
 }
@@ -22,6 +26,21 @@ type UpdateSourceRequest struct {
 	Name    *string `json:"name"`    // 名称（可选）
 	Path    *string `json:"path"`    // 保存路径（可选）
 	Enabled *bool   `json:"enabled"` // 启用状态（可选）
+	Rule    *string `json:"rule"`    // 过滤规则 JSON（可选，空字符串表示清除）
+
+	ScanInterval *int    `json:"scan_interval"` // 同步间隔（秒，可选，0 表示使用全局同步间隔）
+	ScanCron     *string `json:"scan_cron"`     // 同步 cron 表达式（可选，空字符串表示清除）
+
+	RetentionMode  *string `json:"retention_mode"`  // 保留策略（可选，空字符串表示不清理）：keep_last/max_age/mirror
+	RetentionValue *int    `json:"retention_value"` // keep_last 的保留数量或 max_age 的保留天数（可选）
+
+	DownloadEngine *string `json:"download_engine"` // 下载引擎（可选，空字符串表示使用全局配置）：ytdlp/native
+	PostProcess    *string `json:"post_process"`    // 下载后处理方案（可选，空字符串表示使用全局配置）：none/mkv/h264_1080p/audio_m4a
+
+	UseDynamicAPI       *bool  `json:"use_dynamic_api"`       // 通过空间动态接口扫描（可选，仅UP主投稿）
+	SeasonNumber        *int   `json:"season_number"`         // 季序号（可选，仅番剧）
+	RemoveAfterDownload *bool  `json:"remove_after_download"` // 下载完成后从稍后再看移除（可选，仅稍后再看）
+	MoveToFavoriteID    *int64 `json:"move_to_favorite_id"`   // 移除前加入的收藏夹 ID（可选，仅稍后再看，0 表示直接移除）
 }
 
 // handleListSources 列出所有视频源
@@ -36,15 +55,21 @@ func (s *Server) handleListSources(c *gin.Context) {
 	}
 	for _, fav := range favorites {
 		sources = append(sources, gin.H{
-			"id":           fav.ID,
-			"type":         "favorite",
-			"name":         fav.Name,
-			"path":         fav.Path,
-			"f_id":         strconv.FormatInt(fav.FID, 10),
-			"enabled":      fav.Enabled,
-			"last_scan_at": fav.LastScanAt,
-			"video_count":  len(fav.Videos),
-			"created_at":   fav.CreatedAt,
+			"id":              fav.ID,
+			"type":            "favorite",
+			"name":            fav.Name,
+			"path":            fav.Path,
+			"f_id":            strconv.FormatInt(fav.FID, 10),
+			"enabled":         fav.Enabled,
+			"last_scan_at":    fav.LastScanAt,
+			"scan_interval":   fav.ScanInterval,
+			"scan_cron":       fav.ScanCron,
+			"retention_mode":  fav.RetentionMode,
+			"retention_value": fav.RetentionValue,
+			"download_engine": fav.DownloadEngine,
+			"post_process":    fav.PostProcess,
+			"video_count":     len(fav.Videos),
+			"created_at":      fav.CreatedAt,
 		})
 	}
 
@@ -56,14 +81,22 @@ func (s *Server) handleListSources(c *gin.Context) {
 	}
 	for _, wl := range watchLaters {
 		sources = append(sources, gin.H{
-			"id":           wl.ID,
-			"type":         "watch_later",
-			"name":         wl.Name,
-			"path":         wl.Path,
-			"enabled":      wl.Enabled,
-			"last_scan_at": wl.LastScanAt,
-			"video_count":  len(wl.Videos),
-			"created_at":   wl.CreatedAt,
+			"id":                    wl.ID,
+			"type":                  "watch_later",
+			"name":                  wl.Name,
+			"path":                  wl.Path,
+			"enabled":               wl.Enabled,
+			"last_scan_at":          wl.LastScanAt,
+			"scan_interval":         wl.ScanInterval,
+			"scan_cron":             wl.ScanCron,
+			"remove_after_download": wl.RemoveAfterDownload,
+			"move_to_favorite_id":   wl.MoveToFavoriteID,
+			"retention_mode":        wl.RetentionMode,
+			"retention_value":       wl.RetentionValue,
+			"download_engine":       wl.DownloadEngine,
+			"post_process":          wl.PostProcess,
+			"video_count":           len(wl.Videos),
+			"created_at":            wl.CreatedAt,
 		})
 	}
 
@@ -75,15 +108,21 @@ func (s *Server) handleListSources(c *gin.Context) {
 	}
 	for _, col := range collections {
 		sources = append(sources, gin.H{
-			"id":           col.ID,
-			"type":         "collection",
-			"name":         col.Name,
-			"path":         col.Path,
-			"cid":          col.CID,
-			"enabled":      col.Enabled,
-			"last_scan_at": col.LastScanAt,
-			"video_count":  len(col.Videos),
-			"created_at":   col.CreatedAt,
+			"id":              col.ID,
+			"type":            "collection",
+			"name":            col.Name,
+			"path":            col.Path,
+			"cid":             col.CID,
+			"enabled":         col.Enabled,
+			"last_scan_at":    col.LastScanAt,
+			"scan_interval":   col.ScanInterval,
+			"scan_cron":       col.ScanCron,
+			"retention_mode":  col.RetentionMode,
+			"retention_value": col.RetentionValue,
+			"download_engine": col.DownloadEngine,
+			"post_process":    col.PostProcess,
+			"video_count":     len(col.Videos),
+			"created_at":      col.CreatedAt,
 		})
 	}
 
@@ -95,17 +134,53 @@ func (s *Server) handleListSources(c *gin.Context) {
 	}
 	for _, sub := range submissions {
 		sources = append(sources, gin.H{
-			"id":           sub.ID,
-			"type":         "submission",
-			"name":         sub.Name,
-			"path":         sub.Path,
-			"mid":          strconv.FormatInt(sub.UpperID, 10),
-			"upper_id":     sub.UpperID,
-			"upper_face":   sub.UpperFace,
-			"enabled":      sub.Enabled,
-			"last_scan_at": sub.LastScanAt,
-			"video_count":  len(sub.Videos),
-			"created_at":   sub.CreatedAt,
+			"id":              sub.ID,
+			"type":            "submission",
+			"name":            sub.Name,
+			"path":            sub.Path,
+			"mid":             strconv.FormatInt(sub.UpperID, 10),
+			"upper_id":        sub.UpperID,
+			"upper_face":      sub.UpperFace,
+			"enabled":         sub.Enabled,
+			"last_scan_at":    sub.LastScanAt,
+			"scan_interval":   sub.ScanInterval,
+			"scan_cron":       sub.ScanCron,
+			"retention_mode":  sub.RetentionMode,
+			"retention_value": sub.RetentionValue,
+			"download_engine": sub.DownloadEngine,
+			"post_process":    sub.PostProcess,
+			"use_dynamic_api": sub.UseDynamicAPI,
+			"video_count":     len(sub.Videos),
+			"created_at":      sub.CreatedAt,
+		})
+	}
+
+	// 番剧
+	var bangumis []models.Bangumi
+	if err := s.db.Find(&bangumis).Error; err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	for _, bgm := range bangumis {
+		sources = append(sources, gin.H{
+			"id":              bgm.ID,
+			"type":            "bangumi",
+			"name":            bgm.Name,
+			"path":            bgm.Path,
+			"season_id":       bgm.SeasonID,
+			"media_id":        bgm.MediaID,
+			"season_number":   bgm.SeasonNumber,
+			"finished":        bgm.Finished,
+			"enabled":         bgm.Enabled,
+			"last_scan_at":    bgm.LastScanAt,
+			"scan_interval":   bgm.ScanInterval,
+			"scan_cron":       bgm.ScanCron,
+			"retention_mode":  bgm.RetentionMode,
+			"retention_value": bgm.RetentionValue,
+			"download_engine": bgm.DownloadEngine,
+			"post_process":    bgm.PostProcess,
+			"video_count":     len(bgm.Videos),
+			"created_at":      bgm.CreatedAt,
 		})
 	}
 
@@ -229,7 +304,7 @@ func (s *Server) handleAddSource(c *gin.Context) {
 		}
 
 		// 获取UP主信息
-		upperInfo, err := s.biliClient.GetUpperInfo(parsed.ID)
+		upperInfo, err := s.biliClient.GetUpperInfo(c.Request.Context(), parsed.ID)
 		var upperFace string
 		if err == nil && upperInfo != nil {
 			upperFace = upperInfo.Face
@@ -259,6 +334,45 @@ func (s *Server) handleAddSource(c *gin.Context) {
 			"source":  submission,
 		})
 
+	case bilibili.SourceTypeBangumi:
+		// ep/md 链接先解析到所属季度
+		season, err := s.biliClient.ResolveBangumiSeason(c.Request.Context(), parsed)
+		if err != nil {
+			respondValidationError(c, fmt.Sprintf("获取番剧信息失败: %v", err))
+			return
+		}
+
+		var bangumi models.Bangumi
+		// 检查是否已存在
+		if err := s.db.Where("season_id = ?", season.SeasonID).First(&bangumi).Error; err == nil {
+			respondValidationError(c, fmt.Sprintf("番剧 (SeasonID: %d) 已存在", season.SeasonID))
+			return
+		}
+
+		// 创建新番剧，默认保存到以系列名称命名的目录，同系列各季按 Season XX 子目录存放
+		name := req.Name
+		if name == "" {
+			name = season.Title
+		}
+		bangumi = models.Bangumi{
+			SeasonID:     season.SeasonID,
+			MediaID:      season.MediaID,
+			SeasonType:   season.Type,
+			SeasonNumber: season.SeasonNumber(),
+			Finished:     season.IsFinished(),
+			Name:         name,
+			Path:         utils.Filenamify(season.ShowName()),
+			Enabled:      true,
+		}
+		if err := s.db.Create(&bangumi).Error; err != nil {
+			respondInternalError(c, fmt.Errorf("创建番剧失败: %w", err))
+			return
+		}
+		respondSuccess(c, gin.H{
+			"message": "添加番剧成功",
+			"source":  bangumi,
+		})
+
 	default:
 		respondValidationError(c, fmt.Sprintf("不支持的视频源类型: %s", parsed.Type))
 	}
@@ -313,6 +427,14 @@ func (s *Server) handleGetSource(c *gin.Context) {
 		}
 		respondSuccess(c, submission)
 
+	case "bangumi":
+		var bangumi models.Bangumi
+		if err := s.db.Preload("Videos").First(&bangumi, id).Error; err != nil {
+			respondNotFound(c, "番剧未找到")
+			return
+		}
+		respondSuccess(c, bangumi)
+
 	default:
 		respondValidationError(c, fmt.Sprintf("不支持的视频源类型: %s", sourceType))
 	}
@@ -351,6 +473,94 @@ func (s *Server) handleUpdateSource(c *gin.Context) {
 	if req.Enabled != nil {
 		updates["enabled"] = *req.Enabled
 	}
+	if req.Rule != nil {
+		if _, err := scheduler.ParseRuleFromJSON(*req.Rule); err != nil {
+			respondValidationError(c, fmt.Sprintf("过滤规则格式错误: %v", err))
+			return
+		}
+		updates["rule"] = *req.Rule
+	}
+	if req.ScanInterval != nil || req.ScanCron != nil {
+		interval, cron := 0, ""
+		if req.ScanInterval != nil {
+			interval = *req.ScanInterval
+		}
+		if req.ScanCron != nil {
+			cron = strings.TrimSpace(*req.ScanCron)
+		}
+		if err := scheduler.ValidateSourceSchedule(interval, cron); err != nil {
+			respondValidationError(c, fmt.Sprintf("同步计划格式错误: %v", err))
+			return
+		}
+		if req.ScanInterval != nil {
+			updates["scan_interval"] = interval
+		}
+		if req.ScanCron != nil {
+			updates["scan_cron"] = cron
+		}
+	}
+
+	if req.RetentionMode != nil || req.RetentionValue != nil {
+		// 只更新其中一项时，与已保存的另一项一起校验
+		current, err := s.loadRetentionSource(sourceType, uint(id))
+		if err != nil {
+			respondNotFound(c, fmt.Sprintf("视频源未找到: %v", err))
+			return
+		}
+		mode, value := current.Mode, current.Value
+		if req.RetentionMode != nil {
+			mode = strings.TrimSpace(*req.RetentionMode)
+		}
+		if req.RetentionValue != nil {
+			value = *req.RetentionValue
+		}
+		if err := validateRetentionPolicy(mode, value); err != nil {
+			respondValidationError(c, fmt.Sprintf("保留策略格式错误: %v", err))
+			return
+		}
+		updates["retention_mode"] = mode
+		updates["retention_value"] = value
+	}
+	if req.DownloadEngine != nil {
+		engine := strings.TrimSpace(*req.DownloadEngine)
+		if err := config.ValidateDownloadEngine(engine); err != nil {
+			respondValidationError(c, fmt.Sprintf("下载引擎格式错误: %v", err))
+			return
+		}
+		updates["download_engine"] = engine
+	}
+	if req.PostProcess != nil {
+		profile := strings.TrimSpace(*req.PostProcess)
+		if err := config.ValidatePostProcess(profile); err != nil {
+			respondValidationError(c, fmt.Sprintf("后处理方案格式错误: %v", err))
+			return
+		}
+		updates["post_process"] = profile
+	}
+
+	// 仅特定类型视频源支持的字段
+	if req.UseDynamicAPI != nil && sourceType == "submission" {
+		updates["use_dynamic_api"] = *req.UseDynamicAPI
+	}
+	if req.SeasonNumber != nil && sourceType == "bangumi" {
+		if *req.SeasonNumber < 1 {
+			respondValidationError(c, "季序号必须大于 0")
+			return
+		}
+		updates["season_number"] = *req.SeasonNumber
+	}
+	if sourceType == "watch_later" {
+		if req.RemoveAfterDownload != nil {
+			updates["remove_after_download"] = *req.RemoveAfterDownload
+		}
+		if req.MoveToFavoriteID != nil {
+			if *req.MoveToFavoriteID < 0 {
+				respondValidationError(c, "收藏夹 ID 无效")
+				return
+			}
+			updates["move_to_favorite_id"] = *req.MoveToFavoriteID
+		}
+	}
 
 	// 如果没有任何更新字段，返回错误
 	if len(updates) == 0 {
@@ -384,11 +594,22 @@ func (s *Server) handleUpdateSource(c *gin.Context) {
 			return
 		}
 
+	case "bangumi":
+		if err := s.db.Model(&models.Bangumi{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			respondInternalError(c, err)
+			return
+		}
+
 	default:
 		respondValidationError(c, fmt.Sprintf("不支持的视频源类型: %s", sourceType))
 		return
 	}
 
+	// 同步计划可能已变化，让调度器重新推算各视频源的下次同步时间
+	if s.scheduler != nil {
+		s.scheduler.ResetSourceSchedules()
+	}
+
 	respondSuccess(c, gin.H{
 		"message": "更新成功",
 	})
@@ -435,6 +656,12 @@ func (s *Server) handleDeleteSource(c *gin.Context) {
 			return
 		}
 
+	case "bangumi":
+		if err := s.db.Delete(&models.Bangumi{}, id).Error; err != nil {
+			respondInternalError(c, err)
+			return
+		}
+
 	default:
 		respondValidationError(c, fmt.Sprintf("不支持的视频源类型: %s", sourceType))
 		return
@@ -464,6 +691,49 @@ func (s *Server) handleScanSource(c *gin.Context) {
 	})
 }
 
+// FilterPreviewRequest 过滤规则预览请求
+type FilterPreviewRequest struct {
+	Rule  *string `json:"rule"`  // 待调试的过滤规则 JSON（可选，不传则使用已保存的规则）
+	Limit int     `json:"limit"` // 最多扫描的视频数（可选，0 表示不限制）
+}
+
+// handleFilterPreviewSource 对视频源当前扫描结果试运行过滤规则（不入库、不下载）
+func (s *Server) handleFilterPreviewSource(c *gin.Context) {
+	idStr := c.Param("id")
+	sourceType := c.Query("type")
+
+	if sourceType == "" {
+		respondValidationError(c, "缺少 type 参数")
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondValidationError(c, "无效的 ID")
+		return
+	}
+
+	var req FilterPreviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err.Error())
+			return
+		}
+	}
+	if req.Limit < 0 {
+		respondValidationError(c, "limit 不能为负数")
+		return
+	}
+
+	result, err := s.scheduler.PreviewSourceFilter(c.Request.Context(), sourceType, uint(id), req.Rule, req.Limit)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondSuccess(c, result)
+}
+
 // EnableSourceRequest 启用/禁用视频源请求
 type EnableSourceRequest struct {
 	Enabled bool `json:"enabled"`
@@ -517,6 +787,12 @@ func (s *Server) handleEnableSource(c *gin.Context) {
 			return
 		}
 
+	case "bangumi":
+		if err := s.db.Model(&models.Bangumi{}).Where("id = ?", id).Update("enabled", req.Enabled).Error; err != nil {
+			respondInternalError(c, err)
+			return
+		}
+
 	default:
 		respondValidationError(c, fmt.Sprintf("不支持的视频源类型: %s", sourceType))
 		return
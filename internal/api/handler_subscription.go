@@ -20,14 +20,14 @@ type SubscribeRequest struct {
 // handleGetMyFavorites 获取我创建的收藏夹列表
 func (s *Server) handleGetMyFavorites(c *gin.Context) {
 	// 获取当前用户信息
-	userInfo, err := s.biliClient.GetMe()
+	userInfo, err := s.biliClient.GetMe(c.Request.Context())
 	if err != nil {
 		respondError(c, 401, "获取用户信息失败: "+err.Error())
 		return
 	}
 
 	// 获取用户收藏夹列表
-	favorites, err := s.biliClient.GetUserCreatedFavorites(userInfo.Mid)
+	favorites, err := s.biliClient.GetUserCreatedFavorites(c.Request.Context(), userInfo.Mid)
 	if err != nil {
 		respondInternalError(c, fmt.Errorf("获取收藏夹列表失败: %w", err))
 		return
@@ -61,7 +61,7 @@ func (s *Server) handleGetMyFavorites(c *gin.Context) {
 // handleGetMyFollowings 获取我关注的UP主列表
 func (s *Server) handleGetMyFollowings(c *gin.Context) {
 	// 获取当前用户信息
-	userInfo, err := s.biliClient.GetMe()
+	userInfo, err := s.biliClient.GetMe(c.Request.Context())
 	if err != nil {
 		respondError(c, 401, "获取用户信息失败: "+err.Error())
 		return
@@ -77,9 +77,9 @@ func (s *Server) handleGetMyFollowings(c *gin.Context) {
 
 	if name != "" {
 		// 使用B站搜索API
-		followings, total, err = s.biliClient.SearchFollowings(userInfo.Mid, name, pn, ps)
+		followings, total, err = s.biliClient.SearchFollowings(c.Request.Context(), userInfo.Mid, name, pn, ps)
 	} else {
-		followings, total, err = s.biliClient.GetUserFollowings(userInfo.Mid, pn, ps)
+		followings, total, err = s.biliClient.GetUserFollowings(c.Request.Context(), userInfo.Mid, pn, ps)
 	}
 	if err != nil {
 		respondInternalError(c, fmt.Errorf("获取关注列表失败: %w", err))
@@ -132,7 +132,7 @@ func (s *Server) handleSubscribeFavorite(c *gin.Context) {
 	}
 
 	// 获取收藏夹信息
-	favInfo, err := s.biliClient.GetFavoriteInfo(strconv.FormatInt(req.ID, 10))
+	favInfo, err := s.biliClient.GetFavoriteInfo(c.Request.Context(), strconv.FormatInt(req.ID, 10))
 	if err != nil {
 		respondInternalError(c, fmt.Errorf("获取收藏夹信息失败: %w", err))
 		return
@@ -179,7 +179,7 @@ func (s *Server) handleSubscribeUpper(c *gin.Context) {
 	}
 
 	// 获取UP主信息
-	upperInfo, err := s.biliClient.GetUpperInfo(req.ID)
+	upperInfo, err := s.biliClient.GetUpperInfo(c.Request.Context(), req.ID)
 	if err != nil {
 		respondInternalError(c, fmt.Errorf("获取UP主信息失败: %w", err))
 		return
@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
 	"bili-download/internal/scheduler"
 	"bili-download/internal/utils"
 
@@ -186,3 +189,63 @@ func (s *Server) handleCredentialInvalidEvent(event scheduler.Event) {
 	// 节流：同 key 至少间隔 6 小时再次通过 Telegram 提醒
 	s.notifyTelegramAdmins(text, "bili_credential_invalid", 6*time.Hour)
 }
+
+// maxLostVideosInMessage Telegram 通知中最多列出的失效视频数
+const maxLostVideosInMessage = 10
+
+// handleVideosLostEvent 处理调度器上报的视频从视频源消失事件，通过 Telegram 通知管理员
+func (s *Server) handleVideosLostEvent(event scheduler.Event) {
+	dataMap, _ := event.Data.(map[string]interface{})
+	if dataMap == nil {
+		return
+	}
+	lost, _ := dataMap["videos"].([]scheduler.LostVideo)
+	if len(lost) == 0 {
+		return
+	}
+	sourceName, _ := dataMap["source_name"].(string)
+	s.notifyTelegramAdmins(formatLostVideosMessage(sourceName, lost), "", 0)
+}
+
+// formatLostVideosMessage 生成视频从视频源消失的通知文本
+func formatLostVideosMessage(sourceName string, lost []scheduler.LostVideo) string {
+	saved := 0
+	for _, v := range lost {
+		if v.Downloaded {
+			saved++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 video-sync：视频源「%s」有 %d 个视频已从视频源消失，其中 %d 个已保存到本地。\n", sourceName, len(lost), saved)
+	for i, v := range lost {
+		if i >= maxLostVideosInMessage {
+			fmt.Fprintf(&b, "\n…… 另有 %d 个，请在后台视频列表中筛选「已从源消失」查看", len(lost)-maxLostVideosInMessage)
+			break
+		}
+		status := "未下载"
+		if v.Downloaded {
+			status = "已保存"
+		}
+		fmt.Fprintf(&b, "\n• %s（%s）：%s，%s", v.Name, v.BVid, models.LostReasonLabel(v.Reason), status)
+	}
+	return b.String()
+}
+
+// handleRiskControlCooldown 处理 B 站接口风控冷却状态变化：进入冷却时推送告警，恢复后清除
+func (s *Server) handleRiskControlCooldown(state bilibili.CooldownState) {
+	if !state.Active {
+		s.clearAlert("bili_risk_control")
+		return
+	}
+
+	s.pushAlert(SystemAlert{
+		Key:      "bili_risk_control",
+		Type:     "risk_control",
+		Title:    "B 站接口触发风控",
+		Message:  fmt.Sprintf("请求被 B 站拦截（错误码 %d），已暂停请求至 %s，期间同步与下载会自动等待。", state.Code, state.Until.Format("15:04:05")),
+		Severity: "warning",
+		Action:   "/config?tab=advanced",
+		Data:     state,
+	})
+}
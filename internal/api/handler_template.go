@@ -0,0 +1,135 @@
+package api
+
+import (
+	"path/filepath"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+	"bili-download/internal/downloader"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templatePreviewLimit 预览时最多取用的已入库视频数量
+const templatePreviewLimit = 3
+
+// TemplatePreviewRequest 命名模板预览请求，字段为空时使用当前配置
+type TemplatePreviewRequest struct {
+	VideoName  string `json:"video_name"`
+	PageName   string `json:"page_name"`
+	TimeFormat string `json:"time_format"`
+}
+
+// TemplatePreviewItem 单个视频的命名预览
+type TemplatePreviewItem struct {
+	BVid   string   `json:"bvid"`
+	Title  string   `json:"title"`
+	Sample bool     `json:"sample"` // 是否为内置示例视频
+	Folder string   `json:"folder"` // 视频目录（相对于视频源目录）
+	Files  []string `json:"files"`  // 各分P视频文件路径（相对于视频源目录）
+}
+
+// handleTemplatePreview 按命名模板渲染示例路径
+func (s *Server) handleTemplatePreview(c *gin.Context) {
+	var req TemplatePreviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err.Error())
+			return
+		}
+	}
+
+	cfg := *s.config
+	if req.VideoName != "" {
+		cfg.Template.VideoName = req.VideoName
+	}
+	if req.PageName != "" {
+		cfg.Template.PageName = req.PageName
+	}
+	if req.TimeFormat != "" {
+		cfg.Template.TimeFormat = req.TimeFormat
+	}
+	if err := cfg.Template.Validate(); err != nil {
+		respondValidationError(c, err.Error())
+		return
+	}
+
+	videos := make([]models.Video, 0, templatePreviewLimit)
+	if s.db != nil {
+		s.db.Preload("Pages").
+			Where("media_kind = ?", "video").
+			Order("id DESC").
+			Limit(templatePreviewLimit).
+			Find(&videos)
+	}
+
+	items := make([]TemplatePreviewItem, 0, len(videos)+2)
+	for i := range videos {
+		items = append(items, s.renderTemplatePreview(&cfg, &videos[i], false))
+	}
+	for _, sample := range templatePreviewSamples() {
+		items = append(items, s.renderTemplatePreview(&cfg, &sample, true))
+	}
+
+	respondSuccess(c, gin.H{
+		"template": cfg.Template,
+		"items":    items,
+	})
+}
+
+// renderTemplatePreview 渲染单个视频的目录与分P文件名
+func (s *Server) renderTemplatePreview(cfg *config.Config, video *models.Video, sample bool) TemplatePreviewItem {
+	sourceName := "示例收藏夹"
+	if !sample {
+		sourceName = downloader.LookupSourceName(s.db, video)
+	}
+
+	folder := downloader.VideoFolderName(cfg, video, sourceName)
+	item := TemplatePreviewItem{
+		BVid:   video.BVid,
+		Title:  video.Name,
+		Sample: sample,
+		Folder: filepath.ToSlash(folder),
+		Files:  make([]string, 0, len(video.Pages)),
+	}
+	for i := range video.Pages {
+		baseName := downloader.PageFileBaseName(cfg, video, &video.Pages[i], sourceName)
+		item.Files = append(item.Files, filepath.ToSlash(filepath.Join(folder, baseName+".mp4")))
+	}
+	return item
+}
+
+// templatePreviewSamples 内置示例视频：单P与多P各一个
+func templatePreviewSamples() []models.Video {
+	pubTime := time.Date(2024, 5, 20, 20, 0, 0, 0, time.Local)
+	favTime := time.Date(2024, 6, 1, 9, 30, 0, 0, time.Local)
+	favoriteID := uint(1)
+
+	return []models.Video{
+		{
+			BVid:       "BV1xx411c7mD",
+			Name:       "示例视频",
+			UpperID:    12345,
+			UpperName:  "示例UP主",
+			PubTime:    pubTime,
+			FavTime:    favTime,
+			CTime:      pubTime,
+			SinglePage: true,
+			FavoriteID: &favoriteID,
+			Pages:      []models.Page{{PID: 1, Name: "示例视频"}},
+		},
+		{
+			BVid:       "BV1yy411c7mE",
+			Name:       "示例多P视频",
+			UpperID:    12345,
+			UpperName:  "示例UP主",
+			PubTime:    pubTime,
+			FavTime:    favTime,
+			CTime:      pubTime,
+			SinglePage: false,
+			FavoriteID: &favoriteID,
+			Pages:      []models.Page{{PID: 1, Name: "第一集"}, {PID: 2, Name: "第二集"}},
+		},
+	}
+}
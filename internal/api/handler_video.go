@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"bili-download/internal/database/models"
+	"bili-download/internal/downloader"
 	"bili-download/internal/service"
 	"bili-download/internal/utils"
 
@@ -53,6 +54,8 @@ func (s *Server) handleListVideos(c *gin.Context) {
 				query = query.Where("collection_id = ?", sourceID)
 			case "submission":
 				query = query.Where("submission_id = ?", sourceID)
+			case "bangumi":
+				query = query.Where("bangumi_id = ?", sourceID)
 			}
 		} else {
 			// 如果只提供了 source_type，过滤该类型的所有视频
@@ -65,9 +68,11 @@ func (s *Server) handleListVideos(c *gin.Context) {
 				query = query.Where("collection_id IS NOT NULL")
 			case "submission":
 				query = query.Where("submission_id IS NOT NULL")
+			case "bangumi":
+				query = query.Where("bangumi_id IS NOT NULL")
 			case "url":
 				// URL 下载的视频：没有任何视频源关联
-				query = query.Where("favorite_id IS NULL AND watch_later_id IS NULL AND collection_id IS NULL AND submission_id IS NULL")
+				query = query.Where("favorite_id IS NULL AND watch_later_id IS NULL AND collection_id IS NULL AND submission_id IS NULL AND bangumi_id IS NULL")
 			}
 		}
 	}
@@ -92,6 +97,43 @@ func (s *Server) handleListVideos(c *gin.Context) {
 		query = query.Where("id IN (SELECT video_id FROM page WHERE orientation = ?)", models.OrientationPortrait)
 	}
 
+	// 按视频编码过滤（如 avc/hevc/av1，unknown 表示已下载但尚未探测编码）
+	if codec := c.Query("video_codec"); codec != "" {
+		if codec == "unknown" {
+			query = query.Where("id IN (SELECT video_id FROM page WHERE (download_status & ?) <> 0 AND video_codec = '')", models.PageStatusVideo)
+		} else {
+			query = query.Where("id IN (SELECT video_id FROM page WHERE video_codec = ?)", models.NormalizeVideoCodec(codec))
+		}
+	}
+
+	// 按动态范围过滤：hdr（HDR10/HLG）、dolby_vision、sdr
+	switch c.Query("dynamic_range") {
+	case "hdr":
+		query = query.Where("id IN (SELECT video_id FROM page WHERE hdr_format <> '')")
+	case "dolby_vision":
+		query = query.Where("id IN (SELECT video_id FROM page WHERE dolby_vision = ?)", true)
+	case "sdr":
+		query = query.Where("id IN (SELECT video_id FROM page WHERE video_codec <> '' AND hdr_format = '' AND dolby_vision = ?)", false)
+	}
+
+	// 按音频编码过滤（如 aac/flac/eac3）
+	if audioCodec := c.Query("audio_codec"); audioCodec != "" {
+		query = query.Where("id IN (SELECT video_id FROM page WHERE audio_codec = ?)", strings.ToLower(audioCodec))
+	}
+
+	// 按音频类型过滤（standard/dolby/dolby_atmos/hires）
+	if audioType := c.Query("audio_type"); audioType != "" {
+		query = query.Where("id IN (SELECT video_id FROM page WHERE audio_type = ?)", strings.ToLower(audioType))
+	}
+
+	// 按是否已从视频源消失过滤（lost=true 查看B站已删除、不可见或移出视频源的存档视频）
+	switch c.Query("lost") {
+	case "true":
+		query = query.Where("valid = ?", false)
+	case "false":
+		query = query.Where("valid = ?", true)
+	}
+
 	// 获取总数
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -108,6 +150,7 @@ func (s *Server) handleListVideos(c *gin.Context) {
 		"created_at": "created_at",
 		"pubtime":    "pubtime",
 		"view_count": "view_count",
+		"lost_at":    "lost_at",
 	}
 	sortColumn, ok := allowedSortFields[sortBy]
 	if !ok {
@@ -140,11 +183,16 @@ func (s *Server) handleListVideos(c *gin.Context) {
 		s.convertVideoCoverPathToURL(&videos[i])
 	}
 
-	// 查询每个视频的最高画质并拼装到响应
+	// 查询每个视频的最高画质与编码信息并拼装到响应
 	type videoListItem struct {
 		models.Video
-		MaxQuality      int8   `json:"max_quality"`
-		MaxQualityLabel string `json:"max_quality_label"`
+		MaxQuality      int8     `json:"max_quality"`
+		MaxQualityLabel string   `json:"max_quality_label"`
+		VideoCodecs     []string `json:"video_codecs"` // 各分P的视频编码（去重）
+		HDRFormat       string   `json:"hdr_format"`   // 任一分P的 HDR 格式
+		DolbyVision     bool     `json:"dolby_vision"` // 任一分P为杜比视界
+		AudioCodecs     []string `json:"audio_codecs"` // 各分P的音频编码（去重）
+		AudioTypes      []string `json:"audio_types"`  // 各分P的音频类型（去重）
 	}
 
 	items := make([]videoListItem, 0, len(videos))
@@ -167,13 +215,44 @@ func (s *Server) handleListVideos(c *gin.Context) {
 		for _, r := range rows {
 			maxMap[r.VideoID] = r.MaxQuality
 		}
+
+		var streamRows []models.Page
+		s.db.Model(&models.Page{}).
+			Select("video_id, video_codec, hdr_format, dolby_vision, audio_codec, audio_type").
+			Where("video_id IN ? AND video_codec <> ''", videoIDs).
+			Find(&streamRows)
+		streamMap := make(map[uint]*videoListItem, len(streamRows))
+		for _, p := range streamRows {
+			// 仅借用编码相关字段汇总各分P的流信息
+			info := streamMap[p.VideoID]
+			if info == nil {
+				info = &videoListItem{}
+				streamMap[p.VideoID] = info
+			}
+			info.VideoCodecs = appendUniqueString(info.VideoCodecs, p.VideoCodec)
+			info.AudioCodecs = appendUniqueString(info.AudioCodecs, p.AudioCodec)
+			info.AudioTypes = appendUniqueString(info.AudioTypes, p.AudioType)
+			if info.HDRFormat == "" {
+				info.HDRFormat = p.HDRFormat
+			}
+			info.DolbyVision = info.DolbyVision || p.DolbyVision
+		}
+
 		for i := range videos {
 			q := maxMap[videos[i].ID]
-			items = append(items, videoListItem{
+			item := videoListItem{
 				Video:           videos[i],
 				MaxQuality:      q,
 				MaxQualityLabel: models.QualityLabel(q),
-			})
+			}
+			if info := streamMap[videos[i].ID]; info != nil {
+				item.VideoCodecs = info.VideoCodecs
+				item.HDRFormat = info.HDRFormat
+				item.DolbyVision = info.DolbyVision
+				item.AudioCodecs = info.AudioCodecs
+				item.AudioTypes = info.AudioTypes
+			}
+			items = append(items, item)
 		}
 	}
 
@@ -258,26 +337,32 @@ func (s *Server) handleDeleteVideo(c *gin.Context) {
 		return
 	}
 
+	if _, err := s.removeVideo(&video); err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondSuccess(c, gin.H{
+		"message": "删除成功",
+	})
+}
+
+// removeVideo 删除视频的本地文件、下载记录与视频记录
+// 本地文件删除失败时仍删除数据库记录，错误通过 fileErr 返回
+func (s *Server) removeVideo(video *models.Video) (fileErr error, err error) {
 	// 删除本地文件
-	if err := s.deleteLocalFiles(&video); err != nil {
-		utils.Warn("删除本地文件失败: %v", err)
+	if fileErr = s.deleteLocalFiles(video); fileErr != nil {
+		utils.Warn("删除本地文件失败: %v", fileErr)
 		// 继续删除数据库记录，即使文件删除失败
 	}
 
 	// 删除关联的下载记录
-	if err := s.db.Where("video_id = ?", id).Delete(&models.DownloadRecord{}).Error; err != nil {
+	if err := s.db.Where("video_id = ?", video.ID).Delete(&models.DownloadRecord{}).Error; err != nil {
 		utils.Warn("删除下载记录失败: %v", err)
 	}
 
 	// 删除视频（会级联删除相关的分P）
-	if err := s.db.Delete(&models.Video{}, id).Error; err != nil {
-		respondInternalError(c, err)
-		return
-	}
-
-	respondSuccess(c, gin.H{
-		"message": "删除成功",
-	})
+	return fileErr, s.db.Delete(&models.Video{}, video.ID).Error
 }
 
 // handleDownloadVideo 下载视频
@@ -450,7 +535,11 @@ func (s *Server) findLocalVideoPoster(downloadDir string, video *models.Video) s
 	// 可能的图片扩展名
 	extensions := []string{".jpg", ".jpeg", ".png", ".webp", ".gif"}
 
-	videoName := utils.Filenamify(video.Name)
+	var firstPage *models.Page
+	if len(video.Pages) > 0 {
+		firstPage = &video.Pages[0]
+	}
+	baseName := s.pageFileBaseName(video, firstPage)
 
 	// 使用video.Path作为视频文件夹路径（如果存在）
 	// video.Path已经是完整的视频文件夹路径（例如：D:/Downloads/waasd/视频名 或 D:/Downloads/waasd/收藏夹/rrrrrrry_yang/视频名）
@@ -459,12 +548,12 @@ func (s *Server) findLocalVideoPoster(downloadDir string, video *models.Video) s
 		videoFolder = video.Path
 	} else {
 		// 如果Path为空（旧数据），使用旧的逻辑
-		videoFolder = filepath.Join(downloadDir, videoName)
+		videoFolder = filepath.Join(downloadDir, utils.Filenamify(video.Name))
 	}
 
-	// 单P视频封面格式：{video_name}-poster.ext
+	// 单P视频封面格式：{分P文件名}-poster.ext
 	for _, ext := range extensions {
-		posterFile := videoName + "-poster" + ext
+		posterFile := baseName + "-poster" + ext
 
 		// 检查文件是否存在（在视频文件夹内）
 		fullPath := filepath.Join(videoFolder, posterFile)
@@ -489,8 +578,7 @@ func (s *Server) findLocalPoster(downloadDir string, video *models.Video, page *
 	// 可能的图片扩展名
 	extensions := []string{".jpg", ".jpeg", ".png", ".webp", ".gif"}
 
-	videoName := utils.Filenamify(video.Name)
-	pageName := utils.Filenamify(page.Name)
+	baseName := s.pageFileBaseName(video, page)
 
 	// 使用video.Path作为视频文件夹路径（如果存在）
 	// video.Path已经是完整的视频文件夹路径
@@ -499,20 +587,12 @@ func (s *Server) findLocalPoster(downloadDir string, video *models.Video, page *
 		videoFolder = video.Path
 	} else {
 		// 如果Path为空（旧数据），使用旧的逻辑
-		videoFolder = filepath.Join(downloadDir, videoName)
+		videoFolder = filepath.Join(downloadDir, utils.Filenamify(video.Name))
 	}
 
-	var posterFile string
-
-	// 根据是否为单P视频构建不同的文件名
+	// 封面格式：{分P文件名}-poster.ext
 	for _, ext := range extensions {
-		if video.SinglePage {
-			// 单P视频：{video_name}-poster.ext
-			posterFile = videoName + "-poster" + ext
-		} else {
-			// 多P视频：{video_name}-{ptitle}-poster.ext
-			posterFile = videoName + "-" + pageName + "-poster" + ext
-		}
+		posterFile := baseName + "-poster" + ext
 
 		// 检查文件是否存在（在视频文件夹内）
 		fullPath := filepath.Join(videoFolder, posterFile)
@@ -532,6 +612,11 @@ func (s *Server) findLocalPoster(downloadDir string, video *models.Video, page *
 	return ""
 }
 
+// pageFileBaseName 按当前命名模板计算分P文件（视频、封面、NFO 等）的文件名前缀
+func (s *Server) pageFileBaseName(video *models.Video, page *models.Page) string {
+	return downloader.PageFileBaseName(s.config, video, page, downloader.LookupSourceName(s.db, video))
+}
+
 // fileExists 检查文件是否存在
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
@@ -543,6 +628,11 @@ func fileExists(path string) bool {
 
 // deleteLocalFiles 删除视频相关的本地文件
 func (s *Server) deleteLocalFiles(video *models.Video) error {
+	// 番剧剧集与同季其他剧集共用季目录，只删除本集的文件
+	if video.IsEpisode() {
+		return s.deleteEpisodeFiles(video)
+	}
+
 	// 1) 优先按 video.Path（真实下载目录，gallery / ytdlp / 普通视频均会写入）
 	if p := strings.TrimSpace(video.Path); p != "" {
 		if !filepath.IsAbs(p) {
@@ -593,6 +683,42 @@ func (s *Server) deleteLocalFiles(video *models.Video) error {
 	return nil
 }
 
+// deleteEpisodeFiles 删除番剧剧集在季目录中的文件（视频、NFO、封面、字幕、弹幕），保留季目录
+func (s *Server) deleteEpisodeFiles(video *models.Video) error {
+	dir := strings.TrimSpace(video.Path)
+	if dir == "" {
+		return nil
+	}
+	dirs := []string{dir}
+	if !filepath.IsAbs(dir) {
+		dirs = dirs[:0]
+		for _, base := range s.downloadBases() {
+			dirs = append(dirs, filepath.Join(base, dir))
+		}
+	}
+
+	prefix := downloader.EpisodeFileBaseName(video)
+	for _, candidate := range dirs {
+		entries, err := os.ReadDir(candidate)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(candidate, entry.Name())); err != nil {
+				return fmt.Errorf("删除剧集文件失败: %w", err)
+			}
+		}
+		utils.Info("已删除剧集文件: %s/%s*", candidate, prefix)
+		return nil
+	}
+
+	utils.Info("剧集所在目录不存在: %s", video.Path)
+	return nil
+}
+
 // handleImageProxy 图片代理接口，用于解决B站防盗链问题
 func (s *Server) handleImageProxy(c *gin.Context) {
 	// 获取图片URL
@@ -0,0 +1,487 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retentionRunning 保留策略清理是否正在执行（同步完成后自动触发与手动执行互斥）
+var retentionRunning atomic.Bool
+
+// retentionSourceColumns 视频源类型对应的 video 表外键列
+var retentionSourceColumns = map[string]string{
+	"favorite":    "favorite_id",
+	"watch_later": "watch_later_id",
+	"collection":  "collection_id",
+	"submission":  "submission_id",
+	"bangumi":     "bangumi_id",
+}
+
+// retentionSource 设置了保留策略的视频源
+type retentionSource struct {
+	Type  string
+	ID    uint
+	Name  string
+	Mode  string
+	Value int
+}
+
+// retentionVideo 保留策略判定所需的视频字段
+type retentionVideo struct {
+	ID             uint
+	BVid           string
+	Name           string
+	FavTime        time.Time
+	CreatedAt      time.Time
+	Valid          bool
+	LostReason     string
+	Keep           bool
+	DownloadStatus int
+	Busy           bool // 有等待中或下载中的下载记录
+}
+
+// RetentionCandidate 按保留策略将被清理的视频
+type RetentionCandidate struct {
+	ID         uint   `json:"id"`
+	BVid       string `json:"bvid"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`
+	Downloaded bool   `json:"downloaded"`
+}
+
+// RetentionResult 单个视频源的保留策略执行结果
+type RetentionResult struct {
+	SourceType string               `json:"source_type"`
+	SourceID   uint                 `json:"source_id"`
+	SourceName string               `json:"source_name"`
+	Mode       string               `json:"mode"`
+	Value      int                  `json:"value"`
+	DryRun     bool                 `json:"dry_run"`
+	Total      int                  `json:"total"`      // 视频源当前的视频数
+	Kept       int                  `json:"kept"`       // 用户标记保留而跳过的视频数
+	Busy       int                  `json:"busy"`       // 正在下载而推迟清理的视频数
+	Candidates []RetentionCandidate `json:"candidates"` // 将被（或已被）清理的视频
+	Pruned     int                  `json:"pruned"`
+	Failed     int                  `json:"failed"`
+}
+
+// validateRetentionPolicy 校验保留策略，mode 为空表示不清理
+func validateRetentionPolicy(mode string, value int) error {
+	switch mode {
+	case "", models.RetentionModeMirror:
+		return nil
+	case models.RetentionModeKeepLast:
+		if value < 1 {
+			return fmt.Errorf("保留数量必须大于 0")
+		}
+		return nil
+	case models.RetentionModeMaxAge:
+		if value < 1 {
+			return fmt.Errorf("保留天数必须大于 0")
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的保留策略: %s", mode)
+	}
+}
+
+// selectRetentionCandidates 按保留策略挑选需要清理的视频
+// 用户标记保留的视频不参与 keep_last 排名也不会被清理；正在下载的视频推迟到下次清理
+func selectRetentionCandidates(mode string, value int, videos []retentionVideo, now time.Time) (candidates []RetentionCandidate, kept, busy int) {
+	ranked := make([]retentionVideo, 0, len(videos))
+	for _, v := range videos {
+		if v.Keep {
+			kept++
+			continue
+		}
+		ranked = append(ranked, v)
+	}
+
+	var expired []retentionVideo
+	var reason func(v retentionVideo) string
+	switch mode {
+	case models.RetentionModeKeepLast:
+		// 按加入视频源的时间倒序，保留最新的 value 个
+		sort.SliceStable(ranked, func(i, j int) bool {
+			ti, tj := retentionAddedAt(ranked[i]), retentionAddedAt(ranked[j])
+			if !ti.Equal(tj) {
+				return ti.After(tj)
+			}
+			return ranked[i].ID > ranked[j].ID
+		})
+		if len(ranked) > value {
+			expired = ranked[value:]
+		}
+		reason = func(retentionVideo) string { return fmt.Sprintf("超出保留数量 %d", value) }
+	case models.RetentionModeMaxAge:
+		cutoff := now.AddDate(0, 0, -value)
+		for _, v := range ranked {
+			if retentionAddedAt(v).Before(cutoff) {
+				expired = append(expired, v)
+			}
+		}
+		reason = func(retentionVideo) string { return fmt.Sprintf("加入视频源超过 %d 天", value) }
+	case models.RetentionModeMirror:
+		for _, v := range ranked {
+			if !v.Valid {
+				expired = append(expired, v)
+			}
+		}
+		reason = func(v retentionVideo) string {
+			if label := models.LostReasonLabel(v.LostReason); label != "" {
+				return "已从视频源消失：" + label
+			}
+			return "已从视频源消失"
+		}
+	default:
+		return nil, kept, 0
+	}
+
+	for _, v := range expired {
+		if v.Busy {
+			busy++
+			continue
+		}
+		candidates = append(candidates, RetentionCandidate{
+			ID:         v.ID,
+			BVid:       v.BVid,
+			Name:       v.Name,
+			Reason:     reason(v),
+			Downloaded: v.DownloadStatus != 0,
+		})
+	}
+	return candidates, kept, busy
+}
+
+// retentionAddedAt 视频加入视频源的时间，缺失时使用入库时间
+func retentionAddedAt(v retentionVideo) time.Time {
+	if v.FavTime.IsZero() || v.FavTime.Unix() <= 0 {
+		return v.CreatedAt
+	}
+	return v.FavTime
+}
+
+// loadRetentionSource 加载单个视频源的保留策略
+func (s *Server) loadRetentionSource(sourceType string, id uint) (*retentionSource, error) {
+	src := &retentionSource{Type: sourceType, ID: id}
+	var err error
+	switch sourceType {
+	case "favorite":
+		var fav models.Favorite
+		err = s.db.First(&fav, id).Error
+		src.Name, src.Mode, src.Value = fav.Name, fav.RetentionMode, fav.RetentionValue
+	case "watch_later":
+		var wl models.WatchLater
+		err = s.db.First(&wl, id).Error
+		src.Name, src.Mode, src.Value = wl.Name, wl.RetentionMode, wl.RetentionValue
+	case "collection":
+		var col models.Collection
+		err = s.db.First(&col, id).Error
+		src.Name, src.Mode, src.Value = col.Name, col.RetentionMode, col.RetentionValue
+	case "submission":
+		var sub models.Submission
+		err = s.db.First(&sub, id).Error
+		src.Name, src.Mode, src.Value = sub.Name, sub.RetentionMode, sub.RetentionValue
+	case "bangumi":
+		var bgm models.Bangumi
+		err = s.db.First(&bgm, id).Error
+		src.Name, src.Mode, src.Value = bgm.Name, bgm.RetentionMode, bgm.RetentionValue
+	default:
+		return nil, fmt.Errorf("不支持的视频源类型: %s", sourceType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// loadRetentionSources 加载所有启用且设置了保留策略的视频源
+func (s *Server) loadRetentionSources() ([]retentionSource, error) {
+	sources := make([]retentionSource, 0)
+	where := "enabled = ? AND retention_mode <> ''"
+
+	var favorites []models.Favorite
+	if err := s.db.Where(where, true).Find(&favorites).Error; err != nil {
+		return nil, err
+	}
+	for _, fav := range favorites {
+		sources = append(sources, retentionSource{"favorite", fav.ID, fav.Name, fav.RetentionMode, fav.RetentionValue})
+	}
+
+	var watchLaters []models.WatchLater
+	if err := s.db.Where(where, true).Find(&watchLaters).Error; err != nil {
+		return nil, err
+	}
+	for _, wl := range watchLaters {
+		sources = append(sources, retentionSource{"watch_later", wl.ID, wl.Name, wl.RetentionMode, wl.RetentionValue})
+	}
+
+	var collections []models.Collection
+	if err := s.db.Where(where, true).Find(&collections).Error; err != nil {
+		return nil, err
+	}
+	for _, col := range collections {
+		sources = append(sources, retentionSource{"collection", col.ID, col.Name, col.RetentionMode, col.RetentionValue})
+	}
+
+	var submissions []models.Submission
+	if err := s.db.Where(where, true).Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	for _, sub := range submissions {
+		sources = append(sources, retentionSource{"submission", sub.ID, sub.Name, sub.RetentionMode, sub.RetentionValue})
+	}
+
+	var bangumis []models.Bangumi
+	if err := s.db.Where(where, true).Find(&bangumis).Error; err != nil {
+		return nil, err
+	}
+	for _, bgm := range bangumis {
+		sources = append(sources, retentionSource{"bangumi", bgm.ID, bgm.Name, bgm.RetentionMode, bgm.RetentionValue})
+	}
+
+	return sources, nil
+}
+
+// applyRetention 对单个视频源执行保留策略，dryRun 时只返回将被清理的视频
+func (s *Server) applyRetention(src retentionSource, dryRun bool) (*RetentionResult, error) {
+	column, ok := retentionSourceColumns[src.Type]
+	if !ok {
+		return nil, fmt.Errorf("不支持的视频源类型: %s", src.Type)
+	}
+	if err := validateRetentionPolicy(src.Mode, src.Value); err != nil {
+		return nil, err
+	}
+
+	result := &RetentionResult{
+		SourceType: src.Type,
+		SourceID:   src.ID,
+		SourceName: src.Name,
+		Mode:       src.Mode,
+		Value:      src.Value,
+		DryRun:     dryRun,
+		Candidates: make([]RetentionCandidate, 0),
+	}
+	if src.Mode == "" {
+		return result, nil
+	}
+
+	var videos []retentionVideo
+	if err := s.db.Model(&models.Video{}).
+		Select("id, bvid, name, favtime AS fav_time, created_at, valid, lost_reason, keep, download_status").
+		Where(column+" = ?", src.ID).
+		Scan(&videos).Error; err != nil {
+		return nil, fmt.Errorf("加载视频源视频失败: %w", err)
+	}
+	result.Total = len(videos)
+
+	var busyIDs []uint
+	if err := s.db.Model(&models.DownloadRecord{}).
+		Joins("JOIN video ON video.id = download_records.video_id").
+		Where("video."+column+" = ? AND download_records.status IN ?", src.ID, []string{"pending", "downloading"}).
+		Distinct().Pluck("download_records.video_id", &busyIDs).Error; err != nil {
+		return nil, fmt.Errorf("加载下载中的视频失败: %w", err)
+	}
+	busy := make(map[uint]bool, len(busyIDs))
+	for _, id := range busyIDs {
+		busy[id] = true
+	}
+	for i := range videos {
+		videos[i].Busy = busy[videos[i].ID]
+	}
+
+	candidates, kept, busyCount := selectRetentionCandidates(src.Mode, src.Value, videos, time.Now())
+	result.Kept, result.Busy = kept, busyCount
+	if dryRun {
+		result.Candidates = append(result.Candidates, candidates...)
+		return result, nil
+	}
+
+	for _, candidate := range candidates {
+		var video models.Video
+		if err := s.db.Preload("Pages").First(&video, candidate.ID).Error; err != nil {
+			utils.Warn("保留策略清理: 加载视频 %d 失败: %v", candidate.ID, err)
+			result.Failed++
+			continue
+		}
+		// 执行期间用户可能已标记保留
+		if video.Keep {
+			result.Kept++
+			continue
+		}
+
+		fileErr, err := s.removeVideo(&video)
+		if err != nil {
+			utils.Warn("保留策略清理: 删除视频 %s (BV%s) 失败: %v", video.Name, video.BVid, err)
+			result.Failed++
+			continue
+		}
+
+		record := models.PrunedVideo{
+			VideoID:    video.ID,
+			BVid:       video.BVid,
+			Name:       video.Name,
+			SourceType: src.Type,
+			SourceID:   src.ID,
+			SourceName: src.Name,
+			Mode:       src.Mode,
+			Reason:     candidate.Reason,
+			Path:       video.Path,
+			PrunedAt:   time.Now(),
+		}
+		if fileErr != nil {
+			record.FileError = fileErr.Error()
+		}
+		if err := s.db.Create(&record).Error; err != nil {
+			utils.Warn("保留策略清理: 记录已清理视频 %s 失败: %v", video.BVid, err)
+		}
+
+		utils.Info("保留策略清理: 视频源 %s 已清理 %s (BV%s): %s", src.Name, video.Name, video.BVid, candidate.Reason)
+		result.Candidates = append(result.Candidates, candidate)
+		result.Pruned++
+	}
+	return result, nil
+}
+
+// runRetentionAfterSync 同步完成后对所有设置了保留策略的视频源执行清理
+func (s *Server) runRetentionAfterSync(syncID string) {
+	if !retentionRunning.CompareAndSwap(false, true) {
+		utils.Info("保留策略清理正在执行中，跳过同步 %s 后的清理", syncID)
+		return
+	}
+	defer retentionRunning.Store(false)
+
+	sources, err := s.loadRetentionSources()
+	if err != nil {
+		utils.Error("保留策略清理: 加载视频源失败: %v", err)
+		return
+	}
+	for _, src := range sources {
+		result, err := s.applyRetention(src, false)
+		if err != nil {
+			utils.Warn("保留策略清理: 视频源 %s 执行失败: %v", src.Name, err)
+			continue
+		}
+		if result.Pruned > 0 || result.Failed > 0 {
+			utils.Info("保留策略清理: 视频源 %s (%s) 清理 %d 个，失败 %d 个，标记保留 %d 个，下载中推迟 %d 个",
+				src.Name, models.RetentionModeLabel(src.Mode), result.Pruned, result.Failed, result.Kept, result.Busy)
+		}
+	}
+}
+
+// RetentionRunRequest 执行视频源保留策略请求
+type RetentionRunRequest struct {
+	DryRun bool `json:"dry_run"` // 只预览将被清理的视频，不删除
+	// 待试运行的保留策略（可选，仅 dry_run 时生效，不传则使用已保存的策略）
+	RetentionMode  *string `json:"retention_mode"`
+	RetentionValue *int    `json:"retention_value"`
+}
+
+// handleRunSourceRetention 对视频源执行（或试运行）保留策略
+func (s *Server) handleRunSourceRetention(c *gin.Context) {
+	idStr := c.Param("id")
+	sourceType := c.Query("type")
+
+	if sourceType == "" {
+		respondValidationError(c, "缺少 type 参数")
+		return
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		respondValidationError(c, "无效的 ID")
+		return
+	}
+
+	var req RetentionRunRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err.Error())
+			return
+		}
+	}
+
+	src, err := s.loadRetentionSource(sourceType, uint(id))
+	if err != nil {
+		respondNotFound(c, fmt.Sprintf("视频源未找到: %v", err))
+		return
+	}
+	if req.DryRun {
+		if req.RetentionMode != nil {
+			src.Mode = *req.RetentionMode
+		}
+		if req.RetentionValue != nil {
+			src.Value = *req.RetentionValue
+		}
+	}
+	if err := validateRetentionPolicy(src.Mode, src.Value); err != nil {
+		respondValidationError(c, err.Error())
+		return
+	}
+
+	if !req.DryRun {
+		if !retentionRunning.CompareAndSwap(false, true) {
+			respondError(c, 409, "保留策略清理正在执行中，请稍后再试")
+			return
+		}
+		defer retentionRunning.Store(false)
+	}
+
+	result, err := s.applyRetention(*src, req.DryRun)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	respondSuccess(c, result)
+}
+
+// handleListPrunedVideos 获取保留策略清理记录
+func (s *Server) handleListPrunedVideos(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := s.db.Model(&models.PrunedVideo{})
+	if sourceType := c.Query("source_type"); sourceType != "" {
+		query = query.Where("source_type = ?", sourceType)
+	}
+	if sourceID := c.Query("source_id"); sourceID != "" {
+		query = query.Where("source_id = ?", sourceID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	var records []models.PrunedVideo
+	if err := query.Order("pruned_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&records).Error; err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	respondSuccess(c, gin.H{
+		"items":       records,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+	})
+}
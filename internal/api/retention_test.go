@@ -0,0 +1,136 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func TestSelectRetentionCandidatesKeepLast(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	videos := []retentionVideo{
+		{ID: 1, BVid: "BV1", FavTime: now.Add(-4 * time.Hour)},
+		{ID: 2, BVid: "BV2", FavTime: now.Add(-1 * time.Hour)},
+		{ID: 3, BVid: "BV3", FavTime: now.Add(-3 * time.Hour), Keep: true},
+		{ID: 4, BVid: "BV4", FavTime: now.Add(-2 * time.Hour), DownloadStatus: 1},
+		{ID: 5, BVid: "BV5", FavTime: now.Add(-5 * time.Hour), Busy: true},
+	}
+
+	candidates, kept, busy := selectRetentionCandidates(models.RetentionModeKeepLast, 2, videos, now)
+	if kept != 1 || busy != 1 {
+		t.Fatalf("kept=%d busy=%d, want 1 and 1", kept, busy)
+	}
+	// 标记保留的 BV3 不占保留名额，BV2、BV4 为最新的两个，BV5 下载中推迟
+	if len(candidates) != 1 || candidates[0].BVid != "BV1" {
+		t.Fatalf("candidates = %+v, want only BV1", candidates)
+	}
+}
+
+func TestSelectRetentionCandidatesMaxAge(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	videos := []retentionVideo{
+		{ID: 1, BVid: "BV1", FavTime: now.AddDate(0, 0, -10)},
+		{ID: 2, BVid: "BV2", FavTime: now.AddDate(0, 0, -2)},
+		{ID: 3, BVid: "BV3", FavTime: time.Unix(0, 0), CreatedAt: now.AddDate(0, 0, -8)},
+		{ID: 4, BVid: "BV4", FavTime: now.AddDate(0, 0, -30), Keep: true},
+	}
+
+	candidates, kept, _ := selectRetentionCandidates(models.RetentionModeMaxAge, 7, videos, now)
+	if kept != 1 {
+		t.Fatalf("kept = %d, want 1", kept)
+	}
+	got := map[string]bool{}
+	for _, c := range candidates {
+		got[c.BVid] = true
+	}
+	if len(candidates) != 2 || !got["BV1"] || !got["BV3"] {
+		t.Fatalf("candidates = %+v, want BV1 and BV3", candidates)
+	}
+}
+
+func TestSelectRetentionCandidatesMirror(t *testing.T) {
+	videos := []retentionVideo{
+		{ID: 1, BVid: "BV1", Valid: true},
+		{ID: 2, BVid: "BV2", Valid: false, LostReason: models.LostReasonDeleted},
+		{ID: 3, BVid: "BV3", Valid: false, LostReason: models.LostReasonRemoved, Keep: true},
+	}
+
+	candidates, kept, _ := selectRetentionCandidates(models.RetentionModeMirror, 0, videos, time.Now())
+	if kept != 1 || len(candidates) != 1 || candidates[0].BVid != "BV2" {
+		t.Fatalf("candidates = %+v kept = %d, want only BV2", candidates, kept)
+	}
+	if candidates[0].Reason != "已从视频源消失：已删除" {
+		t.Errorf("reason = %q", candidates[0].Reason)
+	}
+
+	if candidates, _, _ := selectRetentionCandidates("", 0, videos, time.Now()); len(candidates) != 0 {
+		t.Errorf("no policy should prune nothing, got %+v", candidates)
+	}
+}
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		mode    string
+		value   int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{models.RetentionModeMirror, 0, false},
+		{models.RetentionModeKeepLast, 20, false},
+		{models.RetentionModeKeepLast, 0, true},
+		{models.RetentionModeMaxAge, -1, true},
+		{"keep_all", 1, true},
+	}
+	for _, tt := range tests {
+		if err := validateRetentionPolicy(tt.mode, tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("validateRetentionPolicy(%q, %d) error = %v, wantErr %v", tt.mode, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDeleteLocalFilesKeepsOtherEpisodes(t *testing.T) {
+	base := t.TempDir()
+	seasonDir := filepath.Join(base, "番剧", "Season 01")
+	if err := os.MkdirAll(seasonDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		"S01E01 - 第1话.mp4", "S01E01 - 第1话.nfo", "S01E01 - 第1话-thumb.jpg",
+		"S01E02 - 第2话.mp4", "season.nfo",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(seasonDir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bangumiID := uint(1)
+	server := &Server{config: &config.Config{Paths: config.PathsConfig{DownloadBase: base}}}
+	video := &models.Video{
+		Name:          "第1话",
+		Path:          filepath.Join("番剧", "Season 01"),
+		BangumiID:     &bangumiID,
+		EpID:          100,
+		SeasonNumber:  1,
+		EpisodeNumber: 1,
+	}
+	if err := server.deleteLocalFiles(video); err != nil {
+		t.Fatalf("deleteLocalFiles: %v", err)
+	}
+
+	entries, err := os.ReadDir(seasonDir)
+	if err != nil {
+		t.Fatalf("season directory should be kept: %v", err)
+	}
+	remaining := map[string]bool{}
+	for _, entry := range entries {
+		remaining[entry.Name()] = true
+	}
+	if len(remaining) != 2 || !remaining["S01E02 - 第2话.mp4"] || !remaining["season.nfo"] {
+		t.Errorf("remaining files = %v", remaining)
+	}
+}
@@ -182,6 +182,7 @@ func (s *Server) setupRouter() {
 			config.GET("", s.handleGetConfig)
 			config.POST("", s.handleUpdateConfig)
 			config.POST("/validate", s.handleValidateConfig)
+			config.POST("/template/preview", s.handleTemplatePreview)
 			config.POST("/validate-credential", s.handleValidateBilibiliCredential)
 		}
 
@@ -204,6 +205,8 @@ func (s *Server) setupRouter() {
 			sources.PUT("/:id", s.handleUpdateSource)
 			sources.DELETE("/:id", s.handleDeleteSource)
 			sources.POST("/:id/scan", s.handleScanSource)
+			sources.POST("/:id/filter-preview", s.handleFilterPreviewSource)
+			sources.POST("/:id/retention", s.handleRunSourceRetention)
 			sources.PUT("/:id/enable", s.handleEnableSource)
 		}
 
@@ -216,9 +219,14 @@ func (s *Server) setupRouter() {
 			videoSources.PUT("/:id", s.handleUpdateSource)
 			videoSources.DELETE("/:id", s.handleDeleteSource)
 			videoSources.POST("/:id/scan", s.handleScanSource)
+			videoSources.POST("/:id/filter-preview", s.handleFilterPreviewSource)
+			videoSources.POST("/:id/retention", s.handleRunSourceRetention)
 			videoSources.PUT("/:id/enable", s.handleEnableSource)
 		}
 
+		// 保留策略清理记录
+		api.GET("/retention/pruned", s.handleListPrunedVideos)
+
 		// 视频管理
 		videos := api.Group("/videos")
 		{
@@ -259,6 +267,8 @@ func (s *Server) setupRouter() {
 			maintenance.POST("/refresh-upper-faces", s.handleRefreshUpperFaces)
 			maintenance.POST("/backfill-quality", s.handleBackfillQuality)
 			maintenance.POST("/reparse-page-metadata", s.handleReparsePageMetadata)
+			maintenance.GET("/reorganize/preview", s.handleReorganizePreview)
+			maintenance.POST("/reorganize", s.handleReorganizeLibrary)
 		}
 
 		// 小红书下载
@@ -397,6 +407,22 @@ func (s *Server) Start() error {
 			return
 		}
 
+		// 任务暂停/恢复时同步推送下载记录状态，通用事件仍照常推送
+		if (event.Type == downloader.EventTaskPaused || event.Type == downloader.EventTaskResumed) && event.Task != nil && event.Task.RecordID > 0 {
+			status := "paused"
+			if event.Type == downloader.EventTaskResumed {
+				status = "pending"
+			}
+			s.websocketHub.BroadcastPriority(WebSocketMessage{
+				Type: "download_status",
+				Data: gin.H{
+					"record_id": event.Task.RecordID,
+					"status":    status,
+				},
+				Timestamp: event.Timestamp,
+			})
+		}
+
 		// 非下载记录相关的通用事件推送
 		s.websocketHub.Broadcast(WebSocketMessage{
 			Type:      string(event.Type),
@@ -405,6 +431,11 @@ func (s *Server) Start() error {
 		})
 	})
 
+	// 监听 B 站接口风控冷却状态，推送告警
+	if s.biliClient != nil {
+		s.biliClient.RateLimiter().OnCooldownChange(s.handleRiskControlCooldown)
+	}
+
 	// 监听调度器事件，推送到 WebSocket
 	s.scheduler.OnEvent(func(event scheduler.Event) {
 		// 凭据失效事件：推送告警 + Telegram 通知
@@ -412,6 +443,16 @@ func (s *Server) Start() error {
 			s.handleCredentialInvalidEvent(event)
 			return
 		}
+		// 视频从视频源消失：额外通过 Telegram 通知，WebSocket 照常推送
+		if event.Type == scheduler.EventVideosLost {
+			s.handleVideosLostEvent(event)
+		}
+		// 同步完成：按视频源保留策略清理视频
+		if event.Type == scheduler.EventSyncCompleted {
+			dataMap, _ := event.Data.(map[string]interface{})
+			syncID, _ := dataMap["sync_id"].(string)
+			go s.runRetentionAfterSync(syncID)
+		}
 		s.websocketHub.Broadcast(WebSocketMessage{
 			Type:      string(event.Type),
 			Data:      event.Data,
@@ -0,0 +1,135 @@
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxWaitSlice 单次等待的最长时间，等待期间上限被修改时能尽快按新上限生效
+const maxWaitSlice = 200 * time.Millisecond
+
+// maxReadChunk 限速读取时单次读取的最大字节数，避免一次读取消耗过多令牌造成速度抖动
+const maxReadChunk = 32 * 1024
+
+// Limiter 令牌桶带宽限制器，可被多个下载同时使用并在运行时调整上限
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int64   // 字节/秒，0 表示不限速
+	tokens float64 // 可用令牌（字节），允许为负表示透支
+	last   time.Time
+}
+
+// NewLimiter 创建带宽限制器，limit 为字节/秒，0 表示不限速
+func NewLimiter(limit int64) *Limiter {
+	l := &Limiter{}
+	l.SetLimit(limit)
+	return l
+}
+
+var shared = NewLimiter(0)
+
+// Shared 进程内共享的带宽预算，所有 yt-dlp 进程与原生 HTTP 下载共用
+func Shared() *Limiter {
+	return shared
+}
+
+// SetLimit 调整带宽上限（字节/秒），对正在进行的下载立即生效
+func (l *Limiter) SetLimit(limit int64) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit == limit {
+		return
+	}
+	// 突发量为一秒的额度，从不限速切换为限速时给满突发量
+	if l.limit == 0 || l.tokens > float64(limit) {
+		l.tokens = float64(limit)
+	}
+	l.limit = limit
+	l.last = time.Now()
+}
+
+// Limit 当前带宽上限（字节/秒），0 表示不限速
+func (l *Limiter) Limit() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// WaitN 消耗 n 字节的额度，额度不足时阻塞直到补足或 ctx 取消
+// 有剩余额度时允许一次透支，透支部分由后续调用者等待偿还
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		if l.limit <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.limit)
+		if l.tokens > float64(l.limit) {
+			l.tokens = float64(l.limit)
+		}
+		l.last = now
+
+		if l.tokens > 0 {
+			l.tokens -= float64(n)
+			l.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration(-l.tokens / float64(l.limit) * float64(time.Second))
+		l.mu.Unlock()
+
+		if wait > maxWaitSlice {
+			wait = maxWaitSlice
+		}
+		if wait < time.Millisecond {
+			wait = time.Millisecond
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Reader 包装 io.Reader，读取的数据计入带宽预算
+func (l *Limiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &limitedReader{ctx: ctx, r: r, limiter: l}
+}
+
+// limitedReader 限速读取器
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *Limiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > maxReadChunk && lr.limiter.Limit() > 0 {
+		p = p[:maxReadChunk]
+	}
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		if waitErr := lr.limiter.WaitN(lr.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
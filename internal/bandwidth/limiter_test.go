@@ -0,0 +1,80 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLimiterUnlimitedDoesNotBlock(t *testing.T) {
+	l := NewLimiter(0)
+
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		if err := l.WaitN(context.Background(), 1<<20); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("unlimited limiter blocked for %v", elapsed)
+	}
+}
+
+func TestLimiterThrottlesReader(t *testing.T) {
+	l := NewLimiter(100 * 1024)
+
+	// 首秒额度为突发量，之后约 100KB/s，读取 200KB 应耗时约 1 秒
+	data := bytes.Repeat([]byte("x"), 200*1024)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, l.Reader(context.Background(), bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("expected %d bytes, got %d", len(data), n)
+	}
+	if elapsed := time.Since(start); elapsed < 700*time.Millisecond {
+		t.Fatalf("expected throttled copy to take about 1s, took %v", elapsed)
+	}
+}
+
+func TestLimiterSetLimitAppliesToWaiters(t *testing.T) {
+	l := NewLimiter(1024)
+	// 透支大量额度，按 1KB/s 需要等待很久
+	if err := l.WaitN(context.Background(), 1024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.WaitN(context.Background(), 10*1024*1024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.WaitN(context.Background(), 1)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	l.SetLimit(0)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after removing the limit")
+	}
+}
+
+func TestLimiterWaitHonorsContext(t *testing.T) {
+	l := NewLimiter(1)
+	l.WaitN(context.Background(), 1024)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.WaitN(ctx, 1); err == nil {
+		t.Fatal("expected context error")
+	}
+}
@@ -0,0 +1,280 @@
+package bandwidth
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Proxy 本地限速 HTTP 代理
+// yt-dlp 等外部进程无法在运行中调整 --limit-rate，改为通过该代理访问网络，
+// 下行流量计入共享的带宽预算，上限变化时对已启动的进程立即生效
+type Proxy struct {
+	limiter   *Limiter
+	upstream  atomic.Pointer[url.URL]
+	transport *http.Transport
+	dialer    net.Dialer
+
+	mu       sync.Mutex
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewProxy 创建限速代理
+func NewProxy(limiter *Limiter) *Proxy {
+	p := &Proxy{
+		limiter: limiter,
+		dialer:  net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	p.transport = &http.Transport{
+		Proxy: func(*http.Request) (*url.URL, error) {
+			return p.upstream.Load(), nil
+		},
+		DialContext:         p.dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return p
+}
+
+var (
+	sharedProxy     *Proxy
+	sharedProxyOnce sync.Once
+	sharedProxyErr  error
+)
+
+// SharedProxy 获取使用共享带宽预算的本地代理，首次调用时启动
+func SharedProxy() (*Proxy, error) {
+	sharedProxyOnce.Do(func() {
+		sharedProxy = NewProxy(Shared())
+		sharedProxyErr = sharedProxy.Start()
+	})
+	return sharedProxy, sharedProxyErr
+}
+
+// SetUpstream 设置上游代理（仅支持 http/https），nil 表示直连
+func (p *Proxy) SetUpstream(upstream *url.URL) {
+	p.upstream.Store(upstream)
+}
+
+// Start 在本地回环地址的随机端口上启动代理
+func (p *Proxy) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("启动限速代理失败: %w", err)
+	}
+	p.listener = listener
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+
+	go p.server.Serve(listener)
+	return nil
+}
+
+// URL 代理地址，如 http://127.0.0.1:34567
+func (p *Proxy) URL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return ""
+	}
+	return "http://" + p.listener.Addr().String()
+}
+
+// Close 关闭代理
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.server == nil {
+		return nil
+	}
+	err := p.server.Close()
+	p.server = nil
+	p.listener = nil
+	p.transport.CloseIdleConnections()
+	return err
+}
+
+// ServeHTTP 处理 CONNECT 隧道与普通 HTTP 代理请求
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+	p.handleHTTP(w, r)
+}
+
+// handleConnect 建立 CONNECT 隧道，目标到客户端方向的数据受带宽限制
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	target, err := p.dial(r.Context(), r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		target.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		target.Close()
+		return
+	}
+
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		client.Close()
+		target.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	closeBoth := func() {
+		once.Do(func() {
+			cancel()
+			client.Close()
+			target.Close()
+		})
+	}
+
+	go func() {
+		io.Copy(target, buffered.Reader)
+		closeBoth()
+	}()
+	go func() {
+		io.Copy(client, p.limiter.Reader(ctx, target))
+		closeBoth()
+	}()
+}
+
+// handleHTTP 转发普通 HTTP 请求，响应体受带宽限制
+func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if !r.URL.IsAbs() {
+		http.Error(w, "proxy request must use absolute URL", http.StatusBadRequest)
+		return
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	removeHopHeaders(outReq.Header)
+
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, p.limiter.Reader(r.Context(), resp.Body))
+}
+
+// dial 连接目标地址，配置了上游代理时通过上游代理的 CONNECT 建立隧道
+func (p *Proxy) dial(ctx context.Context, addr string) (net.Conn, error) {
+	upstream := p.upstream.Load()
+	if upstream == nil {
+		return p.dialer.DialContext(ctx, "tcp", addr)
+	}
+
+	upstreamAddr := upstream.Host
+	if upstream.Port() == "" {
+		if upstream.Scheme == "https" {
+			upstreamAddr = net.JoinHostPort(upstream.Hostname(), "443")
+		} else {
+			upstreamAddr = net.JoinHostPort(upstream.Hostname(), "80")
+		}
+	}
+
+	conn, err := p.dialer.DialContext(ctx, "tcp", upstreamAddr)
+	if err != nil {
+		return nil, fmt.Errorf("连接上游代理失败: %w", err)
+	}
+	if upstream.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: upstream.Hostname()})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if upstream.User != nil {
+		password, _ := upstream.User.Password()
+		credential := base64.StdEncoding.EncodeToString([]byte(upstream.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credential)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("发送 CONNECT 请求失败: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("读取上游代理响应失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("上游代理拒绝连接: %s", resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn 读取时优先消费建立隧道时已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// hopHeaders 逐跳头部，不应由代理转发
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, key := range hopHeaders {
+		header.Del(key)
+	}
+}
@@ -0,0 +1,70 @@
+package bandwidth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func startTestProxy(t *testing.T, limiter *Limiter) *url.URL {
+	t.Helper()
+
+	proxy := NewProxy(limiter)
+	if err := proxy.Start(); err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	t.Cleanup(func() { proxy.Close() })
+
+	proxyURL, err := url.Parse(proxy.URL())
+	if err != nil {
+		t.Fatalf("parse proxy url: %v", err)
+	}
+	return proxyURL
+}
+
+func TestProxyForwardsHTTPRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "ok")
+		io.WriteString(w, "hello "+r.URL.Path)
+	}))
+	defer server.Close()
+
+	proxyURL := startTestProxy(t, NewLimiter(0))
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(server.URL + "/video")
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello /video" || resp.Header.Get("X-Test") != "ok" {
+		t.Fatalf("unexpected response: %q %v", body, resp.Header)
+	}
+}
+
+func TestProxyTunnelsConnectRequests(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "secure")
+	}))
+	defer server.Close()
+
+	proxyURL := startTestProxy(t, NewLimiter(1024*1024))
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request through tunnel failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "secure" {
+		t.Fatalf("unexpected response: %q", body)
+	}
+}
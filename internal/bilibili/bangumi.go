@@ -0,0 +1,210 @@
+package bilibili
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 番剧（PGC）相关错误码
+const (
+	CodeBangumiRestricted = -10403 // 地区限制或大会员专享
+)
+
+// BangumiSeason 番剧/影视季度信息（pgc/view/web/season 接口的 result）
+type BangumiSeason struct {
+	SeasonID    int64              `json:"season_id"`    // 季度ID（ss）
+	MediaID     int64              `json:"media_id"`     // 剧集ID（md）
+	Title       string             `json:"title"`        // 完整标题（如 "xxx 第二季"）
+	SeasonTitle string             `json:"season_title"` // 季度标题（如 "第二季"）
+	Cover       string             `json:"cover"`        // 封面
+	Evaluate    string             `json:"evaluate"`     // 简介
+	Type        int                `json:"type"`         // 类型：1-番剧 2-电影 3-纪录片 4-国创 5-电视剧 7-综艺
+	Styles      []string           `json:"styles"`       // 风格标签
+	Publish     BangumiPublish     `json:"publish"`      // 发布状态
+	Series      BangumiSeries      `json:"series"`       // 所属系列
+	UpInfo      BangumiUpInfo      `json:"up_info"`      // 出品方账号
+	Rights      BangumiRights      `json:"rights"`       // 权限信息
+	Seasons     []BangumiSeasonRef `json:"seasons"`      // 同系列的各季
+	Episodes    []BangumiEpisode   `json:"episodes"`     // 正片剧集
+}
+
+// BangumiPublish 番剧发布状态
+type BangumiPublish struct {
+	IsFinish int    `json:"is_finish"` // 是否完结
+	PubTime  string `json:"pub_time"`  // 开播时间
+}
+
+// BangumiSeries 番剧所属系列
+type BangumiSeries struct {
+	SeriesID    int64  `json:"series_id"`
+	SeriesTitle string `json:"series_title"`
+}
+
+// BangumiUpInfo 番剧出品方账号
+type BangumiUpInfo struct {
+	Mid    int64  `json:"mid"`
+	Uname  string `json:"uname"`
+	Avatar string `json:"avatar"`
+}
+
+// BangumiRights 番剧权限信息
+type BangumiRights struct {
+	AreaLimit int `json:"area_limit"` // 是否有地区限制
+}
+
+// BangumiSeasonRef 同系列季度的简要信息
+type BangumiSeasonRef struct {
+	SeasonID    int64  `json:"season_id"`
+	MediaID     int64  `json:"media_id"`
+	SeasonTitle string `json:"season_title"`
+}
+
+// BangumiEpisode 番剧剧集
+type BangumiEpisode struct {
+	ID        int64     `json:"id"`         // 剧集ID（ep）
+	Aid       int64     `json:"aid"`        // avid
+	BVid      string    `json:"bvid"`       // bvid
+	CID       int64     `json:"cid"`        // cid
+	Title     string    `json:"title"`      // 集数标题（如 "1"、"SP"、"正片"）
+	LongTitle string    `json:"long_title"` // 单集标题
+	ShowTitle string    `json:"show_title"` // 展示标题（如 "第1话 xxx"）
+	Cover     string    `json:"cover"`      // 封面
+	Duration  int64     `json:"duration"`   // 时长（毫秒）
+	PubTime   int64     `json:"pub_time"`   // 上线时间（时间戳）
+	Badge     string    `json:"badge"`      // 角标（如 "会员"、"限免"）
+	Dimension Dimension `json:"dimension"`  // 分辨率
+}
+
+// ShowName 系列名称，同一系列的各季共用，用作剧集目录名
+func (s *BangumiSeason) ShowName() string {
+	if title := strings.TrimSpace(s.Series.SeriesTitle); title != "" {
+		return title
+	}
+	return s.Title
+}
+
+// SeasonNumber 当前季在同系列各季中的序号（从 1 开始），无法确定时返回 1
+func (s *BangumiSeason) SeasonNumber() int {
+	for i, ref := range s.Seasons {
+		if ref.SeasonID == s.SeasonID {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// IsFinished 是否已完结
+func (s *BangumiSeason) IsFinished() bool {
+	return s.Publish.IsFinish == 1
+}
+
+// DisplayTitle 剧集展示标题
+func (e *BangumiEpisode) DisplayTitle() string {
+	if e.ShowTitle != "" {
+		return e.ShowTitle
+	}
+	title := e.Title
+	if _, err := strconv.ParseFloat(title, 64); err == nil {
+		title = "第" + title + "话"
+	}
+	if e.LongTitle != "" {
+		return strings.TrimSpace(title + " " + e.LongTitle)
+	}
+	return title
+}
+
+// DurationSeconds 剧集时长（秒）
+func (e *BangumiEpisode) DurationSeconds() int {
+	return int(e.Duration / 1000)
+}
+
+// GetBangumiSeason 获取番剧季度信息，seasonID 与 epID 二选一
+func (c *Client) GetBangumiSeason(ctx context.Context, seasonID, epID int64) (*BangumiSeason, error) {
+	params := url.Values{}
+	if seasonID > 0 {
+		params.Set("season_id", strconv.FormatInt(seasonID, 10))
+	} else if epID > 0 {
+		params.Set("ep_id", strconv.FormatInt(epID, 10))
+	} else {
+		return nil, fmt.Errorf("番剧季度ID与剧集ID不能同时为空")
+	}
+
+	var result struct {
+		Code    int           `json:"code"`
+		Message string        `json:"message"`
+		Result  BangumiSeason `json:"result"`
+	}
+
+	apiURL := "https://api.bilibili.com/pgc/view/web/season?" + params.Encode()
+	if err := c.GetJSON(ctx, apiURL, nil, &result); err != nil {
+		return nil, fmt.Errorf("获取番剧信息失败: %w", err)
+	}
+
+	if result.Code != 0 {
+		if result.Code == CodeBangumiRestricted {
+			return nil, &BiliError{
+				Code:    result.Code,
+				Message: "番剧受地区限制或需要大会员: " + result.Message,
+			}
+		}
+		return nil, &BiliError{
+			Code:    result.Code,
+			Message: result.Message,
+		}
+	}
+
+	return &result.Result, nil
+}
+
+// GetBangumiSeasonIDByMedia 通过剧集ID（md）查询季度ID（ss）
+func (c *Client) GetBangumiSeasonIDByMedia(ctx context.Context, mediaID int64) (int64, error) {
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Result  struct {
+			Media struct {
+				MediaID  int64  `json:"media_id"`
+				SeasonID int64  `json:"season_id"`
+				Title    string `json:"title"`
+			} `json:"media"`
+		} `json:"result"`
+	}
+
+	apiURL := fmt.Sprintf("https://api.bilibili.com/pgc/review/user?media_id=%d", mediaID)
+	if err := c.GetJSON(ctx, apiURL, nil, &result); err != nil {
+		return 0, fmt.Errorf("获取番剧剧集信息失败: %w", err)
+	}
+
+	if result.Code != 0 {
+		return 0, &BiliError{
+			Code:    result.Code,
+			Message: result.Message,
+		}
+	}
+	if result.Result.Media.SeasonID == 0 {
+		return 0, fmt.Errorf("剧集 md%d 没有对应的季度", mediaID)
+	}
+
+	return result.Result.Media.SeasonID, nil
+}
+
+// ResolveBangumiSeason 按解析出的番剧 URL（ss/ep/md）获取季度信息
+func (c *Client) ResolveBangumiSeason(ctx context.Context, parsed *ParsedURL) (*BangumiSeason, error) {
+	switch parsed.SubType {
+	case BangumiSubTypeSeason:
+		return c.GetBangumiSeason(ctx, parsed.ID, 0)
+	case BangumiSubTypeEpisode:
+		return c.GetBangumiSeason(ctx, 0, parsed.ID)
+	case BangumiSubTypeMedia:
+		seasonID, err := c.GetBangumiSeasonIDByMedia(ctx, parsed.ID)
+		if err != nil {
+			return nil, err
+		}
+		return c.GetBangumiSeason(ctx, seasonID, 0)
+	default:
+		return nil, fmt.Errorf("未知的番剧链接类型: %s", parsed.SubType)
+	}
+}
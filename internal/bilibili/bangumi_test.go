@@ -0,0 +1,63 @@
+package bilibili
+
+import "testing"
+
+func TestURLParserBangumi(t *testing.T) {
+	cases := map[string]struct {
+		id      int64
+		subType string
+	}{
+		"https://www.bilibili.com/bangumi/play/ss28747":            {28747, BangumiSubTypeSeason},
+		"https://www.bilibili.com/bangumi/play/ep281340?from=home": {281340, BangumiSubTypeEpisode},
+		"https://www.bilibili.com/bangumi/media/md28222622/":       {28222622, BangumiSubTypeMedia},
+		"https://m.bilibili.com/bangumi/play/ss28747":              {28747, BangumiSubTypeSeason},
+	}
+	parser := NewURLParser()
+	for rawURL, want := range cases {
+		parsed, err := parser.Parse(rawURL)
+		if err != nil {
+			t.Fatalf("parse %q: %v", rawURL, err)
+		}
+		if parsed.Type != SourceTypeBangumi || parsed.ID != want.id || parsed.SubType != want.subType {
+			t.Fatalf("parse %q: unexpected result %+v", rawURL, parsed)
+		}
+	}
+
+	if _, err := parser.Parse("https://www.bilibili.com/bangumi/play/xx1"); err == nil {
+		t.Fatal("expected invalid bangumi URL to fail")
+	}
+}
+
+func TestBangumiSeasonHelpers(t *testing.T) {
+	season := &BangumiSeason{
+		SeasonID: 2,
+		Title:    "某番剧 第二季",
+		Seasons:  []BangumiSeasonRef{{SeasonID: 1}, {SeasonID: 2}, {SeasonID: 3}},
+	}
+	if got := season.SeasonNumber(); got != 2 {
+		t.Fatalf("expected season 2, got %d", got)
+	}
+	if got := season.ShowName(); got != "某番剧 第二季" {
+		t.Fatalf("expected fallback to title, got %q", got)
+	}
+	season.Series.SeriesTitle = "某番剧"
+	if got := season.ShowName(); got != "某番剧" {
+		t.Fatalf("expected series title, got %q", got)
+	}
+
+	season.SeasonID = 99
+	if got := season.SeasonNumber(); got != 1 {
+		t.Fatalf("expected unknown season to default to 1, got %d", got)
+	}
+
+	episodes := map[string]BangumiEpisode{
+		"第3话 标题": {Title: "3", LongTitle: "标题"},
+		"SP":     {Title: "SP"},
+		"展示标题":   {Title: "1", LongTitle: "标题", ShowTitle: "展示标题"},
+	}
+	for want, ep := range episodes {
+		if got := ep.DisplayTitle(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
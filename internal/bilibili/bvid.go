@@ -0,0 +1,39 @@
+package bilibili
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BV 号与 av 号互转使用的参数（B站 2024 年起的 BV 号算法）
+const (
+	bvidXorCode  = 23442827791579
+	bvidMaskCode = 1<<51 - 1
+	bvidBase     = 58
+	bvidAlphabet = "FcwAPNKTMug3GV5Lj7EJnHpWsx4tb8haYeviqBz6rkCy12mUSDQX9RdoZf"
+)
+
+// BVToAid 将 BV 号转换为 av 号（aid），部分接口（如弹幕元数据）只接受 aid
+func BVToAid(bvid string) (int64, error) {
+	bvid = strings.TrimSpace(bvid)
+	if !strings.HasPrefix(strings.ToUpper(bvid), "BV") {
+		bvid = "BV" + bvid
+	}
+	if len(bvid) != 12 {
+		return 0, fmt.Errorf("无效的 BV 号: %s", bvid)
+	}
+
+	chars := []byte(bvid)
+	chars[3], chars[9] = chars[9], chars[3]
+	chars[4], chars[7] = chars[7], chars[4]
+
+	var tmp int64
+	for _, ch := range chars[3:] {
+		idx := strings.IndexByte(bvidAlphabet, ch)
+		if idx < 0 {
+			return 0, fmt.Errorf("无效的 BV 号: %s", bvid)
+		}
+		tmp = tmp*bvidBase + int64(idx)
+	}
+	return (tmp & bvidMaskCode) ^ bvidXorCode, nil
+}
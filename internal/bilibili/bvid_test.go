@@ -0,0 +1,26 @@
+package bilibili
+
+import "testing"
+
+func TestBVToAid(t *testing.T) {
+	tests := []struct {
+		bvid string
+		want int64
+	}{
+		{"BV17x411w7KC", 170001},
+		{"BV1L9Uoa9EUx", 111298867365120},
+		{"1L9Uoa9EUx", 111298867365120},
+	}
+	for _, tt := range tests {
+		got, err := BVToAid(tt.bvid)
+		if err != nil || got != tt.want {
+			t.Errorf("BVToAid(%q) = %d, %v, want %d", tt.bvid, got, err, tt.want)
+		}
+	}
+
+	for _, bvid := range []string{"", "BV123", "BV1L9Uoa9EU0"} {
+		if _, err := BVToAid(bvid); err == nil {
+			t.Errorf("BVToAid(%q) expected error", bvid)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package bilibili
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"bili-download/internal/config"
@@ -20,10 +23,14 @@ const (
 
 // Client B站 HTTP 客户端
 type Client struct {
-	httpClient  *http.Client
-	credential  *Credential
-	wbiMixinKey string
-	wbiCachedAt time.Time
+	httpClient *http.Client
+	credential *Credential
+	limiter    *RateLimiter
+	// 网络错误与 5xx 响应的重试次数与首次重试间隔
+	maxRetries   int
+	retryBackoff time.Duration
+	wbiMixinKey  string
+	wbiCachedAt  time.Time
 	// 用户信息缓存
 	cachedUserInfo *UserInfo
 	userCachedAt   time.Time
@@ -31,8 +38,12 @@ type Client struct {
 
 // NewClient 创建新的 B站 客户端
 func NewClient(cfg *config.Config) *Client {
+	SharedRateLimiter().Configure(cfg.Advanced.RateLimit)
 	return &Client{
-		httpClient: utils.NewHTTPClient(cfg.Proxy, 30*time.Second, 100, 10),
+		httpClient:   utils.NewHTTPClient(cfg.Proxy, 30*time.Second, 100, 10),
+		limiter:      SharedRateLimiter(),
+		maxRetries:   cfg.Advanced.APIRetry.MaxRetries,
+		retryBackoff: cfg.Advanced.APIRetry.Backoff(),
 		credential: &Credential{
 			SESSDATA:    cfg.Bilibili.Credential.SESSDATA,
 			BiliJct:     cfg.Bilibili.Credential.BiliJct,
@@ -43,9 +54,81 @@ func NewClient(cfg *config.Config) *Client {
 	}
 }
 
-// Request 发送 HTTP 请求
-func (c *Client) Request(method, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+// Request 发送 HTTP 请求，ctx 取消时中止等待与在途请求
+// GET 等幂等请求的网络错误与 5xx 响应按配置重试，重试间隔逐次翻倍；最后一次重试仍为 5xx 时原样返回响应
+// POST 等写操作不重试，避免服务端已处理的请求被重复执行
+func (c *Client) Request(ctx context.Context, method, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	// 读出请求体以便重试时重新发送
+	var payload []byte
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		payload = data
+	}
+
+	class := classifyEndpoint(url)
+	maxRetries := 0
+	if idempotentMethod(method) {
+		maxRetries = c.maxRetries
+	}
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx, class); err != nil {
+			return nil, err
+		}
+
+		req, err := c.newRequest(ctx, method, url, headers, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		retryable := attempt < maxRetries && ctx.Err() == nil
+		if err != nil {
+			if !retryable {
+				return nil, fmt.Errorf("发送请求失败: %w", err)
+			}
+			utils.Debug("B 站接口请求失败，%s 后重试（%d/%d）: %v", backoff, attempt+1, c.maxRetries, err)
+		} else if resp.StatusCode >= http.StatusInternalServerError && retryable {
+			resp.Body.Close()
+			utils.Debug("B 站接口返回 HTTP %d，%s 后重试（%d/%d）: %s", resp.StatusCode, backoff, attempt+1, maxRetries, req.URL.Path)
+		} else {
+			if err := c.inspectResponse(req, resp); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// idempotentMethod 请求方法是否幂等（可安全重试）
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// newRequest 构建带默认 headers 与凭据 cookies 的请求
+func (c *Client) newRequest(ctx context.Context, method, url string, headers map[string]string, payload []byte) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -63,32 +146,58 @@ func (c *Client) Request(method, url string, headers map[string]string, body io.
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	return req, nil
+}
+
+// inspectResponse 检查响应是否被风控拦截，被拦截时通知限速器进入冷却
+// JSON 响应会被完整读取以检查业务错误码，随后重新放回 resp.Body 供调用方读取
+func (c *Client) inspectResponse(req *http.Request, resp *http.Response) error {
+	if resp.StatusCode == HTTPStatusRiskControl {
+		c.limiter.ReportRiskControl(HTTPStatusRiskControl, req.URL.Path)
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return nil
+	}
 
-	resp, err := c.httpClient.Do(req)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
 	if err != nil {
-		return nil, fmt.Errorf("发送请求失败: %w", err)
+		return fmt.Errorf("读取响应失败: %w", err)
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
 
-	return resp, nil
+	var result struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return nil
+	}
+	if IsRiskControlError(result.Code) || IsTooManyRequestsError(result.Code) {
+		c.limiter.ReportRiskControl(result.Code, req.URL.Path)
+	} else {
+		c.limiter.ReportSuccess()
+	}
+	return nil
 }
 
 // Get 发送 GET 请求
-func (c *Client) Get(url string, headers map[string]string) (*http.Response, error) {
-	return c.Request(http.MethodGet, url, headers, nil)
+func (c *Client) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	return c.Request(ctx, http.MethodGet, url, headers, nil)
 }
 
 // Post 发送 POST 请求
-func (c *Client) Post(url string, headers map[string]string, body io.Reader) (*http.Response, error) {
+func (c *Client) Post(ctx context.Context, url string, headers map[string]string, body io.Reader) (*http.Response, error) {
 	if headers == nil {
 		headers = make(map[string]string)
 	}
 	headers["Content-Type"] = "application/x-www-form-urlencoded"
-	return c.Request(http.MethodPost, url, headers, body)
+	return c.Request(ctx, http.MethodPost, url, headers, body)
 }
 
 // GetJSON 发送 GET 请求并解析 JSON 响应
-func (c *Client) GetJSON(url string, headers map[string]string, result interface{}) error {
-	resp, err := c.Get(url, headers)
+func (c *Client) GetJSON(ctx context.Context, url string, headers map[string]string, result interface{}) error {
+	resp, err := c.Get(ctx, url, headers)
 	if err != nil {
 		return err
 	}
@@ -103,8 +212,8 @@ func (c *Client) GetJSON(url string, headers map[string]string, result interface
 }
 
 // PostJSON 发送 POST 请求并解析 JSON 响应
-func (c *Client) PostJSON(url string, headers map[string]string, body io.Reader, result interface{}) error {
-	resp, err := c.Post(url, headers, body)
+func (c *Client) PostJSON(ctx context.Context, url string, headers map[string]string, body io.Reader, result interface{}) error {
+	resp, err := c.Post(ctx, url, headers, body)
 	if err != nil {
 		return err
 	}
@@ -118,6 +227,11 @@ func (c *Client) PostJSON(url string, headers map[string]string, body io.Reader,
 	return json.NewDecoder(resp.Body).Decode(result)
 }
 
+// RateLimiter 客户端使用的请求限速器
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
 // SetCredential 设置凭据
 func (c *Client) SetCredential(credential *Credential) {
 	c.credential = credential
@@ -142,21 +256,24 @@ func (c *Client) UpdateCredential(credentialCfg *config.CredentialConfig) {
 // UpdateConfig 更新客户端配置
 func (c *Client) UpdateConfig(cfg *config.Config) {
 	c.httpClient = utils.NewHTTPClient(cfg.Proxy, 30*time.Second, 100, 10)
+	c.limiter.Configure(cfg.Advanced.RateLimit)
+	c.maxRetries = cfg.Advanced.APIRetry.MaxRetries
+	c.retryBackoff = cfg.Advanced.APIRetry.Backoff()
 	c.UpdateCredential(&cfg.Bilibili.Credential)
 }
 
 // ValidateCredential 验证认证信息是否有效
-func (c *Client) ValidateCredential() error {
+func (c *Client) ValidateCredential(ctx context.Context) error {
 	// 首先检查凭据是否存在
 	if c.credential == nil || c.credential.SESSDATA == "" {
 		return fmt.Errorf("未配置认证信息")
 	}
 
 	// 尝试获取用户信息来验证登录状态（这是最直接的验证方式）
-	_, err := c.GetMe()
+	_, err := c.GetMe(ctx)
 	if err != nil {
 		// 如果获取用户信息失败，再检查凭据有效性
-		valid, checkErr := c.CheckCredentialValid()
+		valid, checkErr := c.CheckCredentialValid(ctx)
 		if checkErr != nil {
 			return fmt.Errorf("验证失败: %w", checkErr)
 		}
@@ -0,0 +1,102 @@
+package bilibili
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+)
+
+func newRetryTestClient(server *httptest.Server, maxRetries int) *Client {
+	return &Client{
+		httpClient:   server.Client(),
+		limiter:      NewRateLimiter(config.RateLimitConfig{DurationMS: 250, Limit: 100}),
+		maxRetries:   maxRetries,
+		retryBackoff: time.Millisecond,
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":0,"message":"","data":null}`))
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(server, 3)
+	resp, err := client.Get(context.Background(), server.URL+"/x/retry", nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("expected success on third attempt, got status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+
+	// 重试次数用尽后原样返回 5xx 响应
+	calls.Store(-10)
+	client.maxRetries = 1
+	resp, err = client.Get(context.Background(), server.URL+"/x/retry", nil)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != -8 {
+		t.Fatalf("expected 502 after 2 attempts, got status %d after %d calls", resp.StatusCode, calls.Load()+10)
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(server, 3)
+	resp, err := client.Post(context.Background(), server.URL+"/x/v2/history/toview/del", nil, strings.NewReader("aid=1"))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+		t.Fatalf("expected a single attempt for POST, got status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestClientRequestHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newRetryTestClient(server, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.Get(ctx, server.URL+"/x/slow", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected in-flight request to abort promptly, took %s", elapsed)
+	}
+}
@@ -1,6 +1,7 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -98,17 +99,17 @@ type CollectionListParams struct {
 }
 
 // GetCollectionInfo 获取合集/系列信息
-func (c *Client) GetCollectionInfo(params CollectionListParams) (*CollectionInfo, error) {
+func (c *Client) GetCollectionInfo(ctx context.Context, params CollectionListParams) (*CollectionInfo, error) {
 	// 对于 Season，我们需要先获取第一页来获取 meta 信息
 	// 对于 Series，有专门的接口
 	if params.CollectionType == CollectionTypeSeries {
-		return c.getSeriesInfo(params.SeriesID)
+		return c.getSeriesInfo(ctx, params.SeriesID)
 	}
 
 	// Season 类型，获取第一页来提取 meta
 	params.PageNum = 1
 	params.PageSize = 1
-	resp, err := c.GetCollectionList(params)
+	resp, err := c.GetCollectionList(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +118,7 @@ func (c *Client) GetCollectionInfo(params CollectionListParams) (*CollectionInfo
 }
 
 // getSeriesInfo 获取系列信息
-func (c *Client) getSeriesInfo(seriesID string) (*CollectionInfo, error) {
+func (c *Client) getSeriesInfo(ctx context.Context, seriesID string) (*CollectionInfo, error) {
 	params := url.Values{}
 	params.Set("series_id", seriesID)
 
@@ -131,7 +132,7 @@ func (c *Client) getSeriesInfo(seriesID string) (*CollectionInfo, error) {
 		} `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("获取系列信息失败: %w", err)
 	}
@@ -151,7 +152,7 @@ func (c *Client) getSeriesInfo(seriesID string) (*CollectionInfo, error) {
 }
 
 // GetCollectionList 获取合集/系列视频列表
-func (c *Client) GetCollectionList(params CollectionListParams) (*CollectionListResponse, error) {
+func (c *Client) GetCollectionList(ctx context.Context, params CollectionListParams) (*CollectionListResponse, error) {
 	var apiURL string
 	query := url.Values{}
 
@@ -192,7 +193,7 @@ func (c *Client) GetCollectionList(params CollectionListParams) (*CollectionList
 
 	fullURL := apiURL + "?" + query.Encode()
 
-	resp, err := c.Get(fullURL, nil)
+	resp, err := c.Get(ctx, fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取合集列表失败: %w", err)
 	}
@@ -232,13 +233,13 @@ func (c *Client) GetCollectionList(params CollectionListParams) (*CollectionList
 }
 
 // GetAllCollectionVideos 获取合集/系列所有视频（自动翻页）
-func (c *Client) GetAllCollectionVideos(params CollectionListParams) ([]CollectionArchive, error) {
+func (c *Client) GetAllCollectionVideos(ctx context.Context, params CollectionListParams) ([]CollectionArchive, error) {
 	var allVideos []CollectionArchive
 	page := 1
 
 	for {
 		params.PageNum = page
-		listResp, err := c.GetCollectionList(params)
+		listResp, err := c.GetCollectionList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取第 %d 页失败: %w", page, err)
 		}
@@ -3,9 +3,11 @@ package bilibili
 import (
 	"bytes"
 	"compress/flate"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 	"strconv"
 	"strings"
 )
@@ -92,10 +94,10 @@ func (d *DanmakuElem) ParseAttr() error {
 }
 
 // GetDanmakuXML 获取弹幕（XML格式）
-func (c *Client) GetDanmakuXML(cid int64) (*DanmakuResponse, error) {
+func (c *Client) GetDanmakuXML(ctx context.Context, cid int64) (*DanmakuResponse, error) {
 	apiURL := fmt.Sprintf("https://api.bilibili.com/x/v1/dm/list.so?oid=%d", cid)
 
-	resp, err := c.Get(apiURL, nil)
+	resp, err := c.Get(ctx, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取弹幕失败: %w", err)
 	}
@@ -142,8 +144,8 @@ type DanmakuSegmentParams struct {
 }
 
 // GetDanmakuSegmentRaw 获取弹幕分段原始数据（Protobuf格式）
-// 注意：返回的是protobuf二进制数据，需要使用protobuf库解析
-func (c *Client) GetDanmakuSegmentRaw(params DanmakuSegmentParams) ([]byte, error) {
+// 返回的是 DmSegMobileReply 二进制数据，可使用 DecodeDanmakuSegment 解析
+func (c *Client) GetDanmakuSegmentRaw(ctx context.Context, params DanmakuSegmentParams) ([]byte, error) {
 	if params.Type == 0 {
 		params.Type = 1
 	}
@@ -159,12 +161,16 @@ func (c *Client) GetDanmakuSegmentRaw(params DanmakuSegmentParams) ([]byte, erro
 		apiURL += fmt.Sprintf("&pid=%d", params.PID)
 	}
 
-	resp, err := c.Get(apiURL, nil)
+	resp, err := c.Get(ctx, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取弹幕分段失败: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取弹幕分段失败: HTTP %d", resp.StatusCode)
+	}
+
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %w", err)
@@ -221,6 +227,12 @@ type SubtitleLan struct {
 	Lan         string `json:"lan"`          // 语言代码
 	LanDoc      string `json:"lan_doc"`      // 语言名称
 	SubtitleURL string `json:"subtitle_url"` // 字幕URL
+	Type        int    `json:"type"`         // 字幕类型：0人工（CC），1AI生成
+}
+
+// IsAI 是否为AI生成的字幕
+func (s SubtitleLan) IsAI() bool {
+	return s.Type == 1 || strings.HasPrefix(s.Lan, "ai-")
 }
 
 // PlayerConfig 播放器配置
@@ -244,13 +256,17 @@ type ReportInfo struct {
 	StateKey string `json:"state_key"` // 状态Key
 }
 
-// GetDanmakuMetadata 获取弹幕元数据
-func (c *Client) GetDanmakuMetadata(cid int64, bvid string) (*DanmakuMetadata, error) {
-	apiURL := fmt.Sprintf(
-		"https://api.bilibili.com/x/v2/dm/web/view?type=1&oid=%d&pid=%s",
-		cid,
-		bvid,
-	)
+// GetDanmakuMetadata 获取弹幕元数据（含字幕列表），bvid 用于换算接口要求的 aid，为空时只按 cid 查询
+// 字幕列表同时按视频与 cid 索引，缺少 aid 时可能返回空列表
+func (c *Client) GetDanmakuMetadata(ctx context.Context, cid int64, bvid string) (*DanmakuMetadata, error) {
+	apiURL := fmt.Sprintf("https://api.bilibili.com/x/v2/dm/web/view?type=1&oid=%d", cid)
+	if bvid != "" {
+		aid, err := BVToAid(bvid)
+		if err != nil {
+			return nil, err
+		}
+		apiURL += fmt.Sprintf("&pid=%d", aid)
+	}
 
 	var result struct {
 		Code    int             `json:"code"`
@@ -258,7 +274,7 @@ func (c *Client) GetDanmakuMetadata(cid int64, bvid string) (*DanmakuMetadata, e
 		Data    DanmakuMetadata `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("获取弹幕元数据失败: %w", err)
 	}
@@ -274,8 +290,8 @@ func (c *Client) GetDanmakuMetadata(cid int64, bvid string) (*DanmakuMetadata, e
 }
 
 // GetDanmakuCount 获取弹幕数量
-func (c *Client) GetDanmakuCount(cid int64) (int, error) {
-	metadata, err := c.GetDanmakuMetadata(cid, "")
+func (c *Client) GetDanmakuCount(ctx context.Context, cid int64) (int, error) {
+	metadata, err := c.GetDanmakuMetadata(ctx, cid, "")
 	if err != nil {
 		return 0, err
 	}
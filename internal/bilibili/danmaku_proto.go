@@ -0,0 +1,199 @@
+package bilibili
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// DanmakuSegmentDuration 弹幕分段时长（秒），seg.so 每段覆盖 6 分钟
+	DanmakuSegmentDuration = 360
+	// maxDanmakuSegments 时长未知时最多拉取的分段数（约 10 小时）
+	maxDanmakuSegments = 100
+)
+
+// DmSegMobileReply 字段号
+const (
+	dmSegFieldElems = 1
+)
+
+// DanmakuElem (protobuf) 字段号
+const (
+	dmElemFieldID       = 1
+	dmElemFieldProgress = 2
+	dmElemFieldMode     = 3
+	dmElemFieldFontSize = 4
+	dmElemFieldColor    = 5
+	dmElemFieldMidHash  = 6
+	dmElemFieldContent  = 7
+	dmElemFieldCtime    = 8
+	dmElemFieldPool     = 11
+	dmElemFieldIDStr    = 12
+)
+
+// DecodeDanmakuSegment 解析 seg.so 返回的 DmSegMobileReply（protobuf 格式）
+func DecodeDanmakuSegment(data []byte) ([]DanmakuElem, error) {
+	var danmakus []DanmakuElem
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, fmt.Errorf("解析弹幕分段失败: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if num == dmSegFieldElems && typ == protowire.BytesType {
+			raw, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, fmt.Errorf("解析弹幕分段失败: %w", protowire.ParseError(n))
+			}
+			data = data[n:]
+
+			elem, err := decodeDanmakuElem(raw)
+			if err != nil {
+				return nil, err
+			}
+			danmakus = append(danmakus, elem)
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return nil, fmt.Errorf("解析弹幕分段失败: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+
+	return danmakus, nil
+}
+
+// decodeDanmakuElem 解析单条弹幕
+func decodeDanmakuElem(data []byte) (DanmakuElem, error) {
+	var elem DanmakuElem
+	var idStr string
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return elem, fmt.Errorf("解析弹幕失败: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return elem, fmt.Errorf("解析弹幕失败: %w", protowire.ParseError(n))
+			}
+			data = data[n:]
+
+			switch num {
+			case dmElemFieldID:
+				elem.RowID = int64(v)
+			case dmElemFieldProgress:
+				elem.Progress = int(int32(v))
+			case dmElemFieldMode:
+				elem.Mode = int(int32(v))
+			case dmElemFieldFontSize:
+				elem.FontSize = int(int32(v))
+			case dmElemFieldColor:
+				elem.Color = uint32(v)
+			case dmElemFieldCtime:
+				elem.SendTime = int64(v)
+			case dmElemFieldPool:
+				elem.Pool = int(int32(v))
+			}
+
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return elem, fmt.Errorf("解析弹幕失败: %w", protowire.ParseError(n))
+			}
+			data = data[n:]
+
+			switch num {
+			case dmElemFieldMidHash:
+				elem.SenderID = string(v)
+			case dmElemFieldContent:
+				elem.Content = string(v)
+			case dmElemFieldIDStr:
+				idStr = string(v)
+			}
+
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return elem, fmt.Errorf("解析弹幕失败: %w", protowire.ParseError(n))
+			}
+			data = data[n:]
+		}
+	}
+
+	// 部分弹幕只下发字符串形式的ID
+	if elem.RowID == 0 && idStr != "" {
+		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
+			elem.RowID = id
+		}
+	}
+
+	return elem, nil
+}
+
+// GetDanmakuSegments 拉取分P的全部弹幕分段，按弹幕ID去重后按出现时间排序
+// duration 为分P时长（秒）；为 0 时逐段拉取直到遇到空分段
+func (c *Client) GetDanmakuSegments(ctx context.Context, cid, aid int64, duration int) ([]DanmakuElem, error) {
+	segments := maxDanmakuSegments
+	if duration > 0 {
+		segments = (duration + DanmakuSegmentDuration - 1) / DanmakuSegmentDuration
+	}
+
+	seen := make(map[int64]struct{})
+	var danmakus []DanmakuElem
+
+	for index := 1; index <= segments; index++ {
+		data, err := c.GetDanmakuSegmentRaw(ctx, DanmakuSegmentParams{
+			Type:         1,
+			OID:          cid,
+			PID:          aid,
+			SegmentIndex: index,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取第 %d 段弹幕失败: %w", index, err)
+		}
+
+		elems, err := DecodeDanmakuSegment(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析第 %d 段弹幕失败: %w", index, err)
+		}
+
+		if len(elems) == 0 && duration <= 0 {
+			break
+		}
+
+		danmakus = mergeDanmakus(danmakus, elems, seen)
+	}
+
+	sort.SliceStable(danmakus, func(i, j int) bool {
+		return danmakus[i].Progress < danmakus[j].Progress
+	})
+
+	return danmakus, nil
+}
+
+// mergeDanmakus 合并弹幕，跳过已出现过的弹幕ID（分段边界处可能重复下发）
+func mergeDanmakus(dst, src []DanmakuElem, seen map[int64]struct{}) []DanmakuElem {
+	for _, elem := range src {
+		if elem.RowID != 0 {
+			if _, ok := seen[elem.RowID]; ok {
+				continue
+			}
+			seen[elem.RowID] = struct{}{}
+		}
+		dst = append(dst, elem)
+	}
+	return dst
+}
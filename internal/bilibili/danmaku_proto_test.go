@@ -0,0 +1,78 @@
+package bilibili
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendDanmakuElem(b []byte, id int64, progress int, content string) []byte {
+	var elem []byte
+	elem = protowire.AppendTag(elem, dmElemFieldID, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, uint64(id))
+	elem = protowire.AppendTag(elem, dmElemFieldProgress, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, uint64(progress))
+	elem = protowire.AppendTag(elem, dmElemFieldMode, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, 1)
+	elem = protowire.AppendTag(elem, dmElemFieldFontSize, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, 25)
+	elem = protowire.AppendTag(elem, dmElemFieldColor, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, 0xFFFFFF)
+	elem = protowire.AppendTag(elem, dmElemFieldMidHash, protowire.BytesType)
+	elem = protowire.AppendString(elem, "abcd1234")
+	elem = protowire.AppendTag(elem, dmElemFieldContent, protowire.BytesType)
+	elem = protowire.AppendString(elem, content)
+	elem = protowire.AppendTag(elem, dmElemFieldCtime, protowire.VarintType)
+	elem = protowire.AppendVarint(elem, 1700000000)
+	// 未知字段应被跳过
+	elem = protowire.AppendTag(elem, 14, protowire.BytesType)
+	elem = protowire.AppendString(elem, "{}")
+
+	b = protowire.AppendTag(b, dmSegFieldElems, protowire.BytesType)
+	return protowire.AppendBytes(b, elem)
+}
+
+func TestDecodeDanmakuSegment(t *testing.T) {
+	var data []byte
+	data = appendDanmakuElem(data, 1001, 1500, "第一条")
+	data = appendDanmakuElem(data, 1002, 361000, "第二条")
+	// DmSegMobileReply 的其他字段（如 state）应被忽略
+	data = protowire.AppendTag(data, 2, protowire.VarintType)
+	data = protowire.AppendVarint(data, 0)
+
+	danmakus, err := DecodeDanmakuSegment(data)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(danmakus) != 2 {
+		t.Fatalf("expected 2 danmakus, got %d", len(danmakus))
+	}
+
+	first := danmakus[0]
+	if first.RowID != 1001 || first.Progress != 1500 || first.Mode != 1 || first.FontSize != 25 {
+		t.Fatalf("unexpected danmaku fields: %+v", first)
+	}
+	if first.Color != 0xFFFFFF || first.SenderID != "abcd1234" || first.Content != "第一条" || first.SendTime != 1700000000 {
+		t.Fatalf("unexpected danmaku fields: %+v", first)
+	}
+	if danmakus[1].Progress != 361000 {
+		t.Fatalf("expected second danmaku at 361000ms, got %d", danmakus[1].Progress)
+	}
+}
+
+func TestDecodeDanmakuSegmentRejectsTruncatedData(t *testing.T) {
+	data := appendDanmakuElem(nil, 1001, 1500, "第一条")
+	if _, err := DecodeDanmakuSegment(data[:len(data)-3]); err == nil {
+		t.Fatal("expected truncated segment to fail")
+	}
+}
+
+func TestMergeDanmakusSkipsDuplicateIDs(t *testing.T) {
+	seen := make(map[int64]struct{})
+	merged := mergeDanmakus(nil, []DanmakuElem{{RowID: 1}, {RowID: 2}}, seen)
+	merged = mergeDanmakus(merged, []DanmakuElem{{RowID: 2}, {RowID: 3}, {RowID: 0}, {RowID: 0}}, seen)
+
+	if len(merged) != 5 {
+		t.Fatalf("expected 5 danmakus after merge, got %d", len(merged))
+	}
+}
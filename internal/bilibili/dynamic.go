@@ -0,0 +1,147 @@
+package bilibili
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 动态类型
+const (
+	DynamicTypeAV      = "DYNAMIC_TYPE_AV"      // 视频投稿动态（含仅动态可见的视频）
+	DynamicTypeForward = "DYNAMIC_TYPE_FORWARD" // 转发动态
+	MajorTypeArchive   = "MAJOR_TYPE_ARCHIVE"   // 动态主体为视频
+)
+
+// DynamicFeedResponse 空间动态列表响应
+type DynamicFeedResponse struct {
+	HasMore        bool          `json:"has_more"`        // 是否还有更多
+	Offset         string        `json:"offset"`          // 下一页游标
+	UpdateBaseline string        `json:"update_baseline"` // 最新动态ID
+	Items          []DynamicItem `json:"items"`           // 动态列表
+}
+
+// DynamicItem 动态条目
+type DynamicItem struct {
+	IDStr   string         `json:"id_str"`  // 动态ID
+	Type    string         `json:"type"`    // 动态类型
+	Visible bool           `json:"visible"` // 是否可见
+	Modules DynamicModules `json:"modules"` // 动态模块
+}
+
+// DynamicModules 动态模块
+type DynamicModules struct {
+	Author  DynamicAuthor  `json:"module_author"`  // 发布者信息
+	Dynamic DynamicContent `json:"module_dynamic"` // 动态内容
+	Tag     *DynamicTag    `json:"module_tag"`     // 标签（如置顶）
+}
+
+// DynamicAuthor 动态发布者信息
+type DynamicAuthor struct {
+	Mid   int64  `json:"mid"`    // UP主ID
+	Name  string `json:"name"`   // 昵称
+	Face  string `json:"face"`   // 头像URL
+	PubTS int64  `json:"pub_ts"` // 发布时间（时间戳）
+}
+
+// DynamicContent 动态内容
+type DynamicContent struct {
+	Major *DynamicMajor `json:"major"` // 动态主体（可能为null）
+}
+
+// DynamicMajor 动态主体
+type DynamicMajor struct {
+	Type    string          `json:"type"`    // 主体类型
+	Archive *DynamicArchive `json:"archive"` // 视频信息（类型为视频时存在）
+}
+
+// DynamicArchive 动态中的视频信息
+type DynamicArchive struct {
+	Aid          string             `json:"aid"`           // avid（字符串）
+	BVid         string             `json:"bvid"`          // bvid
+	Title        string             `json:"title"`         // 标题
+	Desc         string             `json:"desc"`          // 简介
+	Cover        string             `json:"cover"`         // 封面
+	DurationText string             `json:"duration_text"` // 时长 MM:SS 或 HH:MM:SS
+	Stat         DynamicArchiveStat `json:"stat"`          // 统计信息
+}
+
+// DynamicArchiveStat 动态中的视频统计信息（如 "1.2万"）
+type DynamicArchiveStat struct {
+	Play    string `json:"play"`    // 播放数
+	Danmaku string `json:"danmaku"` // 弹幕数
+}
+
+// DynamicTag 动态标签
+type DynamicTag struct {
+	Text string `json:"text"` // 标签文字
+}
+
+// IsPinned 是否为置顶动态
+func (i *DynamicItem) IsPinned() bool {
+	return i.Modules.Tag != nil && i.Modules.Tag.Text == "置顶"
+}
+
+// VideoArchive 视频投稿动态中的视频信息，非视频动态（图文、转发等）返回 nil
+func (i *DynamicItem) VideoArchive() *DynamicArchive {
+	if i.Type != DynamicTypeAV {
+		return nil
+	}
+	major := i.Modules.Dynamic.Major
+	if major == nil || major.Type != MajorTypeArchive || major.Archive == nil || major.Archive.BVid == "" {
+		return nil
+	}
+	return major.Archive
+}
+
+// GetSpaceDynamics 获取UP主空间动态列表（需要WBI签名）
+// offset 为上一页返回的游标，首页传空字符串
+func (c *Client) GetSpaceDynamics(ctx context.Context, mid string, offset string) (*DynamicFeedResponse, error) {
+	params := url.Values{}
+	params.Set("host_mid", mid)
+	params.Set("offset", offset)
+	params.Set("timezone_offset", "-480")
+	params.Set("features", "itemOpusStyle")
+
+	var result struct {
+		Code    int                 `json:"code"`
+		Message string              `json:"message"`
+		Data    DynamicFeedResponse `json:"data"`
+	}
+
+	err := c.GetJSONWithWBI(ctx, "https://api.bilibili.com/x/polymer/web-dynamic/v1/feed/space", params, &result)
+	if err != nil {
+		return nil, fmt.Errorf("获取UP主动态失败: %w", err)
+	}
+
+	if result.Code != 0 {
+		return nil, &BiliError{
+			Code:    result.Code,
+			Message: result.Message,
+		}
+	}
+
+	return &result.Data, nil
+}
+
+// ParseCountText 解析动态中的计数文本（如 "1234"、"1.2万"、"3.5亿"），无法解析时返回 0
+func ParseCountText(text string) int {
+	text = strings.TrimSpace(text)
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(text, "万"):
+		multiplier = 1e4
+		text = strings.TrimSuffix(text, "万")
+	case strings.HasSuffix(text, "亿"):
+		multiplier = 1e8
+		text = strings.TrimSuffix(text, "亿")
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0
+	}
+	return int(math.Round(value * multiplier))
+}
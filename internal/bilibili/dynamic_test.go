@@ -0,0 +1,56 @@
+package bilibili
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDynamicItemVideoArchive(t *testing.T) {
+	raw := `{"has_more":true,"offset":"123","items":[
+		{"id_str":"1","type":"DYNAMIC_TYPE_AV","modules":{"module_author":{"mid":2,"name":"UP","pub_ts":1700000000},
+			"module_dynamic":{"major":{"type":"MAJOR_TYPE_ARCHIVE","archive":{"aid":"170001","bvid":"BV17x411w7KC","title":"置顶视频","duration_text":"1:02:03","stat":{"play":"1.5万","danmaku":"32"}}}},
+			"module_tag":{"text":"置顶"}}},
+		{"id_str":"2","type":"DYNAMIC_TYPE_DRAW","modules":{"module_author":{"mid":2,"pub_ts":1700000100},"module_dynamic":{"major":null}}},
+		{"id_str":"3","type":"DYNAMIC_TYPE_FORWARD","modules":{"module_author":{"mid":2,"pub_ts":1700000200},"module_dynamic":{"major":null}}}
+	]}`
+
+	var feed DynamicFeedResponse
+	if err := json.Unmarshal([]byte(raw), &feed); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !feed.HasMore || feed.Offset != "123" || len(feed.Items) != 3 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	pinned := feed.Items[0]
+	if !pinned.IsPinned() {
+		t.Fatal("expected first item to be pinned")
+	}
+	archive := pinned.VideoArchive()
+	if archive == nil || archive.BVid != "BV17x411w7KC" || archive.DurationText != "1:02:03" {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+
+	for _, item := range feed.Items[1:] {
+		if item.IsPinned() || item.VideoArchive() != nil {
+			t.Fatalf("expected non-video item %s to be ignored", item.IDStr)
+		}
+	}
+}
+
+func TestParseCountText(t *testing.T) {
+	cases := map[string]int{
+		"1234": 1234,
+		"1.5万": 15000,
+		"2.3万": 23000,
+		"2亿":   200000000,
+		"":     0,
+		"-":    0,
+		" 87 ": 87,
+	}
+	for text, want := range cases {
+		if got := ParseCountText(text); got != want {
+			t.Fatalf("ParseCountText(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
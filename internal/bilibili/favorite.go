@@ -1,11 +1,13 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // FavoriteInfo 收藏夹元数据
@@ -77,7 +79,7 @@ type FavoriteListParams struct {
 }
 
 // GetFavoriteInfo 获取收藏夹元数据
-func (c *Client) GetFavoriteInfo(mediaID string) (*FavoriteInfo, error) {
+func (c *Client) GetFavoriteInfo(ctx context.Context, mediaID string) (*FavoriteInfo, error) {
 	type Response struct {
 		Code    int          `json:"code"`
 		Message string       `json:"message"`
@@ -91,7 +93,7 @@ func (c *Client) GetFavoriteInfo(mediaID string) (*FavoriteInfo, error) {
 	apiURL := "https://api.bilibili.com/x/v3/fav/folder/info?" + params.Encode()
 
 	var resp Response
-	err := c.GetJSON(apiURL, nil, &resp)
+	err := c.GetJSON(ctx, apiURL, nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取收藏夹信息失败: %w", err)
 	}
@@ -107,7 +109,7 @@ func (c *Client) GetFavoriteInfo(mediaID string) (*FavoriteInfo, error) {
 }
 
 // GetFavoriteList 获取收藏夹内容列表
-func (c *Client) GetFavoriteList(params FavoriteListParams) (*FavoriteListResponse, error) {
+func (c *Client) GetFavoriteList(ctx context.Context, params FavoriteListParams) (*FavoriteListResponse, error) {
 	// 构建查询参数
 	query := url.Values{}
 	query.Set("media_id", params.MediaID)
@@ -147,7 +149,7 @@ func (c *Client) GetFavoriteList(params FavoriteListParams) (*FavoriteListRespon
 	apiURL := "https://api.bilibili.com/x/v3/fav/resource/list?" + query.Encode()
 
 	// 发送请求
-	resp, err := c.Get(apiURL, nil)
+	resp, err := c.Get(ctx, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取收藏夹列表失败: %w", err)
 	}
@@ -181,7 +183,7 @@ func (c *Client) GetFavoriteList(params FavoriteListParams) (*FavoriteListRespon
 }
 
 // GetAllFavoriteVideos 获取收藏夹所有视频（自动翻页）
-func (c *Client) GetAllFavoriteVideos(mediaID string) ([]FavoriteMedia, error) {
+func (c *Client) GetAllFavoriteVideos(ctx context.Context, mediaID string) ([]FavoriteMedia, error) {
 	var allVideos []FavoriteMedia
 	page := 1
 	pageSize := 20
@@ -194,7 +196,7 @@ func (c *Client) GetAllFavoriteVideos(mediaID string) ([]FavoriteMedia, error) {
 			Order:   "mtime", // 按收藏时间排序
 		}
 
-		listResp, err := c.GetFavoriteList(params)
+		listResp, err := c.GetFavoriteList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取第 %d 页失败: %w", page, err)
 		}
@@ -218,7 +220,7 @@ func (c *Client) GetAllFavoriteVideos(mediaID string) ([]FavoriteMedia, error) {
 }
 
 // GetFavoriteIDs 获取收藏夹所有内容ID
-func (c *Client) GetFavoriteIDs(mediaID string) ([]struct {
+func (c *Client) GetFavoriteIDs(ctx context.Context, mediaID string) ([]struct {
 	ID   int64  `json:"id"`
 	Type int    `json:"type"`
 	BVid string `json:"bvid"`
@@ -239,7 +241,7 @@ func (c *Client) GetFavoriteIDs(mediaID string) ([]struct {
 	apiURL := "https://api.bilibili.com/x/v3/fav/resource/ids?" + params.Encode()
 
 	var resp Response
-	err := c.GetJSON(apiURL, nil, &resp)
+	err := c.GetJSON(ctx, apiURL, nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取收藏夹ID列表失败: %w", err)
 	}
@@ -253,3 +255,37 @@ func (c *Client) GetFavoriteIDs(mediaID string) ([]struct {
 
 	return resp.Data, nil
 }
+
+// AddToFavorite 将视频添加到收藏夹（mediaID 为收藏夹 mlid）
+func (c *Client) AddToFavorite(ctx context.Context, aid, mediaID int64) error {
+	if c.credential == nil || c.credential.BiliJct == "" {
+		return fmt.Errorf("需要登录凭据（bili_jct）")
+	}
+
+	// 构建表单数据
+	form := url.Values{}
+	form.Set("rid", strconv.FormatInt(aid, 10))
+	form.Set("type", "2") // 2-视频稿件
+	form.Set("add_media_ids", strconv.FormatInt(mediaID, 10))
+	form.Set("csrf", c.credential.BiliJct)
+
+	type Response struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	var resp Response
+	err := c.PostJSON(ctx, "https://api.bilibili.com/x/v3/fav/resource/deal", nil, strings.NewReader(form.Encode()), &resp)
+	if err != nil {
+		return fmt.Errorf("添加到收藏夹失败: %w", err)
+	}
+
+	if resp.Code != 0 {
+		return &BiliError{
+			Code:    resp.Code,
+			Message: resp.Message,
+		}
+	}
+
+	return nil
+}
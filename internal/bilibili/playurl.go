@@ -0,0 +1,147 @@
+package bilibili
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// fnval 取流格式标志：16 DASH | 64 HDR | 128 4K | 256 杜比音频 | 512 杜比视界 | 1024 8K | 2048 AV1
+const playURLFnval = 4048
+
+// PlayURLInfo 取流信息
+type PlayURLInfo struct {
+	Quality           int       `json:"quality"`            // 实际返回的最高画质
+	Format            string    `json:"format"`             // 格式名
+	TimeLength        int64     `json:"timelength"`         // 时长（毫秒）
+	AcceptQuality     []int     `json:"accept_quality"`     // 可用画质列表
+	AcceptDescription []string  `json:"accept_description"` // 可用画质名称
+	Dash              *DashInfo `json:"dash"`               // DASH 流信息（非 DASH 格式时为空）
+}
+
+// DashInfo DASH 流信息
+type DashInfo struct {
+	Duration int          `json:"duration"` // 时长（秒）
+	Video    []DashStream `json:"video"`    // 视频流
+	Audio    []DashStream `json:"audio"`    // 音频流（无音频时为空）
+	Dolby    *DashDolby   `json:"dolby"`    // 杜比音频，视频不提供或账号无权限时为空
+	Flac     *DashFlac    `json:"flac"`     // Hi-Res 无损音频，视频不提供或账号无权限时为空
+}
+
+// DashDolby 杜比音频信息
+type DashDolby struct {
+	Type  int          `json:"type"`  // 1 普通杜比音效，2 杜比全景声
+	Audio []DashStream `json:"audio"` // 杜比音频流（音质代码 30250）
+}
+
+// DashFlac Hi-Res 无损音频信息
+type DashFlac struct {
+	Display bool        `json:"display"` // 是否在播放器中展示
+	Audio   *DashStream `json:"audio"`   // 无损音频流（音质代码 30251）
+}
+
+// AudioStreams 全部可用音频流，包括杜比音频与 Hi-Res 无损音频
+func (d *DashInfo) AudioStreams() []DashStream {
+	streams := append([]DashStream(nil), d.Audio...)
+	if d.Dolby != nil {
+		streams = append(streams, d.Dolby.Audio...)
+	}
+	if d.Flac != nil && d.Flac.Audio != nil {
+		streams = append(streams, *d.Flac.Audio)
+	}
+	return streams
+}
+
+// DashStream DASH 音视频流
+type DashStream struct {
+	ID        int      `json:"id"`         // 画质/音质代码
+	BaseURL   string   `json:"base_url"`   // 主地址
+	BackupURL []string `json:"backup_url"` // 备用地址
+	Bandwidth int64    `json:"bandwidth"`  // 码率（bps）
+	MimeType  string   `json:"mime_type"`  // 媒体类型
+	Codecs    string   `json:"codecs"`     // 编码字符串
+	CodecID   int      `json:"codecid"`    // 视频编码：7 AVC，12 HEVC，13 AV1
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	FrameRate string   `json:"frame_rate"`
+}
+
+// URLs 流的全部下载地址（主地址在前）
+func (s DashStream) URLs() []string {
+	urls := make([]string, 0, 1+len(s.BackupURL))
+	if s.BaseURL != "" {
+		urls = append(urls, s.BaseURL)
+	}
+	for _, u := range s.BackupURL {
+		if u != "" && u != s.BaseURL {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// GetPlayURL 获取普通视频分P的 DASH 取流地址（WBI 签名），qn 为期望的最高画质代码
+func (c *Client) GetPlayURL(ctx context.Context, bvid string, cid int64, qn int) (*PlayURLInfo, error) {
+	params := url.Values{}
+	params.Set("bvid", bvid)
+	params.Set("cid", strconv.FormatInt(cid, 10))
+	params.Set("qn", strconv.Itoa(qn))
+	params.Set("fnval", strconv.Itoa(playURLFnval))
+	params.Set("fnver", "0")
+	params.Set("fourk", "1")
+
+	var result struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Data    PlayURLInfo `json:"data"`
+	}
+	if err := c.GetJSONWithWBI(ctx, "https://api.bilibili.com/x/player/wbi/playurl", params, &result); err != nil {
+		return nil, fmt.Errorf("获取取流地址失败: %w", err)
+	}
+
+	if result.Code != 0 {
+		return nil, &BiliError{
+			Code:    result.Code,
+			Message: result.Message,
+		}
+	}
+	return &result.Data, nil
+}
+
+// GetBangumiPlayURL 获取番剧剧集的 DASH 取流地址，qn 为期望的最高画质代码
+func (c *Client) GetBangumiPlayURL(ctx context.Context, epID, cid int64, qn int) (*PlayURLInfo, error) {
+	params := url.Values{}
+	params.Set("ep_id", strconv.FormatInt(epID, 10))
+	if cid > 0 {
+		params.Set("cid", strconv.FormatInt(cid, 10))
+	}
+	params.Set("qn", strconv.Itoa(qn))
+	params.Set("fnval", strconv.Itoa(playURLFnval))
+	params.Set("fnver", "0")
+	params.Set("fourk", "1")
+
+	var result struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Result  PlayURLInfo `json:"result"`
+	}
+	apiURL := "https://api.bilibili.com/pgc/player/web/playurl?" + params.Encode()
+	if err := c.GetJSON(ctx, apiURL, nil, &result); err != nil {
+		return nil, fmt.Errorf("获取番剧取流地址失败: %w", err)
+	}
+
+	if result.Code != 0 {
+		if result.Code == CodeBangumiRestricted {
+			return nil, &BiliError{
+				Code:    result.Code,
+				Message: "番剧受地区限制或需要大会员: " + result.Message,
+			}
+		}
+		return nil, &BiliError{
+			Code:    result.Code,
+			Message: result.Message,
+		}
+	}
+	return &result.Result, nil
+}
@@ -0,0 +1,46 @@
+package bilibili
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDashInfoAudioStreams(t *testing.T) {
+	raw := `{"duration":120,
+		"video":[{"id":126,"base_url":"https://upos/dv.m4s","codecid":12},{"id":80,"base_url":"https://upos/80.m4s","codecid":7}],
+		"audio":[{"id":30280,"base_url":"https://upos/30280.m4s"},{"id":30216,"base_url":"https://upos/30216.m4s"}],
+		"dolby":{"type":2,"audio":[{"id":30250,"base_url":"https://upos/dolby.m4s","codecs":"ec-3"}]},
+		"flac":{"display":true,"audio":{"id":30251,"base_url":"https://upos/flac.m4s","codecs":"fLaC"}}}`
+
+	var dash DashInfo
+	if err := json.Unmarshal([]byte(raw), &dash); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	streams := dash.AudioStreams()
+	var ids []int
+	for _, s := range streams {
+		ids = append(ids, s.ID)
+	}
+	want := []int{30280, 30216, 30250, 30251}
+	if len(ids) != len(want) {
+		t.Fatalf("AudioStreams() ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("AudioStreams() ids = %v, want %v", ids, want)
+		}
+	}
+	if len(dash.Audio) != 2 {
+		t.Errorf("AudioStreams should not modify the normal audio list, got %d streams", len(dash.Audio))
+	}
+
+	// 无权限时 dolby/flac 为 null
+	var plain DashInfo
+	if err := json.Unmarshal([]byte(`{"audio":[{"id":30280}],"dolby":null,"flac":{"display":false,"audio":null}}`), &plain); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := plain.AudioStreams(); len(got) != 1 || got[0].ID != 30280 {
+		t.Errorf("expected only the normal audio stream, got %+v", got)
+	}
+}
@@ -0,0 +1,236 @@
+package bilibili
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/utils"
+)
+
+// EndpointClass 接口类别，各类别使用独立的请求预算
+type EndpointClass string
+
+const (
+	EndpointDefault EndpointClass = "default" // 普通接口
+	EndpointWBI     EndpointClass = "wbi"     // WBI 签名接口
+	EndpointPlayURL EndpointClass = "playurl" // 取流接口
+	EndpointDanmaku EndpointClass = "danmaku" // 弹幕接口
+)
+
+const (
+	// cooldownBase 首次触发风控后的冷却时间，连续触发时逐次翻倍
+	cooldownBase = 15 * time.Second
+	// cooldownMax 冷却时间上限
+	cooldownMax = 10 * time.Minute
+	// HTTPStatusRiskControl HTTP 412，B 站风控拦截
+	HTTPStatusRiskControl = 412
+)
+
+// classifyEndpoint 按请求地址判断接口类别
+func classifyEndpoint(rawURL string) EndpointClass {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return EndpointDefault
+	}
+	path := u.Path
+	switch {
+	case strings.Contains(path, "playurl"):
+		return EndpointPlayURL
+	case strings.Contains(path, "/dm/"):
+		return EndpointDanmaku
+	case strings.Contains(path, "/wbi/"):
+		return EndpointWBI
+	default:
+		return EndpointDefault
+	}
+}
+
+// CooldownState 风控冷却状态
+type CooldownState struct {
+	Active  bool      `json:"active"`
+	Until   time.Time `json:"until"`
+	Strikes int       `json:"strikes"`  // 连续触发风控的次数
+	Code    int       `json:"code"`     // 最近一次触发风控的错误码（HTTP 412 记为 412）
+	URLPath string    `json:"url_path"` // 最近一次触发风控的接口
+}
+
+// tokenBucket 令牌桶，每 interval 补充一个令牌，最多积累 capacity 个
+type tokenBucket struct {
+	capacity float64
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rule config.RateLimitRule) *tokenBucket {
+	b := &tokenBucket{}
+	b.configure(rule)
+	b.tokens = b.capacity
+	return b
+}
+
+// configure 按预算调整容量与补充速度，保留已有令牌
+func (b *tokenBucket) configure(rule config.RateLimitRule) {
+	b.capacity = float64(rule.Limit)
+	b.interval = time.Duration(rule.DurationMS) * time.Millisecond / time.Duration(rule.Limit)
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = time.Now()
+}
+
+// reserve 取一个令牌，返回需要等待的时间（0 表示已取得）
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(b.interval)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
+
+// RateLimiter B 站接口请求限速器
+// 按接口类别分别限速，检测到风控（-412/-509/HTTP 412）后进入指数退避的冷却期，冷却期内所有请求等待
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[EndpointClass]*tokenBucket
+	cooldown  CooldownState
+	listeners []func(CooldownState)
+}
+
+// NewRateLimiter 创建限速器
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{buckets: make(map[EndpointClass]*tokenBucket)}
+	l.Configure(cfg)
+	return l
+}
+
+var sharedRateLimiter = NewRateLimiter(config.RateLimitConfig{DurationMS: 250, Limit: 4})
+
+// SharedRateLimiter 进程内共享的限速器，所有 Client 共用同一份预算与冷却状态
+func SharedRateLimiter() *RateLimiter {
+	return sharedRateLimiter
+}
+
+// Configure 更新各类接口的预算，对等待中的请求立即生效
+func (l *RateLimiter) Configure(cfg config.RateLimitConfig) {
+	if cfg.DurationMS <= 0 || cfg.Limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rules := map[EndpointClass]config.RateLimitRule{
+		EndpointDefault: {DurationMS: cfg.DurationMS, Limit: cfg.Limit},
+		EndpointWBI:     cfg.WBI.Resolve(cfg),
+		EndpointPlayURL: cfg.PlayURL.Resolve(cfg),
+		EndpointDanmaku: cfg.Danmaku.Resolve(cfg),
+	}
+	for class, rule := range rules {
+		if bucket, ok := l.buckets[class]; ok {
+			bucket.configure(rule)
+		} else {
+			l.buckets[class] = newTokenBucket(rule)
+		}
+	}
+}
+
+// Wait 等待冷却结束并取得对应类别的请求额度，ctx 取消时返回错误
+func (l *RateLimiter) Wait(ctx context.Context, class EndpointClass) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if l.cooldown.Active && now.Before(l.cooldown.Until) {
+			wait = l.cooldown.Until.Sub(now)
+		} else if bucket, ok := l.buckets[class]; ok {
+			wait = bucket.reserve(now)
+		}
+		l.mu.Unlock()
+
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// ReportRiskControl 记录一次风控响应，进入（或延长）冷却期
+func (l *RateLimiter) ReportRiskControl(code int, urlPath string) {
+	l.mu.Lock()
+	now := time.Now()
+	// 冷却期内已在途的请求返回风控时不重复翻倍
+	if l.cooldown.Active && now.Before(l.cooldown.Until) {
+		l.mu.Unlock()
+		return
+	}
+
+	l.cooldown.Strikes++
+	backoff := cooldownBase << (l.cooldown.Strikes - 1)
+	if backoff > cooldownMax || backoff <= 0 {
+		backoff = cooldownMax
+	}
+	l.cooldown.Active = true
+	l.cooldown.Until = now.Add(backoff)
+	l.cooldown.Code = code
+	l.cooldown.URLPath = urlPath
+	state := l.cooldown
+	listeners := append([]func(CooldownState){}, l.listeners...)
+	l.mu.Unlock()
+
+	utils.Warn("B 站接口触发风控（%d，%s），暂停请求 %s（第 %d 次）", code, urlPath, backoff, state.Strikes)
+	for _, fn := range listeners {
+		fn(state)
+	}
+}
+
+// ReportSuccess 记录一次正常响应，冷却期结束后的首次正常响应解除风控状态
+func (l *RateLimiter) ReportSuccess() {
+	l.mu.Lock()
+	if !l.cooldown.Active || time.Now().Before(l.cooldown.Until) {
+		l.mu.Unlock()
+		return
+	}
+	l.cooldown = CooldownState{}
+	state := l.cooldown
+	listeners := append([]func(CooldownState){}, l.listeners...)
+	l.mu.Unlock()
+
+	utils.Info("B 站接口已恢复正常，解除风控冷却")
+	for _, fn := range listeners {
+		fn(state)
+	}
+}
+
+// Cooldown 当前风控冷却状态
+func (l *RateLimiter) Cooldown() CooldownState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cooldown
+}
+
+// OnCooldownChange 注册冷却状态变化回调：进入冷却时 Active 为 true，恢复正常时为 false
+func (l *RateLimiter) OnCooldownChange(fn func(CooldownState)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
@@ -0,0 +1,165 @@
+package bilibili
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+)
+
+func TestClassifyEndpoint(t *testing.T) {
+	cases := map[string]EndpointClass{
+		"https://api.bilibili.com/x/space/wbi/arc/search?mid=1":     EndpointWBI,
+		"https://api.bilibili.com/x/web-interface/wbi/view?bvid=BV": EndpointWBI,
+		"https://api.bilibili.com/x/player/wbi/playurl?bvid=BV":     EndpointPlayURL,
+		"https://api.bilibili.com/x/v2/dm/web/seg.so?oid=1":         EndpointDanmaku,
+		"https://api.bilibili.com/x/v3/fav/resource/list?media_id=": EndpointDefault,
+	}
+	for rawURL, want := range cases {
+		if got := classifyEndpoint(rawURL); got != want {
+			t.Fatalf("classifyEndpoint(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestRateLimiterUsesPerClassBudgets(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{
+		DurationMS: 200,
+		Limit:      2,
+		Danmaku:    config.RateLimitRule{DurationMS: 1000, Limit: 10},
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background(), EndpointDefault); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected default budget to throttle, took %s", elapsed)
+	}
+
+	// 弹幕接口使用独立预算，不受默认预算消耗影响
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background(), EndpointDanmaku); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected danmaku budget to allow burst, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterWaitHonoursContextDuringCooldown(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{DurationMS: 250, Limit: 4})
+	limiter.ReportRiskControl(CodeRiskControl, "/x/test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, EndpointDefault); err == nil {
+		t.Fatal("expected wait to be cancelled during cooldown")
+	}
+}
+
+func TestRateLimiterBackoffDoublesAndResetsOnSuccess(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{DurationMS: 250, Limit: 4})
+
+	var changes []CooldownState
+	limiter.OnCooldownChange(func(state CooldownState) {
+		changes = append(changes, state)
+	})
+
+	limiter.ReportRiskControl(CodeTooManyRequests, "/x/a")
+	first := limiter.Cooldown()
+	if !first.Active || first.Strikes != 1 || first.Code != CodeTooManyRequests {
+		t.Fatalf("unexpected cooldown state: %+v", first)
+	}
+
+	// 冷却期内的重复上报不延长冷却
+	limiter.ReportRiskControl(CodeRiskControl, "/x/b")
+	if got := limiter.Cooldown(); got.Strikes != 1 {
+		t.Fatalf("expected strikes to stay 1 during cooldown, got %+v", got)
+	}
+
+	limiter.mu.Lock()
+	limiter.cooldown.Until = time.Now().Add(-time.Second)
+	limiter.mu.Unlock()
+
+	before := time.Now()
+	limiter.ReportRiskControl(CodeRiskControl, "/x/c")
+	second := limiter.Cooldown()
+	if second.Strikes != 2 || second.Until.Sub(before) < 2*cooldownBase-time.Second {
+		t.Fatalf("expected doubled backoff, got %+v", second)
+	}
+
+	// 冷却期内的正常响应不解除冷却
+	limiter.ReportSuccess()
+	if !limiter.Cooldown().Active {
+		t.Fatal("expected cooldown to stay active before it expires")
+	}
+
+	limiter.mu.Lock()
+	limiter.cooldown.Until = time.Now().Add(-time.Second)
+	limiter.mu.Unlock()
+	limiter.ReportSuccess()
+	if got := limiter.Cooldown(); got.Active || got.Strikes != 0 {
+		t.Fatalf("expected cooldown to reset after success, got %+v", got)
+	}
+
+	if len(changes) != 3 || !changes[0].Active || !changes[1].Active || changes[2].Active {
+		t.Fatalf("unexpected cooldown notifications: %+v", changes)
+	}
+}
+
+func TestClientReportsRiskControlResponses(t *testing.T) {
+	var code atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(int(status.Load()))
+		fmt.Fprintf(w, `{"code":%d,"message":"","data":{"value":1}}`, code.Load())
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(config.RateLimitConfig{DurationMS: 250, Limit: 100})
+	client := &Client{httpClient: server.Client(), limiter: limiter}
+
+	var result struct {
+		Code int `json:"code"`
+		Data struct {
+			Value int `json:"value"`
+		} `json:"data"`
+	}
+	if err := client.GetJSON(context.Background(), server.URL+"/x/ok", nil, &result); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if result.Data.Value != 1 {
+		t.Fatalf("expected body to remain readable after inspection, got %+v", result)
+	}
+
+	code.Store(CodeRiskControl)
+	if err := client.GetJSON(context.Background(), server.URL+"/x/blocked", nil, &result); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if state := limiter.Cooldown(); !state.Active || state.Code != CodeRiskControl || state.URLPath != "/x/blocked" {
+		t.Fatalf("expected -412 to start cooldown, got %+v", state)
+	}
+
+	limiter.mu.Lock()
+	limiter.cooldown = CooldownState{}
+	limiter.mu.Unlock()
+
+	code.Store(0)
+	status.Store(HTTPStatusRiskControl)
+	client.GetJSON(context.Background(), server.URL+"/x/http412", nil, &result)
+	if state := limiter.Cooldown(); !state.Active || state.Code != HTTPStatusRiskControl {
+		t.Fatalf("expected HTTP 412 to start cooldown, got %+v", state)
+	}
+}
@@ -1,6 +1,7 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -97,7 +98,7 @@ type SubmissionListParams struct {
 }
 
 // GetSubmissionList 获取UP主投稿视频列表（需要WBI签名）
-func (c *Client) GetSubmissionList(params SubmissionListParams) (*SubmissionListResponse, error) {
+func (c *Client) GetSubmissionList(ctx context.Context, params SubmissionListParams) (*SubmissionListResponse, error) {
 	// 构建查询参数
 	query := url.Values{}
 	query.Set("mid", params.Mid)
@@ -132,7 +133,7 @@ func (c *Client) GetSubmissionList(params SubmissionListParams) (*SubmissionList
 	query.Set("web_location", "1550101")
 
 	// 进行 WBI 签名
-	signedParams, err := c.GetWbiSignedParams(query)
+	signedParams, err := c.GetWbiSignedParams(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("WBI签名失败: %w", err)
 	}
@@ -140,7 +141,7 @@ func (c *Client) GetSubmissionList(params SubmissionListParams) (*SubmissionList
 	// 构建完整URL
 	apiURL := "https://api.bilibili.com/x/space/wbi/arc/search?" + signedParams.Encode()
 
-	resp, err := c.Get(apiURL, nil)
+	resp, err := c.Get(ctx, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取UP主投稿列表失败: %w", err)
 	}
@@ -172,7 +173,7 @@ func (c *Client) GetSubmissionList(params SubmissionListParams) (*SubmissionList
 }
 
 // GetAllSubmissionVideos 获取UP主所有投稿视频（自动翻页）
-func (c *Client) GetAllSubmissionVideos(mid string, order string) ([]SubmissionVideo, error) {
+func (c *Client) GetAllSubmissionVideos(ctx context.Context, mid string, order string) ([]SubmissionVideo, error) {
 	var allVideos []SubmissionVideo
 	page := 1
 	pageSize := 30
@@ -185,7 +186,7 @@ func (c *Client) GetAllSubmissionVideos(mid string, order string) ([]SubmissionV
 			Order: order,
 		}
 
-		listResp, err := c.GetSubmissionList(params)
+		listResp, err := c.GetSubmissionList(ctx, params)
 		if err != nil {
 			return nil, fmt.Errorf("获取第 %d 页失败: %w", page, err)
 		}
@@ -209,7 +210,7 @@ func (c *Client) GetAllSubmissionVideos(mid string, order string) ([]SubmissionV
 }
 
 // GetUpperCard 获取UP主名片信息
-func (c *Client) GetUpperCard(mid string) (*UpperCardInfo, error) {
+func (c *Client) GetUpperCard(ctx context.Context, mid string) (*UpperCardInfo, error) {
 	apiURL := fmt.Sprintf("https://api.bilibili.com/x/web-interface/card?mid=%s", mid)
 
 	var result struct {
@@ -220,7 +221,7 @@ func (c *Client) GetUpperCard(mid string) (*UpperCardInfo, error) {
 		} `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("获取UP主信息失败: %w", err)
 	}
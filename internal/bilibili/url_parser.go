@@ -16,14 +16,25 @@ const (
 	SourceTypeWatchLater SourceType = "watch_later"
 	SourceTypeCollection SourceType = "collection"
 	SourceTypeSubmission SourceType = "submission"
+	SourceTypeBangumi    SourceType = "bangumi"
 )
 
+// 番剧 URL 子类型
+const (
+	BangumiSubTypeSeason  = "ss" // 季度 /bangumi/play/ssXXX
+	BangumiSubTypeEpisode = "ep" // 单集 /bangumi/play/epXXX
+	BangumiSubTypeMedia   = "md" // 剧集详情页 /bangumi/media/mdXXX
+)
+
+// bangumiPathPattern 番剧 URL 路径
+var bangumiPathPattern = regexp.MustCompile(`/bangumi/(?:play|media)/(ss|ep|md)(\d+)`)
+
 // ParsedURL 解析后的 URL 信息
 type ParsedURL struct {
 	Type    SourceType
 	ID      int64  // FID, CID, UpperID 等
 	Name    string // 可选的名称
-	SubType string // 合集子类型 (series/season)
+	SubType string // 合集子类型 (series/season)，番剧为 ss/ep/md
 }
 
 // URLParser URL 解析器
@@ -115,21 +126,20 @@ func (p *URLParser) Parse(rawURL string) (*ParsedURL, error) {
 		}, nil
 	}
 
-	// 番剧/影视合集: https://www.bilibili.com/bangumi/play/ss12345
-	if strings.Contains(path, "/bangumi/play/ss") {
-		re := regexp.MustCompile(`/bangumi/play/ss(\d+)`)
-		matches := re.FindStringSubmatch(path)
-		if len(matches) < 2 {
+	// 番剧/影视: https://www.bilibili.com/bangumi/play/ss12345、/bangumi/play/ep12345、/bangumi/media/md12345
+	if strings.Contains(path, "/bangumi/") {
+		matches := bangumiPathPattern.FindStringSubmatch(path)
+		if len(matches) < 3 {
 			return nil, fmt.Errorf("无效的番剧 URL")
 		}
-		ssid, err := strconv.ParseInt(matches[1], 10, 64)
+		id, err := strconv.ParseInt(matches[2], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("无效的番剧 ID: %s", matches[1])
+			return nil, fmt.Errorf("无效的番剧 ID: %s", matches[2])
 		}
 		return &ParsedURL{
-			Type:    SourceTypeCollection,
-			ID:      ssid,
-			SubType: "season",
+			Type:    SourceTypeBangumi,
+			ID:      id,
+			SubType: matches[1],
 		}, nil
 	}
 
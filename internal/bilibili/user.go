@@ -1,6 +1,7 @@
 package bilibili
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -45,7 +46,7 @@ type UserCollection struct {
 }
 
 // GetMe 获取当前登录用户信息（带缓存，10分钟过期）
-func (c *Client) GetMe() (*UserInfo, error) {
+func (c *Client) GetMe(ctx context.Context) (*UserInfo, error) {
 	// 缓存有效则直接返回
 	if c.cachedUserInfo != nil && time.Since(c.userCachedAt) < 10*time.Minute {
 		return c.cachedUserInfo, nil
@@ -69,7 +70,7 @@ func (c *Client) GetMe() (*UserInfo, error) {
 	}
 
 	var resp NavResponse
-	err := c.GetJSON("https://api.bilibili.com/x/web-interface/nav", nil, &resp)
+	err := c.GetJSON(ctx, "https://api.bilibili.com/x/web-interface/nav", nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取用户信息失败: %w", err)
 	}
@@ -105,7 +106,7 @@ func (c *Client) GetMe() (*UserInfo, error) {
 }
 
 // CheckCredentialValid 检查凭据是否有效
-func (c *Client) CheckCredentialValid() (bool, error) {
+func (c *Client) CheckCredentialValid(ctx context.Context) (bool, error) {
 	type CookieInfoResponse struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -115,7 +116,7 @@ func (c *Client) CheckCredentialValid() (bool, error) {
 	}
 
 	var resp CookieInfoResponse
-	err := c.GetJSON("https://passport.bilibili.com/x/passport-login/web/cookie/info", nil, &resp)
+	err := c.GetJSON(ctx, "https://passport.bilibili.com/x/passport-login/web/cookie/info", nil, &resp)
 	if err != nil {
 		return false, fmt.Errorf("检查凭据失败: %w", err)
 	}
@@ -136,7 +137,7 @@ func (c *Client) CheckCredentialValid() (bool, error) {
 }
 
 // GetUpperInfo 获取 UP 主信息
-func (c *Client) GetUpperInfo(mid int64) (*UserInfo, error) {
+func (c *Client) GetUpperInfo(ctx context.Context, mid int64) (*UserInfo, error) {
 	type UserInfoResponse struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -153,7 +154,7 @@ func (c *Client) GetUpperInfo(mid int64) (*UserInfo, error) {
 
 	url := fmt.Sprintf("https://api.bilibili.com/x/space/acc/info?mid=%d", mid)
 	var resp UserInfoResponse
-	err := c.GetJSON(url, nil, &resp)
+	err := c.GetJSON(ctx, url, nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取 UP 主信息失败: %w", err)
 	}
@@ -177,7 +178,7 @@ func (c *Client) GetUpperInfo(mid int64) (*UserInfo, error) {
 }
 
 // GetUserCreatedFavorites 获取用户创建的收藏夹列表
-func (c *Client) GetUserCreatedFavorites(mid int64) ([]UserFavoriteFolder, error) {
+func (c *Client) GetUserCreatedFavorites(ctx context.Context, mid int64) ([]UserFavoriteFolder, error) {
 	type Response struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -193,7 +194,7 @@ func (c *Client) GetUserCreatedFavorites(mid int64) ([]UserFavoriteFolder, error
 	apiURL := "https://api.bilibili.com/x/v3/fav/folder/created/list-all?" + params.Encode()
 
 	var resp Response
-	err := c.GetJSON(apiURL, nil, &resp)
+	err := c.GetJSON(ctx, apiURL, nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取用户收藏夹列表失败: %w", err)
 	}
@@ -218,7 +219,7 @@ type FollowingUser struct {
 }
 
 // GetUserFollowings 获取用户关注的UP主列表
-func (c *Client) GetUserFollowings(mid int64, pn, ps int) ([]FollowingUser, int, error) {
+func (c *Client) GetUserFollowings(ctx context.Context, mid int64, pn, ps int) ([]FollowingUser, int, error) {
 	type Response struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -245,7 +246,7 @@ func (c *Client) GetUserFollowings(mid int64, pn, ps int) ([]FollowingUser, int,
 	apiURL := "https://api.bilibili.com/x/relation/followings?" + params.Encode()
 
 	var resp Response
-	err := c.GetJSON(apiURL, nil, &resp)
+	err := c.GetJSON(ctx, apiURL, nil, &resp)
 	if err != nil {
 		return nil, 0, fmt.Errorf("获取关注列表失败: %w", err)
 	}
@@ -261,7 +262,7 @@ func (c *Client) GetUserFollowings(mid int64, pn, ps int) ([]FollowingUser, int,
 }
 
 // SearchFollowings 搜索关注的UP主
-func (c *Client) SearchFollowings(mid int64, name string, pn, ps int) ([]FollowingUser, int, error) {
+func (c *Client) SearchFollowings(ctx context.Context, mid int64, name string, pn, ps int) ([]FollowingUser, int, error) {
 	type Response struct {
 		Code int `json:"code"`
 
@@ -288,7 +289,7 @@ func (c *Client) SearchFollowings(mid int64, name string, pn, ps int) ([]Followi
 	apiURL := "https://api.bilibili.com/x/relation/followings/search?" + params.Encode()
 
 	var resp Response
-	err := c.GetJSON(apiURL, nil, &resp)
+	err := c.GetJSON(ctx, apiURL, nil, &resp)
 	if err != nil {
 		return nil, 0, fmt.Errorf("搜索关注列表失败: %w", err)
 	}
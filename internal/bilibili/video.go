@@ -1,10 +1,12 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 )
 
 // VideoDetail 视频详细信息
@@ -170,13 +172,13 @@ type StaffMember struct {
 }
 
 // GetVideoDetail 获取视频详细信息（需要WBI签名）
-func (c *Client) GetVideoDetail(bvid string) (*VideoDetail, error) {
+func (c *Client) GetVideoDetail(ctx context.Context, bvid string) (*VideoDetail, error) {
 	// 构建查询参数
 	query := url.Values{}
 	query.Set("bvid", bvid)
 
 	// 进行 WBI 签名
-	signedParams, err := c.GetWbiSignedParams(query)
+	signedParams, err := c.GetWbiSignedParams(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("WBI签名失败: %w", err)
 	}
@@ -184,7 +186,7 @@ func (c *Client) GetVideoDetail(bvid string) (*VideoDetail, error) {
 	// 构建完整URL
 	apiURL := "https://api.bilibili.com/x/web-interface/wbi/view?" + signedParams.Encode()
 
-	resp, err := c.Get(apiURL, nil)
+	resp, err := c.Get(ctx, apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取视频详情失败: %w", err)
 	}
@@ -216,7 +218,7 @@ func (c *Client) GetVideoDetail(bvid string) (*VideoDetail, error) {
 }
 
 // GetVideoPages 获取视频分P列表
-func (c *Client) GetVideoPages(bvid string) ([]VideoPage, error) {
+func (c *Client) GetVideoPages(ctx context.Context, bvid string) ([]VideoPage, error) {
 	apiURL := fmt.Sprintf("https://api.bilibili.com/x/player/pagelist?bvid=%s", bvid)
 
 	var result struct {
@@ -225,7 +227,7 @@ func (c *Client) GetVideoPages(bvid string) ([]VideoPage, error) {
 		Data    []VideoPage `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("获取视频分P列表失败: %w", err)
 	}
@@ -250,7 +252,7 @@ type VideoTag struct {
 }
 
 // GetVideoTags 获取视频标签
-func (c *Client) GetVideoTags(bvid string) ([]VideoTag, error) {
+func (c *Client) GetVideoTags(ctx context.Context, bvid string) ([]VideoTag, error) {
 	apiURL := fmt.Sprintf("https://api.bilibili.com/x/web-interface/view/detail/tag?bvid=%s", bvid)
 
 	var result struct {
@@ -259,7 +261,7 @@ func (c *Client) GetVideoTags(bvid string) ([]VideoTag, error) {
 		Data    []VideoTag `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("获取视频标签失败: %w", err)
 	}
@@ -275,7 +277,7 @@ func (c *Client) GetVideoTags(bvid string) ([]VideoTag, error) {
 }
 
 // GetVideoDescription 获取视频简介
-func (c *Client) GetVideoDescription(bvid string) (string, error) {
+func (c *Client) GetVideoDescription(ctx context.Context, bvid string) (string, error) {
 	apiURL := fmt.Sprintf("https://api.bilibili.com/x/web-interface/archive/desc?bvid=%s", bvid)
 
 	var result struct {
@@ -284,7 +286,7 @@ func (c *Client) GetVideoDescription(bvid string) (string, error) {
 		Data    string `json:"data"`
 	}
 
-	err := c.GetJSON(apiURL, nil, &result)
+	err := c.GetJSON(ctx, apiURL, nil, &result)
 	if err != nil {
 		return "", fmt.Errorf("获取视频简介失败: %w", err)
 	}
@@ -313,11 +315,14 @@ type SubtitleLine struct {
 }
 
 // GetSubtitleContent 下载字幕内容
-func (c *Client) GetSubtitleContent(subtitleURL string) (*SubtitleContent, error) {
-	// 字幕URL需要添加https前缀
-	fullURL := "https:" + subtitleURL
+func (c *Client) GetSubtitleContent(ctx context.Context, subtitleURL string) (*SubtitleContent, error) {
+	// 字幕URL可能省略协议（//开头），需要添加https前缀
+	fullURL := subtitleURL
+	if strings.HasPrefix(fullURL, "//") {
+		fullURL = "https:" + fullURL
+	}
 
-	resp, err := c.Get(fullURL, nil)
+	resp, err := c.Get(ctx, fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("下载字幕失败: %w", err)
 	}
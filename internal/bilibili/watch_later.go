@@ -2,6 +2,7 @@ package bilibili
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -78,8 +79,8 @@ type WatchLaterListResponse struct {
 }
 
 // GetWatchLaterList 获取稍后再看视频列表
-func (c *Client) GetWatchLaterList() (*WatchLaterListResponse, error) {
-	resp, err := c.Get("https://api.bilibili.com/x/v2/history/toview", nil)
+func (c *Client) GetWatchLaterList(ctx context.Context) (*WatchLaterListResponse, error) {
+	resp, err := c.Get(ctx, "https://api.bilibili.com/x/v2/history/toview", nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取稍后再看列表失败: %w", err)
 	}
@@ -111,7 +112,7 @@ func (c *Client) GetWatchLaterList() (*WatchLaterListResponse, error) {
 }
 
 // AddToWatchLater 添加视频到稍后再看
-func (c *Client) AddToWatchLater(bvid string) error {
+func (c *Client) AddToWatchLater(ctx context.Context, bvid string) error {
 	if c.credential == nil || c.credential.BiliJct == "" {
 		return fmt.Errorf("需要登录凭据（bili_jct）")
 	}
@@ -120,6 +121,7 @@ func (c *Client) AddToWatchLater(bvid string) error {
 	formData := fmt.Sprintf("bvid=%s&csrf=%s", bvid, c.credential.BiliJct)
 
 	resp, err := c.Post(
+		ctx,
 		"https://api.bilibili.com/x/v2/history/toview/add",
 		map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
@@ -156,7 +158,7 @@ func (c *Client) AddToWatchLater(bvid string) error {
 }
 
 // DeleteFromWatchLater 从稍后再看删除视频
-func (c *Client) DeleteFromWatchLater(aid int64) error {
+func (c *Client) DeleteFromWatchLater(ctx context.Context, aid int64) error {
 	if c.credential == nil || c.credential.BiliJct == "" {
 		return fmt.Errorf("需要登录凭据（bili_jct）")
 	}
@@ -165,6 +167,7 @@ func (c *Client) DeleteFromWatchLater(aid int64) error {
 	formData := fmt.Sprintf("aid=%d&csrf=%s", aid, c.credential.BiliJct)
 
 	resp, err := c.Post(
+		ctx,
 		"https://api.bilibili.com/x/v2/history/toview/del",
 		map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
@@ -201,7 +204,7 @@ func (c *Client) DeleteFromWatchLater(aid int64) error {
 }
 
 // ClearWatchLater 清空稍后再看列表
-func (c *Client) ClearWatchLater() error {
+func (c *Client) ClearWatchLater(ctx context.Context) error {
 	if c.credential == nil || c.credential.BiliJct == "" {
 		return fmt.Errorf("需要登录凭据（bili_jct）")
 	}
@@ -210,6 +213,7 @@ func (c *Client) ClearWatchLater() error {
 	formData := fmt.Sprintf("csrf=%s", c.credential.BiliJct)
 
 	resp, err := c.Post(
+		ctx,
 		"https://api.bilibili.com/x/v2/history/toview/clear",
 		map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
@@ -1,6 +1,7 @@
 package bilibili
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
@@ -67,7 +68,7 @@ func getFilename(urlStr string) string {
 }
 
 // GetWbiImg 获取 WBI 图片信息
-func (c *Client) GetWbiImg() (*WbiImg, error) {
+func (c *Client) GetWbiImg(ctx context.Context) (*WbiImg, error) {
 	type NavResponse struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -77,7 +78,7 @@ func (c *Client) GetWbiImg() (*WbiImg, error) {
 	}
 
 	var resp NavResponse
-	err := c.GetJSON("https://api.bilibili.com/x/web-interface/nav", nil, &resp)
+	err := c.GetJSON(ctx, "https://api.bilibili.com/x/web-interface/nav", nil, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("获取 WBI 信息失败: %w", err)
 	}
@@ -124,8 +125,8 @@ func SignWBI(params url.Values, mixinKey string) url.Values {
 }
 
 // GetWbiSignedParams 获取带 WBI 签名的参数
-func (c *Client) GetWbiSignedParams(params url.Values) (url.Values, error) {
-	mixinKey, err := c.getCachedMixinKey()
+func (c *Client) GetWbiSignedParams(ctx context.Context, params url.Values) (url.Values, error) {
+	mixinKey, err := c.getCachedMixinKey(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -134,12 +135,12 @@ func (c *Client) GetWbiSignedParams(params url.Values) (url.Values, error) {
 }
 
 // getCachedMixinKey 获取缓存的WBI混淆密钥，30分钟刷新一次
-func (c *Client) getCachedMixinKey() (string, error) {
+func (c *Client) getCachedMixinKey(ctx context.Context) (string, error) {
 	if c.wbiMixinKey != "" && time.Since(c.wbiCachedAt) < 30*time.Minute {
 		return c.wbiMixinKey, nil
 	}
 
-	wbiImg, err := c.GetWbiImg()
+	wbiImg, err := c.GetWbiImg(ctx)
 	if err != nil {
 		// 缓存未过期时降级使用旧key
 		if c.wbiMixinKey != "" {
@@ -162,9 +163,9 @@ func (c *Client) getCachedMixinKey() (string, error) {
 }
 
 // GetJSONWithWBI 使用 WBI 签名发送 GET 请求并解析 JSON 响应
-func (c *Client) GetJSONWithWBI(baseURL string, params url.Values, result interface{}) error {
+func (c *Client) GetJSONWithWBI(ctx context.Context, baseURL string, params url.Values, result interface{}) error {
 	// 对参数进行 WBI 签名
-	signedParams, err := c.GetWbiSignedParams(params)
+	signedParams, err := c.GetWbiSignedParams(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -175,5 +176,5 @@ func (c *Client) GetJSONWithWBI(baseURL string, params url.Values, result interf
 		fullURL += "?" + signedParams.Encode()
 	}
 
-	return c.GetJSON(fullURL, nil, result)
+	return c.GetJSON(ctx, fullURL, nil, result)
 }
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -43,8 +45,56 @@ type DatabaseConfig struct {
 
 // SyncConfig 同步配置
 type SyncConfig struct {
-	Interval int  `yaml:"interval" mapstructure:"interval" json:"interval"` // 秒
-	ScanOnly bool `yaml:"scan_only" mapstructure:"scan_only" json:"scan_only"`
+	Interval   int              `yaml:"interval" mapstructure:"interval" json:"interval"` // 秒
+	Cron       string           `yaml:"cron" mapstructure:"cron" json:"cron"`             // 全局同步 cron 表达式（5 段），设置后替代 interval 作为默认同步计划
+	ScanOnly   bool             `yaml:"scan_only" mapstructure:"scan_only" json:"scan_only"`
+	GlobalRule string           `yaml:"global_rule" mapstructure:"global_rule" json:"global_rule"` // 全局过滤规则（JSON，与视频源 rule 字段格式相同，视频源规则优先）
+	QuietHours QuietHoursConfig `yaml:"quiet_hours" mapstructure:"quiet_hours" json:"quiet_hours"`
+	// 全量扫描间隔（天）：平时增量扫描，遇到已入库的视频即停止翻页；每隔该天数完整扫描一次以发现顺序变化，0 表示仅首次扫描为全量
+	FullScanDays int `yaml:"full_scan_days" mapstructure:"full_scan_days" json:"full_scan_days"`
+}
+
+// QuietHoursConfig 静默时段配置：时段内不触发自动同步，下载管理器也不再开始新任务（运行中的任务可继续完成）
+type QuietHoursConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled" json:"enabled"`
+	Start   string `yaml:"start" mapstructure:"start" json:"start"` // 开始时间（HH:MM）
+	End     string `yaml:"end" mapstructure:"end" json:"end"`       // 结束时间（HH:MM），早于开始时间表示跨越午夜
+}
+
+// Contains 判断给定时间（按其所在时区的钟面时间）是否处于静默时段
+func (c QuietHoursConfig) Contains(t time.Time) bool {
+	if !c.Enabled {
+		return false
+	}
+	return clockWindowContains(c.Start, c.End, t)
+}
+
+// clockWindowContains 判断给定时间是否落在 [start, end) 的每日时段内，end 早于 start 表示跨越午夜
+func clockWindowContains(startValue, endValue string, t time.Time) bool {
+	start, err := parseClock(startValue)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(endValue)
+	if err != nil || start == end {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	// 跨越午夜，如 22:00-07:00
+	return now >= start || now < end
+}
+
+// parseClock 解析 HH:MM 为当天的分钟数
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
 }
 
 // PathsConfig 路径配置
@@ -83,6 +133,20 @@ type QualityConfig struct {
 	CDNSort       bool     `yaml:"cdn_sort" mapstructure:"cdn_sort" json:"cdn_sort"`
 }
 
+// 下载引擎
+const (
+	DownloadEngineYtdlp  = "ytdlp"  // 调用 yt-dlp 下载
+	DownloadEngineNative = "native" // 通过取流接口原生下载 DASH 音视频流并用 ffmpeg 合并，失败时回退 yt-dlp
+)
+
+// 下载后处理方案
+const (
+	PostProcessNone     = "none"       // 不处理，视频源用于关闭全局配置的后处理
+	PostProcessMKV      = "mkv"        // 封装为 MKV 并内嵌弹幕与字幕
+	PostProcessH264     = "h264_1080p" // 转码为 H.264 1080p，兼容不支持 HEVC/AV1 的设备
+	PostProcessAudioM4A = "audio_m4a"  // 额外提取音频为 m4a
+)
+
 // DownloadConfig 下载配置
 type DownloadConfig struct {
 	SkipPoster   bool `yaml:"skip_poster" mapstructure:"skip_poster" json:"skip_poster"`
@@ -90,6 +154,59 @@ type DownloadConfig struct {
 	SkipUpper    bool `yaml:"skip_upper" mapstructure:"skip_upper" json:"skip_upper"`
 	SkipDanmaku  bool `yaml:"skip_danmaku" mapstructure:"skip_danmaku" json:"skip_danmaku"`
 	SkipSubtitle bool `yaml:"skip_subtitle" mapstructure:"skip_subtitle" json:"skip_subtitle"`
+	// SubtitleLangs 字幕语言白名单（B站语言代码，如 zh-CN、en-US、ai-zh），为空时下载全部字幕
+	SubtitleLangs []string `yaml:"subtitle_langs" mapstructure:"subtitle_langs" json:"subtitle_langs"`
+	// SubtitleFormats 字幕输出格式（srt/ass），可同时输出多种
+	SubtitleFormats []string `yaml:"subtitle_formats" mapstructure:"subtitle_formats" json:"subtitle_formats"`
+	// Bandwidth 全局下载带宽限制，所有 yt-dlp 进程与原生 HTTP 下载共享
+	Bandwidth BandwidthConfig `yaml:"bandwidth" mapstructure:"bandwidth" json:"bandwidth"`
+	// Engine B站视频下载引擎（ytdlp/native），视频源可单独覆盖
+	Engine string `yaml:"engine" mapstructure:"engine" json:"engine"`
+	// PostProcess 视频下载完成后的处理方案（mkv/h264_1080p/audio_m4a），为空不处理，视频源可单独覆盖
+	PostProcess string `yaml:"post_process" mapstructure:"post_process" json:"post_process"`
+	// PostProcessWorkers 同时运行的后处理 ffmpeg 进程数
+	PostProcessWorkers int `yaml:"post_process_workers" mapstructure:"post_process_workers" json:"post_process_workers"`
+}
+
+// BandwidthConfig 下载带宽限制配置
+type BandwidthConfig struct {
+	Limit    int                     `yaml:"limit" mapstructure:"limit" json:"limit"` // 默认带宽上限（KB/s），0 表示不限速
+	Schedule []BandwidthScheduleRule `yaml:"schedule" mapstructure:"schedule" json:"schedule"`
+}
+
+// BandwidthScheduleRule 按时段覆盖的带宽上限，多条规则重叠时取第一条
+type BandwidthScheduleRule struct {
+	Start string `yaml:"start" mapstructure:"start" json:"start"` // 开始时间（HH:MM）
+	End   string `yaml:"end" mapstructure:"end" json:"end"`       // 结束时间（HH:MM），早于开始时间表示跨越午夜
+	Limit int    `yaml:"limit" mapstructure:"limit" json:"limit"` // 时段内带宽上限（KB/s），0 表示不限速
+}
+
+// Enabled 是否配置了任何带宽限制（默认上限或时段规则）
+func (c BandwidthConfig) Enabled() bool {
+	if c.Limit > 0 {
+		return true
+	}
+	for _, rule := range c.Schedule {
+		if rule.Limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitAt 获取给定时间生效的带宽上限（字节/秒），0 表示不限速
+func (c BandwidthConfig) LimitAt(t time.Time) int64 {
+	limit := c.Limit
+	for _, rule := range c.Schedule {
+		if clockWindowContains(rule.Start, rule.End, t) {
+			limit = rule.Limit
+			break
+		}
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return int64(limit) * 1024
 }
 
 // DanmakuConfig 弹幕配置
@@ -117,6 +234,21 @@ type AdvancedConfig struct {
 	NFOTimeType     string                `yaml:"nfo_time_type" mapstructure:"nfo_time_type" json:"nfo_time_type"`
 	YtdlpExtraArgs  []string              `yaml:"ytdlp_extra_args" mapstructure:"ytdlp_extra_args" json:"ytdlp_extra_args"`
 	MaxRetryCount   int                   `yaml:"max_retry_count" mapstructure:"max_retry_count" json:"max_retry_count"`
+	APIRetry        APIRetryConfig        `yaml:"api_retry" mapstructure:"api_retry" json:"api_retry"`
+}
+
+// APIRetryConfig B 站接口请求重试配置，网络错误与 5xx 响应时重试
+type APIRetryConfig struct {
+	MaxRetries int `yaml:"max_retries" mapstructure:"max_retries" json:"max_retries"` // 最大重试次数，0 表示不重试
+	BackoffMS  int `yaml:"backoff_ms" mapstructure:"backoff_ms" json:"backoff_ms"`    // 首次重试前的等待时间（毫秒），之后逐次翻倍
+}
+
+// Backoff 首次重试前的等待时间
+func (c APIRetryConfig) Backoff() time.Duration {
+	if c.BackoffMS <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.BackoffMS) * time.Millisecond
 }
 
 // ConcurrentLimitConfig 并发限制配置
@@ -125,12 +257,35 @@ type ConcurrentLimitConfig struct {
 	Page  int `yaml:"page" mapstructure:"page" json:"page"`
 }
 
-// RateLimitConfig 速率限制配置
+// RateLimitConfig 速率限制配置：每 DurationMS 毫秒最多 Limit 个 B 站接口请求
+// WBI、PlayURL、Danmaku 为对应类别接口的独立预算，未设置（为 0）时沿用默认预算
 type RateLimitConfig struct {
+	DurationMS int           `yaml:"duration_ms" mapstructure:"duration_ms" json:"duration_ms"`
+	Limit      int           `yaml:"limit" mapstructure:"limit" json:"limit"`
+	WBI        RateLimitRule `yaml:"wbi" mapstructure:"wbi" json:"wbi"`             // WBI 签名接口（投稿搜索、视频详情）
+	PlayURL    RateLimitRule `yaml:"playurl" mapstructure:"playurl" json:"playurl"` // 取流接口
+	Danmaku    RateLimitRule `yaml:"danmaku" mapstructure:"danmaku" json:"danmaku"` // 弹幕接口
+}
+
+// RateLimitRule 单类接口的请求预算
+type RateLimitRule struct {
 	DurationMS int `yaml:"duration_ms" mapstructure:"duration_ms" json:"duration_ms"`
 	Limit      int `yaml:"limit" mapstructure:"limit" json:"limit"`
 }
 
+// Enabled 是否设置了独立预算
+func (r RateLimitRule) Enabled() bool {
+	return r.DurationMS > 0 && r.Limit > 0
+}
+
+// Resolve 返回实际生效的预算，未设置时沿用默认预算
+func (r RateLimitRule) Resolve(fallback RateLimitConfig) RateLimitRule {
+	if r.Enabled() {
+		return r
+	}
+	return RateLimitRule{DurationMS: fallback.DurationMS, Limit: fallback.Limit}
+}
+
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level      string `yaml:"level" mapstructure:"level" json:"level"`
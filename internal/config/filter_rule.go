@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+)
+
+// FilterRule 过滤规则（全局规则与视频源规则共用，由同步任务的过滤引擎执行）
+type FilterRule struct {
+	// 关键词过滤
+	Keywords        []string `json:"keywords"`         // 标题包含（OR关系）
+	ExcludeKeywords []string `json:"exclude_keywords"` // 标题排除（AND关系）
+	KeywordMode     string   `json:"keyword_mode"`     // and / or（keywords之间的关系）
+
+	// 时长过滤
+	MinDuration int `json:"min_duration"` // 最小时长（秒）
+	MaxDuration int `json:"max_duration"` // 最大时长（秒）
+
+	// 时间过滤
+	PubDateAfter  string `json:"pub_date_after"`  // 发布时间晚于（RFC3339格式）
+	PubDateBefore string `json:"pub_date_before"` // 发布时间早于
+	FavDateAfter  string `json:"fav_date_after"`  // 收藏时间晚于
+	FavDateBefore string `json:"fav_date_before"` // 收藏时间早于
+
+	// UP主过滤
+	AllowedUppers []int64 `json:"allowed_uppers"` // UP主白名单
+	BlockedUppers []int64 `json:"blocked_uppers"` // UP主黑名单
+
+	// 其他
+	OnlyOriginal bool `json:"only_original"` // 仅原创
+	MinViews     int  `json:"min_views"`     // 最小播放量
+}
+
+// ParseFilterRule 从JSON解析过滤规则，空字符串返回空规则
+func ParseFilterRule(jsonStr string) (*FilterRule, error) {
+	if jsonStr == "" {
+		return &FilterRule{}, nil
+	}
+
+	var rule FilterRule
+	if err := json.Unmarshal([]byte(jsonStr), &rule); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
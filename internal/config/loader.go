@@ -28,6 +28,9 @@ func Load(configPath string) (*Config, error) {
 	v.SetDefault("telegram.notify_on_accept", true)
 	v.SetDefault("telegram.notify_on_complete", true)
 	v.SetDefault("telegram.notify_on_fail", true)
+	v.SetDefault("sync.full_scan_days", 7)
+	v.SetDefault("advanced.api_retry.max_retries", 3)
+	v.SetDefault("advanced.api_retry.backoff_ms", 500)
 
 	// 设置配置文件路径
 	if configPath != "" {
@@ -85,8 +88,14 @@ func createDefaultConfig(v *viper.Viper) (*Config, error) {
 			URL:     "",
 		},
 		Sync: SyncConfig{
-			Interval: 3600,
-			ScanOnly: false,
+			Interval:     3600,
+			ScanOnly:     false,
+			FullScanDays: 7,
+			QuietHours: QuietHoursConfig{
+				Enabled: false,
+				Start:   "19:00",
+				End:     "23:30",
+			},
 		},
 		Paths: PathsConfig{
 			DownloadBase:    "./downloads",
@@ -105,11 +114,15 @@ func createDefaultConfig(v *viper.Viper) (*Config, error) {
 			CDNSort:       false,
 		},
 		Download: DownloadConfig{
-			SkipPoster:   false,
-			SkipVideoNFO: false,
-			SkipUpper:    false,
-			SkipDanmaku:  false,
-			SkipSubtitle: false,
+			Engine:             DownloadEngineYtdlp,
+			SkipPoster:         false,
+			SkipVideoNFO:       false,
+			SkipUpper:          false,
+			SkipDanmaku:        false,
+			SkipSubtitle:       false,
+			SubtitleLangs:      []string{},
+			SubtitleFormats:    []string{"srt"},
+			PostProcessWorkers: 1,
 		},
 		Danmaku: DanmakuConfig{
 			Duration:         12.0,
@@ -135,10 +148,15 @@ func createDefaultConfig(v *viper.Viper) (*Config, error) {
 			RateLimit: RateLimitConfig{
 				DurationMS: 250,
 				Limit:      4,
+				WBI:        RateLimitRule{DurationMS: 1000, Limit: 2},
 			},
 			NFOTimeType:    "favtime",
 			YtdlpExtraArgs: []string{},
 			MaxRetryCount:  3,
+			APIRetry: APIRetryConfig{
+				MaxRetries: 3,
+				BackoffMS:  500,
+			},
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -36,3 +36,44 @@ func TestLoadAppliesTelegramNotificationDefaults(t *testing.T) {
 		t.Fatalf("expected webhook_secret default to be empty, got %q", cfg.Telegram.WebhookSecret)
 	}
 }
+
+func TestLoadAppliesAPIRetryDefaults(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("advanced:\n  max_retry_count: 3\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	if cfg.Advanced.APIRetry.MaxRetries != 3 {
+		t.Fatalf("expected api_retry.max_retries default to be 3, got %d", cfg.Advanced.APIRetry.MaxRetries)
+	}
+	if cfg.Advanced.APIRetry.BackoffMS != 500 {
+		t.Fatalf("expected api_retry.backoff_ms default to be 500, got %d", cfg.Advanced.APIRetry.BackoffMS)
+	}
+}
+
+func TestLoadAppliesFullScanDaysDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("sync:\n  interval: 3600\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	if cfg.Sync.FullScanDays != 7 {
+		t.Fatalf("expected full_scan_days default to be 7, got %d", cfg.Sync.FullScanDays)
+	}
+}
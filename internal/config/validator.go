@@ -6,6 +6,9 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"bili-download/internal/cron"
+	"bili-download/internal/naming"
 )
 
 var telegramWebhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
@@ -37,6 +40,9 @@ func (c *Config) Validate() error {
 	if err := c.Quality.Validate(); err != nil {
 		return fmt.Errorf("quality config error: %w", err)
 	}
+	if err := c.Download.Validate(); err != nil {
+		return fmt.Errorf("download config error: %w", err)
+	}
 	if err := c.Danmaku.Validate(); err != nil {
 		return fmt.Errorf("danmaku config error: %w", err)
 	}
@@ -92,6 +98,32 @@ func (c *SyncConfig) Validate() error {
 	if c.Interval <= 0 {
 		return errors.New("interval must be greater than 0")
 	}
+	if strings.TrimSpace(c.Cron) != "" {
+		if _, err := cron.Parse(c.Cron); err != nil {
+			return fmt.Errorf("cron error: %w", err)
+		}
+	}
+	if c.FullScanDays < 0 {
+		return errors.New("full_scan_days must not be negative")
+	}
+	if c.QuietHours.Enabled {
+		start, err := parseClock(c.QuietHours.Start)
+		if err != nil {
+			return fmt.Errorf("quiet_hours.start error: %w", err)
+		}
+		end, err := parseClock(c.QuietHours.End)
+		if err != nil {
+			return fmt.Errorf("quiet_hours.end error: %w", err)
+		}
+		if start == end {
+			return errors.New("quiet_hours.start and quiet_hours.end cannot be the same")
+		}
+	}
+	if strings.TrimSpace(c.GlobalRule) != "" {
+		if _, err := ParseFilterRule(c.GlobalRule); err != nil {
+			return fmt.Errorf("global_rule is not a valid filter rule: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -112,9 +144,15 @@ func (c *TemplateConfig) Validate() error {
 	if c.VideoName == "" {
 		return errors.New("video_name cannot be empty")
 	}
+	if err := naming.Validate(c.VideoName, false); err != nil {
+		return fmt.Errorf("video_name error: %w", err)
+	}
 	if c.PageName == "" {
 		return errors.New("page_name cannot be empty")
 	}
+	if err := naming.Validate(c.PageName, true); err != nil {
+		return fmt.Errorf("page_name error: %w", err)
+	}
 	if c.TimeFormat == "" {
 		return errors.New("time_format cannot be empty")
 	}
@@ -163,6 +201,76 @@ func (c *QualityConfig) Validate() error {
 	return nil
 }
 
+func (c *DownloadConfig) Validate() error {
+	if err := ValidateDownloadEngine(c.Engine); err != nil {
+		return err
+	}
+	if err := ValidatePostProcess(c.PostProcess); err != nil {
+		return err
+	}
+	if c.PostProcessWorkers < 0 {
+		return errors.New("post_process_workers cannot be negative")
+	}
+	for _, format := range c.SubtitleFormats {
+		if format != "srt" && format != "ass" {
+			return fmt.Errorf("invalid subtitle_formats entry: %s (must be srt or ass)", format)
+		}
+	}
+	for _, lang := range c.SubtitleLangs {
+		if strings.TrimSpace(lang) == "" {
+			return errors.New("subtitle_langs cannot contain empty entries")
+		}
+	}
+	if err := c.Bandwidth.Validate(); err != nil {
+		return fmt.Errorf("bandwidth error: %w", err)
+	}
+	return nil
+}
+
+// ValidateDownloadEngine 校验下载引擎，空字符串表示使用默认引擎
+func ValidateDownloadEngine(engine string) error {
+	switch engine {
+	case "", DownloadEngineYtdlp, DownloadEngineNative:
+		return nil
+	default:
+		return fmt.Errorf("invalid engine: %s (must be %s or %s)", engine, DownloadEngineYtdlp, DownloadEngineNative)
+	}
+}
+
+// ValidatePostProcess 校验下载后处理方案，空字符串表示不处理（视频源中表示使用全局配置）
+func ValidatePostProcess(profile string) error {
+	switch profile {
+	case "", PostProcessNone, PostProcessMKV, PostProcessH264, PostProcessAudioM4A:
+		return nil
+	default:
+		return fmt.Errorf("invalid post_process: %s (must be %s, %s, %s or %s)",
+			profile, PostProcessNone, PostProcessMKV, PostProcessH264, PostProcessAudioM4A)
+	}
+}
+
+func (c *BandwidthConfig) Validate() error {
+	if c.Limit < 0 {
+		return errors.New("limit cannot be negative")
+	}
+	for i, rule := range c.Schedule {
+		if rule.Limit < 0 {
+			return fmt.Errorf("schedule[%d].limit cannot be negative", i)
+		}
+		start, err := parseClock(rule.Start)
+		if err != nil {
+			return fmt.Errorf("schedule[%d].start error: %w", i, err)
+		}
+		end, err := parseClock(rule.End)
+		if err != nil {
+			return fmt.Errorf("schedule[%d].end error: %w", i, err)
+		}
+		if start == end {
+			return fmt.Errorf("schedule[%d].start and schedule[%d].end cannot be the same", i, i)
+		}
+	}
+	return nil
+}
+
 func (c *DanmakuConfig) Validate() error {
 	if c.Duration <= 0 {
 		return errors.New("duration must be greater than 0")
@@ -210,6 +318,17 @@ func (c *AdvancedConfig) Validate() error {
 	if c.RateLimit.Limit <= 0 {
 		return errors.New("rate_limit.limit must be greater than 0")
 	}
+	if c.APIRetry.MaxRetries < 0 || c.APIRetry.MaxRetries > 10 {
+		return errors.New("api_retry.max_retries must be between 0 and 10")
+	}
+	if c.APIRetry.BackoffMS < 0 {
+		return errors.New("api_retry.backoff_ms must not be negative")
+	}
+	for name, rule := range map[string]RateLimitRule{"wbi": c.RateLimit.WBI, "playurl": c.RateLimit.PlayURL, "danmaku": c.RateLimit.Danmaku} {
+		if rule.DurationMS < 0 || rule.Limit < 0 {
+			return fmt.Errorf("rate_limit.%s must not be negative", name)
+		}
+	}
 
 	validNFOTimeTypes := []string{"favtime", "pubtime"}
 	valid := false
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestTelegramConfigValidateAllowsWebhookMode(t *testing.T) {
 	t.Parallel()
@@ -152,3 +155,174 @@ func TestTelegramConfigValidateRejectsUnsupportedChatType(t *testing.T) {
 		t.Fatal("expected unsupported chat type to fail validation")
 	}
 }
+
+func TestSyncConfigValidateAcceptsGlobalRule(t *testing.T) {
+	t.Parallel()
+
+	cfg := SyncConfig{
+		Interval:   3600,
+		GlobalRule: `{"exclude_keywords":["直播回放"],"min_duration":60}`,
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected global rule to validate, got %v", err)
+	}
+}
+
+func TestSyncConfigValidateRejectsMalformedGlobalRule(t *testing.T) {
+	t.Parallel()
+
+	cfg := SyncConfig{
+		Interval:   3600,
+		GlobalRule: `{"min_duration":`,
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected malformed global rule to fail validation")
+	}
+
+	// 字段类型与过滤规则不符时同步会忽略整条规则，需在保存配置时拒绝
+	for _, rule := range []string{`{"min_views":"100"}`, `{"keywords":"直播"}`, `[]`} {
+		cfg.GlobalRule = rule
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected global rule %s to fail validation", rule)
+		}
+	}
+}
+
+func TestDownloadConfigValidateRejectsUnknownSubtitleFormat(t *testing.T) {
+	t.Parallel()
+
+	cfg := DownloadConfig{SubtitleFormats: []string{"srt", "vtt"}}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected unknown subtitle format to fail validation")
+	}
+}
+
+func TestDownloadConfigValidateEngine(t *testing.T) {
+	t.Parallel()
+
+	for _, engine := range []string{"", DownloadEngineYtdlp, DownloadEngineNative} {
+		cfg := DownloadConfig{Engine: engine}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("expected engine %q to be valid, got %v", engine, err)
+		}
+	}
+
+	cfg := DownloadConfig{Engine: "aria2"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected unknown engine to fail validation")
+	}
+}
+
+func TestDownloadConfigValidatePostProcess(t *testing.T) {
+	t.Parallel()
+
+	for _, profile := range []string{"", PostProcessNone, PostProcessMKV, PostProcessH264, PostProcessAudioM4A} {
+		cfg := DownloadConfig{PostProcess: profile, PostProcessWorkers: 2}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("expected post_process %q to be valid, got %v", profile, err)
+		}
+	}
+
+	cfg := DownloadConfig{PostProcess: "h265_4k"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected unknown post_process to fail validation")
+	}
+
+	cfg = DownloadConfig{PostProcessWorkers: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected negative post_process_workers to fail validation")
+	}
+}
+
+func TestSyncConfigValidateRejectsInvalidCronAndQuietHours(t *testing.T) {
+	t.Parallel()
+
+	cases := []SyncConfig{
+		{Interval: 3600, Cron: "0 */2 * *"},
+		{Interval: 3600, QuietHours: QuietHoursConfig{Enabled: true, Start: "25:00", End: "07:00"}},
+		{Interval: 3600, QuietHours: QuietHoursConfig{Enabled: true, Start: "22:00", End: "22:00"}},
+	}
+
+	for _, cfg := range cases {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected %+v to fail validation", cfg)
+		}
+	}
+
+	valid := SyncConfig{Interval: 3600, Cron: "0 */2 * * *", QuietHours: QuietHoursConfig{Enabled: true, Start: "19:00", End: "23:30"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid sync config, got %v", err)
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	t.Parallel()
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	evening := QuietHoursConfig{Enabled: true, Start: "19:00", End: "23:30"}
+	overnight := QuietHoursConfig{Enabled: true, Start: "22:00", End: "07:00"}
+
+	cases := []struct {
+		cfg  QuietHoursConfig
+		t    time.Time
+		want bool
+	}{
+		{evening, at(18, 59), false},
+		{evening, at(19, 0), true},
+		{evening, at(23, 29), true},
+		{evening, at(23, 30), false},
+		{overnight, at(23, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 0), false},
+		{overnight, at(12, 0), false},
+		{QuietHoursConfig{Start: "00:00", End: "23:59"}, at(12, 0), false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.cfg.Contains(tc.t); got != tc.want {
+			t.Fatalf("%s-%s at %s: expected %v, got %v", tc.cfg.Start, tc.cfg.End, tc.t.Format("15:04"), tc.want, got)
+		}
+	}
+}
+
+func TestBandwidthConfigLimitAt(t *testing.T) {
+	t.Parallel()
+
+	cfg := BandwidthConfig{
+		Limit: 2048,
+		Schedule: []BandwidthScheduleRule{
+			{Start: "19:00", End: "23:30", Limit: 512},
+			{Start: "23:30", End: "07:00", Limit: 0},
+		},
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	if got := cfg.LimitAt(at(12, 0)); got != 2048*1024 {
+		t.Fatalf("expected default limit at noon, got %d", got)
+	}
+	if got := cfg.LimitAt(at(20, 0)); got != 512*1024 {
+		t.Fatalf("expected evening limit, got %d", got)
+	}
+	if got := cfg.LimitAt(at(2, 0)); got != 0 {
+		t.Fatalf("expected unlimited overnight, got %d", got)
+	}
+}
+
+func TestDownloadConfigValidateRejectsInvalidBandwidthSchedule(t *testing.T) {
+	t.Parallel()
+
+	cfg := DownloadConfig{Bandwidth: BandwidthConfig{Schedule: []BandwidthScheduleRule{{Start: "19:00", End: "7", Limit: 512}}}}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected invalid bandwidth schedule to fail validation")
+	}
+}
@@ -0,0 +1,227 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 标准 5 段 cron 表达式：分 时 日 月 周
+// 支持 *、数字、范围（a-b）、步长（*/n、a-b/n）、列表（a,b）、月份与星期英文缩写，以及 @hourly 等常用别名
+type Schedule struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// 日与周同时被限制时，按 cron 惯例满足任一即可
+	domRestricted bool
+	dowRestricted bool
+}
+
+// cronField cron 字段定义
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "分钟", min: 0, max: 59}
+	cronHour   = cronField{name: "小时", min: 0, max: 23}
+	cronDom    = cronField{name: "日", min: 1, max: 31}
+	cronMonth  = cronField{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{name: "星期", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronAliases 常用 cron 别名
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse 解析 cron 表达式
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if alias, ok := cronAliases[strings.ToLower(spec)]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式需要 5 个字段（分 时 日 月 周），实际为 %d 个: %q", len(fields), expr)
+	}
+
+	schedule := &Schedule{expr: expr}
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, err
+	}
+
+	// 星期中的 7 与 0 均表示周日
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	schedule.domRestricted = fields[2] != "*" && fields[2] != "?"
+	schedule.dowRestricted = fields[4] != "*" && fields[4] != "?"
+
+	return schedule, nil
+}
+
+// parseCronField 解析单个字段为位图
+func parseCronField(field string, def cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("%s字段格式错误: %q", def.name, field)
+		}
+
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s字段步长无效: %q", def.name, part)
+			}
+			step = n
+		}
+
+		start, end := def.min, def.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], def); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], def); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%s字段范围无效: %q", def.name, rangePart)
+			}
+		default:
+			value, err := parseCronValue(rangePart, def)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			// a/n 表示从 a 开始到最大值每隔 n
+			if step > 1 {
+				end = def.max
+			} else {
+				end = value
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue 解析字段中的单个值（数字或英文缩写）
+func parseCronValue(value string, def cronField) (int, error) {
+	if def.names != nil {
+		if v, ok := def.names[strings.ToLower(value)]; ok {
+			return v, nil
+		}
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s字段值无效: %q", def.name, value)
+	}
+	if v < def.min || v > def.max {
+		return 0, fmt.Errorf("%s字段值超出范围 %d-%d: %d", def.name, def.min, def.max, v)
+	}
+	return v, nil
+}
+
+// String 返回原始表达式
+func (c *Schedule) String() string {
+	return c.expr
+}
+
+// Next 返回严格晚于 t 的下一个触发时间（按 t 所在时区计算），5 年内无匹配时返回零值
+func (c *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches 检查日期是否匹配日/周字段
+func (c *Schedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
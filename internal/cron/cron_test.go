@@ -0,0 +1,44 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	base := time.Date(2024, 3, 15, 10, 7, 30, 0, loc) // 周五
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/10 * * * *", time.Date(2024, 3, 15, 10, 10, 0, 0, loc)},
+		{"0 3 * * *", time.Date(2024, 3, 16, 3, 0, 0, 0, loc)},
+		{"30 9-18/3 * * mon-fri", time.Date(2024, 3, 15, 12, 30, 0, 0, loc)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, loc)},
+		{"0 8 * * 7", time.Date(2024, 3, 17, 8, 0, 0, 0, loc)},
+		{"@daily", time.Date(2024, 3, 16, 0, 0, 0, 0, loc)},
+		// 日与周同时限制时满足任一即可：20 日或周一
+		{"0 0 20 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, loc)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+		}
+		if got := schedule.Next(base); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
@@ -99,7 +99,11 @@ func Migrate(db *gorm.DB) error {
 		&models.WatchLater{},
 		&models.Collection{},
 		&models.Submission{},
+		&models.Bangumi{},
 		&models.DownloadRecord{},
+		&models.DownloadQueueItem{},
+		&models.ReorganizeJournal{},
+		&models.PrunedVideo{},
 		&models.User{},
 		&models.TelegramRuntimeState{},
 		&models.TelegramRequestLog{},
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Bangumi 番剧/影视（PGC）季度模型，每一集对应一个 Video
+type Bangumi struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SeasonID     int64     `gorm:"uniqueIndex;not null" json:"season_id"` // B站季度 ID（ss）
+	MediaID      int64     `gorm:"index" json:"media_id"`                 // B站剧集 ID（md）
+	SeasonType   int       `gorm:"default:1" json:"season_type"`          // 1-番剧 2-电影 3-纪录片 4-国创 5-电视剧 7-综艺
+	SeasonNumber int       `gorm:"default:1" json:"season_number"`        // 在同系列中的季序号
+	Finished     bool      `gorm:"default:false" json:"finished"`         // 是否已完结
+	Name         string    `gorm:"size:255;not null" json:"name"`
+	Path         string    `gorm:"size:500" json:"path"`
+	Enabled      bool      `gorm:"default:true;index" json:"enabled"`
+	Rule         string    `gorm:"type:jsonb" json:"rule,omitempty"` // 过滤规则 JSON
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 调度相关字段
+	Priority            int        `gorm:"default:0" json:"priority"`              // 优先级 (0-10)
+	ScanInterval        int        `gorm:"default:0" json:"scan_interval"`         // 同步间隔（秒），0 表示使用全局同步间隔
+	ScanCron            string     `gorm:"size:100" json:"scan_cron"`              // 同步 cron 表达式（5 段），设置后优先于同步间隔
+	HealthStatus        string     `gorm:"default:'healthy'" json:"health_status"` // healthy/degraded/unhealthy
+	ConsecutiveFailures int        `gorm:"default:0" json:"consecutive_failures"`  // 连续失败次数
+	LastScanAt          *time.Time `json:"last_scan_at,omitempty"`                 // 最后扫描时间
+	LastScanError       string     `json:"last_scan_error,omitempty"`              // 最后扫描错误
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`              // 最后成功时间
+	LastFullScanAt      *time.Time `json:"last_full_scan_at,omitempty"`            // 最后全量扫描时间
+
+	// 保留策略：同步完成后按策略清理视频（含本地文件），标记为保留的视频不受影响
+	RetentionMode  string `gorm:"size:20;default:''" json:"retention_mode"` // 空（不清理）/keep_last/max_age/mirror
+	RetentionValue int    `gorm:"default:0" json:"retention_value"`         // keep_last 为保留数量，max_age 为保留天数
+
+	// 下载引擎：为空时使用全局配置 download.engine
+	DownloadEngine string `gorm:"size:20;default:''" json:"download_engine"` // 空/ytdlp/native
+
+	// 下载后处理方案：为空时使用全局配置 download.post_process，none 表示不处理
+	PostProcess string `gorm:"size:20;default:''" json:"post_process"` // 空/none/mkv/h264_1080p/audio_m4a
+
+	// 关联
+	Videos []Video `gorm:"foreignKey:BangumiID" json:"videos,omitempty"`
+}
+
+// TableName 指定表名
+func (Bangumi) TableName() string {
+	return "bangumi"
+}
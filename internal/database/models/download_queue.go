@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// DownloadQueueItem 持久化的下载队列条目
+// 记录所有尚未结束的下载任务（排队中/运行中），服务重启后据此恢复队列
+type DownloadQueueItem struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TaskID     string    `gorm:"size:128;uniqueIndex;not null" json:"task_id"`
+	TaskType   string    `gorm:"size:20;not null" json:"task_type"` // video/page/ytdlp/xhs
+	Priority   int       `gorm:"not null;default:5" json:"priority"`
+	VideoID    uint      `gorm:"not null;index" json:"video_id"`
+	PageID     *uint     `json:"page_id,omitempty"` // 仅分P任务
+	RecordID   uint      `gorm:"index" json:"record_id"`
+	URL        string    `gorm:"size:1000" json:"url"`
+	OutputDir  string    `gorm:"size:500" json:"output_dir"`
+	RetryCount int       `gorm:"default:0" json:"retry_count"`
+	MaxRetries int       `gorm:"default:3" json:"max_retries"`
+	Paused     bool      `gorm:"default:false" json:"paused"`       // 用户暂停的任务，恢复后保持暂停
+	Repair     bool      `gorm:"default:false" json:"repair"`       // 修复任务，只下载缺失的文件
+	EnqueuedAt time.Time `gorm:"not null;index" json:"enqueued_at"` // 入队时间，恢复时用于保持原有顺序
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (DownloadQueueItem) TableName() string {
+	return "download_queue"
+}
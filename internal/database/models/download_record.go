@@ -38,6 +38,16 @@ type FileDetail struct {
 	Status   string  `json:"status"`   // pending/downloading/completed/failed/skipped
 	Size     int64   `json:"size"`     // 已下载大小
 	Progress float64 `json:"progress"` // 0-100
+	// Tracks 子轨道状态（如字幕的各语言轨道）
+	Tracks []FileTrack `json:"tracks,omitempty"`
+}
+
+// FileTrack 文件子轨道状态
+type FileTrack struct {
+	Name   string `json:"name"`            // 轨道标识（字幕为B站语言代码，如 zh-CN / ai-zh）
+	Label  string `json:"label"`           // 显示名称
+	Status string `json:"status"`          // succeeded/failed/skipped
+	Error  string `json:"error,omitempty"` // 错误信息
 }
 
 // FileDetailsData FileDetails 的 JSON 结构
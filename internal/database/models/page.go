@@ -6,23 +6,31 @@ import (
 
 // Page 视频分P模型
 type Page struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	VideoID        uint      `gorm:"not null;index" json:"video_id"`
-	CID            int64     `gorm:"column:cid;not null" json:"cid"`
-	PID            int       `gorm:"column:pid;not null" json:"pid"` // 分P编号
-	Name           string    `gorm:"size:255" json:"name"`
-	Duration       int       `json:"duration"` // 时长（秒）
-	Width          int       `json:"width"`
-	Height         int       `json:"height"`
-	FrameRate      float32   `json:"frame_rate"`                       // 实际帧率，由 ffprobe 探测
-	Quality        int8      `gorm:"index;default:0" json:"quality"`   // 画质编码
-	Orientation    int8      `gorm:"default:0" json:"orientation"`     // 方向：0未知 1横屏 2竖屏
-	Image          string    `gorm:"size:500" json:"image"`            // 封面URL
-	DownloadStatus int       `gorm:"default:0" json:"download_status"` // 位标志
-	Path           string    `gorm:"size:500" json:"path"`
-	Kind           string    `gorm:"size:20;default:'video'" json:"kind"`     // video | image | live_photo
-	FilePath       string    `gorm:"size:500" json:"file_path"`               // 单文件落地路径（图集场景使用）
-	CreatedAt      time.Time `json:"created_at"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	VideoID         uint      `gorm:"not null;index" json:"video_id"`
+	CID             int64     `gorm:"column:cid;not null" json:"cid"`
+	PID             int       `gorm:"column:pid;not null" json:"pid"` // 分P编号
+	Name            string    `gorm:"size:255" json:"name"`
+	Duration        int       `json:"duration"` // 时长（秒）
+	Width           int       `json:"width"`
+	Height          int       `json:"height"`
+	FrameRate       float32   `json:"frame_rate"`                        // 实际帧率，由 ffprobe 探测
+	Quality         int8      `gorm:"index;default:0" json:"quality"`    // 画质编码
+	Orientation     int8      `gorm:"default:0" json:"orientation"`      // 方向：0未知 1横屏 2竖屏
+	VideoCodec      string    `gorm:"size:20;index" json:"video_codec"`  // 视频编码（ffprobe 编码名：h264/hevc/av1），由 ffprobe 探测
+	VideoBitrate    int64     `json:"video_bitrate"`                     // 视频码率（bps）
+	HDRFormat       string    `gorm:"size:20" json:"hdr_format"`         // HDR 格式：hdr10/hlg，SDR 为空
+	DolbyVision     bool      `gorm:"default:false" json:"dolby_vision"` // 杜比视界
+	AudioCodec      string    `gorm:"size:20" json:"audio_codec"`        // 音频编码（aac/flac/eac3 等）
+	AudioChannels   int       `json:"audio_channels"`                    // 音频声道数
+	AudioSampleRate int       `json:"audio_sample_rate"`                 // 音频采样率（Hz）
+	AudioType       string    `gorm:"size:20" json:"audio_type"`         // 音频类型：standard/dolby/dolby_atmos/hires
+	Image           string    `gorm:"size:500" json:"image"`             // 封面URL
+	DownloadStatus  int       `gorm:"default:0" json:"download_status"`  // 位标志，见 PageStatusVideo 等
+	Path            string    `gorm:"size:500" json:"path"`
+	Kind            string    `gorm:"size:20;default:'video'" json:"kind"` // video | image | live_photo
+	FilePath        string    `gorm:"size:500" json:"file_path"`           // 单文件落地路径（图集场景使用）
+	CreatedAt       time.Time `json:"created_at"`
 
 	// 非持久化字段：由文件系统 stat 填充，仅响应时返回
 	FileSize   int64  `gorm:"-" json:"file_size,omitempty"`
@@ -36,3 +44,17 @@ type Page struct {
 func (Page) TableName() string {
 	return "page"
 }
+
+// 分P下载状态位（Page.DownloadStatus），每一位表示对应文件已写入磁盘
+const (
+	PageStatusVideo    = 1 << iota // 视频文件
+	PageStatusPoster               // 封面
+	PageStatusDanmaku              // 弹幕 ASS
+	PageStatusSubtitle             // 字幕（至少一个轨道）
+	PageStatusNFO                  // NFO 元数据
+)
+
+// HasStatus 分P是否已写入指定的文件
+func (p *Page) HasStatus(flag int) bool {
+	return p.DownloadStatus&flag != 0
+}
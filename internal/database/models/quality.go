@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // 画质编码常量
 // 数值越大画质越高；预留间隔便于扩展
 const (
@@ -91,3 +93,86 @@ func OrientationLabel(code int8) string {
 		return ""
 	}
 }
+
+// 视频编码（与 ffprobe 的编码名一致）
+const (
+	VideoCodecAVC  = "h264"
+	VideoCodecHEVC = "hevc"
+	VideoCodecAV1  = "av1"
+)
+
+// HDR 格式（与 Kodi NFO 的 hdrtype 取值一致）
+const (
+	HDRFormatHDR10 = "hdr10"
+	HDRFormatHLG   = "hlg"
+)
+
+// 音频类型（按实际获取到的音频流识别）
+const (
+	AudioTypeStandard   = "standard"    // 普通音频（AAC 等）
+	AudioTypeDolby      = "dolby"       // 杜比音效（E-AC-3）
+	AudioTypeDolbyAtmos = "dolby_atmos" // 杜比全景声（E-AC-3 JOC）
+	AudioTypeHiRes      = "hires"       // Hi-Res 无损（FLAC）
+)
+
+// AudioTypeFromCodec 按 ffprobe 的音频编码名与 profile 识别音频类型，无音频时返回空
+func AudioTypeFromCodec(codec, profile string) string {
+	switch strings.ToLower(strings.TrimSpace(codec)) {
+	case "":
+		return ""
+	case "flac", "alac":
+		return AudioTypeHiRes
+	case "eac3", "ac3", "truehd":
+		if strings.Contains(strings.ToLower(profile), "atmos") {
+			return AudioTypeDolbyAtmos
+		}
+		return AudioTypeDolby
+	default:
+		return AudioTypeStandard
+	}
+}
+
+// AudioTypeLabel 音频类型展示文本
+func AudioTypeLabel(audioType string) string {
+	switch audioType {
+	case AudioTypeDolby:
+		return "杜比音效"
+	case AudioTypeDolbyAtmos:
+		return "杜比全景声"
+	case AudioTypeHiRes:
+		return "Hi-Res 无损"
+	case AudioTypeStandard:
+		return "普通"
+	default:
+		return ""
+	}
+}
+
+// NormalizeVideoCodec 将编码别名（avc/h265/hev1/av01 等）转换为 ffprobe 编码名，无法识别时原样返回小写
+func NormalizeVideoCodec(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	switch codec {
+	case "avc", "avc1", "h264", "h.264":
+		return VideoCodecAVC
+	case "hevc", "h265", "h.265", "hev1", "hvc1":
+		return VideoCodecHEVC
+	case "av1", "av01":
+		return VideoCodecAV1
+	default:
+		return codec
+	}
+}
+
+// VideoCodecLabel 视频编码展示文本
+func VideoCodecLabel(codec string) string {
+	switch NormalizeVideoCodec(codec) {
+	case VideoCodecAVC:
+		return "AVC"
+	case VideoCodecHEVC:
+		return "HEVC"
+	case VideoCodecAV1:
+		return "AV1"
+	default:
+		return strings.ToUpper(codec)
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// 媒体库重整日志条目类型
+const (
+	ReorganizeKindFile = "file" // 单个文件（或视频目录下的子目录）
+	ReorganizeKindDir  = "dir"  // 视频目录本身，完成后更新 video.path
+)
+
+// 媒体库重整日志条目状态
+const (
+	ReorganizeStatusPending = "pending" // 已计划，尚未移动
+	ReorganizeStatusMoved   = "moved"   // 已移动，等待数据库更新
+)
+
+// ReorganizeJournal 媒体库重整日志
+// 移动文件前先写入日志，全部移动完成并更新数据库后删除；
+// 重整过程中服务中断时，下次启动或再次执行重整时据此继续完成
+type ReorganizeJournal struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	VideoID   uint      `gorm:"not null;index" json:"video_id"`
+	PageID    *uint     `json:"page_id,omitempty"` // 分P视频文件对应的分P，完成后更新 page.path
+	Kind      string    `gorm:"size:20;not null;default:'file'" json:"kind"`
+	FromPath  string    `gorm:"size:1000;not null" json:"from_path"`
+	ToPath    string    `gorm:"size:1000;not null" json:"to_path"`
+	Status    string    `gorm:"size:20;not null;default:'pending'" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ReorganizeJournal) TableName() string {
+	return "reorganize_journal"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// 视频源保留策略
+const (
+	RetentionModeKeepLast = "keep_last" // 只保留最近加入视频源的 N 个视频
+	RetentionModeMaxAge   = "max_age"   // 只保留加入视频源不超过 D 天的视频
+	RetentionModeMirror   = "mirror"    // 与远端列表保持一致，清理已从视频源消失的视频
+)
+
+// RetentionModeLabel 保留策略展示文本，未知策略返回空字符串
+func RetentionModeLabel(mode string) string {
+	switch mode {
+	case RetentionModeKeepLast:
+		return "保留最近 N 个"
+	case RetentionModeMaxAge:
+		return "保留 D 天内"
+	case RetentionModeMirror:
+		return "镜像远端列表"
+	default:
+		return ""
+	}
+}
+
+// PrunedVideo 保留策略清理记录
+// 按 keep_last/max_age 清理的视频仍在远端列表中，同步时据此跳过，不会重新入库下载
+type PrunedVideo struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	VideoID    uint      `gorm:"index" json:"video_id"` // 已删除的视频记录 ID
+	BVid       string    `gorm:"column:bvid;size:20;index" json:"bvid"`
+	Name       string    `gorm:"size:255" json:"name"`
+	SourceType string    `gorm:"size:20;not null;index:idx_pruned_video_source" json:"source_type"`
+	SourceID   uint      `gorm:"not null;index:idx_pruned_video_source" json:"source_id"` // 视频源数据库 ID
+	SourceName string    `gorm:"size:255" json:"source_name"`
+	Mode       string    `gorm:"size:20" json:"mode"`         // 触发清理的保留策略
+	Reason     string    `gorm:"size:255" json:"reason"`      // 清理原因说明
+	Path       string    `gorm:"size:500" json:"path"`        // 清理前的本地路径
+	FileError  string    `gorm:"type:text" json:"file_error"` // 删除本地文件失败时的错误
+	PrunedAt   time.Time `gorm:"not null;index" json:"pruned_at"`
+}
+
+// TableName 指定表名
+func (PrunedVideo) TableName() string {
+	return "pruned_video"
+}
@@ -18,11 +18,24 @@ type Submission struct {
 
 	// 调度相关字段
 	Priority            int        `gorm:"default:0" json:"priority"`              // 优先级 (0-10)
+	ScanInterval        int        `gorm:"default:0" json:"scan_interval"`         // 同步间隔（秒），0 表示使用全局同步间隔
+	ScanCron            string     `gorm:"size:100" json:"scan_cron"`              // 同步 cron 表达式（5 段），设置后优先于同步间隔
 	HealthStatus        string     `gorm:"default:'healthy'" json:"health_status"` // healthy/degraded/unhealthy
 	ConsecutiveFailures int        `gorm:"default:0" json:"consecutive_failures"`  // 连续失败次数
 	LastScanAt          *time.Time `json:"last_scan_at,omitempty"`                 // 最后扫描时间
 	LastScanError       string     `json:"last_scan_error,omitempty"`              // 最后扫描错误
 	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`              // 最后成功时间
+	LastFullScanAt      *time.Time `json:"last_full_scan_at,omitempty"`            // 最后全量扫描时间
+
+	// 保留策略：同步完成后按策略清理视频（含本地文件），标记为保留的视频不受影响
+	RetentionMode  string `gorm:"size:20;default:''" json:"retention_mode"` // 空（不清理）/keep_last/max_age/mirror
+	RetentionValue int    `gorm:"default:0" json:"retention_value"`         // keep_last 为保留数量，max_age 为保留天数
+
+	// 下载引擎：为空时使用全局配置 download.engine
+	DownloadEngine string `gorm:"size:20;default:''" json:"download_engine"` // 空/ytdlp/native
+
+	// 下载后处理方案：为空时使用全局配置 download.post_process，none 表示不处理
+	PostProcess string `gorm:"size:20;default:''" json:"post_process"` // 空/none/mkv/h264_1080p/audio_m4a
 
 	// 关联
 	Videos []Video `gorm:"foreignKey:SubmissionID" json:"videos,omitempty"`
@@ -56,11 +56,13 @@ type VideoSourceScan struct {
 	DurationMs   int       `json:"duration_ms"`
 	Success      bool      `gorm:"default:true" json:"success"`
 	ErrorMessage string    `json:"error_message"`
+	FullScan     bool      `gorm:"default:false" json:"full_scan"` // 是否为全量扫描（否则为增量扫描）
 
 	VideosFound    int `gorm:"default:0" json:"videos_found"`
 	VideosNew      int `gorm:"default:0" json:"videos_new"`
 	VideosFiltered int `gorm:"default:0" json:"videos_filtered"`
 	VideosQueued   int `gorm:"default:0" json:"videos_queued"`
+	VideosLost     int `gorm:"default:0" json:"videos_lost"` // 全量扫描时发现从视频源消失的视频数
 
 	Metadata  datatypes.JSON `gorm:"type:jsonb" json:"metadata"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -23,8 +23,12 @@ type Video struct {
 	FavTime        time.Time      `gorm:"column:favtime;not null;index" json:"favtime"`
 	CTime          time.Time      `gorm:"column:ctime;not null" json:"ctime"`
 	SinglePage     bool           `json:"single_page"`
-	Valid          bool           `gorm:"default:true" json:"valid"`
+	Valid          bool           `gorm:"default:true;index" json:"valid"`      // 视频仍存在于视频源，失效后为 false
+	LostReason     string         `gorm:"size:20" json:"lost_reason,omitempty"` // 失效原因：deleted/unavailable/removed
+	LostAt         *time.Time     `json:"lost_at,omitempty"`                    // 检测到失效的时间
+	ArchivedAt     *time.Time     `json:"archived_at,omitempty"`                // 下载完成后由本程序移出稍后再看的时间，不影响有效状态
 	ShouldDownload bool           `gorm:"default:true" json:"should_download"`
+	Keep           bool           `gorm:"default:false" json:"keep"`        // 用户标记保留，不受视频源保留策略清理
 	DownloadStatus int            `gorm:"default:0" json:"download_status"` // 位标志
 	Path           string         `gorm:"size:500" json:"path"`
 	MediaKind      string         `gorm:"size:20;default:'video';index" json:"media_kind"` // video | gallery
@@ -34,6 +38,12 @@ type Video struct {
 	WatchLaterID *uint `gorm:"index" json:"watch_later_id,omitempty"`
 	CollectionID *uint `gorm:"index" json:"collection_id,omitempty"`
 	SubmissionID *uint `gorm:"index" json:"submission_id,omitempty"`
+	BangumiID    *uint `gorm:"index" json:"bangumi_id,omitempty"`
+
+	// 番剧剧集信息（仅番剧视频源）
+	EpID          int64 `gorm:"default:0" json:"ep_id,omitempty"`          // B站剧集 ID（ep），下载时使用 ep 链接
+	SeasonNumber  int   `gorm:"default:0" json:"season_number,omitempty"`  // 季序号
+	EpisodeNumber int   `gorm:"default:0" json:"episode_number,omitempty"` // 集序号
 
 	// 关联
 	Pages []Page `gorm:"foreignKey:VideoID" json:"pages,omitempty"`
@@ -45,3 +55,29 @@ type Video struct {
 func (Video) TableName() string {
 	return "video"
 }
+
+// 视频失效原因（同步时发现视频从视频源中消失）
+const (
+	LostReasonDeleted     = "deleted"     // 视频已被删除
+	LostReasonUnavailable = "unavailable" // 视频不可见（审核中、仅UP主自己可见等）
+	LostReasonRemoved     = "removed"     // 视频仍可访问，但已移出视频源（取消收藏、移出合集或稍后再看等）
+)
+
+// LostReasonLabel 失效原因展示文本
+func LostReasonLabel(reason string) string {
+	switch reason {
+	case LostReasonDeleted:
+		return "已删除"
+	case LostReasonUnavailable:
+		return "不可见"
+	case LostReasonRemoved:
+		return "已移出视频源"
+	default:
+		return ""
+	}
+}
+
+// IsEpisode 是否为番剧剧集（按 ep 下载并按季/集组织目录）
+func (v *Video) IsEpisode() bool {
+	return v.BangumiID != nil && v.EpID != 0
+}
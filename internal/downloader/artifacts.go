@@ -0,0 +1,83 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bili-download/internal/database/models"
+)
+
+// pageVideoExts 分P视频文件的扩展名
+var pageVideoExts = []string{".mp4", ".mkv", ".webm", ".flv", ".avi", ".m4v"}
+
+// pageVideoFile 判断文件是否为分P的视频文件（{baseName}.ext 或 {baseName}.fXXXXX.ext）
+// temp 表示 yt-dlp 音视频流分离时产生的中间文件
+func pageVideoFile(name, baseName string) (ok, temp bool) {
+	lower := strings.ToLower(name)
+	for _, ext := range pageVideoExts {
+		if !strings.HasSuffix(lower, ext) {
+			continue
+		}
+		stem := name[:len(name)-len(ext)]
+		if stem == baseName {
+			return true, false
+		}
+		// 检查是否是中间文件（包含 .fXXXXX 格式ID后缀）
+		suffix, found := strings.CutPrefix(stem, baseName+".f")
+		if !found || suffix == "" {
+			return false, false
+		}
+		for _, c := range suffix {
+			if c < '0' || c > '9' {
+				return false, false
+			}
+		}
+		return true, true
+	}
+	return false, false
+}
+
+// scanPageArtifacts 扫描输出目录中分P已写入的文件，返回下载状态位与视频文件名
+func scanPageArtifacts(outputDir, baseName string) (status int, videoFile string) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return 0, ""
+	}
+
+	danmakuFile := baseName + ".zh-CN.default.ass"
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if !strings.HasPrefix(name, baseName) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+
+		if ok, temp := pageVideoFile(name, baseName); ok {
+			if !temp && videoFile == "" {
+				status |= models.PageStatusVideo
+				videoFile = name
+			}
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(name))
+		switch {
+		case name == baseName+".nfo":
+			status |= models.PageStatusNFO
+		case strings.HasPrefix(name, baseName+"-poster."):
+			status |= models.PageStatusPoster
+		case name == danmakuFile:
+			status |= models.PageStatusDanmaku
+		case strings.HasPrefix(name, baseName+".") && (ext == ".srt" || ext == ".ass"):
+			status |= models.PageStatusSubtitle
+		}
+	}
+	return status, videoFile
+}
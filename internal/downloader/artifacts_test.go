@@ -0,0 +1,114 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func TestPageVideoFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantOK   bool
+		wantTemp bool
+	}{
+		{"视频-P1.mp4", true, false},
+		{"视频-P1.MKV", true, false},
+		{"视频-P1.f30080.mp4", true, true},
+		{"视频-P1.fx.mp4", false, false},
+		{"视频-P10.mp4", false, false},
+		{"视频-P1-poster.jpg", false, false},
+		{"视频-P1.nfo", false, false},
+	}
+	for _, tt := range tests {
+		ok, temp := pageVideoFile(tt.name, "视频-P1")
+		if ok != tt.wantOK || temp != tt.wantTemp {
+			t.Errorf("pageVideoFile(%q) = %v, %v, want %v, %v", tt.name, ok, temp, tt.wantOK, tt.wantTemp)
+		}
+	}
+}
+
+func TestScanPageArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"视频-P1.f30080.mp4":          "temp",
+		"视频-P1.mp4":                 "video",
+		"视频-P1-poster.jpg":          "jpg",
+		"视频-P1.zh-CN.srt":           "srt",
+		"视频-P1.zh-CN.default.ass":   "", // 空文件视为缺失
+		"视频-P10.nfo":                "<episodedetails/>",
+		"视频-P10.zh-CN.default.ass":  "ass",
+		"视频-P10-poster.jpg":         "jpg",
+		"视频-P1.video.m4s.part":      "part",
+		"视频-P1.unrelated-extension": "x",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status, videoFile := scanPageArtifacts(dir, "视频-P1")
+	want := models.PageStatusVideo | models.PageStatusPoster | models.PageStatusSubtitle
+	if status != want {
+		t.Errorf("status = %05b, want %05b", status, want)
+	}
+	if videoFile != "视频-P1.mp4" {
+		t.Errorf("videoFile = %q, want 视频-P1.mp4", videoFile)
+	}
+
+	status, videoFile = scanPageArtifacts(dir, "视频-P10")
+	want = models.PageStatusPoster | models.PageStatusDanmaku | models.PageStatusNFO
+	if status != want || videoFile != "" {
+		t.Errorf("P10 status = %05b, video %q, want %05b without video", status, videoFile, want)
+	}
+
+	if status, _ := scanPageArtifacts(filepath.Join(dir, "missing"), "视频-P1"); status != 0 {
+		t.Errorf("expected no artifacts for a missing directory, got %05b", status)
+	}
+}
+
+func TestRepairPageOnlyRegeneratesMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	d := &Downloader{
+		config: &config.Config{Download: config.DownloadConfig{SkipSubtitle: true, SkipDanmaku: true}},
+		// 封面已存在，不会发起网络请求（biliClient 为空）
+		tracker: NewProgressTracker(),
+	}
+	video := &models.Video{ID: 1, BVid: "BV1xx411c7mD", Name: "修复测试", SinglePage: true}
+	page := &models.Page{ID: 2, PID: 1, CID: 100, Name: "P1", DownloadStatus: models.PageStatusVideo}
+	video.Pages = []models.Page{*page}
+
+	base := d.pageFileBaseName(video, page)
+	for _, name := range []string{base + ".mp4", base + "-poster.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := d.RepairPage(context.Background(), video, page, dir); err != nil {
+		t.Fatalf("RepairPage: %v", err)
+	}
+
+	if !fileExists(filepath.Join(dir, base+".nfo")) {
+		t.Error("expected missing NFO to be regenerated")
+	}
+	want := models.PageStatusVideo | models.PageStatusPoster | models.PageStatusNFO
+	if page.DownloadStatus != want {
+		t.Errorf("DownloadStatus = %05b, want %05b", page.DownloadStatus, want)
+	}
+	if page.Path != filepath.Join(dir, base+".mp4") {
+		t.Errorf("Path = %q, want existing video file", page.Path)
+	}
+
+	pageProgress := d.tracker.GetVideo(video.ID).GetPage(page.PID)
+	for _, name := range []string{"video", "poster", "nfo"} {
+		if st := pageProgress.GetSubTask(name); st == nil || st.Status != StatusSucceeded {
+			t.Errorf("expected %s subtask succeeded, got %+v", name, st)
+		}
+	}
+}
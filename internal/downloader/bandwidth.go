@@ -0,0 +1,63 @@
+package downloader
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"bili-download/internal/bandwidth"
+	"bili-download/internal/config"
+	"bili-download/internal/utils"
+)
+
+// applyBandwidthLimit 按配置与当前时间更新共享带宽预算，对运行中的下载立即生效
+func applyBandwidthLimit(cfg *config.Config, now time.Time) {
+	if cfg == nil {
+		return
+	}
+
+	limiter := bandwidth.Shared()
+	limit := cfg.Download.Bandwidth.LimitAt(now)
+	if limiter.Limit() == limit {
+		return
+	}
+	limiter.SetLimit(limit)
+
+	if limit > 0 {
+		utils.Info("下载带宽上限已调整为 %d KB/s", limit/1024)
+	} else {
+		utils.Info("下载带宽已取消限速")
+	}
+}
+
+// ytdlpNetworkArgs 生成 yt-dlp 的代理与带宽限制参数
+// 配置了带宽限制时让 yt-dlp 经本地限速代理下载（上游代理由限速代理转发），与其他下载共享预算并可在运行中调整上限；
+// 限速代理不可用时退回按当前上限设置 --limit-rate
+func ytdlpNetworkArgs(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	var args []string
+	if cfg.Download.Bandwidth.Enabled() {
+		proxy, err := bandwidth.SharedProxy()
+		if err == nil {
+			var upstream *url.URL
+			if upstream, err = cfg.Proxy.ParseURL(); err == nil {
+				proxy.SetUpstream(upstream)
+				return []string{"--proxy", proxy.URL()}
+			}
+		}
+
+		utils.Warn("限速代理不可用，yt-dlp 改用固定的 --limit-rate: %v", err)
+		if limit := bandwidth.Shared().Limit(); limit > 0 {
+			args = append(args, "--limit-rate", strconv.FormatInt(limit, 10))
+		}
+	}
+
+	if cfg.Proxy.IsEnabled() {
+		args = append(args, "--proxy", strings.TrimSpace(cfg.Proxy.URL))
+	}
+	return args
+}
@@ -0,0 +1,588 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bili-download/internal/bandwidth"
+	"bili-download/internal/bilibili"
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+)
+
+const (
+	// dashChunkSize 单次 Range 请求的分段大小，分段失败时只需从断点重新请求
+	dashChunkSize = 16 << 20
+	// dashStallTimeout 连续未收到数据的最长时间，超时后切换到下一个 CDN 地址
+	dashStallTimeout = 60 * time.Second
+	// dashDefaultQn 无法识别最高画质配置时使用的画质代码（1080P）
+	dashDefaultQn = 80
+
+	qnDolbyVision = 126   // 杜比视界画质代码
+	qnHDR         = 125   // HDR 真彩画质代码
+	audioIDDolby  = 30250 // 杜比音频音质代码
+	audioIDHiRes  = 30251 // Hi-Res 无损音质代码
+)
+
+// resolutionQn 最高画质配置对应的 B站画质代码
+var resolutionQn = map[string]int{
+	"8K":      127,
+	"DOLBY":   126,
+	"HDR":     125,
+	"4K":      120,
+	"1080P60": 116,
+	"1080P+":  112,
+	"1080P":   80,
+	"720P":    64,
+	"480P":    32,
+	"360P":    16,
+}
+
+// dashCodecIDs 编码优先级配置对应的 DASH codecid
+var dashCodecIDs = map[string]int{
+	"AVC":  7,
+	"HEVC": 12,
+	"AV1":  13,
+}
+
+// dashAudioRank 音质代码由高到低的顺序（Hi-Res 与杜比音频在取流结果的 flac/dolby 中单独返回）
+var dashAudioRank = []int{audioIDHiRes, audioIDDolby, 30280, 30232, 30216}
+
+// maxQnForResolution 按最高画质配置获取画质代码
+func maxQnForResolution(resolution string) int {
+	if qn, ok := resolutionQn[strings.ToUpper(strings.TrimSpace(resolution))]; ok {
+		return qn
+	}
+	return dashDefaultQn
+}
+
+// audioQualityID 解析音质配置，支持音质代码与 high/medium/low
+func audioQualityID(quality string) int {
+	switch strings.ToLower(strings.TrimSpace(quality)) {
+	case "high", "":
+		return 30280
+	case "medium":
+		return 30232
+	case "low":
+		return 30216
+	}
+	if id, err := strconv.Atoi(quality); err == nil {
+		return id
+	}
+	return 30280
+}
+
+// audioRank 音质代码的排序位置，越小音质越高；未知代码排在最后
+func audioRank(id int) int {
+	for i, v := range dashAudioRank {
+		if v == id {
+			return i
+		}
+	}
+	return len(dashAudioRank)
+}
+
+// selectVideoStream 选择不超过最高画质的最高画质视频流，同画质按编码优先级选择，再按码率
+// 杜比视界/HDR 的画质代码高于 4K，仅在最高画质配置为 DOLBY/HDR 时选择，其余配置（包括 8K）只在普通动态范围的流中选择
+// 没有不超过最高画质的流时选择画质最低的流
+func selectVideoStream(streams []bilibili.DashStream, maxQn int, codecPriority []string) *bilibili.DashStream {
+	if len(streams) == 0 {
+		return nil
+	}
+
+	codecRank := func(codecID int) int {
+		for i, name := range codecPriority {
+			if dashCodecIDs[strings.ToUpper(name)] == codecID {
+				return i
+			}
+		}
+		return len(codecPriority)
+	}
+
+	allowDynamicRange := isDynamicRangeQn(maxQn)
+	target := -1
+	lowest := streams[0].ID
+	for _, s := range streams {
+		if s.ID <= maxQn && s.ID > target && (allowDynamicRange || !isDynamicRangeQn(s.ID)) {
+			target = s.ID
+		}
+		if s.ID < lowest {
+			lowest = s.ID
+		}
+	}
+	if target < 0 {
+		target = lowest
+	}
+
+	var best *bilibili.DashStream
+	for i := range streams {
+		s := &streams[i]
+		if s.ID != target {
+			continue
+		}
+		if best == nil {
+			best = s
+			continue
+		}
+		if r, br := codecRank(s.CodecID), codecRank(best.CodecID); r < br || (r == br && s.Bandwidth > best.Bandwidth) {
+			best = s
+		}
+	}
+	return best
+}
+
+// isDynamicRangeQn 画质代码是否为杜比视界或 HDR
+func isDynamicRangeQn(qn int) bool {
+	return qn == qnDolbyVision || qn == qnHDR
+}
+
+// selectAudioStream 选择不高于配置音质的最高音质音频流，没有时选择音质最低的流
+func selectAudioStream(streams []bilibili.DashStream, quality string) *bilibili.DashStream {
+	if len(streams) == 0 {
+		return nil
+	}
+	limit := audioRank(audioQualityID(quality))
+
+	var best, worst *bilibili.DashStream
+	for i := range streams {
+		s := &streams[i]
+		rank := audioRank(s.ID)
+		if rank >= limit && (best == nil || rank < audioRank(best.ID)) {
+			best = s
+		}
+		if worst == nil || rank > audioRank(worst.ID) {
+			worst = s
+		}
+	}
+	if best == nil {
+		return worst
+	}
+	return best
+}
+
+// cdnHostRank CDN 地址的优先级：官方 upos 源站优先，其次为地区节点，PCDN（mcdn、IP 直连、非标准端口）最后
+func cdnHostRank(rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 3
+	}
+	host := u.Hostname()
+	switch {
+	case net.ParseIP(host) != nil, u.Port() != "" && u.Port() != "443",
+		strings.Contains(host, "mcdn.bilivideo"), strings.HasSuffix(host, ".szbdyd.com"):
+		return 2
+	case strings.HasPrefix(host, "upos-"):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// sortCDNURLs 按 CDN 优先级排序下载地址，同优先级保持接口返回的顺序
+func sortCDNURLs(urls []string) []string {
+	sorted := append([]string(nil), urls...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return cdnHostRank(sorted[i]) < cdnHostRank(sorted[j])
+	})
+	return sorted
+}
+
+// newStreamClient 创建下载音视频流使用的 HTTP 客户端（不限制整体超时，由分段停滞检测兜底）
+func newStreamClient(cfg *config.Config) *http.Client {
+	transport := utils.NewHTTPTransport(cfg.Proxy, 20, 4)
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &http.Client{Transport: transport}
+}
+
+// dashFetcher 按 Range 分段下载单个流，支持断点续传与 CDN 切换
+type dashFetcher struct {
+	client    *http.Client
+	chunkSize int64
+	rounds    int // 全部地址均失败时的重试轮数
+}
+
+// fetch 下载流到 dest，先写入 dest.part，已有的 .part 文件从断点继续
+// onProgress 回调本流已下载字节数与总大小（未知时为 0）
+func (f *dashFetcher) fetch(ctx context.Context, urls []string, dest string, onProgress func(downloaded, total int64)) error {
+	if len(urls) == 0 {
+		return errors.New("没有可用的下载地址")
+	}
+	partPath := dest + ".part"
+	rounds := f.rounds
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	var lastErr error
+	for round := 0; round < rounds; round++ {
+		if round > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(round) * 2 * time.Second):
+			}
+		}
+		for _, u := range urls {
+			err := f.fetchFrom(ctx, u, partPath, onProgress)
+			if err == nil {
+				return os.Rename(partPath, dest)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			utils.Warn("下载流失败，切换 CDN 地址: %s, %v", cdnHost(u), err)
+		}
+	}
+	return fmt.Errorf("全部 CDN 地址下载失败: %w", lastErr)
+}
+
+// fetchFrom 从单个地址按分段下载，直到文件完整
+func (f *dashFetcher) fetchFrom(ctx context.Context, rawURL, partPath string, onProgress func(downloaded, total int64)) error {
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	chunk := f.chunkSize
+	if chunk <= 0 {
+		chunk = dashChunkSize
+	}
+	for {
+		n, total, err := f.fetchChunk(ctx, rawURL, file, offset, offset+chunk-1, onProgress)
+		if err != nil {
+			return err
+		}
+		short := n-offset < chunk
+		offset = n
+		if total >= 0 {
+			if offset >= total {
+				break
+			}
+			continue
+		}
+		// 服务器未返回总大小时以不足一个分段的响应作为结束
+		if short {
+			break
+		}
+	}
+	return nil
+}
+
+// fetchChunk 请求 [start, end] 区间并追加写入，返回写入后的文件大小与流总大小（未知时为 -1）
+// 服务器忽略 Range 返回完整内容时从头写入，总大小即写入的大小
+func (f *dashFetcher) fetchChunk(ctx context.Context, rawURL string, file *os.File, start, end int64, onProgress func(downloaded, total int64)) (int64, int64, error) {
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(dashStallTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(chunkCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return start, -1, err
+	}
+	req.Header.Set("User-Agent", bilibili.DefaultUserAgent)
+	req.Header.Set("Referer", bilibili.DefaultReferer)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return start, -1, err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	complete := false
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		if start > 0 {
+			if err := file.Truncate(0); err != nil {
+				return start, -1, err
+			}
+			start = 0
+		}
+		complete = true
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return start, -1, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 断点已在文件末尾：.part 文件已完整
+		if size := contentRangeTotal(resp.Header.Get("Content-Range")); size >= 0 && size == start {
+			return start, size, nil
+		}
+		return start, -1, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return start, -1, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	written := start
+	body := &stallReader{r: resp.Body, timer: stall}
+	buf := make([]byte, 32*1024)
+	reader := bandwidth.Shared().Reader(chunkCtx, body)
+	for {
+		n, readErr := reader.Read(buf)
+		if n > 0 {
+			if _, err := file.Write(buf[:n]); err != nil {
+				return written, -1, err
+			}
+			written += int64(n)
+			if onProgress != nil {
+				onProgress(written, max(total, 0))
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if ctx.Err() == nil && chunkCtx.Err() != nil {
+				return written, -1, fmt.Errorf("超过 %v 未收到数据", dashStallTimeout)
+			}
+			return written, -1, readErr
+		}
+	}
+	if complete {
+		total = written
+	}
+	return written, total, nil
+}
+
+// stallReader 每次读到数据时重置停滞计时器
+type stallReader struct {
+	r     io.Reader
+	timer *time.Timer
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.timer.Reset(dashStallTimeout)
+	}
+	return n, err
+}
+
+// contentRangeTotal 解析 Content-Range（bytes a-b/total）中的总大小，未知时返回 -1
+func contentRangeTotal(header string) int64 {
+	idx := strings.LastIndex(header, "/")
+	if idx < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(header[idx+1:]), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// cdnHost 下载地址的主机名（用于日志，避免输出带签名的完整地址）
+func cdnHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// muxStreams 使用 ffmpeg 将视频流与音频流无损合并为 output，audioPath 为空时只封装视频
+func muxStreams(ctx context.Context, videoPath, audioPath, output string) error {
+	tmp := output + ".muxing"
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", videoPath}
+	if audioPath != "" {
+		args = append(args, "-i", audioPath, "-map", "0:v:0", "-map", "1:a:0")
+	}
+	args = append(args, "-c", "copy", "-f", "mp4", tmp)
+
+	out, err := exec.CommandContext(ctx, "ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		os.Remove(tmp)
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("ffmpeg 合并失败: %w: %s", err, msg)
+		}
+		return fmt.Errorf("ffmpeg 合并失败: %w", err)
+	}
+	return os.Rename(tmp, output)
+}
+
+// downloadEngine 视频使用的下载引擎：视频源设置优先，其次为全局配置
+func (d *Downloader) downloadEngine(video *models.Video) string {
+	if engine := lookupSourceEngine(d.db, video); engine != "" {
+		return engine
+	}
+	if d.config.Download.Engine != "" {
+		return d.config.Download.Engine
+	}
+	return config.DownloadEngineYtdlp
+}
+
+// downloadPageNative 通过取流接口下载分P的 DASH 音视频流，并用 ffmpeg 合并为 mp4
+func (d *Downloader) downloadPageNative(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) (*dashSelection, error) {
+	quality := d.config.Quality
+	qn := maxQnForResolution(quality.MaxResolution)
+
+	var (
+		info *bilibili.PlayURLInfo
+		err  error
+	)
+	if video.IsEpisode() {
+		info, err = d.biliClient.GetBangumiPlayURL(ctx, video.EpID, page.CID, qn)
+	} else {
+		info, err = d.biliClient.GetPlayURL(ctx, video.BVid, page.CID, qn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Dash == nil || len(info.Dash.Video) == 0 {
+		return nil, errors.New("取流接口未返回 DASH 视频流")
+	}
+
+	videoStream := selectVideoStream(info.Dash.Video, qn, quality.CodecPriority)
+	audioStream := selectAudioStream(info.Dash.AudioStreams(), quality.AudioQuality)
+
+	// 杜比视界、HDR、Hi-Res、杜比音频需要大会员且视频本身提供，取流结果中没有时回退到较低的画质/音质
+	if (qn == qnDolbyVision || qn == qnHDR) && videoStream.ID != qn {
+		utils.Info("未获取到%s视频流（视频不提供或账号无权限），回退画质 %d: %s P%d",
+			strings.ToUpper(quality.MaxResolution), videoStream.ID, video.Name, page.PID)
+	}
+	if want := audioQualityID(quality.AudioQuality); (want == audioIDHiRes || want == audioIDDolby) && audioStream != nil && audioStream.ID != want {
+		utils.Info("未获取到 %d 音频流（视频不提供或账号无权限），回退音质 %d: %s P%d",
+			want, audioStream.ID, video.Name, page.PID)
+	}
+
+	selection := &dashSelection{VideoID: videoStream.ID}
+	if audioStream != nil {
+		selection.AudioID = audioStream.ID
+		switch audioStream.ID {
+		case audioIDHiRes:
+			selection.AudioType = models.AudioTypeHiRes
+		case audioIDDolby:
+			selection.AudioType = models.AudioTypeDolby
+			if info.Dash.Dolby != nil && info.Dash.Dolby.Type == 2 {
+				selection.AudioType = models.AudioTypeDolbyAtmos
+			}
+		}
+	}
+
+	type streamJob struct {
+		kind   string
+		stream *bilibili.DashStream
+		path   string
+	}
+	base := filepath.Join(outputDir, d.pageFileBaseName(video, page))
+	jobs := []streamJob{{kind: "video", stream: videoStream, path: base + ".video.m4s"}}
+	if audioStream != nil {
+		jobs = append(jobs, streamJob{kind: "audio", stream: audioStream, path: base + ".audio.m4s"})
+		utils.Info("选择 DASH 流: %s P%d -> 画质 %d %s %dx%d, 音质 %d",
+			video.Name, page.PID, videoStream.ID, videoStream.Codecs, videoStream.Width, videoStream.Height, audioStream.ID)
+	} else {
+		utils.Info("选择 DASH 流: %s P%d -> 画质 %d %s %dx%d, 无音频",
+			video.Name, page.PID, videoStream.ID, videoStream.Codecs, videoStream.Width, videoStream.Height)
+	}
+
+	fetcher := &dashFetcher{client: d.streamClient, chunkSize: dashChunkSize, rounds: d.maxRetries}
+	start := time.Now()
+	var finished, resumed int64 // 已完成流的大小、续传前已存在的字节数
+	for _, job := range jobs {
+		urls := job.stream.URLs()
+		if quality.CDNSort {
+			urls = sortCDNURLs(urls)
+		}
+		if st, err := os.Stat(job.path + ".part"); err == nil {
+			resumed += st.Size()
+		}
+
+		// 进度回调节流，每 500ms 通知一次
+		var lastNotify time.Time
+		err := fetcher.fetch(ctx, urls, job.path, func(downloaded, total int64) {
+			now := time.Now()
+			if now.Sub(lastNotify) < 500*time.Millisecond && downloaded < total {
+				return
+			}
+			lastNotify = now
+
+			elapsed := now.Sub(start).Seconds()
+			pageProgress.UpdateSubTask("video", func(task *SubTaskProgress) {
+				task.DownloadedSize = finished + downloaded
+				if total > 0 {
+					task.TotalSize = finished + total
+					task.Progress = float64(downloaded) * 100 / float64(total)
+				}
+				if elapsed > 0 {
+					task.Speed = float64(finished+downloaded-resumed) / elapsed
+					if task.Speed > 0 && total > 0 {
+						task.ETA = float64(total-downloaded) / task.Speed
+					}
+				}
+			})
+			d.tracker.NotifyProgress(video.ID, page.PID, "video", pageProgress.GetSubTask("video"))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("下载%s流失败: %w", job.kind, err)
+		}
+		if st, err := os.Stat(job.path); err == nil {
+			finished += st.Size()
+		}
+	}
+
+	audioPath := ""
+	if len(jobs) > 1 {
+		audioPath = jobs[1].path
+	}
+	if err := muxStreams(ctx, jobs[0].path, audioPath, base+".mp4"); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.path); err != nil {
+			utils.Warn("清理中间文件失败: %s, %v", job.path, err)
+		}
+	}
+	return selection, nil
+}
+
+// dashSelection 原生下载实际选择的音视频流
+type dashSelection struct {
+	VideoID   int    // 画质代码
+	AudioID   int    // 音质代码，无音频时为 0
+	AudioType string // 按音质代码确定的音频类型，普通音频为空
+}
+
+// applyToPage 按实际下载的流补充 ffprobe 无法可靠识别的杜比视界与音频类型（如全景声）
+func (s *dashSelection) applyToPage(page *models.Page) {
+	if s == nil {
+		return
+	}
+	if s.VideoID == qnDolbyVision {
+		page.DolbyVision = true
+	}
+	if s.AudioType != "" {
+		page.AudioType = s.AudioType
+	}
+}
+
+// removeNativeTempFiles 清理原生下载的中间文件（回退 yt-dlp 时调用）
+func removeNativeTempFiles(outputDir, baseName string) {
+	for _, suffix := range []string{".video.m4s", ".audio.m4s", ".video.m4s.part", ".audio.m4s.part", ".mp4.muxing"} {
+		path := filepath.Join(outputDir, baseName+suffix)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			utils.Warn("清理中间文件失败: %s, %v", path, err)
+		}
+	}
+}
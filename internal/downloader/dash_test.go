@@ -0,0 +1,290 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bili-download/internal/bilibili"
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func TestSelectVideoStream(t *testing.T) {
+	streams := []bilibili.DashStream{
+		{ID: 120, CodecID: 12, Bandwidth: 9000},
+		{ID: 80, CodecID: 7, Bandwidth: 2000},
+		{ID: 80, CodecID: 12, Bandwidth: 1500},
+		{ID: 80, CodecID: 13, Bandwidth: 1200},
+		{ID: 64, CodecID: 7, Bandwidth: 1000},
+	}
+
+	tests := []struct {
+		name     string
+		maxQn    int
+		priority []string
+		wantID   int
+		wantCdc  int
+	}{
+		{"highest allowed", maxQnForResolution("4K"), []string{"AVC"}, 120, 12},
+		{"codec priority", maxQnForResolution("1080P+"), []string{"HEVC", "AVC"}, 80, 12},
+		{"av1 first", maxQnForResolution("1080P"), []string{"AV1", "HEVC", "AVC"}, 80, 13},
+		{"unlisted codec ranks last", maxQnForResolution("1080P"), []string{"AV1"}, 80, 13},
+		{"lower cap", maxQnForResolution("720P"), []string{"HEVC"}, 64, 7},
+		{"below all streams", maxQnForResolution("360P"), []string{"AVC"}, 64, 7},
+	}
+	for _, tt := range tests {
+		got := selectVideoStream(streams, tt.maxQn, tt.priority)
+		if got == nil || got.ID != tt.wantID || got.CodecID != tt.wantCdc {
+			t.Errorf("%s: got %+v, want id=%d codec=%d", tt.name, got, tt.wantID, tt.wantCdc)
+		}
+	}
+
+	// 编码优先级相同时选择码率更高的流
+	if got := selectVideoStream(streams, 80, nil); got.CodecID != 7 {
+		t.Errorf("expected highest bandwidth stream without codec priority, got %+v", got)
+	}
+}
+
+func TestSelectAudioStream(t *testing.T) {
+	streams := []bilibili.DashStream{{ID: 30216}, {ID: 30280}, {ID: 30232}}
+
+	tests := []struct {
+		quality string
+		want    int
+	}{
+		{"30280", 30280},
+		{"high", 30280},
+		{"30232", 30232},
+		{"medium", 30232},
+		{"low", 30216},
+		{"30251", 30280}, // Hi-Res 不在普通音频流中时退回最高音质
+	}
+	for _, tt := range tests {
+		if got := selectAudioStream(streams, tt.quality); got == nil || got.ID != tt.want {
+			t.Errorf("quality %q: got %+v, want %d", tt.quality, got, tt.want)
+		}
+	}
+
+	if got := selectAudioStream(streams[1:2], "low"); got == nil || got.ID != 30280 {
+		t.Errorf("expected fallback to the only stream, got %+v", got)
+	}
+	if got := selectAudioStream(nil, "high"); got != nil {
+		t.Errorf("expected nil for no audio streams, got %+v", got)
+	}
+}
+
+func TestSelectAudioStreamHiResAndDolby(t *testing.T) {
+	dash := bilibili.DashInfo{
+		Audio: []bilibili.DashStream{{ID: 30280}, {ID: 30216}},
+		Dolby: &bilibili.DashDolby{Type: 2, Audio: []bilibili.DashStream{{ID: 30250}}},
+		Flac:  &bilibili.DashFlac{Audio: &bilibili.DashStream{ID: 30251}},
+	}
+	noFlac := dash
+	noFlac.Flac = nil
+	plain := bilibili.DashInfo{Audio: dash.Audio}
+
+	tests := []struct {
+		name    string
+		dash    bilibili.DashInfo
+		quality string
+		want    int
+	}{
+		{"hires", dash, "30251", 30251},
+		{"dolby", dash, "30250", 30250},
+		{"192K excludes lossless and dolby", dash, "30280", 30280},
+		{"hires falls back to dolby", noFlac, "30251", 30250},
+		{"hires falls back to normal audio", plain, "30251", 30280},
+		{"dolby falls back to normal audio", plain, "30250", 30280},
+	}
+	for _, tt := range tests {
+		if got := selectAudioStream(tt.dash.AudioStreams(), tt.quality); got == nil || got.ID != tt.want {
+			t.Errorf("%s: got %+v, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSelectVideoStreamDynamicRange(t *testing.T) {
+	streams := []bilibili.DashStream{{ID: 126, CodecID: 12}, {ID: 125, CodecID: 12}, {ID: 120, CodecID: 12}, {ID: 80, CodecID: 7}}
+	if got := selectVideoStream(streams, maxQnForResolution("DOLBY"), nil); got.ID != 126 {
+		t.Errorf("DOLBY: got %d, want 126", got.ID)
+	}
+	if got := selectVideoStream(streams, maxQnForResolution("HDR"), nil); got.ID != 125 {
+		t.Errorf("HDR: got %d, want 125", got.ID)
+	}
+	// 无大会员时取流结果不含杜比视界/HDR，回退到 4K
+	if got := selectVideoStream(streams[2:], maxQnForResolution("DOLBY"), nil); got.ID != 120 {
+		t.Errorf("DOLBY fallback: got %d, want 120", got.ID)
+	}
+	// 8K 的画质代码高于杜比视界/HDR，但未要求动态范围时只选普通流
+	if got := selectVideoStream(streams, maxQnForResolution("8K"), nil); got.ID != 120 {
+		t.Errorf("8K without 8K stream: got %d, want 120", got.ID)
+	}
+	withEightK := append([]bilibili.DashStream{{ID: 127, CodecID: 13}}, streams...)
+	if got := selectVideoStream(withEightK, maxQnForResolution("8K"), nil); got.ID != 127 {
+		t.Errorf("8K: got %d, want 127", got.ID)
+	}
+	if got := selectVideoStream(withEightK, maxQnForResolution("DOLBY"), nil); got.ID != 126 {
+		t.Errorf("DOLBY with 8K stream: got %d, want 126", got.ID)
+	}
+}
+
+func TestDashSelectionApplyToPage(t *testing.T) {
+	page := &models.Page{HDRFormat: models.HDRFormatHDR10, AudioCodec: "eac3", AudioType: models.AudioTypeDolby}
+	(&dashSelection{VideoID: 126, AudioID: 30250, AudioType: models.AudioTypeDolbyAtmos}).applyToPage(page)
+	if !page.DolbyVision || page.AudioType != models.AudioTypeDolbyAtmos {
+		t.Errorf("expected Dolby Vision and Atmos from the selected streams, got %+v", page)
+	}
+
+	page = &models.Page{AudioCodec: "aac", AudioType: models.AudioTypeStandard}
+	(&dashSelection{VideoID: 80, AudioID: 30280}).applyToPage(page)
+	var nilSelection *dashSelection
+	nilSelection.applyToPage(page)
+	if page.DolbyVision || page.AudioType != models.AudioTypeStandard {
+		t.Errorf("expected probe results kept for normal streams, got %+v", page)
+	}
+}
+
+func TestBuildFormatSelectorDynamicRangeAndAudio(t *testing.T) {
+	tests := []struct {
+		resolution string
+		audio      string
+		want       string
+	}{
+		{"DOLBY", "30250",
+			"bestvideo[height<=2160][dynamic_range=DV]+bestaudio[acodec^=ec-3]/bestvideo[height<=2160][dynamic_range=DV]+bestaudio/" +
+				"bestvideo[height<=2160][dynamic_range^=HDR]+bestaudio[acodec^=ec-3]/bestvideo[height<=2160][dynamic_range^=HDR]+bestaudio/" +
+				"bestvideo[height<=2160]+bestaudio[acodec^=ec-3]/bestvideo[height<=2160]+bestaudio/best"},
+		{"HDR", "30251",
+			"bestvideo[height<=2160][dynamic_range^=HDR]+bestaudio[acodec=flac]/bestvideo[height<=2160][dynamic_range^=HDR]+bestaudio[acodec^=ec-3]/bestvideo[height<=2160][dynamic_range^=HDR]+bestaudio/" +
+				"bestvideo[height<=2160]+bestaudio[acodec=flac]/bestvideo[height<=2160]+bestaudio[acodec^=ec-3]/bestvideo[height<=2160]+bestaudio/best"},
+		{"1080P", "30280",
+			"bestvideo[height<=1080]+bestaudio[acodec!=flac][acodec!^=ec-3]/bestvideo[height<=1080]+bestaudio/best"},
+	}
+	for _, tt := range tests {
+		d := &Downloader{config: &config.Config{Quality: config.QualityConfig{MaxResolution: tt.resolution, AudioQuality: tt.audio}}}
+		if got := d.buildFormatSelector(); got != tt.want {
+			t.Errorf("%s/%s:\n got  %s\n want %s", tt.resolution, tt.audio, got, tt.want)
+		}
+	}
+}
+
+func TestSortCDNURLs(t *testing.T) {
+	urls := []string{
+		"https://xy1x2x3x4xy.mcdn.bilivideo.cn:4483/upgcxcode/a.m4s",
+		"https://cn-gdfs-ct-01-01.bilivideo.com/upgcxcode/a.m4s",
+		"https://upos-sz-mirrorali.bilivideo.com/upgcxcode/a.m4s",
+		"http://123.45.67.89:8082/upgcxcode/a.m4s",
+		"https://upos-sz-mirrorcos.bilivideo.com/upgcxcode/a.m4s",
+	}
+	got := sortCDNURLs(urls)
+	want := []string{urls[2], urls[4], urls[1], urls[0], urls[3]}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortCDNURLs()[%d] = %s, want %s (all: %v)", i, got[i], want[i], got)
+		}
+	}
+	if urls[0] != "https://xy1x2x3x4xy.mcdn.bilivideo.cn:4483/upgcxcode/a.m4s" {
+		t.Error("sortCDNURLs should not modify the input slice")
+	}
+}
+
+func TestDashFetcherResumesAndFailsOver(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var ranges []string
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Referer") == "" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "a.m4s", time.Time{}, bytes.NewReader(content))
+	}))
+	defer good.Close()
+
+	var badHits atomic.Int32
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		badHits.Add(1)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+
+	dest := filepath.Join(t.TempDir(), "a.video.m4s")
+	// 已下载的前 3000 字节
+	if err := os.WriteFile(dest+".part", content[:3000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var lastDownloaded, lastTotal int64
+	f := &dashFetcher{client: good.Client(), chunkSize: 4000, rounds: 1}
+	err := f.fetch(context.Background(), []string{bad.URL, good.URL}, dest, func(downloaded, total int64) {
+		lastDownloaded, lastTotal = downloaded, total
+	})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("downloaded content mismatch: got %d bytes, want %d", len(got), len(content))
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error("expected .part file to be renamed")
+	}
+	if badHits.Load() != 1 {
+		t.Errorf("expected failing CDN to be tried once, got %d", badHits.Load())
+	}
+	wantRanges := []string{"bytes=3000-6999", "bytes=7000-10999"}
+	if strings.Join(ranges, ",") != strings.Join(wantRanges, ",") {
+		t.Errorf("ranges = %v, want %v", ranges, wantRanges)
+	}
+	if lastDownloaded != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("last progress = %d/%d, want %d/%d", lastDownloaded, lastTotal, len(content), len(content))
+	}
+}
+
+func TestDashFetcherRestartsWhenRangeIgnored(t *testing.T) {
+	content := []byte("full content without range support")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "a.audio.m4s")
+	if err := os.WriteFile(dest+".part", []byte("stale partial data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &dashFetcher{client: srv.Client(), chunkSize: 8, rounds: 1}
+	if err := f.fetch(context.Background(), []string{srv.URL}, dest, nil); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, content) {
+		t.Errorf("got %q, want %q", got, content)
+	}
+}
+
+func TestDashFetcherAllURLsFail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	f := &dashFetcher{client: srv.Client(), chunkSize: 1024, rounds: 1}
+	err := f.fetch(context.Background(), []string{srv.URL, srv.URL + "/backup"}, filepath.Join(t.TempDir(), "x.m4s"), nil)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Fatalf("expected HTTP 404 error, got %v", err)
+	}
+}
@@ -4,26 +4,36 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"bili-download/internal/bandwidth"
 	"bili-download/internal/bilibili"
 	"bili-download/internal/config"
 	"bili-download/internal/database/models"
 	"bili-download/internal/nfo"
 	"bili-download/internal/utils"
+
+	"gorm.io/gorm"
 )
 
 // Downloader B站视频下载器
 type Downloader struct {
-	config      *config.Config
-	biliClient  *bilibili.Client
-	ytdlp       *YtdlpDownloader
-	tracker     *ProgressTracker
-	cookiesFile string
-	maxRetries  int
+	config       *config.Config
+	biliClient   *bilibili.Client
+	ytdlp        *YtdlpDownloader
+	streamClient *http.Client // 原生下载 DASH 流使用的 HTTP 客户端
+	tracker      *ProgressTracker
+	cookiesFile  string
+	maxRetries   int
+	db           *gorm.DB // 用于查询视频源名称（命名模板变量），可为空
+
+	postProcessPool atomic.Pointer[ffmpegPool] // 后处理 ffmpeg 进程池，配置更新时整体替换，分P下载中并发读取
 }
 
 // NewDownloader 创建新的下载器
@@ -31,18 +41,23 @@ func NewDownloader(cfg *config.Config, biliClient *bilibili.Client) (*Downloader
 	tracker := NewProgressTracker()
 	ytdlp := NewYtdlpDownloader(cfg, tracker)
 
-	// 检查 yt-dlp 是否可用
+	// 检查 yt-dlp 是否可用（默认使用原生下载时 yt-dlp 仅作为回退，缺失时只记录警告）
 	if err := CheckYtdlpAvailable(); err != nil {
-		return nil, err
+		if cfg.Download.Engine != config.DownloadEngineNative {
+			return nil, err
+		}
+		utils.Warn("yt-dlp 不可用，原生下载失败时将无法回退: %v", err)
 	}
 
 	d := &Downloader{
-		config:     cfg,
-		biliClient: biliClient,
-		ytdlp:      ytdlp,
-		tracker:    tracker,
-		maxRetries: 3,
+		config:       cfg,
+		biliClient:   biliClient,
+		ytdlp:        ytdlp,
+		streamClient: newStreamClient(cfg),
+		tracker:      tracker,
+		maxRetries:   3,
 	}
+	d.postProcessPool.Store(newFFmpegPool(cfg.Download.PostProcessWorkers))
 
 	// 创建 cookies 文件
 	if err := d.createCookiesFile(); err != nil {
@@ -100,55 +115,106 @@ func (d *Downloader) Cleanup() {
 	}
 }
 
-// DownloadPage 下载单个分P
+// DownloadPage 下载单个分P的视频及封面、字幕、弹幕、NFO
 func (d *Downloader) DownloadPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
-	// 创建分P进度
-	pageProgress := NewPageProgress(page.ID, page.CID, page.PID, page.Name)
+	return d.downloadPage(ctx, video, page, outputDir, 0)
+}
 
-	videoProgress := d.tracker.GetVideo(video.ID)
-	if videoProgress == nil {
-		videoProgress = d.tracker.AddVideo(video.ID, video.BVid, video.Name, len(video.Pages))
+// RepairPage 按磁盘上已有的文件修复分P，只重新下载或生成缺失的文件
+func (d *Downloader) RepairPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+	present, videoFile := scanPageArtifacts(outputDir, d.pageFileBaseName(video, page))
+	if videoFile != "" {
+		page.Path = filepath.Join(outputDir, videoFile)
 	}
-	videoProgress.AddPage(page.PID, pageProgress)
+	utils.Info("修复分P: %s P%d，已有文件状态 %05b", video.Name, page.PID, present)
+	return d.downloadPage(ctx, video, page, outputDir, present)
+}
 
-	// 下载视频
-	if err := d.downloadPageVideo(ctx, video, page, outputDir, pageProgress); err != nil {
-		return err
+// downloadPage 下载分P中 present 以外的文件，page.DownloadStatus 记录最终写入磁盘的文件
+func (d *Downloader) downloadPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string, present int) error {
+	// 获取分P进度（视频任务会预先登记待下载的分P，重试时沿用）
+	videoProgress := d.tracker.EnsureVideo(video.ID, video.BVid, video.Name, len(video.Pages))
+	pageProgress := videoProgress.GetPage(page.PID)
+	if pageProgress == nil {
+		pageProgress = NewPageProgress(page.ID, page.CID, page.PID, page.Name)
+		videoProgress.AddPage(page.PID, pageProgress)
 	}
+	page.DownloadStatus = present
 
-	// 下载封面
-	if !d.config.Download.SkipPoster {
-		if err := d.downloadPoster(ctx, video, page, outputDir, pageProgress); err != nil {
-			utils.Error("下载封面失败: %v", err)
+	// 下载视频
+	if present&models.PageStatusVideo != 0 {
+		d.markArtifactPresent(video, page, pageProgress, "video")
+	} else {
+		if err := d.downloadPageVideo(ctx, video, page, outputDir, pageProgress); err != nil {
+			return err
 		}
+		page.DownloadStatus |= models.PageStatusVideo
 	}
 
-	// 下载字幕
-	if !d.config.Download.SkipSubtitle {
-		if err := d.downloadSubtitles(ctx, video, page, outputDir, pageProgress); err != nil {
-			utils.Error("下载字幕失败: %v", err)
-		}
+	type pageStep struct {
+		flag  int
+		name  string
+		label string
+		skip  bool
+		run   func(context.Context, *models.Video, *models.Page, string, *PageProgress) error
 	}
-
-	// 下载弹幕
-	if !d.config.Download.SkipDanmaku {
-		if err := d.downloadDanmaku(ctx, video, page, outputDir, pageProgress); err != nil {
-			utils.Error("下载弹幕失败: %v", err)
+	runSteps := func(steps ...pageStep) {
+		for _, step := range steps {
+			if step.skip {
+				continue
+			}
+			if present&step.flag != 0 {
+				d.markArtifactPresent(video, page, pageProgress, step.name)
+				continue
+			}
+			if err := step.run(ctx, video, page, outputDir, pageProgress); err != nil {
+				utils.Error("%s失败: %v", step.label, err)
+				continue
+			}
+			// 没有内容可写（无字幕、无弹幕）时子任务为跳过，不记录状态位，修复时会再次尝试
+			if st := pageProgress.GetSubTask(step.name); st != nil && st.Status == StatusSucceeded {
+				page.DownloadStatus |= step.flag
+			}
 		}
 	}
+	runSteps(
+		pageStep{models.PageStatusPoster, "poster", "下载封面", d.config.Download.SkipPoster, d.downloadPoster},
+		pageStep{models.PageStatusSubtitle, "subtitle", "下载字幕", d.config.Download.SkipSubtitle, d.downloadSubtitles},
+		pageStep{models.PageStatusDanmaku, "danmaku", "下载弹幕", d.config.Download.SkipDanmaku, d.downloadDanmaku},
+	)
 
-	// 生成NFO元数据
-	if !d.config.Download.SkipVideoNFO {
-		if err := d.generateNFO(ctx, video, page, outputDir, pageProgress); err != nil {
-			utils.Error("生成NFO失败: %v", err)
-		}
+	// 后处理在字幕、弹幕之后（MKV 内嵌）、NFO 之前（写入处理后的流信息），处理过的视频需重新生成 NFO
+	if processed, err := d.postProcessPage(ctx, video, page, outputDir, pageProgress); err != nil {
+		utils.Error("视频后处理失败: %v", err)
+	} else if processed {
+		present &^= models.PageStatusNFO
 	}
 
+	runSteps(pageStep{models.PageStatusNFO, "nfo", "生成NFO", d.config.Download.SkipVideoNFO, d.generateNFO})
+
 	// 更新状态
 	pageProgress.UpdateStatus(StatusSucceeded)
 	return nil
 }
 
+// markArtifactPresent 将磁盘上已存在的文件对应的子任务标记为成功
+func (d *Downloader) markArtifactPresent(video *models.Video, page *models.Page, pageProgress *PageProgress, name string) {
+	var size int64
+	if name == "video" && page.Path != "" {
+		if info, err := os.Stat(page.Path); err == nil {
+			size = info.Size()
+		}
+	}
+	pageProgress.UpdateSubTask(name, func(task *SubTaskProgress) {
+		task.Status = StatusSucceeded
+		task.Progress = 100
+		task.DownloadedSize = size
+		task.TotalSize = size
+		task.EndTime = time.Now()
+	})
+	d.tracker.NotifyProgress(video.ID, page.PID, name, pageProgress.GetSubTask(name))
+}
+
 // downloadPageVideo 下载分P视频
 func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
 	utils.Info("开始下载视频: %s [BV%s] P%d - %s", video.Name, video.BVid, page.PID, page.Name)
@@ -160,60 +226,26 @@ func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video,
 		task.StartTime = time.Now()
 	})
 
-	// 构建视频 URL
-	videoURL := fmt.Sprintf("https://www.bilibili.com/video/%s?p=%d", video.BVid, page.PID)
-
-	// 构建输出文件名
-	outputTemplate := d.buildOutputTemplate(video, page)
-	utils.Debug("输出文件名模板: %s", outputTemplate)
-
-	// 构建格式选择器
-	format := d.buildFormatSelector()
-
-	// 下载选项
-	opts := &DownloadOptions{
-		URL:            videoURL,
-		OutputPath:     outputDir,
-		OutputTemplate: outputTemplate,
-		Cookies:        d.cookiesFile,
-		Format:         format,
-		WriteSubtitles: !d.config.Download.SkipSubtitle,
-		SubtitleLangs:  []string{"zh-CN", "zh-Hans"},
-		WriteThumbnail: false, // 缩略图单独下载
-		ExtraArgs:      d.config.Advanced.YtdlpExtraArgs,
-	}
-
-	// 进度回调 - 累加多个流（视频流+音频流）的大小
-	var completedStreamSize int64
-	var lastStreamTotal int64
-	progressCallback := func(progress *ProgressInfo) {
-		// 当新流开始时（totalBytes 变小），累加上一个流的大小
-		currentTotal := progress.TotalBytes
-		if currentTotal == 0 {
-			currentTotal = progress.TotalBytesEst
-		}
-		if currentTotal > 0 && lastStreamTotal > 0 && currentTotal < lastStreamTotal/2 {
-			completedStreamSize += lastStreamTotal
-		}
-		if currentTotal > 0 {
-			lastStreamTotal = currentTotal
+	// 按视频源或全局配置选择下载引擎，原生下载失败时回退 yt-dlp
+	var (
+		err       error
+		selection *dashSelection
+	)
+	if d.downloadEngine(video) == config.DownloadEngineNative {
+		if selection, err = d.downloadPageNative(ctx, video, page, outputDir, pageProgress); err != nil && ctx.Err() == nil {
+			utils.Warn("原生下载失败，回退 yt-dlp: %s [BV%s] P%d, %v", video.Name, video.BVid, page.PID, err)
+			removeNativeTempFiles(outputDir, d.pageFileBaseName(video, page))
+			pageProgress.UpdateSubTask("video", func(task *SubTaskProgress) {
+				task.Progress = 0
+				task.DownloadedSize = 0
+				task.TotalSize = 0
+			})
+			err = d.downloadPageYtdlp(ctx, video, page, outputDir, pageProgress)
 		}
-
-		pageProgress.UpdateSubTask("video", func(task *SubTaskProgress) {
-			task.Progress = progress.Percentage
-			task.Speed = progress.Speed
-			task.DownloadedSize = completedStreamSize + progress.DownloadedBytes
-			task.TotalSize = completedStreamSize + currentTotal
-			task.ETA = progress.ETA
-		})
-
-		// 通知进度更新
-		d.tracker.NotifyProgress(video.ID, page.PID, "video", pageProgress.GetSubTask("video"))
+	} else {
+		err = d.downloadPageYtdlp(ctx, video, page, outputDir, pageProgress)
 	}
 
-	// 执行下载
-	err := d.ytdlp.DownloadWithRetry(ctx, opts, d.maxRetries, progressCallback)
-
 	if err != nil {
 		pageProgress.UpdateSubTask("video", func(task *SubTaskProgress) {
 			task.Status = StatusFailed
@@ -227,8 +259,7 @@ func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video,
 	videoFileExists := false
 	var videoFileSize int64
 	var videoFileName string
-	videoExts := []string{".mp4", ".mkv", ".webm", ".flv", ".avi", ".m4v"}
-	baseName := utils.Filenamify(video.Name)
+	baseName := d.pageFileBaseName(video, page)
 	entries, _ := os.ReadDir(outputDir)
 	// 正则匹配 yt-dlp 中间文件: filename.fXXXXX.ext（音视频流分离时产生）
 	var tempFiles []string
@@ -237,35 +268,19 @@ func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video,
 			continue
 		}
 		name := entry.Name()
-		for _, ext := range videoExts {
-			if !strings.HasSuffix(strings.ToLower(name), ext) || !strings.HasPrefix(name, baseName) {
-				continue
-			}
-			// 检查是否是中间文件（包含 .fXXXXX. 格式ID后缀）
-			nameWithoutExt := name[:len(name)-len(ext)]
-			if idx := strings.LastIndex(nameWithoutExt, ".f"); idx > 0 {
-				suffix := nameWithoutExt[idx+2:]
-				isTemp := len(suffix) > 0
-				for _, c := range suffix {
-					if c < '0' || c > '9' {
-						isTemp = false
-						break
-					}
-				}
-				if isTemp {
-					tempFiles = append(tempFiles, filepath.Join(outputDir, name))
-					break
-				}
-			}
-			info, err := entry.Info()
-			if err == nil && info.Size() > 0 {
-				videoFileExists = true
-				videoFileSize = info.Size()
-				videoFileName = name
-			}
-			break
+		isVideo, isTemp := pageVideoFile(name, baseName)
+		if !isVideo {
+			continue
+		}
+		if isTemp {
+			tempFiles = append(tempFiles, filepath.Join(outputDir, name))
+			continue
 		}
-		if videoFileExists {
+		info, err := entry.Info()
+		if err == nil && info.Size() > 0 {
+			videoFileExists = true
+			videoFileSize = info.Size()
+			videoFileName = name
 			break
 		}
 	}
@@ -313,20 +328,21 @@ func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video,
 	})
 	d.tracker.NotifyProgress(video.ID, page.PID, "video", pageProgress.GetSubTask("video"))
 
-	// 探测实际分辨率/帧率，写入 page 结构供后续持久化
+	// 记录视频文件路径，媒体库重整时据此定位分P文件
+	page.Path = filepath.Join(outputDir, videoFileName)
+
+	// 探测实际分辨率/帧率/编码/音频，写入 page 结构供后续持久化与生成 NFO
 	if videoFileName != "" {
 		probePath := filepath.Join(outputDir, videoFileName)
 		if probe, err := ProbeVideo(ctx, probePath); err != nil {
 			utils.Warn("ffprobe 探测失败: %s, %v", probePath, err)
 		} else {
-			page.Width = probe.Width
-			page.Height = probe.Height
-			page.FrameRate = probe.FrameRate
-			page.Quality = models.CalcQuality(probe.Width, probe.Height, probe.FrameRate)
-			page.Orientation = models.CalcOrientation(probe.Width, probe.Height)
-			utils.Info("探测画质: %s P%d -> %dx%d@%.2ffps [%s]",
+			probe.ApplyToPage(page)
+			selection.applyToPage(page)
+			utils.Info("探测画质: %s P%d -> %dx%d@%.2ffps %s [%s] 音频 %s %dch %s",
 				video.Name, page.PID, probe.Width, probe.Height, probe.FrameRate,
-				models.QualityLabel(page.Quality))
+				models.VideoCodecLabel(page.VideoCodec), models.QualityLabel(page.Quality),
+				page.AudioCodec, page.AudioChannels, models.AudioTypeLabel(page.AudioType))
 		}
 	}
 
@@ -334,20 +350,75 @@ func (d *Downloader) downloadPageVideo(ctx context.Context, video *models.Video,
 	return nil
 }
 
+// downloadPageYtdlp 调用 yt-dlp 下载分P视频
+func (d *Downloader) downloadPageYtdlp(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
+	// 构建视频 URL（番剧剧集使用 ep 链接，以便按番剧权限与地区取流）
+	videoURL := fmt.Sprintf("https://www.bilibili.com/video/%s?p=%d", video.BVid, page.PID)
+	if video.IsEpisode() {
+		videoURL = fmt.Sprintf("https://www.bilibili.com/bangumi/play/ep%d", video.EpID)
+	}
+
+	// 构建输出文件名
+	outputTemplate := d.buildOutputTemplate(video, page)
+	utils.Debug("输出文件名模板: %s", outputTemplate)
+
+	// 构建格式选择器
+	format := d.buildFormatSelector()
+
+	// 下载选项
+	opts := &DownloadOptions{
+		URL:            videoURL,
+		OutputPath:     outputDir,
+		OutputTemplate: outputTemplate,
+		Cookies:        d.cookiesFile,
+		Format:         format,
+		WriteSubtitles: false, // 字幕通过B站接口单独下载（含AI字幕）
+		WriteThumbnail: false, // 缩略图单独下载
+		ExtraArgs:      d.config.Advanced.YtdlpExtraArgs,
+	}
+
+	// 进度回调 - 累加多个流（视频流+音频流）的大小
+	var completedStreamSize int64
+	var lastStreamTotal int64
+	progressCallback := func(progress *ProgressInfo) {
+		// 当新流开始时（totalBytes 变小），累加上一个流的大小
+		currentTotal := progress.TotalBytes
+		if currentTotal == 0 {
+			currentTotal = progress.TotalBytesEst
+		}
+		if currentTotal > 0 && lastStreamTotal > 0 && currentTotal < lastStreamTotal/2 {
+			completedStreamSize += lastStreamTotal
+		}
+		if currentTotal > 0 {
+			lastStreamTotal = currentTotal
+		}
+
+		pageProgress.UpdateSubTask("video", func(task *SubTaskProgress) {
+			task.Progress = progress.Percentage
+			task.Speed = progress.Speed
+			task.DownloadedSize = completedStreamSize + progress.DownloadedBytes
+			task.TotalSize = completedStreamSize + currentTotal
+			task.ETA = progress.ETA
+		})
+
+		// 通知进度更新
+		d.tracker.NotifyProgress(video.ID, page.PID, "video", pageProgress.GetSubTask("video"))
+	}
+
+	// 执行下载
+	return d.ytdlp.DownloadWithRetry(ctx, opts, d.maxRetries, progressCallback)
+}
+
 // buildOutputTemplate 构建输出文件名模板
 func (d *Downloader) buildOutputTemplate(video *models.Video, page *models.Page) string {
 	// 注意：outputDir已经是视频专属文件夹（例如：D:/Downloads/waasd/视频名/）
-	// 所以这里只需要返回文件名，不需要再包含视频名称
-
-	// 单页视频: {video_name}.%(ext)s
-	// 多页视频: {video_name}-{ptitle}.%(ext)s
-	if video.SinglePage {
-		return fmt.Sprintf("%s.%%(ext)s", utils.Filenamify(video.Name))
-	}
+	// 所以这里只需要返回按分P名称模板生成的文件名
+	return d.pageFileBaseName(video, page) + ".%(ext)s"
+}
 
-	return fmt.Sprintf("%s-%s.%%(ext)s",
-		utils.Filenamify(video.Name),
-		utils.Filenamify(page.Name))
+// pageFileBaseName 分P文件（视频、封面、NFO、字幕、弹幕）的文件名前缀，由分P名称模板生成
+func (d *Downloader) pageFileBaseName(video *models.Video, page *models.Page) string {
+	return PageFileBaseName(d.config, video, page, LookupSourceName(d.db, video))
 }
 
 // buildFormatSelector 构建格式选择器
@@ -373,7 +444,11 @@ func (d *Downloader) buildFormatSelector() string {
 	}
 
 	// 构建视频编码格式过滤条件
+	// 杜比视界/HDR 优先选择对应动态范围的视频流，视频不提供或账号无权限时回退到普通视频流
 	var videoFilters []string
+	for _, dr := range ytdlpDynamicRangeFilters(d.config.Quality.MaxResolution) {
+		videoFilters = append(videoFilters, fmt.Sprintf("bestvideo[height<=%s]%s", maxHeight, dr))
+	}
 	if len(d.config.Quality.CodecPriority) > 0 {
 		// 为每个编码格式生成独立的选择器，按优先级排序
 		for _, codec := range d.config.Quality.CodecPriority {
@@ -398,15 +473,17 @@ func (d *Downloader) buildFormatSelector() string {
 		videoFilters = append(videoFilters, fmt.Sprintf("bestvideo[height<=%s]", maxHeight))
 	}
 
-	// 构建音频过滤器 - 简化为纯 bestaudio，让 yt-dlp 自动选择最佳音频
-	audioFilter := "bestaudio"
+	// 构建音频过滤器，按音质配置选择 Hi-Res/杜比音频或普通音频
+	audioFilters := ytdlpAudioFilters(d.config.Quality.AudioQuality)
 
 	// 组合视频和音频选择器
-	// 格式: (视频1+音频)/(视频2+音频)/(视频3+音频)/best
-	// 为每个视频选择器配对音频
+	// 格式: (视频1+音频1)/(视频1+音频2)/(视频2+音频1)/.../best
+	// 为每个视频选择器依次配对音频
 	var finalSelectors []string
 	for _, videoFilter := range videoFilters {
-		finalSelectors = append(finalSelectors, fmt.Sprintf("%s+%s", videoFilter, audioFilter))
+		for _, audioFilter := range audioFilters {
+			finalSelectors = append(finalSelectors, fmt.Sprintf("%s+%s", videoFilter, audioFilter))
+		}
 	}
 	// 添加 best 作为最终备选（包含音视频）
 	finalSelectors = append(finalSelectors, "best")
@@ -414,6 +491,29 @@ func (d *Downloader) buildFormatSelector() string {
 	return joinWithSlash(finalSelectors)
 }
 
+// ytdlpDynamicRangeFilters 最高画质为杜比视界或 HDR 时优先尝试的 yt-dlp 动态范围过滤条件
+func ytdlpDynamicRangeFilters(maxResolution string) []string {
+	switch strings.ToUpper(strings.TrimSpace(maxResolution)) {
+	case "DOLBY":
+		return []string{"[dynamic_range=DV]", "[dynamic_range^=HDR]"}
+	case "HDR":
+		return []string{"[dynamic_range^=HDR]"}
+	}
+	return nil
+}
+
+// ytdlpAudioFilters 按音质配置生成 yt-dlp 音频选择器，依次回退
+// Hi-Res 为 FLAC，杜比音频为 E-AC-3；普通音质排除二者，避免未开启时下载到大体积音轨
+func ytdlpAudioFilters(audioQuality string) []string {
+	switch audioQualityID(audioQuality) {
+	case audioIDHiRes:
+		return []string{"bestaudio[acodec=flac]", "bestaudio[acodec^=ec-3]", "bestaudio"}
+	case audioIDDolby:
+		return []string{"bestaudio[acodec^=ec-3]", "bestaudio"}
+	}
+	return []string{"bestaudio[acodec!=flac][acodec!^=ec-3]", "bestaudio"}
+}
+
 // joinWithSlash 用斜杠连接字符串数组
 func joinWithSlash(parts []string) string {
 	result := ""
@@ -457,25 +557,13 @@ func (d *Downloader) downloadPoster(ctx context.Context, video *models.Video, pa
 	coverURL = normalizeImageURL(coverURL)
 	utils.Info("下载封面: %s", coverURL)
 
-	// 构建输出文件名
-	// 单页视频: {video_name}-poster.jpg
-	// 多页视频: {video_name}-{ptitle}-poster.jpg
+	// 构建输出文件名: {分P文件名}-poster.jpg
 	ext := getImageExtension(coverURL)
-	var posterFile string
-	if video.SinglePage {
-		posterFile = fmt.Sprintf("%s-poster%s",
-			utils.Filenamify(video.Name),
-			ext)
-	} else {
-		posterFile = fmt.Sprintf("%s-%s-poster%s",
-			utils.Filenamify(video.Name),
-			utils.Filenamify(page.Name),
-			ext)
-	}
+	posterFile := fmt.Sprintf("%s-poster%s", d.pageFileBaseName(video, page), ext)
 	posterPath := filepath.Join(outputDir, posterFile)
 
 	// 下载封面
-	resp, err := d.biliClient.Get(coverURL, nil)
+	resp, err := d.biliClient.Get(ctx, coverURL, nil)
 	if err != nil {
 		pageProgress.UpdateSubTask("poster", func(task *SubTaskProgress) {
 			task.Status = StatusFailed
@@ -507,7 +595,7 @@ func (d *Downloader) downloadPoster(ctx context.Context, video *models.Video, pa
 	defer file.Close()
 
 	// 写入文件并统计大小
-	written, err := io.Copy(file, resp.Body)
+	written, err := io.Copy(file, bandwidth.Shared().Reader(ctx, resp.Body))
 	if err != nil {
 		pageProgress.UpdateSubTask("poster", func(task *SubTaskProgress) {
 			task.Status = StatusFailed
@@ -557,17 +645,6 @@ func getImageExtension(url string) string {
 	return ".jpg"
 }
 
-// downloadSubtitles 下载字幕（yt-dlp 已经处理）
-func (d *Downloader) downloadSubtitles(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
-	// 字幕已经由 yt-dlp 下载，这里只是标记状态
-	pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
-		task.Status = StatusSucceeded
-		task.Progress = 100
-	})
-	d.tracker.NotifyProgress(video.ID, page.PID, "subtitle", pageProgress.GetSubTask("subtitle"))
-	return nil
-}
-
 // downloadDanmaku 下载弹幕
 func (d *Downloader) downloadDanmaku(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
 	pageProgress.UpdateSubTask("danmaku", func(task *SubTaskProgress) {
@@ -582,7 +659,7 @@ func (d *Downloader) downloadDanmaku(ctx context.Context, video *models.Video, p
 	}()
 
 	// 获取弹幕
-	danmakuResp, err := d.biliClient.GetDanmakuXML(page.CID)
+	danmakus, err := d.fetchDanmaku(ctx, page)
 	if err != nil {
 		pageProgress.UpdateSubTask("danmaku", func(task *SubTaskProgress) {
 			task.Status = StatusFailed
@@ -591,7 +668,7 @@ func (d *Downloader) downloadDanmaku(ctx context.Context, video *models.Video, p
 		return fmt.Errorf("获取弹幕失败: %w", err)
 	}
 
-	if len(danmakuResp.Danmakus) == 0 {
+	if len(danmakus) == 0 {
 		pageProgress.UpdateSubTask("danmaku", func(task *SubTaskProgress) {
 			task.Status = StatusSkipped
 		})
@@ -601,20 +678,11 @@ func (d *Downloader) downloadDanmaku(ctx context.Context, video *models.Video, p
 	// 构建输出文件名 (ASS格式)
 	// 单页视频: {video_name}.zh-CN.default.ass
 	// 多页视频: {video_name}-{ptitle}.zh-CN.default.ass
-	var danmakuFile string
-	if video.SinglePage {
-		danmakuFile = fmt.Sprintf("%s.zh-CN.default.ass",
-			utils.Filenamify(video.Name))
-	} else {
-		danmakuFile = fmt.Sprintf("%s-%s.zh-CN.default.ass",
-			utils.Filenamify(video.Name),
-			utils.Filenamify(page.Name))
-	}
-	danmakuPath := filepath.Join(outputDir, danmakuFile)
+	danmakuPath := filepath.Join(outputDir, d.pageFileBaseName(video, page)+".zh-CN.default.ass")
 
 	// 转换为 ASS 格式
 	converter := bilibili.NewASSConverter(&d.config.Danmaku, page.Width, page.Height, page.Duration)
-	assContent := converter.ConvertToASS(danmakuResp.Danmakus)
+	assContent := converter.ConvertToASS(danmakus)
 
 	// 写入ASS文件
 	file, err := os.Create(danmakuPath)
@@ -641,10 +709,31 @@ func (d *Downloader) downloadDanmaku(ctx context.Context, video *models.Video, p
 	})
 	d.tracker.NotifyProgress(video.ID, page.PID, "danmaku", pageProgress.GetSubTask("danmaku"))
 
-	utils.Info("弹幕下载完成: %s (共 %d 条)", danmakuPath, len(danmakuResp.Danmakus))
+	utils.Info("弹幕下载完成: %s (共 %d 条)", danmakuPath, len(danmakus))
 	return nil
 }
 
+// fetchDanmaku 获取分P的弹幕
+// 优先按 6 分钟分段拉取 protobuf 弹幕（覆盖完整弹幕），失败时回退到 XML 接口（仅返回部分弹幕）
+func (d *Downloader) fetchDanmaku(ctx context.Context, page *models.Page) ([]bilibili.DanmakuElem, error) {
+	danmakus, err := d.biliClient.GetDanmakuSegments(ctx, page.CID, 0, page.Duration)
+	if err == nil {
+		return danmakus, nil
+	}
+	utils.Warn("获取分段弹幕失败，回退到 XML 接口: %v", err)
+
+	danmakuResp, xmlErr := d.biliClient.GetDanmakuXML(ctx, page.CID)
+	if xmlErr != nil {
+		return nil, xmlErr
+	}
+	return danmakuResp.Danmakus, nil
+}
+
+// SetDB 设置数据库连接，用于命名模板中的视频源变量
+func (d *Downloader) SetDB(db *gorm.DB) {
+	d.db = db
+}
+
 // GetTracker 获取进度追踪器
 func (d *Downloader) GetTracker() *ProgressTracker {
 	return d.tracker
@@ -658,12 +747,51 @@ func (d *Downloader) SetProgressCallback(callback ProgressCallback) {
 // UpdateConfig 更新配置
 func (d *Downloader) UpdateConfig(cfg *config.Config) {
 	d.config = cfg
+	d.streamClient = newStreamClient(cfg)
+	// 并发数变化时换用新池，正在运行的后处理仍在原池中完成
+	if pool := d.postProcessPool.Load(); pool == nil || pool.size() != max(cfg.Download.PostProcessWorkers, 1) {
+		d.postProcessPool.Store(newFFmpegPool(cfg.Download.PostProcessWorkers))
+	}
 	if d.ytdlp != nil {
 		d.ytdlp.UpdateConfig(cfg)
 	}
 	utils.Info("下载器配置已更新")
 }
 
+// PageStreamDetails 按 ffprobe 探测结果构造 NFO 的流信息，未探测到的流返回 nil
+func PageStreamDetails(page *models.Page) (*nfo.VideoStream, *nfo.AudioStream) {
+	var videoStream *nfo.VideoStream
+	if page.Width > 0 && page.Height > 0 {
+		videoStream = &nfo.VideoStream{
+			Codec:             page.VideoCodec,
+			Width:             page.Width,
+			Height:            page.Height,
+			DurationInSeconds: page.Duration,
+			HDRType:           page.HDRFormat,
+			Bitrate:           page.VideoBitrate,
+			FrameRate:         page.FrameRate,
+		}
+		if page.DolbyVision {
+			videoStream.HDRType = "dolbyvision"
+		}
+	}
+
+	var audioStream *nfo.AudioStream
+	if page.AudioCodec != "" {
+		codec := page.AudioCodec
+		// Kodi 以 eac3_ddp_atmos 标识杜比全景声
+		if page.AudioType == models.AudioTypeDolbyAtmos && codec == "eac3" {
+			codec = "eac3_ddp_atmos"
+		}
+		audioStream = &nfo.AudioStream{
+			Codec:        codec,
+			Channels:     page.AudioChannels,
+			SamplingRate: page.AudioSampleRate,
+		}
+	}
+	return videoStream, audioStream
+}
+
 // generateNFO 生成NFO元数据文件
 func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
 	pageProgress.UpdateSubTask("nfo", func(task *SubTaskProgress) {
@@ -680,14 +808,7 @@ func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page
 	// 构建NFO文件名
 	// 单页视频: {video_name}.nfo
 	// 多页视频: {video_name}-{ptitle}.nfo
-	var nfoFile string
-	if video.SinglePage {
-		nfoFile = fmt.Sprintf("%s.nfo", utils.Filenamify(video.Name))
-	} else {
-		nfoFile = fmt.Sprintf("%s-%s.nfo",
-			utils.Filenamify(video.Name),
-			utils.Filenamify(page.Name))
-	}
+	nfoFile := d.pageFileBaseName(video, page) + ".nfo"
 	nfoPath := filepath.Join(outputDir, nfoFile)
 
 	// 根据NFO时间类型选择日期
@@ -699,7 +820,7 @@ func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page
 	}
 
 	// 根据视频类型选择生成器
-	if video.SinglePage {
+	if video.SinglePage && !video.IsEpisode() {
 		// 单P视频使用Movie NFO
 		generator := nfo.NewMovieGenerator()
 		generator.
@@ -716,13 +837,14 @@ func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page
 			AddUniqueID("bvid", video.BVid, true).
 			AddTags(video.Tags)
 
-		// 添加视频流信息
-		if page.Width > 0 && page.Height > 0 {
-			generator.SetVideoInfo("h264", page.Width, page.Height, page.Duration)
+		// 添加 ffprobe 探测到的音视频流信息
+		videoStream, audioStream := PageStreamDetails(page)
+		if videoStream != nil {
+			generator.AddVideoStream(*videoStream)
+		}
+		if audioStream != nil {
+			generator.AddAudioStream(*audioStream)
 		}
-
-		// 添加音频流信息
-		generator.SetAudioInfo("aac", "zh", 2)
 
 		// 添加封面
 		if video.Cover != "" {
@@ -739,14 +861,20 @@ func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page
 		}
 
 	} else {
-		// 多P视频使用Episode NFO
+		// 多P视频与番剧剧集使用Episode NFO
+		// 多P视频默认第一季，集数为分P号；番剧剧集按季/集序号编号，剧名为视频源名称
+		title, showTitle, season, episode := page.Name, video.Name, 1, page.PID
+		if video.IsEpisode() {
+			title, showTitle, season, episode = video.Name, LookupSourceName(d.db, video), video.SeasonNumber, video.EpisodeNumber
+		}
+
 		generator := nfo.NewEpisodeGenerator()
 		generator.
-			SetTitle(page.Name).
-			SetShowTitle(video.Name).
+			SetTitle(title).
+			SetShowTitle(showTitle).
 			SetPlot(video.Intro).
 			SetRuntime(page.Duration).
-			SetSeasonEpisode(1, page.PID). // 默认第一季，集数为分P号
+			SetSeasonEpisode(season, episode).
 			SetAired(video.PubTime).
 			SetDateAdded(dateAdded).
 			SetStudio("bilibili").
@@ -755,14 +883,18 @@ func (d *Downloader) generateNFO(ctx context.Context, video *models.Video, page
 			AddActor(video.UpperName, "UP主", video.UpperFace).
 			AddUniqueID("bvid", video.BVid, true).
 			AddTags(video.Tags)
-
-		// 添加视频流信息
-		if page.Width > 0 && page.Height > 0 {
-			generator.SetVideoInfo("h264", page.Width, page.Height, page.Duration)
+		if video.EpID != 0 {
+			generator.AddUniqueID("bilibili_ep", strconv.FormatInt(video.EpID, 10), false)
 		}
 
-		// 添加音频流信息
-		generator.SetAudioInfo("aac", "zh", 2)
+		// 添加 ffprobe 探测到的音视频流信息
+		videoStream, audioStream := PageStreamDetails(page)
+		if videoStream != nil {
+			generator.AddVideoStream(*videoStream)
+		}
+		if audioStream != nil {
+			generator.AddAudioStream(*audioStream)
+		}
 
 		// 添加封面
 		if page.Image != "" {
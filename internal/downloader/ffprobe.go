@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os/exec"
 	"strconv"
 	"strings"
 	"time"
+
+	"bili-download/internal/database/models"
 )
 
 // ProbeResult ffprobe 探测结果
@@ -15,26 +18,58 @@ type ProbeResult struct {
 	Width     int
 	Height    int
 	FrameRate float32
+
+	VideoCodec   string // ffprobe 编码名（h264/hevc/av1）
+	VideoBitrate int64  // 视频码率（bps），流信息缺失时按容器码率估算
+	HDRFormat    string // 按传输特性识别：PQ 为 hdr10，HLG 为 hlg，SDR 为空
+	DolbyVision  bool   // 含杜比视界配置记录
+
+	AudioCodec      string // 音频编码（aac/flac/eac3 等），无音频流时为空
+	AudioChannels   int
+	AudioSampleRate int
+	AudioType       string // 音频类型（standard/dolby/dolby_atmos/hires），无音频流时为空
+
+	Duration float64 // 时长（秒）
+}
+
+type ffprobeStream struct {
+	CodecType      string `json:"codec_type"`
+	CodecName      string `json:"codec_name"`
+	CodecTagString string `json:"codec_tag_string"`
+	Profile        string `json:"profile"`
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+	RFrameRate     string `json:"r_frame_rate"`
+	AvgFrameRate   string `json:"avg_frame_rate"`
+	BitRate        string `json:"bit_rate"`
+	ColorTransfer  string `json:"color_transfer"`
+	Channels       int    `json:"channels"`
+	SampleRate     string `json:"sample_rate"`
+	SideDataList   []struct {
+		SideDataType string `json:"side_data_type"`
+	} `json:"side_data_list"`
 }
 
 type ffprobeOutput struct {
-	Streams []struct {
-		Width        int    `json:"width"`
-		Height       int    `json:"height"`
-		RFrameRate   string `json:"r_frame_rate"`
-		AvgFrameRate string `json:"avg_frame_rate"`
-	} `json:"streams"`
+	Streams []ffprobeStream `json:"streams"`
+	Format  struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
 }
 
-// ProbeVideo 使用 ffprobe 探测视频实际分辨率与帧率
+// 杜比视界的编码标签（MP4 中 dvh1/dvhe 为 HEVC，dav1 为 AV1）
+var dolbyVisionTags = map[string]bool{"dvh1": true, "dvhe": true, "dav1": true, "dva1": true}
+
+// ProbeVideo 使用 ffprobe 探测视频的分辨率、帧率、编码、码率、动态范围、音频与时长
 func ProbeVideo(ctx context.Context, filePath string) (*ProbeResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "ffprobe",
 		"-v", "error",
-		"-select_streams", "v:0",
-		"-show_entries", "stream=width,height,r_frame_rate,avg_frame_rate",
+		"-show_streams",
+		"-show_format",
 		"-of", "json",
 		filePath,
 	)
@@ -42,26 +77,154 @@ func ProbeVideo(ctx context.Context, filePath string) (*ProbeResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("ffprobe 执行失败: %w", err)
 	}
+	return parseProbeOutput(out)
+}
 
+// parseProbeOutput 解析 ffprobe 的 JSON 输出，取第一个视频流与第一个音频流
+func parseProbeOutput(out []byte) (*ProbeResult, error) {
 	var parsed ffprobeOutput
 	if err := json.Unmarshal(out, &parsed); err != nil {
 		return nil, fmt.Errorf("解析 ffprobe 输出失败: %w", err)
 	}
-	if len(parsed.Streams) == 0 {
+
+	var video, audio *ffprobeStream
+	for i := range parsed.Streams {
+		s := &parsed.Streams[i]
+		switch s.CodecType {
+		case "video":
+			// 跳过内嵌封面等附加图片流
+			if video == nil && s.CodecName != "mjpeg" && s.CodecName != "png" {
+				video = s
+			}
+		case "audio":
+			if audio == nil {
+				audio = s
+			}
+		}
+	}
+	if video == nil {
 		return nil, fmt.Errorf("未找到视频流")
 	}
 
-	s := parsed.Streams[0]
-	fps := parseFrameRate(s.RFrameRate)
+	fps := parseFrameRate(video.RFrameRate)
 	if fps <= 0 {
-		fps = parseFrameRate(s.AvgFrameRate)
+		fps = parseFrameRate(video.AvgFrameRate)
+	}
+
+	result := &ProbeResult{
+		Width:        video.Width,
+		Height:       video.Height,
+		FrameRate:    fps,
+		VideoCodec:   models.NormalizeVideoCodec(video.CodecName),
+		VideoBitrate: parseInt64(video.BitRate),
+		HDRFormat:    hdrFormatByTransfer(video.ColorTransfer),
+		DolbyVision:  dolbyVisionTags[strings.ToLower(video.CodecTagString)],
+	}
+	for _, side := range video.SideDataList {
+		if strings.Contains(side.SideDataType, "DOVI") {
+			result.DolbyVision = true
+		}
+	}
+
+	var audioBitrate int64
+	if audio != nil {
+		result.AudioCodec = strings.ToLower(audio.CodecName)
+		result.AudioChannels = audio.Channels
+		result.AudioSampleRate = int(parseInt64(audio.SampleRate))
+		result.AudioType = models.AudioTypeFromCodec(audio.CodecName, audio.Profile)
+		audioBitrate = parseInt64(audio.BitRate)
+	}
+
+	// MKV 等容器的流不带码率，用容器总码率减去音频码率估算
+	if result.VideoBitrate == 0 {
+		if total := parseInt64(parsed.Format.BitRate); total > audioBitrate {
+			result.VideoBitrate = total - audioBitrate
+		}
+	}
+	if d, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil && d > 0 {
+		result.Duration = d
+	}
+
+	return result, nil
+}
+
+// ApplyToPage 将探测结果写入分P
+func (p *ProbeResult) ApplyToPage(page *models.Page) {
+	page.Width = p.Width
+	page.Height = p.Height
+	page.FrameRate = p.FrameRate
+	page.Quality = models.CalcQuality(p.Width, p.Height, p.FrameRate)
+	page.Orientation = models.CalcOrientation(p.Width, p.Height)
+	page.VideoCodec = p.VideoCodec
+	page.VideoBitrate = p.VideoBitrate
+	page.HDRFormat = p.HDRFormat
+	page.DolbyVision = p.DolbyVision
+	page.AudioCodec = p.AudioCodec
+	page.AudioChannels = p.AudioChannels
+	page.AudioSampleRate = p.AudioSampleRate
+	page.AudioType = p.AudioType
+	if p.Duration > 0 {
+		page.Duration = int(math.Round(p.Duration))
+	}
+}
+
+// PageUpdates 探测结果对应的分P字段更新（供回填等直接更新数据库的场景使用）
+func (p *ProbeResult) PageUpdates() map[string]interface{} {
+	var page models.Page
+	p.ApplyToPage(&page)
+	updates := map[string]interface{}{
+		"width":       page.Width,
+		"height":      page.Height,
+		"frame_rate":  page.FrameRate,
+		"quality":     page.Quality,
+		"orientation": page.Orientation,
+	}
+	for k, v := range pageStreamUpdates(&page) {
+		updates[k] = v
 	}
+	return updates
+}
 
-	return &ProbeResult{
-		Width:     s.Width,
-		Height:    s.Height,
-		FrameRate: fps,
-	}, nil
+// pageStreamUpdates 分P的编码、码率、动态范围、音频与时长字段，未探测到编码时返回 nil
+func pageStreamUpdates(page *models.Page) map[string]interface{} {
+	if page.VideoCodec == "" {
+		return nil
+	}
+	updates := map[string]interface{}{
+		"video_codec":       page.VideoCodec,
+		"video_bitrate":     page.VideoBitrate,
+		"hdr_format":        page.HDRFormat,
+		"dolby_vision":      page.DolbyVision,
+		"audio_codec":       page.AudioCodec,
+		"audio_channels":    page.AudioChannels,
+		"audio_sample_rate": page.AudioSampleRate,
+		"audio_type":        page.AudioType,
+	}
+	if page.Duration > 0 {
+		updates["duration"] = page.Duration
+	}
+	return updates
+}
+
+// hdrFormatByTransfer 按 ffprobe 的 color_transfer 识别 HDR 格式
+func hdrFormatByTransfer(transfer string) string {
+	switch transfer {
+	case "smpte2084":
+		return models.HDRFormatHDR10
+	case "arib-std-b67":
+		return models.HDRFormatHLG
+	default:
+		return ""
+	}
+}
+
+// parseInt64 解析 ffprobe 输出中的数字字符串，无法解析时返回 0
+func parseInt64(s string) int64 {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 // parseFrameRate 解析形如 "60000/1001" 的帧率字符串
@@ -0,0 +1,120 @@
+package downloader
+
+import (
+	"testing"
+
+	"bili-download/internal/database/models"
+)
+
+const ffprobeDolbyVisionMKV = `{
+  "streams": [
+    {"codec_type": "video", "codec_name": "hevc", "codec_tag_string": "[0][0][0][0]", "width": 3840, "height": 2160,
+     "r_frame_rate": "60000/1001", "avg_frame_rate": "60000/1001", "color_transfer": "smpte2084",
+     "side_data_list": [{"side_data_type": "DOVI configuration record"}]},
+    {"codec_type": "audio", "codec_name": "eac3", "channels": 6, "sample_rate": "48000", "bit_rate": "640000"},
+    {"codec_type": "video", "codec_name": "mjpeg", "width": 640, "height": 360}
+  ],
+  "format": {"duration": "1234.567", "bit_rate": "20640000"}
+}`
+
+func TestParseProbeOutputDolbyVision(t *testing.T) {
+	probe, err := parseProbeOutput([]byte(ffprobeDolbyVisionMKV))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if probe.Width != 3840 || probe.Height != 2160 || probe.VideoCodec != models.VideoCodecHEVC {
+		t.Fatalf("unexpected video stream: %+v", probe)
+	}
+	if probe.HDRFormat != models.HDRFormatHDR10 || !probe.DolbyVision {
+		t.Fatalf("expected HDR10 + Dolby Vision, got %q / %v", probe.HDRFormat, probe.DolbyVision)
+	}
+	// MKV 视频流不带码率时按容器码率减去音频码率估算
+	if probe.VideoBitrate != 20000000 {
+		t.Fatalf("expected estimated video bitrate 20000000, got %d", probe.VideoBitrate)
+	}
+	if probe.AudioCodec != "eac3" || probe.AudioChannels != 6 || probe.AudioSampleRate != 48000 {
+		t.Fatalf("unexpected audio stream: %+v", probe)
+	}
+
+	page := &models.Page{Duration: 1200}
+	probe.ApplyToPage(page)
+	if page.Duration != 1235 || page.Quality != models.Quality4K {
+		t.Fatalf("expected duration 1235 and 4K, got %d / %d", page.Duration, page.Quality)
+	}
+	updates := buildDownloadedPageUpdates(page)
+	if updates["video_codec"] != "hevc" || updates["dolby_vision"] != true || updates["audio_channels"] != 6 {
+		t.Fatalf("expected stream fields in page updates, got %#v", updates)
+	}
+
+	videoStream, audioStream := PageStreamDetails(page)
+	if videoStream == nil || videoStream.HDRType != "dolbyvision" || videoStream.Codec != "hevc" {
+		t.Fatalf("unexpected NFO video stream: %+v", videoStream)
+	}
+	if audioStream == nil || audioStream.Codec != "eac3" || audioStream.SamplingRate != 48000 {
+		t.Fatalf("unexpected NFO audio stream: %+v", audioStream)
+	}
+}
+
+func TestParseProbeOutputAVCAndMissingVideo(t *testing.T) {
+	probe, err := parseProbeOutput([]byte(`{"streams": [
+		{"codec_type": "video", "codec_name": "h264", "codec_tag_string": "avc1", "width": 1920, "height": 1080,
+		 "r_frame_rate": "0/0", "avg_frame_rate": "30/1", "bit_rate": "2500000", "color_transfer": "bt709"},
+		{"codec_type": "audio", "codec_name": "aac", "channels": 2, "sample_rate": "44100"}
+	], "format": {"duration": "60.0"}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if probe.VideoCodec != models.VideoCodecAVC || probe.HDRFormat != "" || probe.DolbyVision {
+		t.Fatalf("expected SDR AVC, got %+v", probe)
+	}
+	if probe.FrameRate != 30 || probe.VideoBitrate != 2500000 || probe.Duration != 60 {
+		t.Fatalf("unexpected probe values: %+v", probe)
+	}
+
+	if _, err := parseProbeOutput([]byte(`{"streams": [{"codec_type": "audio", "codec_name": "aac"}]}`)); err == nil {
+		t.Fatal("expected error without video stream")
+	}
+}
+
+func TestPageStreamDetailsWithoutProbeData(t *testing.T) {
+	videoStream, audioStream := PageStreamDetails(&models.Page{Width: 1280, Height: 720, Duration: 30})
+	if videoStream == nil || videoStream.Codec != "" {
+		t.Fatalf("expected video stream without guessed codec, got %+v", videoStream)
+	}
+	if audioStream != nil {
+		t.Fatalf("expected no audio stream without probe data, got %+v", audioStream)
+	}
+}
+
+func TestParseProbeOutputAudioType(t *testing.T) {
+	tests := []struct {
+		audio    string
+		want     string
+		nfoCodec string
+	}{
+		{`{"codec_type": "audio", "codec_name": "eac3", "profile": "Dolby Digital Plus + Dolby Atmos", "channels": 6}`, models.AudioTypeDolbyAtmos, "eac3_ddp_atmos"},
+		{`{"codec_type": "audio", "codec_name": "eac3", "channels": 6}`, models.AudioTypeDolby, "eac3"},
+		{`{"codec_type": "audio", "codec_name": "flac", "channels": 2, "sample_rate": "96000"}`, models.AudioTypeHiRes, "flac"},
+		{`{"codec_type": "audio", "codec_name": "aac", "profile": "LC", "channels": 2}`, models.AudioTypeStandard, "aac"},
+	}
+	for _, tt := range tests {
+		probe, err := parseProbeOutput([]byte(`{"streams": [
+			{"codec_type": "video", "codec_name": "hevc", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+			` + tt.audio + `]}`))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if probe.AudioType != tt.want {
+			t.Errorf("%s: AudioType = %q, want %q", tt.audio, probe.AudioType, tt.want)
+		}
+
+		page := &models.Page{}
+		probe.ApplyToPage(page)
+		if updates := buildDownloadedPageUpdates(page); updates["audio_type"] != tt.want {
+			t.Errorf("%s: expected audio_type in page updates, got %#v", tt.audio, updates["audio_type"])
+		}
+		if _, audioStream := PageStreamDetails(page); audioStream == nil || audioStream.Codec != tt.nfoCodec {
+			t.Errorf("%s: NFO audio codec = %+v, want %s", tt.audio, audioStream, tt.nfoCodec)
+		}
+	}
+}
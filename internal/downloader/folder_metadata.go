@@ -0,0 +1,375 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bili-download/internal/bandwidth"
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+	"bili-download/internal/nfo"
+	"bili-download/internal/utils"
+)
+
+// 目录级元数据文件名（Emby/Jellyfin/Kodi 约定）
+const (
+	tvshowNFOFile  = "tvshow.nfo"
+	seasonNFOFile  = "season.nfo"
+	personNFOFile  = "person.nfo"
+	folderPoster   = "poster"
+	personPortrait = "folder"
+)
+
+// imageExtensions 判断目录图片是否已存在时检查的扩展名
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".gif"}
+
+// showMetadata 剧集目录（tvshow.nfo）的元数据
+type showMetadata struct {
+	Title        string
+	Plot         string
+	Cover        string
+	Premiered    time.Time
+	Status       string // Continuing/Ended，未知时为空
+	Tags         []string
+	UniqueIDType string
+	UniqueID     string
+	EpisodeCount int
+}
+
+// UpperMetadata UP主人物信息
+type UpperMetadata struct {
+	Mid  int64
+	Name string
+	Face string
+	Sign string
+}
+
+// WriteFolderMetadata 视频下载完成后生成目录级元数据
+// 多P视频目录作为剧集写入 tvshow.nfo、season.nfo 与海报；合集、UP主投稿、番剧的视频源目录作为剧集写入 tvshow.nfo 与海报，
+// 番剧的季目录与合集、UP主投稿的视频源目录（第一季）写入 season.nfo；并在 upper_path 下写入UP主的人物信息
+func (d *Downloader) WriteFolderMetadata(ctx context.Context, video *models.Video, outputDir string) {
+	writeNFO := !d.config.Download.SkipVideoNFO
+	writePoster := !d.config.Download.SkipPoster
+
+	if writeNFO || writePoster {
+		if !video.SinglePage && !video.IsEpisode() {
+			d.writeShowFolder(ctx, outputDir, d.videoShowMetadata(video))
+			if writeNFO {
+				d.writeSeasonNFO(outputDir, video.Name, "", 1)
+			}
+		}
+
+		// 视频源目录的元数据需要请求接口，已生成过的目录不再重复生成
+		if sourceDir := d.sourceShowDir(video); sourceDir != "" {
+			seasonDir, season := sourceSeasonDir(video, outputDir, sourceDir)
+			showDone := d.folderMetadataComplete(sourceDir, tvshowNFOFile)
+			seasonDone := seasonDir == "" || d.folderMetadataComplete(seasonDir, seasonNFOFile)
+			if !showDone || !seasonDone {
+				if meta := d.sourceShowMetadata(ctx, video); meta != nil {
+					d.writeShowFolder(ctx, sourceDir, meta)
+					if seasonDir != "" {
+						if writeNFO {
+							d.writeSeasonNFO(seasonDir, LookupSourceName(d.db, video), meta.Plot, season)
+						}
+						if writePoster {
+							d.writeFolderImage(ctx, meta.Cover, seasonDir, folderPoster, false)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if !d.config.Download.SkipUpper && video.UpperID > 0 && video.UpperName != "" {
+		upper := UpperMetadata{Mid: video.UpperID, Name: video.UpperName, Face: video.UpperFace}
+		if err := WriteUpperMetadata(ctx, d.biliClient, d.config.Paths.UpperPath, upper, false); err != nil {
+			utils.Warn("写入UP主 %s 人物信息失败: %v", video.UpperName, err)
+		}
+	}
+}
+
+// folderMetadataComplete 目录的 NFO 与海报是否均已生成（按配置跳过的部分视为已生成）
+func (d *Downloader) folderMetadataComplete(dir, nfoFile string) bool {
+	if !d.config.Download.SkipVideoNFO && !fileExists(filepath.Join(dir, nfoFile)) {
+		return false
+	}
+	return d.config.Download.SkipPoster || findFolderImage(dir, folderPoster) != ""
+}
+
+// writeShowFolder 写入剧集目录的 tvshow.nfo 与海报（海报已存在时不重复下载）
+func (d *Downloader) writeShowFolder(ctx context.Context, dir string, meta *showMetadata) {
+	if !d.config.Download.SkipVideoNFO {
+		generator := nfo.NewTVShowGenerator()
+		generator.
+			SetTitle(meta.Title).
+			SetOriginalTitle(meta.Title).
+			SetPlot(meta.Plot).
+			SetStudio("bilibili").
+			SetStatus(meta.Status).
+			SetEpisodeCount(meta.EpisodeCount).
+			AddTags(meta.Tags)
+		if !meta.Premiered.IsZero() {
+			generator.SetPremiered(meta.Premiered)
+		}
+		if meta.UniqueID != "" {
+			generator.AddUniqueID(meta.UniqueIDType, meta.UniqueID, true)
+		}
+		if meta.Cover != "" {
+			generator.AddThumb(normalizeImageURL(meta.Cover), "poster")
+		}
+		if err := generator.WriteToFile(filepath.Join(dir, tvshowNFOFile)); err != nil {
+			utils.Warn("写入 tvshow.nfo 失败: %v", err)
+		}
+	}
+
+	if !d.config.Download.SkipPoster {
+		d.writeFolderImage(ctx, meta.Cover, dir, folderPoster, false)
+	}
+}
+
+// writeSeasonNFO 写入季目录的 season.nfo
+func (d *Downloader) writeSeasonNFO(dir, title, plot string, season int) {
+	if season <= 0 {
+		season = 1
+	}
+	generator := nfo.NewSeasonGenerator()
+	generator.
+		SetTitle(title).
+		SetPlot(plot).
+		SetSeasonNumber(season)
+	if err := generator.WriteToFile(filepath.Join(dir, seasonNFOFile)); err != nil {
+		utils.Warn("写入 season.nfo 失败: %v", err)
+	}
+}
+
+// writeFolderImage 下载目录图片（如 poster.jpg），overwrite 为 false 时已存在同名图片则跳过
+func (d *Downloader) writeFolderImage(ctx context.Context, imageURL, dir, baseName string, overwrite bool) {
+	if err := downloadFolderImage(ctx, d.biliClient, imageURL, dir, baseName, overwrite); err != nil {
+		utils.Warn("下载目录图片 %s 失败: %v", filepath.Join(dir, baseName), err)
+	}
+}
+
+// videoShowMetadata 多P视频作为剧集的元数据
+func (d *Downloader) videoShowMetadata(video *models.Video) *showMetadata {
+	return &showMetadata{
+		Title:        video.Name,
+		Plot:         video.Intro,
+		Cover:        video.Cover,
+		Premiered:    video.PubTime,
+		Tags:         video.Tags,
+		UniqueIDType: "bvid",
+		UniqueID:     video.BVid,
+		EpisodeCount: len(video.Pages),
+	}
+}
+
+// sourceShowDir 视频源作为剧集时的目录；仅合集、UP主投稿、番剧，且视频源设置了独立保存路径时返回
+func (d *Downloader) sourceShowDir(video *models.Video) string {
+	switch videoSourceType(video) {
+	case "collection", "submission", "bangumi":
+	default:
+		return ""
+	}
+	_, sourcePath := lookupVideoSource(d.db, video)
+	if strings.TrimSpace(sourcePath) == "" {
+		// 未设置保存路径的视频源共用下载根目录，不能作为剧集目录
+		return ""
+	}
+	return filepath.Join(d.config.Paths.DownloadBase, sourcePath)
+}
+
+// sourceSeasonDir 视频源剧集中视频所属季的目录与季序号，无季目录时返回空字符串
+// 番剧剧集位于视频源目录下的季目录中；合集与UP主投稿没有季目录，整个视频源目录作为第一季
+func sourceSeasonDir(video *models.Video, outputDir, sourceDir string) (string, int) {
+	if !video.IsEpisode() {
+		return sourceDir, 1
+	}
+	if filepath.Clean(filepath.Dir(outputDir)) == filepath.Clean(sourceDir) {
+		return outputDir, video.SeasonNumber
+	}
+	return "", 0
+}
+
+// sourceShowMetadata 视频源作为剧集的元数据，番剧与合集的简介、封面从接口获取，获取失败时使用视频信息
+func (d *Downloader) sourceShowMetadata(ctx context.Context, video *models.Video) *showMetadata {
+	if d.db == nil {
+		return nil
+	}
+	sourceName := LookupSourceName(d.db, video)
+	meta := &showMetadata{Title: sourceName, Cover: video.Cover}
+
+	switch {
+	case video.SubmissionID != nil:
+		var sub models.Submission
+		if err := d.db.First(&sub, *video.SubmissionID).Error; err != nil {
+			return nil
+		}
+		meta.Cover = sub.UpperFace
+		meta.Status = "Continuing"
+		meta.UniqueIDType = "bilibili_mid"
+		meta.UniqueID = strconv.FormatInt(sub.UpperID, 10)
+
+	case video.CollectionID != nil:
+		var col models.Collection
+		if err := d.db.First(&col, *video.CollectionID).Error; err != nil {
+			return nil
+		}
+		meta.UniqueIDType = "bilibili_collection"
+		meta.UniqueID = strconv.FormatInt(col.CID, 10)
+		params := bilibili.CollectionListParams{SeasonID: meta.UniqueID, CollectionType: bilibili.CollectionTypeSeason}
+		if col.CType == "series" {
+			params = bilibili.CollectionListParams{SeriesID: meta.UniqueID, CollectionType: bilibili.CollectionTypeSeries}
+		}
+		if info, err := d.biliClient.GetCollectionInfo(ctx, params); err == nil {
+			meta.Plot = info.Description
+			if info.Cover != "" {
+				meta.Cover = info.Cover
+			}
+			meta.EpisodeCount = info.Total
+		} else {
+			utils.Debug("获取合集 %s 信息失败，使用视频信息生成 tvshow.nfo: %v", sourceName, err)
+		}
+
+	case video.BangumiID != nil:
+		var bgm models.Bangumi
+		if err := d.db.First(&bgm, *video.BangumiID).Error; err != nil {
+			return nil
+		}
+		meta.UniqueIDType = "bilibili_season"
+		meta.UniqueID = strconv.FormatInt(bgm.SeasonID, 10)
+		if season, err := d.biliClient.GetBangumiSeason(ctx, bgm.SeasonID, 0); err == nil {
+			meta.Title = season.ShowName()
+			meta.Plot = season.Evaluate
+			meta.Tags = season.Styles
+			if season.Cover != "" {
+				meta.Cover = season.Cover
+			}
+			if season.IsFinished() {
+				meta.Status = "Ended"
+			} else {
+				meta.Status = "Continuing"
+			}
+		} else {
+			utils.Debug("获取番剧 %s 信息失败，使用视频信息生成 tvshow.nfo: %v", sourceName, err)
+		}
+
+	default:
+		return nil
+	}
+
+	if meta.Title == "" {
+		return nil
+	}
+	return meta
+}
+
+// UpperMetadataDir UP主人物信息目录，采用 Emby/Jellyfin 的人物目录结构：{upper_path}/{名称首字}/{名称}
+func UpperMetadataDir(upperPath, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return upperPath
+	}
+	name = utils.Filenamify(name)
+	first := strings.ToUpper(string([]rune(name)[:1]))
+	return filepath.Join(upperPath, first, name)
+}
+
+// WriteUpperMetadata 写入UP主的 person.nfo 与头像 folder.jpg
+// overwrite 为 false 时 person.nfo 与头像均已存在则跳过（下载视频时调用）；刷新UP主头像时传 true 覆盖
+func WriteUpperMetadata(ctx context.Context, client *bilibili.Client, upperPath string, upper UpperMetadata, overwrite bool) error {
+	if upperPath == "" || strings.TrimSpace(upper.Name) == "" {
+		return nil
+	}
+
+	dir := UpperMetadataDir(upperPath, upper.Name)
+	nfoPath := filepath.Join(dir, personNFOFile)
+	if !overwrite && fileExists(nfoPath) && findFolderImage(dir, personPortrait) != "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建UP主目录失败: %w", err)
+	}
+
+	generator := nfo.NewPersonGenerator()
+	generator.
+		SetName(upper.Name).
+		SetRole("UP主").
+		SetBiography(upper.Sign).
+		SetDateAdded(time.Now()).
+		AddUniqueID("bilibili_mid", strconv.FormatInt(upper.Mid, 10), true)
+	if upper.Face != "" {
+		generator.AddThumb(normalizeImageURL(upper.Face), "")
+	}
+	if err := generator.WriteToFile(nfoPath); err != nil {
+		return fmt.Errorf("写入 person.nfo 失败: %w", err)
+	}
+
+	return downloadFolderImage(ctx, client, upper.Face, dir, personPortrait, overwrite)
+}
+
+// downloadFolderImage 下载图片到 {dir}/{baseName}{ext}，overwrite 为 false 时已存在同名图片则跳过
+func downloadFolderImage(ctx context.Context, client *bilibili.Client, imageURL, dir, baseName string, overwrite bool) error {
+	if imageURL == "" || client == nil {
+		return nil
+	}
+	if !overwrite && findFolderImage(dir, baseName) != "" {
+		return nil
+	}
+
+	imageURL = normalizeImageURL(imageURL)
+	resp, err := client.Get(ctx, imageURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	// 先写临时文件再重命名，避免中断后留下不完整的图片
+	target := filepath.Join(dir, baseName+getImageExtension(imageURL))
+	tmp := target + ".part"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, bandwidth.Shared().Reader(ctx, resp.Body)); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// 覆盖时清理其他扩展名的旧图片，避免媒体服务器读到过期头像
+	if existing := findFolderImage(dir, baseName); existing != "" && existing != target {
+		os.Remove(existing)
+	}
+	return os.Rename(tmp, target)
+}
+
+// findFolderImage 查找目录中指定名称的图片（任意常见扩展名），不存在时返回空字符串
+func findFolderImage(dir, baseName string) string {
+	for _, ext := range imageExtensions {
+		path := filepath.Join(dir, baseName+ext)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,137 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func TestUpperMetadataDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"老番茄", filepath.Join("people", "老", "老番茄")},
+		{"bilibili", filepath.Join("people", "B", "bilibili")},
+		{"a/b", filepath.Join("people", "A", "a_b")},
+		{"  ", "people"},
+	}
+	for _, tt := range tests {
+		got := UpperMetadataDir("people", tt.name)
+		if got != tt.expected {
+			t.Errorf("UpperMetadataDir(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteUpperMetadataSkipsExisting(t *testing.T) {
+	base := t.TempDir()
+	upper := UpperMetadata{Mid: 546195, Name: "老番茄", Sign: "第一版签名"}
+
+	if err := WriteUpperMetadata(context.Background(), nil, base, upper, false); err != nil {
+		t.Fatalf("write person metadata: %v", err)
+	}
+	nfoPath := filepath.Join(UpperMetadataDir(base, upper.Name), personNFOFile)
+	data, err := os.ReadFile(nfoPath)
+	if err != nil {
+		t.Fatalf("read person.nfo: %v", err)
+	}
+	for _, want := range []string{"<name>老番茄</name>", "第一版签名", "546195"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("person.nfo missing %q:\n%s", want, data)
+		}
+	}
+
+	// 没有头像时 person.nfo 仍会在下次下载时补写；overwrite 时使用新签名
+	upper.Sign = "第二版签名"
+	if err := WriteUpperMetadata(context.Background(), nil, base, upper, true); err != nil {
+		t.Fatalf("overwrite person metadata: %v", err)
+	}
+	data, _ = os.ReadFile(nfoPath)
+	if !strings.Contains(string(data), "第二版签名") {
+		t.Errorf("expected refreshed biography, got:\n%s", data)
+	}
+
+	// person.nfo 与头像均已存在时不覆盖
+	portrait := filepath.Join(UpperMetadataDir(base, upper.Name), personPortrait+".jpg")
+	if err := os.WriteFile(portrait, []byte("jpg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	upper.Sign = "第三版签名"
+	if err := WriteUpperMetadata(context.Background(), nil, base, upper, false); err != nil {
+		t.Fatalf("write person metadata: %v", err)
+	}
+	data, _ = os.ReadFile(nfoPath)
+	if strings.Contains(string(data), "第三版签名") {
+		t.Errorf("expected existing person metadata to be kept, got:\n%s", data)
+	}
+}
+
+func TestWriteFolderMetadataMultiPageVideo(t *testing.T) {
+	dir := t.TempDir()
+	d := &Downloader{config: &config.Config{
+		Paths:    config.PathsConfig{UpperPath: filepath.Join(dir, "people")},
+		Download: config.DownloadConfig{SkipPoster: true},
+	}}
+	video := &models.Video{
+		BVid:      "BV1xx411c7mD",
+		Name:      "多P教程",
+		Intro:     "教程简介",
+		UpperID:   1,
+		UpperName: "UP",
+		Pages:     []models.Page{{PID: 1}, {PID: 2}},
+	}
+
+	d.WriteFolderMetadata(context.Background(), video, dir)
+
+	tvshow, err := os.ReadFile(filepath.Join(dir, tvshowNFOFile))
+	if err != nil {
+		t.Fatalf("read tvshow.nfo: %v", err)
+	}
+	for _, want := range []string{"<tvshow>", "<title>多P教程</title>", "教程简介", "BV1xx411c7mD"} {
+		if !strings.Contains(string(tvshow), want) {
+			t.Errorf("tvshow.nfo missing %q:\n%s", want, tvshow)
+		}
+	}
+	season, err := os.ReadFile(filepath.Join(dir, seasonNFOFile))
+	if err != nil {
+		t.Fatalf("read season.nfo: %v", err)
+	}
+	if !strings.Contains(string(season), "<seasonnumber>1</seasonnumber>") {
+		t.Errorf("season.nfo missing season number:\n%s", season)
+	}
+	if !fileExists(filepath.Join(UpperMetadataDir(filepath.Join(dir, "people"), "UP"), personNFOFile)) {
+		t.Error("expected person.nfo for the uploader")
+	}
+
+	// 单P视频不生成剧集元数据
+	single := t.TempDir()
+	video.SinglePage = true
+	d.WriteFolderMetadata(context.Background(), video, single)
+	if fileExists(filepath.Join(single, tvshowNFOFile)) {
+		t.Error("single page video should not get tvshow.nfo")
+	}
+}
+
+func TestSourceSeasonDir(t *testing.T) {
+	sourceDir := filepath.Join("downloads", "合集")
+	video := &models.Video{Name: "视频"}
+	if dir, season := sourceSeasonDir(video, filepath.Join(sourceDir, "视频"), sourceDir); dir != sourceDir || season != 1 {
+		t.Errorf("collection season dir = %q, %d, want source dir as season 1", dir, season)
+	}
+
+	bangumiID := uint(1)
+	episode := &models.Video{BangumiID: &bangumiID, EpID: 100, SeasonNumber: 2}
+	seasonPath := filepath.Join(sourceDir, "Season 02")
+	if dir, season := sourceSeasonDir(episode, seasonPath, sourceDir); dir != seasonPath || season != 2 {
+		t.Errorf("bangumi season dir = %q, %d, want %q, 2", dir, season, seasonPath)
+	}
+	if dir, _ := sourceSeasonDir(episode, filepath.Join("elsewhere", "Season 02"), sourceDir); dir != "" {
+		t.Errorf("expected no season dir outside the source dir, got %q", dir)
+	}
+}
@@ -30,7 +30,12 @@ const (
 	EventTaskCancelled ManagerEventType = "task_cancelled"
 	EventTaskRetrying  ManagerEventType = "task_retrying"
 	EventTaskProgress  ManagerEventType = "task_progress"
+	EventTaskPaused    ManagerEventType = "task_paused"
+	EventTaskResumed   ManagerEventType = "task_resumed"
 	EventRecordCreated ManagerEventType = "download_record_created"
+
+	EventDownloadsPaused  ManagerEventType = "downloads_paused"  // 全局暂停开关打开
+	EventDownloadsResumed ManagerEventType = "downloads_resumed" // 全局暂停开关关闭
 )
 
 // ManagerEvent 管理器事件
@@ -45,6 +50,8 @@ type ManagerEvent struct {
 
 type pageDownloadExecutor interface {
 	DownloadPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error
+	RepairPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error
+	WriteFolderMetadata(ctx context.Context, video *models.Video, outputDir string)
 	GetTracker() *ProgressTracker
 	SetProgressCallback(callback ProgressCallback)
 	Cleanup()
@@ -61,9 +68,11 @@ type DownloadManager struct {
 	biliClient         *bilibili.Client
 	downloader         pageDownloadExecutor
 	queue              *TaskQueue
+	store              taskStore // 队列持久化存储（db 为空时为 nil）
 	concurrency        *ConcurrencyController
 	tracker            *ProgressTracker
 	runningTasks       sync.Map // taskID -> *DownloadTask
+	pausedTasks        sync.Map // taskID -> *DownloadTask
 	completedTasks     sync.Map // taskID -> *DownloadTask
 	eventHandlers      []EventHandler
 	ctx                context.Context
@@ -71,8 +80,11 @@ type DownloadManager struct {
 	wg                 sync.WaitGroup
 	mu                 sync.RWMutex
 	running            bool
+	stopping           bool // 正在停止：被中断的任务保留在持久化队列中
+	pausedAll          bool // 全局暂停：不再调度新任务
 	persistPageFn      func(page *models.Page) error
-	lastProgressUpdate sync.Map // videoID -> time.Time (进度更新节流)
+	pageRetryBackoff   time.Duration // 分P下载失败后首次重试的间隔
+	lastProgressUpdate sync.Map      // videoID -> time.Time (进度更新节流)
 }
 
 // NewDownloadManager 创建新的下载管理器
@@ -83,6 +95,8 @@ func NewDownloadManager(cfg *config.Config, db *gorm.DB, biliClient *bilibili.Cl
 		return nil, fmt.Errorf("创建下载器失败: %w", err)
 	}
 
+	downloader.SetDB(db)
+
 	// 创建上下文
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -97,21 +111,32 @@ func NewDownloadManager(cfg *config.Config, db *gorm.DB, biliClient *bilibili.Cl
 	}
 
 	manager := &DownloadManager{
-		config:        cfg,
-		db:            db,
-		biliClient:    biliClient,
-		downloader:    downloader,
-		queue:         NewTaskQueue(),
-		concurrency:   NewConcurrencyController(maxVideos, maxPages),
-		tracker:       downloader.GetTracker(),
-		eventHandlers: make([]EventHandler, 0),
-		ctx:           ctx,
-		cancel:        cancel,
-		running:       false,
-	}
+		config:           cfg,
+		db:               db,
+		biliClient:       biliClient,
+		downloader:       downloader,
+		queue:            NewTaskQueue(),
+		store:            newTaskStore(db),
+		concurrency:      NewConcurrencyController(maxVideos, maxPages),
+		tracker:          downloader.GetTracker(),
+		eventHandlers:    make([]EventHandler, 0),
+		ctx:              ctx,
+		cancel:           cancel,
+		running:          false,
+		pageRetryBackoff: 5 * time.Second,
+	}
+
+	applyBandwidthLimit(cfg, time.Now())
 
 	// 设置进度回调
 	downloader.SetProgressCallback(func(videoID uint, pid int, taskName string, progress *SubTaskProgress) {
+		// 多P并行下载时按视频汇总各分P的同名子任务
+		if videoProgress := manager.tracker.GetVideo(videoID); videoProgress != nil {
+			if aggregated := videoProgress.AggregateSubTask(taskName); aggregated != nil {
+				progress = aggregated
+			}
+		}
+
 		// 查找对应的任务
 		var task *DownloadTask
 		manager.runningTasks.Range(func(key, value interface{}) bool {
@@ -142,8 +167,15 @@ func NewDownloadManager(cfg *config.Config, db *gorm.DB, biliClient *bilibili.Cl
 }
 
 func buildDownloadedPageUpdates(page *models.Page) map[string]interface{} {
+	status := models.PageStatusVideo
+	if page != nil {
+		status |= page.DownloadStatus
+	}
 	updates := map[string]interface{}{
-		"download_status": 1,
+		"download_status": status,
+	}
+	if page != nil && page.Path != "" {
+		updates["path"] = page.Path
 	}
 	if page != nil && page.Width > 0 && page.Height > 0 {
 		updates["width"] = page.Width
@@ -151,6 +183,9 @@ func buildDownloadedPageUpdates(page *models.Page) map[string]interface{} {
 		updates["frame_rate"] = page.FrameRate
 		updates["quality"] = page.Quality
 		updates["orientation"] = page.Orientation
+		for k, v := range pageStreamUpdates(page) {
+			updates[k] = v
+		}
 	}
 	return updates
 }
@@ -168,6 +203,16 @@ func (dm *DownloadManager) persistDownloadedPage(page *models.Page) error {
 	return dm.db.Model(&models.Page{}).Where("id = ?", page.ID).Updates(buildDownloadedPageUpdates(page)).Error
 }
 
+// persistPageStatus 记录下载失败的分P中已写入磁盘的文件
+func (dm *DownloadManager) persistPageStatus(page *models.Page) {
+	if dm.db == nil || page == nil || page.ID == 0 {
+		return
+	}
+	if err := dm.db.Model(&models.Page{}).Where("id = ?", page.ID).Update("download_status", page.DownloadStatus).Error; err != nil {
+		utils.Warn("更新分P下载状态失败: %v", err)
+	}
+}
+
 // Start 启动管理器
 func (dm *DownloadManager) Start() error {
 	dm.mu.Lock()
@@ -178,8 +223,12 @@ func (dm *DownloadManager) Start() error {
 	}
 
 	dm.running = true
+	dm.stopping = false
 	utils.Info("下载管理器已启动")
 
+	// 恢复上次未完成的任务
+	dm.restorePersistedTasks()
+
 	// 启动调度器
 	dm.wg.Add(1)
 	go dm.scheduler()
@@ -196,6 +245,7 @@ func (dm *DownloadManager) Stop() error {
 	}
 
 	dm.running = false
+	dm.stopping = true
 	dm.mu.Unlock()
 
 	utils.Info("正在停止下载管理器...")
@@ -220,6 +270,104 @@ func (dm *DownloadManager) Stop() error {
 	return nil
 }
 
+// isStopping 管理器是否正在停止
+func (dm *DownloadManager) isStopping() bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.stopping
+}
+
+// persistTask 将任务写入持久化队列
+func (dm *DownloadManager) persistTask(task *DownloadTask) {
+	if dm.store == nil || task == nil {
+		return
+	}
+	if err := dm.store.Save(task); err != nil {
+		utils.Warn("持久化任务失败: %s - %v", task.ID, err)
+	}
+}
+
+// forgetTask 任务结束后从持久化队列中移除
+func (dm *DownloadManager) forgetTask(task *DownloadTask) {
+	if dm.store == nil || task == nil {
+		return
+	}
+	// 暂停的任务保留，恢复后继续
+	if task.GetStatus() == TaskStatusPaused {
+		return
+	}
+	// 停机过程中被中断的任务保留，下次启动时恢复
+	if task.GetStatus() != TaskStatusCompleted && dm.isStopping() {
+		return
+	}
+	// 失败后已重新入队等待重试的任务仍需保留
+	if dm.queue != nil && dm.queue.Contains(task.ID) {
+		return
+	}
+	if err := dm.store.Delete(task.ID); err != nil {
+		utils.Warn("移除持久化任务失败: %s - %v", task.ID, err)
+	}
+}
+
+// restorePersistedTasks 从持久化队列恢复未完成的任务
+// 恢复的任务保留原有ID、优先级和入队时间，关联的下载记录重置为 pending；
+// 其余停留在 pending/downloading 但已无对应任务的记录标记为失败，便于手动重试
+func (dm *DownloadManager) restorePersistedTasks() {
+	if dm.store == nil {
+		return
+	}
+
+	tasks, err := dm.store.Restore()
+	if err != nil {
+		utils.Warn("恢复下载队列失败: %v", err)
+		return
+	}
+
+	recordIDs := make([]uint, 0, len(tasks))
+	pausedRecordIDs := make([]uint, 0)
+	for _, task := range tasks {
+		if task.GetStatus() == TaskStatusPaused {
+			dm.pausedTasks.Store(task.ID, task)
+			if task.RecordID > 0 {
+				pausedRecordIDs = append(pausedRecordIDs, task.RecordID)
+			}
+			continue
+		}
+		dm.queue.Enqueue(task)
+		if task.RecordID > 0 {
+			recordIDs = append(recordIDs, task.RecordID)
+		}
+	}
+
+	if dm.db != nil {
+		if len(pausedRecordIDs) > 0 {
+			dm.db.Model(&models.DownloadRecord{}).Where("id IN ?", pausedRecordIDs).Update("status", "paused")
+		}
+		if len(recordIDs) > 0 {
+			dm.db.Model(&models.DownloadRecord{}).
+				Where("id IN ? AND status IN ?", recordIDs, []string{"pending", "downloading", "failed"}).
+				Updates(map[string]interface{}{"status": "pending", "error_message": ""})
+		}
+
+		orphaned := dm.db.Model(&models.DownloadRecord{}).Where("status IN ?", []string{"pending", "downloading", "paused"})
+		if knownIDs := append(recordIDs, pausedRecordIDs...); len(knownIDs) > 0 {
+			orphaned = orphaned.Where("id NOT IN ?", knownIDs)
+		}
+		result := orphaned.Updates(map[string]interface{}{
+			"status":        "failed",
+			"error_message": "服务重启导致任务中断，请重试",
+			"completed_at":  time.Now(),
+		})
+		if result.Error == nil && result.RowsAffected > 0 {
+			utils.Warn("已将 %d 条无法恢复的下载记录标记为失败", result.RowsAffected)
+		}
+	}
+
+	if len(tasks) > 0 {
+		utils.Info("已从持久化队列恢复 %d 个下载任务", len(tasks))
+	}
+}
+
 // scheduler 任务调度器
 func (dm *DownloadManager) scheduler() {
 	defer dm.wg.Done()
@@ -232,6 +380,7 @@ func (dm *DownloadManager) scheduler() {
 		case <-dm.ctx.Done():
 			return
 		case <-ticker.C:
+			dm.refreshBandwidthLimit()
 			dm.scheduleNextTask()
 		}
 	}
@@ -239,6 +388,16 @@ func (dm *DownloadManager) scheduler() {
 
 // scheduleNextTask 调度下一个任务
 func (dm *DownloadManager) scheduleNextTask() {
+	// 全局暂停时不调度新任务
+	if dm.IsAllPaused() {
+		return
+	}
+
+	// 静默时段内不开始新任务，排队中的任务等待时段结束
+	if dm.IsQuietHours() {
+		return
+	}
+
 	// 检查是否可以启动新任务
 	if !dm.concurrency.CanStartVideo() && !dm.concurrency.CanStartPage() {
 		return
@@ -253,6 +412,7 @@ func (dm *DownloadManager) scheduleNextTask() {
 	// 检查任务是否已取消
 	if task.IsCancelled() {
 		task.SetStatus(TaskStatusCancelled)
+		dm.forgetTask(task)
 		dm.emitEvent(ManagerEvent{
 			Type:      EventTaskCancelled,
 			Task:      task,
@@ -295,6 +455,7 @@ func (dm *DownloadManager) scheduleNextTask() {
 		}
 	default:
 		utils.Warn("未知任务类型: %s", task.Type)
+		dm.forgetTask(task)
 	}
 }
 
@@ -303,17 +464,19 @@ func (dm *DownloadManager) executeVideoTask(task *DownloadTask) {
 	defer dm.wg.Done()
 
 	if err := dm.concurrency.AcquireVideo(task.Context); err != nil {
+		if task.IsPauseRequested() {
+			dm.finishTask(task)
+			return
+		}
 		task.SetError(err)
 		task.SetStatus(TaskStatusCancelled)
+		dm.forgetTask(task)
 		return
 	}
 	defer dm.concurrency.ReleaseVideo()
 
 	dm.runningTasks.Store(task.ID, task)
-	defer func() {
-		dm.runningTasks.Delete(task.ID)
-		dm.completedTasks.Store(task.ID, task)
-	}()
+	defer dm.finishTask(task)
 
 	task.SetStatus(TaskStatusRunning)
 	dm.emitEvent(ManagerEvent{
@@ -325,54 +488,37 @@ func (dm *DownloadManager) executeVideoTask(task *DownloadTask) {
 	video := task.Video
 	utils.Info("开始下载视频: %s (BV%s), Pages数量: %d", video.Name, video.BVid, len(video.Pages))
 
-	for i := range video.Pages {
-		page := &video.Pages[i]
-		utils.Info("准备下载分P: %s - P%d (%s)", video.Name, page.PID, page.Name)
-		if task.IsCancelled() {
-			task.SetStatus(TaskStatusCancelled)
-			dm.emitEvent(ManagerEvent{
-				Type:      EventTaskCancelled,
-				Task:      task,
-				Timestamp: time.Now(),
-			})
-			return
-		}
-
-		if err := dm.concurrency.AcquirePage(task.Context); err != nil {
-			task.SetError(err)
-			task.SetStatus(TaskStatusFailed)
-			dm.handleTaskFailure(task)
-			if dm.db != nil && task.RecordID > 0 {
-				now := time.Now()
-				dm.db.Model(&models.DownloadRecord{}).Where("id = ?", task.RecordID).
-					Updates(map[string]interface{}{
-						"status":        "failed",
-						"error_message": err.Error(),
-						"completed_at":  now,
-					})
-			}
-			return
-		}
-
-		err := dm.downloader.DownloadPage(task.Context, video, page, task.OutputDir)
-		dm.concurrency.ReleasePage()
-
-		if err != nil {
-			utils.Error("下载分P失败: %v", err)
-			task.SetError(err)
-			task.SetStatus(TaskStatusFailed)
-			dm.handleTaskFailure(task)
-			if dm.db != nil && task.RecordID > 0 {
-				now := time.Now()
-				dm.db.Model(&models.DownloadRecord{}).Where("id = ?", task.RecordID).
-					Updates(map[string]interface{}{
-						"status":        "failed",
-						"error_message": err.Error(),
-						"completed_at":  now,
-					})
-			}
-			return
+	failedPages := dm.downloadVideoPages(task)
+	if task.IsPauseRequested() {
+		utils.Info("视频任务已暂停: %s", video.Name)
+		return
+	}
+	if task.IsCancelled() {
+		task.SetStatus(TaskStatusCancelled)
+		dm.emitEvent(ManagerEvent{
+			Type:      EventTaskCancelled,
+			Task:      task,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if len(failedPages) > 0 {
+		err := fmt.Errorf("%d/%d 个分P下载失败: %s", len(failedPages), len(video.Pages), strings.Join(failedPages, "; "))
+		utils.Error("视频下载失败: %s, %v", video.Name, err)
+		task.SetError(err)
+		task.SetStatus(TaskStatusFailed)
+		// 分P已各自重试，视频任务不再整体重新入队；手动重试时只下载失败的分P
+		dm.reportTaskFailed(task)
+		if dm.db != nil && task.RecordID > 0 {
+			now := time.Now()
+			dm.db.Model(&models.DownloadRecord{}).Where("id = ?", task.RecordID).
+				Updates(map[string]interface{}{
+					"status":        "failed",
+					"error_message": err.Error(),
+					"completed_at":  now,
+				})
 		}
+		return
 	}
 
 	task.SetStatus(TaskStatusCompleted)
@@ -418,11 +564,8 @@ func (dm *DownloadManager) executeVideoTask(task *DownloadTask) {
 			}
 		}
 
-		for i := range video.Pages {
-			if err := dm.persistDownloadedPage(&video.Pages[i]); err != nil {
-				utils.Warn("更新分P下载状态失败: %v", err)
-			}
-		}
+		dm.downloader.WriteFolderMetadata(task.Context, video, task.OutputDir)
+		dm.archiveWatchLater(task.Context, video)
 	}
 
 	dm.emitEvent(ManagerEvent{
@@ -435,22 +578,123 @@ func (dm *DownloadManager) executeVideoTask(task *DownloadTask) {
 	utils.Info("视频任务完成: %s [%s]", video.Name, task.ID)
 }
 
+// downloadVideoPages 在分P并发限制内并行下载视频的各个分P，每个分P失败后单独重试
+// 下载完成的分P立即持久化并记录在任务中；返回重试后仍失败的分P及原因
+func (dm *DownloadManager) downloadVideoPages(task *DownloadTask) []string {
+	video := task.Video
+
+	var pending []*models.Page
+	for i := range video.Pages {
+		if task.IsPageCompleted(video.Pages[i].PID) {
+			continue
+		}
+		pending = append(pending, &video.Pages[i])
+	}
+	if skipped := len(video.Pages) - len(pending); skipped > 0 {
+		utils.Info("跳过已下载的 %d 个分P: %s", skipped, video.Name)
+	}
+
+	// 预先登记待下载的分P，下载记录的文件进度按全部分P汇总
+	tracker := dm.downloader.GetTracker()
+	tracker.RemoveVideo(video.ID)
+	videoProgress := tracker.AddVideo(video.ID, video.BVid, video.Name, len(pending))
+	for _, page := range pending {
+		videoProgress.AddPage(page.PID, NewPageProgress(page.ID, page.CID, page.PID, page.Name))
+	}
+
+	var (
+		mu     sync.Mutex
+		failed = make(map[int]string)
+		wg     sync.WaitGroup
+	)
+	for _, page := range pending {
+		wg.Add(1)
+		go func(page *models.Page) {
+			defer wg.Done()
+			if err := dm.downloadPageWithRetry(task, page); err != nil {
+				mu.Lock()
+				failed[page.PID] = err.Error()
+				mu.Unlock()
+				dm.persistPageStatus(page)
+				return
+			}
+			task.MarkPageCompleted(page.PID)
+			if err := dm.persistDownloadedPage(page); err != nil {
+				utils.Warn("更新分P下载状态失败: %v", err)
+			}
+		}(page)
+	}
+	wg.Wait()
+
+	if task.IsCancelled() {
+		return nil
+	}
+	var result []string
+	for _, page := range pending {
+		if msg, ok := failed[page.PID]; ok {
+			result = append(result, fmt.Sprintf("P%d: %s", page.PID, msg))
+		}
+	}
+	return result
+}
+
+// downloadPageWithRetry 获取分P许可后下载分P，失败时按任务的最大重试次数重试，重试间隔逐次翻倍
+func (dm *DownloadManager) downloadPageWithRetry(task *DownloadTask, page *models.Page) error {
+	video := task.Video
+	backoff := dm.pageRetryBackoff
+
+	var err error
+	for attempt := 0; attempt <= task.MaxRetries; attempt++ {
+		if attempt > 0 {
+			utils.Info("分P下载失败，第 %d/%d 次重试: %s - P%d, %v", attempt, task.MaxRetries, video.Name, page.PID, err)
+			select {
+			case <-task.Context.Done():
+				return task.Context.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if acquireErr := dm.concurrency.AcquirePage(task.Context); acquireErr != nil {
+			return acquireErr
+		}
+		utils.Info("准备下载分P: %s - P%d (%s)", video.Name, page.PID, page.Name)
+		if task.Repair {
+			err = dm.downloader.RepairPage(task.Context, video, page, task.OutputDir)
+		} else {
+			err = dm.downloader.DownloadPage(task.Context, video, page, task.OutputDir)
+		}
+		dm.concurrency.ReleasePage()
+
+		if err == nil {
+			return nil
+		}
+		if task.IsCancelled() || dm.isStopping() {
+			return err
+		}
+		utils.Error("下载分P失败: %v", err)
+	}
+	return err
+}
+
 // executePageTask 执行分P任务
 func (dm *DownloadManager) executePageTask(task *DownloadTask) {
 	defer dm.wg.Done()
 
 	if err := dm.concurrency.AcquirePage(task.Context); err != nil {
+		if task.IsPauseRequested() {
+			dm.finishTask(task)
+			return
+		}
 		task.SetError(err)
 		task.SetStatus(TaskStatusCancelled)
+		dm.forgetTask(task)
 		return
 	}
 	defer dm.concurrency.ReleasePage()
 
 	dm.runningTasks.Store(task.ID, task)
-	defer func() {
-		dm.runningTasks.Delete(task.ID)
-		dm.completedTasks.Store(task.ID, task)
-	}()
+	defer dm.finishTask(task)
 
 	task.SetStatus(TaskStatusRunning)
 	dm.emitEvent(ManagerEvent{
@@ -460,6 +704,10 @@ func (dm *DownloadManager) executePageTask(task *DownloadTask) {
 	})
 
 	err := dm.downloader.DownloadPage(task.Context, task.Video, task.Page, task.OutputDir)
+	if err != nil && task.IsPauseRequested() {
+		utils.Info("分P任务已暂停: %s P%d", task.Video.Name, task.Page.PID)
+		return
+	}
 	if err != nil {
 		utils.Error("下载分P失败: %v", err)
 		task.SetError(err)
@@ -471,6 +719,7 @@ func (dm *DownloadManager) executePageTask(task *DownloadTask) {
 	if err := dm.persistDownloadedPage(task.Page); err != nil {
 		utils.Warn("更新分P下载状态失败: %v", err)
 	}
+	dm.downloader.WriteFolderMetadata(task.Context, task.Video, task.OutputDir)
 
 	task.SetStatus(TaskStatusCompleted)
 	dm.emitEvent(ManagerEvent{
@@ -485,6 +734,12 @@ func (dm *DownloadManager) executePageTask(task *DownloadTask) {
 
 // handleTaskFailure 处理任务失败
 func (dm *DownloadManager) handleTaskFailure(task *DownloadTask) {
+	// 停机过程中被中断的任务不计入失败，保留在持久化队列中等待下次启动恢复
+	if dm.isStopping() {
+		utils.Info("任务因停机中断，将在下次启动时恢复: %s", task.ID)
+		return
+	}
+
 	// 检查是否可以重试
 	if task.CanRetry() {
 		task.IncrementRetry()
@@ -494,6 +749,7 @@ func (dm *DownloadManager) handleTaskFailure(task *DownloadTask) {
 		newTask := task.Clone()
 		newTask.SetStatus(TaskStatusPending)
 		dm.queue.Enqueue(newTask)
+		dm.persistTask(newTask)
 
 		dm.emitEvent(ManagerEvent{
 			Type:      EventTaskRetrying,
@@ -503,16 +759,25 @@ func (dm *DownloadManager) handleTaskFailure(task *DownloadTask) {
 		})
 	} else {
 		// 无法重试，标记为失败
-		dm.emitEvent(ManagerEvent{
-			Type:      EventTaskFailed,
-			Task:      task,
-			Message:   task.GetError().Error(),
-			Timestamp: time.Now(),
-		})
-		utils.Error("任务失败: %s, 错误: %v", task.ID, task.GetError())
+		dm.reportTaskFailed(task)
 	}
 }
 
+// reportTaskFailed 发出任务最终失败事件（停机中断的任务除外）
+func (dm *DownloadManager) reportTaskFailed(task *DownloadTask) {
+	if dm.isStopping() {
+		utils.Info("任务因停机中断，将在下次启动时恢复: %s", task.ID)
+		return
+	}
+	dm.emitEvent(ManagerEvent{
+		Type:      EventTaskFailed,
+		Task:      task,
+		Message:   task.GetError().Error(),
+		Timestamp: time.Now(),
+	})
+	utils.Error("任务失败: %s, 错误: %v", task.ID, task.GetError())
+}
+
 // AddTask 添加任务
 func (dm *DownloadManager) AddTask(task *DownloadTask) error {
 	if task == nil {
@@ -526,6 +791,7 @@ func (dm *DownloadManager) AddTask(task *DownloadTask) error {
 
 	// 入队
 	dm.queue.Enqueue(task)
+	dm.persistTask(task)
 
 	dm.emitEvent(ManagerEvent{
 		Type:      EventTaskAdded,
@@ -544,8 +810,8 @@ func (dm *DownloadManager) PrepareAndAddVideoTask(video *models.Video, baseDir s
 	var outputDir string
 
 	if autoCreateFolder {
-		// 为视频创建专属文件夹
-		videoFolderName := utils.Filenamify(video.Name)
+		// 为视频创建专属文件夹，目录名由视频名称模板生成（可包含子目录）
+		videoFolderName := VideoFolderName(dm.config, video, LookupSourceName(dm.db, video))
 		outputDir = filepath.Join(baseDir, videoFolderName)
 
 		// 创建目录
@@ -674,17 +940,19 @@ func (dm *DownloadManager) executeYtdlpTask(task *DownloadTask) {
 	defer dm.wg.Done()
 
 	if err := dm.concurrency.AcquireVideo(task.Context); err != nil {
+		if task.IsPauseRequested() {
+			dm.finishTask(task)
+			return
+		}
 		task.SetError(err)
 		task.SetStatus(TaskStatusCancelled)
+		dm.forgetTask(task)
 		return
 	}
 	defer dm.concurrency.ReleaseVideo()
 
 	dm.runningTasks.Store(task.ID, task)
-	defer func() {
-		dm.runningTasks.Delete(task.ID)
-		dm.completedTasks.Store(task.ID, task)
-	}()
+	defer dm.finishTask(task)
 
 	task.SetStatus(TaskStatusRunning)
 	dm.emitEvent(ManagerEvent{
@@ -735,6 +1003,10 @@ func (dm *DownloadManager) executeYtdlpTask(task *DownloadTask) {
 		notifyStatus("video", StatusDownloading, info.Percentage, info.DownloadedBytes, totalBytes)
 	})
 
+	if err != nil && task.IsPauseRequested() {
+		utils.Info("yt-dlp任务已暂停，已下载的 .part 文件将在恢复后续传: %s", video.Name)
+		return
+	}
 	if err != nil {
 		utils.Error("yt-dlp下载失败: %v", err)
 		task.SetError(err)
@@ -884,7 +1156,7 @@ func (dm *DownloadManager) RetryVideoTask(recordID uint, video *models.Video, pr
 
 	// 如果没有Pages，尝试从B站API重新获取
 	if len(videoWithPages.Pages) == 0 {
-		pages, err := dm.biliClient.GetVideoPages(videoWithPages.BVid)
+		pages, err := dm.biliClient.GetVideoPages(dm.ctx, videoWithPages.BVid)
 		if err != nil {
 			return nil, fmt.Errorf("视频没有分P信息且无法从B站获取: %w", err)
 		}
@@ -924,6 +1196,8 @@ func (dm *DownloadManager) RetryVideoTask(recordID uint, video *models.Video, pr
 	task.Priority = priority
 	task.RecordID = recordID
 	task.MaxRetries = dm.getMaxRetries()
+	// 按磁盘上已有的文件修复，只重新下载缺失的视频、封面、字幕、弹幕或 NFO
+	task.Repair = true
 
 	if err := dm.AddTask(task); err != nil {
 		return nil, err
@@ -1007,6 +1281,7 @@ func (dm *DownloadManager) CancelTask(taskID string) error {
 	task := dm.queue.Remove(taskID)
 	if task != nil {
 		task.Cancel()
+		dm.forgetTask(task)
 		dm.emitEvent(ManagerEvent{
 			Type:      EventTaskCancelled,
 			Task:      task,
@@ -1022,17 +1297,196 @@ func (dm *DownloadManager) CancelTask(taskID string) error {
 		return nil
 	}
 
+	// 检查是否已暂停
+	if val, ok := dm.pausedTasks.LoadAndDelete(taskID); ok {
+		task := val.(*DownloadTask)
+		task.Cancel()
+		dm.completedTasks.Store(task.ID, task)
+		dm.forgetTask(task)
+		dm.updateRecordStatus(task.RecordID, "failed", "任务已取消")
+		dm.emitEvent(ManagerEvent{
+			Type:      EventTaskCancelled,
+			Task:      task,
+			Timestamp: time.Now(),
+		})
+		return nil
+	}
+
 	return fmt.Errorf("任务未找到: %s", taskID)
 }
 
-// PauseTask 暂停任务（暂不支持，预留接口）
+// PauseTask 暂停任务
+// 排队中的任务直接移出队列；运行中的任务会终止当前下载（yt-dlp 保留 .part 文件），退出后转为暂停状态
 func (dm *DownloadManager) PauseTask(taskID string) error {
-	return fmt.Errorf("暂停功能暂未实现")
+	return dm.pauseTask(taskID, false)
+}
+
+func (dm *DownloadManager) pauseTask(taskID string, global bool) error {
+	if task := dm.queue.Remove(taskID); task != nil {
+		task.RequestPause(global)
+		dm.finishTask(task)
+		return nil
+	}
+
+	if val, ok := dm.runningTasks.Load(taskID); ok {
+		task := val.(*DownloadTask)
+		if task.IsPauseRequested() {
+			return fmt.Errorf("任务正在暂停: %s", taskID)
+		}
+		task.RequestPause(global)
+		utils.Info("正在暂停任务: %s", taskID)
+		return nil
+	}
+
+	if _, ok := dm.pausedTasks.Load(taskID); ok {
+		return fmt.Errorf("任务已暂停: %s", taskID)
+	}
+
+	return fmt.Errorf("任务未找到: %s", taskID)
 }
 
-// ResumeTask 恢复任务（暂不支持，预留接口）
+// ResumeTask 恢复已暂停的任务（重新入队，yt-dlp 从已下载的 .part 文件续传）
 func (dm *DownloadManager) ResumeTask(taskID string) error {
-	return fmt.Errorf("恢复功能暂未实现")
+	val, ok := dm.pausedTasks.LoadAndDelete(taskID)
+	if !ok {
+		return fmt.Errorf("任务未暂停或不存在: %s", taskID)
+	}
+
+	task := val.(*DownloadTask)
+	task.resetForResume()
+	dm.queue.Enqueue(task)
+	dm.persistTask(task)
+	dm.updateRecordStatus(task.RecordID, "pending", "")
+
+	dm.emitEvent(ManagerEvent{
+		Type:      EventTaskResumed,
+		Task:      task,
+		Timestamp: time.Now(),
+	})
+
+	utils.Info("任务已恢复: %s", taskID)
+	return nil
+}
+
+// PauseAll 打开全局暂停开关：停止调度新任务，并暂停所有运行中的任务
+func (dm *DownloadManager) PauseAll() int {
+	dm.mu.Lock()
+	if dm.pausedAll {
+		dm.mu.Unlock()
+		return 0
+	}
+	dm.pausedAll = true
+	dm.mu.Unlock()
+
+	count := 0
+	dm.runningTasks.Range(func(key, value interface{}) bool {
+		if err := dm.pauseTask(key.(string), true); err == nil {
+			count++
+		}
+		return true
+	})
+
+	dm.emitEvent(ManagerEvent{
+		Type:      EventDownloadsPaused,
+		Message:   fmt.Sprintf("已暂停全部下载，中断 %d 个运行中的任务", count),
+		Timestamp: time.Now(),
+	})
+
+	utils.Info("已开启全局暂停，暂停了 %d 个运行中的任务", count)
+	return count
+}
+
+// ResumeAll 关闭全局暂停开关，并恢复由全局暂停中断的任务（单独暂停的任务保持暂停）
+func (dm *DownloadManager) ResumeAll() int {
+	dm.mu.Lock()
+	if !dm.pausedAll {
+		dm.mu.Unlock()
+		return 0
+	}
+	dm.pausedAll = false
+	dm.mu.Unlock()
+
+	count := 0
+	dm.pausedTasks.Range(func(key, value interface{}) bool {
+		task := value.(*DownloadTask)
+		if task.IsPausedGlobally() {
+			if err := dm.ResumeTask(task.ID); err == nil {
+				count++
+			}
+		}
+		return true
+	})
+
+	dm.emitEvent(ManagerEvent{
+		Type:      EventDownloadsResumed,
+		Message:   fmt.Sprintf("已恢复全部下载，恢复 %d 个任务", count),
+		Timestamp: time.Now(),
+	})
+
+	utils.Info("已关闭全局暂停，恢复了 %d 个任务", count)
+	return count
+}
+
+// IsAllPaused 全局暂停开关是否打开
+func (dm *DownloadManager) IsAllPaused() bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.pausedAll
+}
+
+// refreshBandwidthLimit 按带宽时段规则刷新共享带宽预算
+func (dm *DownloadManager) refreshBandwidthLimit() {
+	dm.mu.RLock()
+	cfg := dm.config
+	dm.mu.RUnlock()
+	applyBandwidthLimit(cfg, time.Now())
+}
+
+// IsQuietHours 当前是否处于配置的静默时段（静默时段内不开始新任务，运行中的任务继续完成）
+func (dm *DownloadManager) IsQuietHours() bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	if dm.config == nil {
+		return false
+	}
+	return dm.config.Sync.QuietHours.Contains(time.Now())
+}
+
+// finishTask 任务执行结束后归档：已请求暂停且未完成的任务转入暂停列表，其余进入已完成列表
+func (dm *DownloadManager) finishTask(task *DownloadTask) {
+	dm.runningTasks.Delete(task.ID)
+
+	if task.IsPauseRequested() && task.GetStatus() != TaskStatusCompleted && !dm.isStopping() {
+		task.SetStatus(TaskStatusPaused)
+		dm.pausedTasks.Store(task.ID, task)
+		dm.persistTask(task)
+		dm.updateRecordStatus(task.RecordID, "paused", "")
+		dm.emitEvent(ManagerEvent{
+			Type:      EventTaskPaused,
+			Task:      task,
+			Timestamp: time.Now(),
+		})
+		utils.Info("任务已暂停: %s", task.ID)
+		return
+	}
+
+	dm.completedTasks.Store(task.ID, task)
+	dm.forgetTask(task)
+}
+
+// updateRecordStatus 更新下载记录状态
+func (dm *DownloadManager) updateRecordStatus(recordID uint, status, errorMessage string) {
+	if dm.db == nil || recordID == 0 {
+		return
+	}
+	updates := map[string]interface{}{
+		"status":        status,
+		"error_message": errorMessage,
+	}
+	if status == "failed" {
+		updates["completed_at"] = time.Now()
+	}
+	dm.db.Model(&models.DownloadRecord{}).Where("id = ?", recordID).Updates(updates)
 }
 
 // RetryTask 重试任务
@@ -1068,6 +1522,11 @@ func (dm *DownloadManager) GetTask(taskID string) *DownloadTask {
 		return val.(*DownloadTask)
 	}
 
+	// 再查已暂停
+	if val, ok := dm.pausedTasks.Load(taskID); ok {
+		return val.(*DownloadTask)
+	}
+
 	// 最后查已完成
 	if val, ok := dm.completedTasks.Load(taskID); ok {
 		return val.(*DownloadTask)
@@ -1089,6 +1548,12 @@ func (dm *DownloadManager) GetAllTasks() []*DownloadTask {
 		return true
 	})
 
+	// 已暂停的任务
+	dm.pausedTasks.Range(func(key, value interface{}) bool {
+		tasks = append(tasks, value.(*DownloadTask))
+		return true
+	})
+
 	// 已完成的任务
 	dm.completedTasks.Range(func(key, value interface{}) bool {
 		tasks = append(tasks, value.(*DownloadTask))
@@ -1113,6 +1578,16 @@ func (dm *DownloadManager) GetRunningTasks() []*DownloadTask {
 	return tasks
 }
 
+// GetPausedTasks 获取已暂停的任务
+func (dm *DownloadManager) GetPausedTasks() []*DownloadTask {
+	tasks := make([]*DownloadTask, 0)
+	dm.pausedTasks.Range(func(key, value interface{}) bool {
+		tasks = append(tasks, value.(*DownloadTask))
+		return true
+	})
+	return tasks
+}
+
 // GetCompletedTasks 获取已完成的任务
 func (dm *DownloadManager) GetCompletedTasks() []*DownloadTask {
 	tasks := make([]*DownloadTask, 0)
@@ -1133,6 +1608,12 @@ func (dm *DownloadManager) GetStats() ManagerStats {
 		return true
 	})
 
+	pausedCount := 0
+	dm.pausedTasks.Range(func(key, value interface{}) bool {
+		pausedCount++
+		return true
+	})
+
 	completedCount := 0
 	failedCount := 0
 	dm.completedTasks.Range(func(key, value interface{}) bool {
@@ -1150,9 +1631,12 @@ func (dm *DownloadManager) GetStats() ManagerStats {
 	return ManagerStats{
 		QueuedTasks:    queuedCount,
 		RunningTasks:   runningCount,
+		PausedTasks:    pausedCount,
 		CompletedTasks: completedCount,
 		FailedTasks:    failedCount,
-		TotalTasks:     queuedCount + runningCount + completedCount + failedCount,
+		TotalTasks:     queuedCount + runningCount + pausedCount + completedCount + failedCount,
+		PausedAll:      dm.IsAllPaused(),
+		QuietHours:     dm.IsQuietHours(),
 		Concurrency:    concurrencyStats,
 	}
 }
@@ -1161,9 +1645,12 @@ func (dm *DownloadManager) GetStats() ManagerStats {
 type ManagerStats struct {
 	QueuedTasks    int   `json:"queued_tasks"`
 	RunningTasks   int   `json:"running_tasks"`
+	PausedTasks    int   `json:"paused_tasks"`
 	CompletedTasks int   `json:"completed_tasks"`
 	FailedTasks    int   `json:"failed_tasks"`
 	TotalTasks     int   `json:"total_tasks"`
+	PausedAll      bool  `json:"paused_all"`
+	QuietHours     bool  `json:"quiet_hours"`
 	Concurrency    Stats `json:"concurrency"`
 }
 
@@ -1294,8 +1781,9 @@ func (dm *DownloadManager) markRecordAsFailed(record *models.DownloadRecord, err
 
 	// 重置视频下载状态
 	dm.db.Model(&models.Video{}).Where("id = ?", record.VideoID).Update("download_status", 0)
-	// 重置分P下载状态
-	dm.db.Model(&models.Page{}).Where("video_id = ?", record.VideoID).Update("download_status", 0)
+	// 清除分P的视频文件状态位，其余文件以重试时的磁盘扫描为准
+	dm.db.Model(&models.Page{}).Where("video_id = ?", record.VideoID).
+		Update("download_status", gorm.Expr("download_status & ?", ^models.PageStatusVideo))
 
 	utils.Info("已修复记录 ID=%d, 视频ID=%d: %s", record.ID, record.VideoID, errMsg)
 }
@@ -1345,6 +1833,9 @@ func (dm *DownloadManager) UpdateConfig(cfg *config.Config) {
 		dm.concurrency.UpdateLimits(maxVideos, maxPages)
 	}
 
+	// 更新带宽上限，对运行中的下载立即生效
+	applyBandwidthLimit(cfg, time.Now())
+
 	// 如果下载基础路径发生变化，更新队列中所有待处理任务的路径
 	if oldURLDownloadBase != cfg.Paths.URLDownloadBase() {
 		dm.updateQueuedTaskPathsByType(oldURLDownloadBase, cfg.Paths.URLDownloadBase(), TaskTypeYtdlp)
@@ -1372,6 +1863,7 @@ func (dm *DownloadManager) updateQueuedTaskPaths(oldBase, newBase string) {
 	for _, task := range tasks {
 		// 只更新待处理或排队中的任务
 		if task.Status == TaskStatusPending || task.Status == TaskStatusQueued {
+			countBefore := updatedCount
 			task.mu.Lock()
 
 			oldPath := filepath.Clean(task.OutputDir)
@@ -1416,6 +1908,9 @@ func (dm *DownloadManager) updateQueuedTaskPaths(oldBase, newBase string) {
 			}
 
 			task.mu.Unlock()
+			if updatedCount > countBefore {
+				dm.persistTask(task)
+			}
 		}
 	}
 
@@ -1441,6 +1936,7 @@ func (dm *DownloadManager) updateQueuedTaskPathsByType(oldBase, newBase string,
 			continue
 		}
 		if task.Status == TaskStatusPending || task.Status == TaskStatusQueued {
+			countBefore := updatedCount
 			task.mu.Lock()
 
 			oldPath := filepath.Clean(task.OutputDir)
@@ -1475,6 +1971,9 @@ func (dm *DownloadManager) updateQueuedTaskPathsByType(oldBase, newBase string,
 			}
 
 			task.mu.Unlock()
+			if updatedCount > countBefore {
+				dm.persistTask(task)
+			}
 		}
 	}
 
@@ -1506,6 +2005,9 @@ func (dm *DownloadManager) buildFileDetails(video *models.Video) models.FileDeta
 		if !dm.config.Download.SkipSubtitle {
 			files = append(files, models.FileDetail{Name: "subtitle", Label: "字幕", Status: "pending"})
 		}
+		if profile := postProcessProfile(dm.config, dm.db, video); profile != "" {
+			files = append(files, models.FileDetail{Name: postProcessTaskName, Label: postProcessLabel(profile), Status: "pending"})
+		}
 	}
 	return models.FileDetailsData{Files: files}
 }
@@ -1540,6 +2042,13 @@ func (dm *DownloadManager) getVideoSourceInfo(video *models.Video) (sourceType s
 		if dm.db.First(&wl, sourceID).Error == nil {
 			sourceName = wl.Name
 		}
+	} else if video.BangumiID != nil {
+		sourceType = "bangumi"
+		sourceID = *video.BangumiID
+		var bgm models.Bangumi
+		if dm.db.First(&bgm, sourceID).Error == nil {
+			sourceName = bgm.Name
+		}
 	} else {
 		sourceType = "url"
 		sourceName = "URL下载"
@@ -1582,6 +2091,9 @@ func (dm *DownloadManager) updateDownloadRecordProgress(videoID uint, taskName s
 			if progress.Label != "" {
 				details.Files[i].Label = progress.Label
 			}
+			if progress.Tracks != nil {
+				details.Files[i].Tracks = progress.Tracks
+			}
 			updated = true
 			break
 		}
@@ -3,6 +3,9 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -536,10 +539,210 @@ func TestExecuteVideoTaskPersistsDetectedPageQuality(t *testing.T) {
 	}
 }
 
+func newMultiPageVideo(pages int) *models.Video {
+	video := &models.Video{ID: 1, BVid: "BV1xx411c7mD", Name: "multi page video"}
+	for i := 1; i <= pages; i++ {
+		video.Pages = append(video.Pages, models.Page{ID: uint(10 + i), PID: i, CID: int64(100 + i), Name: fmt.Sprintf("P%d", i)})
+	}
+	return video
+}
+
+func TestExecuteVideoTaskDownloadsPagesConcurrently(t *testing.T) {
+	video := newMultiPageVideo(5)
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+
+	var running, maxRunning atomic.Int32
+	fakeDownloader := &fakePageDownloader{
+		downloadPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		},
+	}
+
+	var mu sync.Mutex
+	persisted := make(map[uint]bool)
+	dm := &DownloadManager{
+		downloader:  fakeDownloader,
+		concurrency: NewConcurrencyController(1, 2),
+		persistPageFn: func(page *models.Page) error {
+			mu.Lock()
+			persisted[page.ID] = true
+			mu.Unlock()
+			return nil
+		},
+	}
+	dm.wg.Add(1)
+	dm.executeVideoTask(task)
+
+	if task.GetStatus() != TaskStatusCompleted {
+		t.Fatalf("expected completed task, got %s", task.GetStatus())
+	}
+	if got := maxRunning.Load(); got != 2 {
+		t.Fatalf("expected pages to run up to the page limit of 2, got %d", got)
+	}
+	if len(persisted) != 5 {
+		t.Fatalf("expected all 5 pages persisted, got %d", len(persisted))
+	}
+}
+
+func TestExecuteVideoTaskRetriesFailedPagesIndependently(t *testing.T) {
+	video := newMultiPageVideo(3)
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+	task.MaxRetries = 1
+
+	var mu sync.Mutex
+	attempts := make(map[int]int)
+	fakeDownloader := &fakePageDownloader{
+		downloadPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+			mu.Lock()
+			attempts[page.PID]++
+			n := attempts[page.PID]
+			mu.Unlock()
+			switch {
+			case page.PID == 2 && n == 1:
+				return fmt.Errorf("transient error")
+			case page.PID == 3:
+				return fmt.Errorf("permanent error")
+			}
+			return nil
+		},
+	}
+	dm := &DownloadManager{
+		downloader:    fakeDownloader,
+		concurrency:   NewConcurrencyController(1, 3),
+		persistPageFn: func(page *models.Page) error { return nil },
+	}
+	dm.wg.Add(1)
+	dm.executeVideoTask(task)
+
+	if task.GetStatus() != TaskStatusFailed {
+		t.Fatalf("expected failed task, got %s", task.GetStatus())
+	}
+	if attempts[1] != 1 || attempts[2] != 2 || attempts[3] != 2 {
+		t.Fatalf("unexpected attempts per page: %v", attempts)
+	}
+	if err := task.GetError(); err == nil || !strings.Contains(err.Error(), "P3: permanent error") || strings.Contains(err.Error(), "P2") {
+		t.Fatalf("expected only P3 to be reported as failed, got %v", err)
+	}
+
+	// 重试任务只下载失败的分P
+	retry := task.Clone()
+	attempts = make(map[int]int)
+	dm.wg.Add(1)
+	dm.executeVideoTask(retry)
+	if attempts[1] != 0 || attempts[2] != 0 || attempts[3] != 2 {
+		t.Fatalf("expected retry to only download P3, got attempts %v", attempts)
+	}
+}
+
+func TestExecuteVideoTaskRepairModeOnlyRepairsPages(t *testing.T) {
+	video := newMultiPageVideo(2)
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+	task.Repair = true
+
+	var repaired atomic.Int32
+	fakeDownloader := &fakePageDownloader{
+		downloadPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+			t.Errorf("repair task should not fully download P%d", page.PID)
+			return nil
+		},
+		repairPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+			repaired.Add(1)
+			page.DownloadStatus = models.PageStatusVideo | models.PageStatusNFO
+			return nil
+		},
+	}
+
+	var mu sync.Mutex
+	var statuses []interface{}
+	dm := &DownloadManager{
+		downloader:  fakeDownloader,
+		concurrency: NewConcurrencyController(1, 2),
+		persistPageFn: func(page *models.Page) error {
+			mu.Lock()
+			statuses = append(statuses, buildDownloadedPageUpdates(page)["download_status"])
+			mu.Unlock()
+			return nil
+		},
+	}
+	dm.wg.Add(1)
+	dm.executeVideoTask(task)
+
+	if repaired.Load() != 2 {
+		t.Fatalf("expected both pages repaired, got %d", repaired.Load())
+	}
+	for _, status := range statuses {
+		if status != models.PageStatusVideo|models.PageStatusNFO {
+			t.Fatalf("expected persisted bitmask %d, got %#v", models.PageStatusVideo|models.PageStatusNFO, status)
+		}
+	}
+	if !task.Clone().Repair {
+		t.Error("expected cloned task to keep repair mode")
+	}
+}
+
+func TestVideoProgressAggregateSubTask(t *testing.T) {
+	progress := NewVideoProgress(1, "BV1xx411c7mD", "video", 3)
+	p1 := NewPageProgress(11, 101, 1, "P1")
+	p2 := NewPageProgress(12, 102, 2, "P2")
+	progress.AddPage(1, p1)
+	progress.AddPage(2, p2)
+
+	p1.UpdateSubTask("video", func(task *SubTaskProgress) {
+		task.Status = StatusSucceeded
+		task.Progress = 100
+		task.DownloadedSize = 100
+		task.TotalSize = 100
+	})
+	p2.UpdateSubTask("video", func(task *SubTaskProgress) {
+		task.Status = StatusDownloading
+		task.Progress = 50
+		task.DownloadedSize = 50
+		task.TotalSize = 100
+		task.Speed = 10
+	})
+
+	agg := progress.AggregateSubTask("video")
+	if agg.Status != StatusDownloading {
+		t.Fatalf("expected downloading while pages are pending, got %s", agg.Status)
+	}
+	if agg.Progress != 50 || agg.DownloadedSize != 150 || agg.TotalSize != 200 || agg.Speed != 10 {
+		t.Fatalf("unexpected aggregate: %+v", agg)
+	}
+
+	p3 := NewPageProgress(13, 103, 3, "P3")
+	progress.AddPage(3, p3)
+	p2.UpdateSubTask("video", func(task *SubTaskProgress) { task.Status = StatusSucceeded })
+	p3.UpdateSubTask("video", func(task *SubTaskProgress) {
+		task.Status = StatusFailed
+		task.Error = "HTTP 403"
+	})
+	agg = progress.AggregateSubTask("video")
+	if agg.Status != StatusFailed || agg.Error != "P3: HTTP 403" {
+		t.Fatalf("expected failed aggregate with P3 error, got %+v", agg)
+	}
+
+	// 单P视频直接使用该分P的子任务
+	single := NewVideoProgress(2, "BV1", "single", 1)
+	single.AddPage(1, p3)
+	if agg := single.AggregateSubTask("video"); agg.Error != "HTTP 403" {
+		t.Fatalf("expected single page subtask passthrough, got %+v", agg)
+	}
+}
+
 type fakePageDownloader struct {
 	tracker        *ProgressTracker
 	callback       ProgressCallback
 	downloadPageFn func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error
+	repairPageFn   func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error
 }
 
 func (f *fakePageDownloader) DownloadPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
@@ -549,6 +752,16 @@ func (f *fakePageDownloader) DownloadPage(ctx context.Context, video *models.Vid
 	return nil
 }
 
+func (f *fakePageDownloader) RepairPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+	if f.repairPageFn != nil {
+		return f.repairPageFn(ctx, video, page, outputDir)
+	}
+	return nil
+}
+
+func (f *fakePageDownloader) WriteFolderMetadata(ctx context.Context, video *models.Video, outputDir string) {
+}
+
 func (f *fakePageDownloader) GetTracker() *ProgressTracker {
 	if f.tracker == nil {
 		f.tracker = NewProgressTracker()
@@ -563,3 +776,219 @@ func (f *fakePageDownloader) SetProgressCallback(callback ProgressCallback) {
 func (f *fakePageDownloader) Cleanup() {}
 
 func (f *fakePageDownloader) UpdateConfig(cfg *config.Config) {}
+
+func TestStartRestoresPersistedTasksInOriginalOrder(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	video := &models.Video{ID: 1, BVid: "BV1xx411c7mD", Name: "test video"}
+
+	older := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+	older.ID = "video-1"
+	older.CreatedAt = base
+
+	newer := NewDownloadTask(TaskTypeYtdlp, video, nil, "./downloads")
+	newer.ID = "ytdlp-1-100"
+	newer.URL = "https://example.com/v"
+	newer.CreatedAt = base.Add(time.Minute)
+
+	urgent := NewDownloadTask(TaskTypeXHS, video, nil, "./downloads")
+	urgent.ID = "xhs-1"
+	urgent.Priority = PriorityHigh
+	urgent.CreatedAt = base.Add(2 * time.Minute)
+
+	store := newFakeTaskStore(older, newer, urgent)
+	dm := &DownloadManager{
+		downloader:  &fakePageDownloader{},
+		queue:       NewTaskQueue(),
+		store:       store,
+		concurrency: NewConcurrencyController(0, 0),
+	}
+	dm.ctx, dm.cancel = context.WithCancel(context.Background())
+
+	if err := dm.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer dm.Stop()
+
+	want := []string{"xhs-1", "video-1", "ytdlp-1-100"}
+	for _, id := range want {
+		task := dm.queue.Dequeue()
+		if task == nil || task.ID != id {
+			t.Fatalf("expected restored task %s, got %#v", id, task)
+		}
+	}
+	if restored := dm.GetTask("ytdlp-1-100"); restored != nil {
+		t.Fatalf("expected queue to be drained, got %s", restored.ID)
+	}
+}
+
+func TestAddTaskPersistsAndCompletionForgetsTask(t *testing.T) {
+	video := &models.Video{
+		ID:    1,
+		BVid:  "BV1xx411c7mD",
+		Name:  "test video",
+		Pages: []models.Page{{ID: 11, PID: 1, CID: 123, Name: "P1"}},
+	}
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+
+	store := newFakeTaskStore()
+	dm := &DownloadManager{
+		downloader:    &fakePageDownloader{},
+		queue:         NewTaskQueue(),
+		store:         store,
+		concurrency:   NewConcurrencyController(1, 1),
+		persistPageFn: func(page *models.Page) error { return nil },
+	}
+
+	if err := dm.AddTask(task); err != nil {
+		t.Fatalf("add task failed: %v", err)
+	}
+	if _, ok := store.items[task.ID]; !ok {
+		t.Fatal("expected AddTask to persist the task")
+	}
+
+	dm.queue.Remove(task.ID)
+	dm.wg.Add(1)
+	dm.executeVideoTask(task)
+
+	if _, ok := store.items[task.ID]; ok {
+		t.Fatal("expected completed task to be removed from the persisted queue")
+	}
+}
+
+func TestInterruptedTaskStaysPersistedWhileStopping(t *testing.T) {
+	video := &models.Video{
+		ID:    1,
+		BVid:  "BV1xx411c7mD",
+		Name:  "test video",
+		Pages: []models.Page{{ID: 11, PID: 1, CID: 123, Name: "P1"}},
+	}
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+
+	store := newFakeTaskStore(task)
+	dm := &DownloadManager{
+		downloader: &fakePageDownloader{
+			downloadPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+				return context.Canceled
+			},
+		},
+		queue:       NewTaskQueue(),
+		store:       store,
+		concurrency: NewConcurrencyController(1, 1),
+		stopping:    true,
+	}
+	dm.wg.Add(1)
+	dm.executeVideoTask(task)
+
+	if _, ok := store.items[task.ID]; !ok {
+		t.Fatal("expected task interrupted by shutdown to stay persisted")
+	}
+	if dm.queue.Contains(task.ID) {
+		t.Fatal("expected interrupted task not to be retried during shutdown")
+	}
+}
+
+func TestPauseRunningTaskKeepsItPersistedAndResumeRequeues(t *testing.T) {
+	video := &models.Video{
+		ID:    1,
+		BVid:  "BV1xx411c7mD",
+		Name:  "test video",
+		Pages: []models.Page{{ID: 11, PID: 1, CID: 123, Name: "P1"}},
+	}
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "./downloads")
+
+	started := make(chan struct{})
+	store := newFakeTaskStore(task)
+	dm := &DownloadManager{
+		downloader: &fakePageDownloader{
+			downloadPageFn: func(ctx context.Context, video *models.Video, page *models.Page, outputDir string) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		queue:       NewTaskQueue(),
+		store:       store,
+		concurrency: NewConcurrencyController(1, 1),
+	}
+
+	dm.runningTasks.Store(task.ID, task)
+	dm.wg.Add(1)
+	done := make(chan struct{})
+	go func() {
+		dm.executeVideoTask(task)
+		close(done)
+	}()
+
+	<-started
+	if err := dm.PauseTask(task.ID); err != nil {
+		t.Fatalf("pause task failed: %v", err)
+	}
+	<-done
+
+	if task.GetStatus() != TaskStatusPaused {
+		t.Fatalf("expected task to be paused, got %s", task.GetStatus())
+	}
+	if _, ok := store.items[task.ID]; !ok {
+		t.Fatal("expected paused task to stay persisted")
+	}
+	if item := buildQueueItem(task); !item.Paused {
+		t.Fatal("expected persisted item to be marked paused")
+	}
+	if dm.queue.Contains(task.ID) {
+		t.Fatal("expected paused task not to be retried")
+	}
+
+	if err := dm.ResumeTask(task.ID); err != nil {
+		t.Fatalf("resume task failed: %v", err)
+	}
+	if !dm.queue.Contains(task.ID) {
+		t.Fatal("expected resumed task to be queued again")
+	}
+	if task.IsPauseRequested() || task.IsCancelled() {
+		t.Fatal("expected resumed task to have a fresh context and no pause flag")
+	}
+}
+
+type fakeTaskStore struct {
+	mu    sync.Mutex
+	items map[string]*DownloadTask
+	order []string
+}
+
+func newFakeTaskStore(tasks ...*DownloadTask) *fakeTaskStore {
+	store := &fakeTaskStore{items: make(map[string]*DownloadTask)}
+	for _, task := range tasks {
+		store.Save(task)
+	}
+	return store
+}
+
+func (f *fakeTaskStore) Save(task *DownloadTask) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.items[task.ID]; !ok {
+		f.order = append(f.order, task.ID)
+	}
+	f.items[task.ID] = task
+	return nil
+}
+
+func (f *fakeTaskStore) Delete(taskID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, taskID)
+	return nil
+}
+
+func (f *fakeTaskStore) Restore() ([]*DownloadTask, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tasks := make([]*DownloadTask, 0, len(f.items))
+	for _, id := range f.order {
+		if task, ok := f.items[id]; ok {
+			item := buildQueueItem(task)
+			tasks = append(tasks, restoreTaskFromItem(item, task.Video, task.Page))
+		}
+	}
+	return tasks, nil
+}
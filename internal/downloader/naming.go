@@ -0,0 +1,198 @@
+package downloader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+	"bili-download/internal/naming"
+	"bili-download/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// pathSeparatorReplacer 变量值中的路径分隔符不应产生子目录
+var pathSeparatorReplacer = strings.NewReplacer("/", "_", "\\", "_")
+
+// VideoFolderName 按视频名称模板生成视频目录（相对于视频源目录，模板中的 / 表示子目录）
+// 模板无效或渲染结果为空时退回视频标题
+func VideoFolderName(cfg *config.Config, video *models.Video, sourceName string) string {
+	if video.IsEpisode() {
+		return EpisodeSeasonFolder(video.SeasonNumber)
+	}
+
+	fallback := utils.Filenamify(video.Name)
+	if cfg == nil || cfg.Template.VideoName == "" {
+		return fallback
+	}
+
+	rendered, err := naming.Render(cfg.Template.VideoName, namingVars(cfg, video, nil, sourceName))
+	if err != nil {
+		utils.Warn("视频名称模板渲染失败，使用视频标题: %v", err)
+		return fallback
+	}
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(rendered, "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, utils.Filenamify(segment))
+	}
+	if len(segments) == 0 {
+		return fallback
+	}
+	return filepath.Join(segments...)
+}
+
+// PageFileBaseName 按分P名称模板生成分P文件（视频、封面、NFO、字幕、弹幕）的文件名前缀
+// 多P视频的模板未引用 {{ptitle}} 或 {{pid}} 时追加 -{分P标题}，避免各分P文件重名
+func PageFileBaseName(cfg *config.Config, video *models.Video, page *models.Page, sourceName string) string {
+	if video.IsEpisode() {
+		return EpisodeFileBaseName(video)
+	}
+
+	baseName := utils.Filenamify(video.Name)
+	if cfg != nil && cfg.Template.PageName != "" {
+		rendered, err := naming.Render(cfg.Template.PageName, namingVars(cfg, video, page, sourceName))
+		if err != nil {
+			utils.Warn("分P名称模板渲染失败，使用视频标题: %v", err)
+		} else if strings.TrimSpace(rendered) != "" {
+			baseName = utils.Filenamify(rendered)
+		}
+	}
+
+	if video.SinglePage || page == nil {
+		return baseName
+	}
+	if cfg != nil && naming.UsesPageVars(cfg.Template.PageName) {
+		return baseName
+	}
+	return baseName + "-" + utils.Filenamify(page.Name)
+}
+
+// EpisodeSeasonFolder 番剧剧集所在的季目录（Season 01），与 Jellyfin/Emby 的剧集目录结构一致
+func EpisodeSeasonFolder(season int) string {
+	if season <= 0 {
+		season = 1
+	}
+	return fmt.Sprintf("Season %02d", season)
+}
+
+// EpisodeFileBaseName 番剧剧集的文件名前缀（S01E05 - 标题），不使用命名模板以保证媒体库能识别季集编号
+func EpisodeFileBaseName(video *models.Video) string {
+	season := video.SeasonNumber
+	if season <= 0 {
+		season = 1
+	}
+	return utils.Filenamify(fmt.Sprintf("S%02dE%02d - %s", season, video.EpisodeNumber, video.Name))
+}
+
+// namingVars 构造命名模板变量
+func namingVars(cfg *config.Config, video *models.Video, page *models.Page, sourceName string) naming.Vars {
+	timeFormat := cfg.Template.TimeFormat
+	vars := naming.Vars{
+		"bvid":        video.BVid,
+		"title":       video.Name,
+		"upper_name":  video.UpperName,
+		"upper_mid":   strconv.FormatInt(video.UpperID, 10),
+		"pubtime":     naming.FormatTime(video.PubTime, timeFormat),
+		"favtime":     naming.FormatTime(video.FavTime, timeFormat),
+		"ctime":       naming.FormatTime(video.CTime, timeFormat),
+		"source_type": videoSourceType(video),
+		"source_name": sourceName,
+	}
+	if page != nil {
+		vars["ptitle"] = page.Name
+		vars["page_title"] = page.Name
+		vars["pid"] = strconv.Itoa(page.PID)
+	}
+
+	for name, value := range vars {
+		vars[name] = pathSeparatorReplacer.Replace(strings.TrimSpace(value))
+	}
+	return vars
+}
+
+// videoSourceType 视频所属视频源类型
+func videoSourceType(video *models.Video) string {
+	switch {
+	case video.FavoriteID != nil:
+		return "favorite"
+	case video.CollectionID != nil:
+		return "collection"
+	case video.SubmissionID != nil:
+		return "submission"
+	case video.WatchLaterID != nil:
+		return "watch_later"
+	case video.BangumiID != nil:
+		return "bangumi"
+	default:
+		return "url"
+	}
+}
+
+// LookupSourceName 查询视频所属视频源的名称，未关联视频源或查询失败时返回空字符串
+func LookupSourceName(db *gorm.DB, video *models.Video) string {
+	name, _ := lookupVideoSource(db, video)
+	return name
+}
+
+// lookupVideoSource 查询视频所属视频源的名称与保存目录
+func lookupVideoSource(db *gorm.DB, video *models.Video) (string, string) {
+	var source struct {
+		Name string
+		Path string
+	}
+	if !scanVideoSource(db, video, []string{"name", "path"}, &source) {
+		return "", ""
+	}
+	return source.Name, source.Path
+}
+
+// lookupSourceEngine 查询视频所属视频源设置的下载引擎，未设置或查询失败时返回空字符串
+func lookupSourceEngine(db *gorm.DB, video *models.Video) string {
+	var source struct {
+		DownloadEngine string
+	}
+	if !scanVideoSource(db, video, []string{"download_engine"}, &source) {
+		return ""
+	}
+	return source.DownloadEngine
+}
+
+// scanVideoSource 查询视频所属视频源的指定列，未关联视频源或查询失败时返回 false
+func scanVideoSource(db *gorm.DB, video *models.Video, columns []string, dest interface{}) bool {
+	if db == nil || video == nil {
+		return false
+	}
+
+	var (
+		model interface{}
+		id    uint
+	)
+	switch {
+	case video.FavoriteID != nil:
+		model, id = &models.Favorite{}, *video.FavoriteID
+	case video.CollectionID != nil:
+		model, id = &models.Collection{}, *video.CollectionID
+	case video.SubmissionID != nil:
+		model, id = &models.Submission{}, *video.SubmissionID
+	case video.WatchLaterID != nil:
+		model, id = &models.WatchLater{}, *video.WatchLaterID
+	case video.BangumiID != nil:
+		model, id = &models.Bangumi{}, *video.BangumiID
+	default:
+		return false
+	}
+
+	if err := db.Model(model).Select(columns).Where("id = ?", id).Limit(1).Scan(dest).Error; err != nil {
+		utils.Debug("查询视频源失败: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package downloader
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func namingTestConfig(videoName, pageName string) *config.Config {
+	return &config.Config{Template: config.TemplateConfig{
+		VideoName:  videoName,
+		PageName:   pageName,
+		TimeFormat: "%Y-%m-%d",
+	}}
+}
+
+func TestDefaultTemplatesKeepExistingLayout(t *testing.T) {
+	cfg := namingTestConfig("{{title}}", "{{title}}")
+	video := &models.Video{Name: "标题: 测试", SinglePage: false}
+	page := &models.Page{PID: 2, Name: "第二集"}
+
+	if got := VideoFolderName(cfg, video, ""); got != "标题_ 测试" {
+		t.Fatalf("unexpected folder: %q", got)
+	}
+	if got := PageFileBaseName(cfg, video, page, ""); got != "标题_ 测试-第二集" {
+		t.Fatalf("unexpected multi-page base name: %q", got)
+	}
+
+	video.SinglePage = true
+	if got := PageFileBaseName(cfg, video, page, ""); got != "标题_ 测试" {
+		t.Fatalf("unexpected single-page base name: %q", got)
+	}
+}
+
+func TestVideoFolderNameSupportsSubdirectories(t *testing.T) {
+	cfg := namingTestConfig("{{upper_name}}/{{pubtime}} - {{title}}", "{{title}}")
+	video := &models.Video{
+		Name:      "a/b",
+		UpperName: "UP",
+		PubTime:   time.Date(2024, 5, 20, 0, 0, 0, 0, time.Local),
+	}
+
+	// 变量值中的 / 不应产生子目录
+	want := filepath.Join("UP", "2024-05-20 - a_b")
+	if got := VideoFolderName(cfg, video, ""); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPageFileBaseNameWithPageVars(t *testing.T) {
+	cfg := namingTestConfig("{{title}}", "{{bvid}} - P{{pid}} {{ptitle}}")
+	video := &models.Video{BVid: "BV1xx", Name: "标题"}
+	page := &models.Page{PID: 3, Name: "片段"}
+
+	if got := PageFileBaseName(cfg, video, page, ""); got != "BV1xx - P3 片段" {
+		t.Fatalf("unexpected base name: %q", got)
+	}
+
+	// page_title 为 ptitle 的别名
+	cfg = namingTestConfig("{{title}}", "{{title}}-P{{pid}}-{{page_title}}")
+	if got := PageFileBaseName(cfg, video, page, ""); got != "标题-P3-片段" {
+		t.Fatalf("unexpected base name with page_title: %q", got)
+	}
+}
+
+func TestVideoFolderNameFallsBackOnInvalidTemplate(t *testing.T) {
+	cfg := namingTestConfig("{{title", "{{title}}")
+	video := &models.Video{Name: "标题"}
+
+	if got := VideoFolderName(cfg, video, ""); got != "标题" {
+		t.Fatalf("expected fallback to title, got %q", got)
+	}
+}
+
+func TestEpisodeNamingIgnoresTemplates(t *testing.T) {
+	cfg := namingTestConfig("{{upper_name}}/{{title}}", "{{bvid}}")
+	bangumiID := uint(1)
+	video := &models.Video{
+		BVid: "BV1xx", Name: "第5话 标题", BangumiID: &bangumiID,
+		EpID: 12345, SeasonNumber: 2, EpisodeNumber: 5,
+	}
+	page := &models.Page{PID: 1, Name: "第5话 标题"}
+
+	if got := VideoFolderName(cfg, video, "番剧"); got != "Season 02" {
+		t.Fatalf("unexpected episode folder: %q", got)
+	}
+	if got := PageFileBaseName(cfg, video, page, "番剧"); got != "S02E05 - 第5话 标题" {
+		t.Fatalf("unexpected episode base name: %q", got)
+	}
+}
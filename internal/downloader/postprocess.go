@@ -0,0 +1,404 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// postProcessTaskName 后处理子任务名称，对应下载记录中的文件条目
+const postProcessTaskName = "postprocess"
+
+// ffmpegPool 限制同时运行的后处理 ffmpeg 进程数，所有视频共享
+type ffmpegPool struct {
+	slots chan struct{}
+}
+
+func newFFmpegPool(size int) *ffmpegPool {
+	if size < 1 {
+		size = 1
+	}
+	return &ffmpegPool{slots: make(chan struct{}, size)}
+}
+
+// size 池的容量
+func (p *ffmpegPool) size() int {
+	return cap(p.slots)
+}
+
+// acquire 获取一个执行名额，ctx 取消时放弃等待
+func (p *ffmpegPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release 归还执行名额
+func (p *ffmpegPool) release() {
+	<-p.slots
+}
+
+// lookupSourcePostProcess 查询视频所属视频源设置的后处理方案，未设置或查询失败时返回空字符串
+func lookupSourcePostProcess(db *gorm.DB, video *models.Video) string {
+	var source struct {
+		PostProcess string
+	}
+	if !scanVideoSource(db, video, []string{"post_process"}, &source) {
+		return ""
+	}
+	return source.PostProcess
+}
+
+// postProcessProfile 视频使用的后处理方案：视频源设置优先，其次为全局配置，不处理时返回空字符串
+func postProcessProfile(cfg *config.Config, db *gorm.DB, video *models.Video) string {
+	profile := lookupSourcePostProcess(db, video)
+	if profile == "" && cfg != nil {
+		profile = cfg.Download.PostProcess
+	}
+	if profile == config.PostProcessNone {
+		return ""
+	}
+	return profile
+}
+
+// postProcessLabel 后处理方案在下载记录中的显示名称
+func postProcessLabel(profile string) string {
+	switch profile {
+	case config.PostProcessMKV:
+		return "封装MKV"
+	case config.PostProcessH264:
+		return "转码H.264"
+	case config.PostProcessAudioM4A:
+		return "提取音频"
+	default:
+		return "后处理"
+	}
+}
+
+// postProcessJob 一次后处理的 ffmpeg 参数与输出
+type postProcessJob struct {
+	args    []string // 输入与编码参数，不含输出格式和路径
+	format  string   // 输出封装格式（-f）
+	output  string   // 最终输出路径
+	replace bool     // 输出替代原视频文件：删除原文件并重新探测
+}
+
+// postProcessDone 判断分P是否已按方案处理过（修复或重新下载时跳过）
+func postProcessDone(profile string, page *models.Page, outputDir, baseName string) bool {
+	switch profile {
+	case config.PostProcessMKV:
+		return strings.EqualFold(filepath.Ext(page.Path), ".mkv")
+	case config.PostProcessH264:
+		return page.VideoCodec == models.VideoCodecAVC && page.Width > 0 && page.Height > 0 &&
+			min(page.Width, page.Height) <= 1080 && strings.EqualFold(filepath.Ext(page.Path), ".mp4")
+	case config.PostProcessAudioM4A:
+		info, err := os.Stat(filepath.Join(outputDir, baseName+".m4a"))
+		return err == nil && info.Size() > 0
+	}
+	return false
+}
+
+// buildPostProcessJob 按方案构造分P视频的 ffmpeg 处理参数
+func buildPostProcessJob(profile string, video *models.Video, page *models.Page, outputDir, baseName string) (*postProcessJob, error) {
+	if page.Path == "" {
+		return nil, fmt.Errorf("未找到分P视频文件")
+	}
+	args := []string{"-i", page.Path}
+
+	switch profile {
+	case config.PostProcessMKV:
+		// 弹幕 ASS 与各语言字幕作为字幕轨内嵌，外部文件保留供播放器直接加载
+		args = append(args, "-map", "0:v", "-map", "0:a?")
+		tracks := pageSubtitleTracks(outputDir, baseName)
+		for i, track := range tracks {
+			args = append(args, "-i", track.path)
+			args = append(args, "-map", strconv.Itoa(i+1))
+		}
+		for i, track := range tracks {
+			stream := "-metadata:s:s:" + strconv.Itoa(i)
+			args = append(args, stream, "language="+track.language, stream, "title="+track.title)
+		}
+		args = append(args, "-c", "copy")
+		return &postProcessJob{args: args, format: "matroska", output: filepath.Join(outputDir, baseName+".mkv"), replace: true}, nil
+
+	case config.PostProcessH264:
+		// 按短边缩放到不超过 1080，竖屏视频同样适用；HDR 视频色调映射为 SDR
+		filter := "scale=w='if(gte(iw,ih),-2,min(iw,1080))':h='if(gte(iw,ih),min(ih,1080),-2)'"
+		if page.HDRFormat != "" || page.DolbyVision {
+			filter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0," +
+				"zscale=t=bt709:m=bt709:r=tv," + filter
+		}
+		args = append(args, "-map", "0:v:0", "-map", "0:a?",
+			"-vf", filter, "-c:v", "libx264", "-preset", "medium", "-crf", "20", "-pix_fmt", "yuv420p")
+		if page.AudioCodec == "aac" {
+			args = append(args, "-c:a", "copy")
+		} else {
+			args = append(args, "-c:a", "aac", "-b:a", "192k")
+		}
+		args = append(args, "-movflags", "+faststart")
+		return &postProcessJob{args: args, format: "mp4", output: filepath.Join(outputDir, baseName+".mp4"), replace: true}, nil
+
+	case config.PostProcessAudioM4A:
+		// AAC 直接复制，Hi-Res 无损转为 ALAC，其余（杜比）转为 AAC
+		args = append(args, "-map", "0:a:0", "-vn")
+		switch page.AudioCodec {
+		case "aac":
+			args = append(args, "-c:a", "copy")
+		case "flac":
+			args = append(args, "-c:a", "alac")
+		default:
+			args = append(args, "-c:a", "aac", "-b:a", "320k")
+		}
+		title := page.Name
+		if video.SinglePage || title == "" {
+			title = video.Name
+		}
+		args = append(args, "-metadata", "title="+title, "-metadata", "album="+video.Name)
+		if video.UpperName != "" {
+			args = append(args, "-metadata", "artist="+video.UpperName)
+		}
+		return &postProcessJob{args: args, format: "ipod", output: filepath.Join(outputDir, baseName+".m4a")}, nil
+	}
+	return nil, fmt.Errorf("未知的后处理方案: %s", profile)
+}
+
+// subtitleTrack 内嵌到 MKV 的字幕轨
+type subtitleTrack struct {
+	path     string
+	language string // ISO 639-2 语言代码
+	title    string
+}
+
+// pageSubtitleTracks 列出分P的弹幕与字幕文件，弹幕排在最前
+func pageSubtitleTracks(outputDir, baseName string) []subtitleTrack {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil
+	}
+	danmakuFile := baseName + ".zh-CN.default.ass"
+	var danmaku, subtitles []subtitleTrack
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, baseName+".") {
+			continue
+		}
+		if info, err := entry.Info(); err != nil || info.Size() == 0 {
+			continue
+		}
+		path := filepath.Join(outputDir, name)
+		if name == danmakuFile {
+			danmaku = append(danmaku, subtitleTrack{path: path, language: "chi", title: "弹幕"})
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".srt" && ext != ".ass" {
+			continue
+		}
+		lang := strings.TrimSuffix(strings.TrimPrefix(name, baseName+"."), filepath.Ext(name))
+		if lang == "" {
+			continue
+		}
+		subtitles = append(subtitles, subtitleTrack{path: path, language: subtitleISO639(lang), title: lang})
+	}
+	return append(danmaku, subtitles...)
+}
+
+// subtitleISO639 将字幕文件的语言后缀（zh-CN、en-US、zh-CN.ai、ai-zh 等）转换为 MKV 使用的 ISO 639-2 代码
+func subtitleISO639(lang string) string {
+	code := strings.ToLower(strings.TrimPrefix(lang, "ai-"))
+	if i := strings.IndexAny(code, "-_."); i > 0 {
+		code = code[:i]
+	}
+	switch code {
+	case "zh":
+		return "chi"
+	case "en":
+		return "eng"
+	case "ja":
+		return "jpn"
+	case "ko":
+		return "kor"
+	case "fr":
+		return "fre"
+	case "de":
+		return "ger"
+	case "es":
+		return "spa"
+	case "ru":
+		return "rus"
+	case "pt":
+		return "por"
+	case "it":
+		return "ita"
+	case "ar":
+		return "ara"
+	case "th":
+		return "tha"
+	case "vi":
+		return "vie"
+	case "id":
+		return "ind"
+	default:
+		return "und"
+	}
+}
+
+// postProcessPage 按视频源或全局配置的方案处理已下载的分P视频，processed 表示本次实际执行了处理
+func (d *Downloader) postProcessPage(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) (processed bool, err error) {
+	profile := postProcessProfile(d.config, d.db, video)
+	if profile == "" {
+		return false, nil
+	}
+	baseName := d.pageFileBaseName(video, page)
+	if postProcessDone(profile, page, outputDir, baseName) {
+		d.markArtifactPresent(video, page, pageProgress, postProcessTaskName)
+		return false, nil
+	}
+
+	label := postProcessLabel(profile)
+	fail := func(err error) (bool, error) {
+		pageProgress.UpdateSubTask(postProcessTaskName, func(task *SubTaskProgress) {
+			task.Status = StatusFailed
+			task.Error = err.Error()
+			task.EndTime = time.Now()
+		})
+		d.tracker.NotifyProgress(video.ID, page.PID, postProcessTaskName, pageProgress.GetSubTask(postProcessTaskName))
+		return false, err
+	}
+
+	job, err := buildPostProcessJob(profile, video, page, outputDir, baseName)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 排队等待 ffmpeg 名额（配置更新换池后，已取得的名额仍归还给原池）
+	if pool := d.postProcessPool.Load(); pool != nil {
+		if err := pool.acquire(ctx); err != nil {
+			return fail(err)
+		}
+		defer pool.release()
+	}
+
+	pageProgress.UpdateSubTask(postProcessTaskName, func(task *SubTaskProgress) {
+		task.Label = label
+		task.Status = StatusDownloading
+		task.Progress = 0
+		task.Error = ""
+		task.StartTime = time.Now()
+	})
+	d.tracker.NotifyProgress(video.ID, page.PID, postProcessTaskName, pageProgress.GetSubTask(postProcessTaskName))
+	utils.Info("开始后处理（%s）: %s P%d", label, video.Name, page.PID)
+
+	tmp := job.output + ".processing"
+	args := append([]string{"-hide_banner", "-loglevel", "error", "-nostats", "-progress", "pipe:1", "-y"}, job.args...)
+	args = append(args, "-f", job.format, tmp)
+	duration := float64(page.Duration)
+	err = runFFmpegWithProgress(ctx, args, func(seconds float64) {
+		if duration <= 0 {
+			return
+		}
+		pageProgress.UpdateSubTask(postProcessTaskName, func(task *SubTaskProgress) {
+			task.Progress = math.Min(seconds/duration*100, 99)
+		})
+		d.tracker.NotifyProgress(video.ID, page.PID, postProcessTaskName, pageProgress.GetSubTask(postProcessTaskName))
+	})
+	if err != nil {
+		os.Remove(tmp)
+		return fail(fmt.Errorf("ffmpeg 后处理失败: %w", err))
+	}
+	if err := os.Rename(tmp, job.output); err != nil {
+		os.Remove(tmp)
+		return fail(fmt.Errorf("保存后处理文件失败: %w", err))
+	}
+
+	if job.replace {
+		if page.Path != job.output {
+			if err := os.Remove(page.Path); err != nil {
+				utils.Warn("删除原视频文件失败: %s, %v", page.Path, err)
+			}
+		}
+		page.Path = job.output
+		if probe, err := ProbeVideo(ctx, job.output); err != nil {
+			utils.Warn("ffprobe 探测失败: %s, %v", job.output, err)
+		} else {
+			// 杜比视界由取流结果判定，封装 MKV 时视频流不变，保留原标记
+			dolbyVision := page.DolbyVision && profile == config.PostProcessMKV
+			probe.ApplyToPage(page)
+			page.DolbyVision = page.DolbyVision || dolbyVision
+		}
+	}
+
+	var size int64
+	if info, err := os.Stat(job.output); err == nil {
+		size = info.Size()
+	}
+	pageProgress.UpdateSubTask(postProcessTaskName, func(task *SubTaskProgress) {
+		task.Status = StatusSucceeded
+		task.Progress = 100
+		task.DownloadedSize = size
+		task.TotalSize = size
+		task.EndTime = time.Now()
+	})
+	d.tracker.NotifyProgress(video.ID, page.PID, postProcessTaskName, pageProgress.GetSubTask(postProcessTaskName))
+	utils.Info("后处理完成（%s）: %s", label, job.output)
+	return true, nil
+}
+
+// runFFmpegWithProgress 执行 ffmpeg（需带 -progress pipe:1），按已处理的媒体时长（秒）回调进度
+func runFFmpegWithProgress(ctx context.Context, args []string, onProgress func(seconds float64)) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if seconds, ok := parseFFmpegProgress(scanner.Text()); ok && onProgress != nil {
+			onProgress(seconds)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// parseFFmpegProgress 解析 ffmpeg -progress 输出中的 out_time_us（out_time_ms 实际也是微秒）
+func parseFFmpegProgress(line string) (float64, bool) {
+	key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+	if !found || (key != "out_time_us" && key != "out_time_ms") {
+		return 0, false
+	}
+	us, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || us < 0 {
+		return 0, false
+	}
+	return float64(us) / 1e6, true
+}
@@ -0,0 +1,265 @@
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+)
+
+func TestPostProcessProfileFollowsGlobalConfig(t *testing.T) {
+	video := &models.Video{ID: 1}
+	for _, tt := range []struct {
+		global string
+		want   string
+	}{
+		{"", ""},
+		{config.PostProcessNone, ""},
+		{config.PostProcessH264, config.PostProcessH264},
+	} {
+		cfg := &config.Config{Download: config.DownloadConfig{PostProcess: tt.global}}
+		if got := postProcessProfile(cfg, nil, video); got != tt.want {
+			t.Errorf("postProcessProfile(global %q) = %q, want %q", tt.global, got, tt.want)
+		}
+	}
+
+	dm := &DownloadManager{config: &config.Config{Download: config.DownloadConfig{
+		SkipPoster: true, SkipVideoNFO: true, SkipDanmaku: true, SkipSubtitle: true,
+		PostProcess: config.PostProcessAudioM4A,
+	}}}
+	files := dm.buildFileDetails(video).Files
+	if len(files) != 2 || files[1].Name != postProcessTaskName || files[1].Label != "提取音频" {
+		t.Errorf("expected postprocess file detail after video, got %+v", files)
+	}
+}
+
+func TestBuildPostProcessJobMKVEmbedsDanmakuAndSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"视频.mp4":               "video",
+		"视频.zh-CN.default.ass": "danmaku",
+		"视频.en-US.srt":         "en",
+		"视频.zh-CN.ai.srt":      "ai",
+		"视频.zh-CN.ass":         "", // 空文件不内嵌
+		"视频-poster.jpg":        "jpg",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	page := &models.Page{Path: filepath.Join(dir, "视频.mp4")}
+
+	job, err := buildPostProcessJob(config.PostProcessMKV, &models.Video{Name: "视频"}, page, dir, "视频")
+	if err != nil {
+		t.Fatalf("buildPostProcessJob: %v", err)
+	}
+	if job.format != "matroska" || job.output != filepath.Join(dir, "视频.mkv") || !job.replace {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	args := strings.Join(job.args, " ")
+	for _, want := range []string{
+		"-i " + filepath.Join(dir, "视频.zh-CN.default.ass") + " -map 1",
+		"-metadata:s:s:0 language=chi -metadata:s:s:0 title=弹幕",
+		"-metadata:s:s:1 language=eng -metadata:s:s:1 title=en-US",
+		"-metadata:s:s:2 language=chi -metadata:s:s:2 title=zh-CN.ai",
+		"-c copy",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+	if strings.Contains(args, "视频.zh-CN.ass") || strings.Contains(args, "-map 4") {
+		t.Errorf("empty subtitle should not be embedded: %q", args)
+	}
+}
+
+func TestBuildPostProcessJobTranscodeAndAudio(t *testing.T) {
+	dir := t.TempDir()
+	video := &models.Video{Name: "演奏合集", UpperName: "某UP主"}
+	page := &models.Page{Name: "第一首", Path: filepath.Join(dir, "演奏合集-P1.mp4"), AudioCodec: "flac", HDRFormat: models.HDRFormatHDR10}
+
+	job, err := buildPostProcessJob(config.PostProcessH264, video, page, dir, "演奏合集-P1")
+	if err != nil {
+		t.Fatalf("buildPostProcessJob(h264): %v", err)
+	}
+	args := strings.Join(job.args, " ")
+	for _, want := range []string{"-c:v libx264", "tonemap=tonemap=hable", "min(ih,1080)", "-c:a aac", "+faststart"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("h264 args %q missing %q", args, want)
+		}
+	}
+	if job.format != "mp4" || !job.replace {
+		t.Errorf("unexpected h264 job: %+v", job)
+	}
+
+	job, err = buildPostProcessJob(config.PostProcessAudioM4A, video, page, dir, "演奏合集-P1")
+	if err != nil {
+		t.Fatalf("buildPostProcessJob(audio): %v", err)
+	}
+	args = strings.Join(job.args, " ")
+	for _, want := range []string{"-map 0:a:0 -vn", "-c:a alac", "title=第一首", "album=演奏合集", "artist=某UP主"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("audio args %q missing %q", args, want)
+		}
+	}
+	if job.replace || job.output != filepath.Join(dir, "演奏合集-P1.m4a") {
+		t.Errorf("audio extraction should keep the video, got %+v", job)
+	}
+
+	if _, err := buildPostProcessJob(config.PostProcessMKV, video, &models.Page{}, dir, "x"); err == nil {
+		t.Error("expected error without video file")
+	}
+}
+
+func TestPostProcessDone(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "视频.m4a"), []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		profile string
+		page    models.Page
+		want    bool
+	}{
+		{config.PostProcessMKV, models.Page{Path: "/v/视频.mkv"}, true},
+		{config.PostProcessMKV, models.Page{Path: "/v/视频.mp4"}, false},
+		{config.PostProcessH264, models.Page{Path: "/v/视频.mp4", VideoCodec: "h264", Width: 1920, Height: 1080}, true},
+		{config.PostProcessH264, models.Page{Path: "/v/视频.mp4", VideoCodec: "h264", Width: 1080, Height: 1920}, true},
+		{config.PostProcessH264, models.Page{Path: "/v/视频.mp4", VideoCodec: "h264", Width: 3840, Height: 2160}, false},
+		{config.PostProcessH264, models.Page{Path: "/v/视频.mp4", VideoCodec: "hevc", Width: 1920, Height: 1080}, false},
+		{config.PostProcessH264, models.Page{Path: "/v/视频.mp4"}, false},
+		{config.PostProcessAudioM4A, models.Page{}, true},
+	}
+	for _, tt := range tests {
+		if got := postProcessDone(tt.profile, &tt.page, dir, "视频"); got != tt.want {
+			t.Errorf("postProcessDone(%s, %+v) = %v, want %v", tt.profile, tt.page, got, tt.want)
+		}
+	}
+	if postProcessDone(config.PostProcessAudioM4A, &models.Page{}, dir, "其他") {
+		t.Error("expected missing m4a to be reported as not done")
+	}
+}
+
+func TestParseFFmpegProgress(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   float64
+		wantOK bool
+	}{
+		{"out_time_us=12500000", 12.5, true},
+		{"out_time_ms=3000000", 3, true},
+		{"out_time_us=N/A", 0, false},
+		{"out_time=00:00:12.500000", 0, false},
+		{"progress=continue", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseFFmpegProgress(tt.line)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseFFmpegProgress(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFFmpegPoolLimitsConcurrency(t *testing.T) {
+	pool := newFFmpegPool(2)
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.acquire(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			defer pool.release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+
+	// 名额占满时取消等待
+	full := newFFmpegPool(0)
+	if full.size() != 1 {
+		t.Fatalf("size = %d, want at least 1", full.size())
+	}
+	_ = full.acquire(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := full.acquire(ctx); err == nil {
+		t.Error("expected acquire to fail after context cancellation")
+	}
+}
+
+func TestRepairPageMarksCompletedPostProcess(t *testing.T) {
+	dir := t.TempDir()
+	d := &Downloader{
+		config: &config.Config{Download: config.DownloadConfig{
+			SkipPoster: true, SkipSubtitle: true, SkipDanmaku: true, SkipVideoNFO: true,
+			PostProcess: config.PostProcessMKV,
+		}},
+		tracker: NewProgressTracker(),
+	}
+	video := &models.Video{ID: 1, BVid: "BV1xx411c7mD", Name: "封装测试", SinglePage: true}
+	page := &models.Page{ID: 2, PID: 1, CID: 100, Name: "P1"}
+	video.Pages = []models.Page{*page}
+
+	base := d.pageFileBaseName(video, page)
+	if err := os.WriteFile(filepath.Join(dir, base+".mkv"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.RepairPage(context.Background(), video, page, dir); err != nil {
+		t.Fatalf("RepairPage: %v", err)
+	}
+	st := d.tracker.GetVideo(video.ID).GetPage(page.PID).GetSubTask(postProcessTaskName)
+	if st == nil || st.Status != StatusSucceeded {
+		t.Errorf("expected existing MKV to mark postprocess succeeded, got %+v", st)
+	}
+}
+
+func TestUpdateConfigSwapsPostProcessPoolConcurrently(t *testing.T) {
+	d := &Downloader{config: &config.Config{}}
+	var wg sync.WaitGroup
+	// 分P下载读取进程池的同时，配置更新换池
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if pool := d.postProcessPool.Load(); pool != nil {
+					if err := pool.acquire(context.Background()); err != nil {
+						t.Error(err)
+						return
+					}
+					pool.release()
+				}
+			}
+		}()
+	}
+	for workers := 1; workers <= 4; workers++ {
+		d.UpdateConfig(&config.Config{Download: config.DownloadConfig{PostProcessWorkers: workers}})
+	}
+	wg.Wait()
+
+	if pool := d.postProcessPool.Load(); pool == nil || pool.size() != 4 {
+		t.Fatalf("expected pool of size 4 after update, got %v", pool)
+	}
+}
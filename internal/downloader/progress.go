@@ -1,8 +1,13 @@
 package downloader
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"bili-download/internal/database/models"
 )
 
 // DownloadStatus 下载状态枚举
@@ -48,6 +53,8 @@ type SubTaskProgress struct {
 	RetryCount     int            `json:"retry_count"`     // 重试次数
 	StartTime      time.Time      `json:"start_time"`      // 开始时间
 	EndTime        time.Time      `json:"end_time"`        // 结束时间
+	// Tracks 子轨道状态（字幕各语言轨道），为空时不更新
+	Tracks []models.FileTrack `json:"tracks,omitempty"`
 }
 
 // PageProgress 分P进度
@@ -203,6 +210,93 @@ func (v *VideoProgress) GetOverallProgress() float64 {
 	return totalProgress / float64(len(v.Pages))
 }
 
+// AggregateSubTask 汇总各分P同名子任务的进度，多P并行下载时作为视频整体的文件进度
+// 尚未开始的分P（含未登记的分P）按 0% 计入；全部分P结束后有失败即为失败。单P视频直接返回该分P子任务的副本
+func (v *VideoProgress) AggregateSubTask(name string) *SubTaskProgress {
+	v.mu.RLock()
+	pids := make([]int, 0, len(v.Pages))
+	for pid := range v.Pages {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	pages := make([]*PageProgress, 0, len(pids))
+	for _, pid := range pids {
+		pages = append(pages, v.Pages[pid])
+	}
+	expected := max(v.TotalPages, len(pages))
+	v.mu.RUnlock()
+
+	if expected == 1 && len(pages) == 1 {
+		return pages[0].GetSubTask(name)
+	}
+
+	agg := &SubTaskProgress{Name: name, Status: StatusPending}
+	var (
+		progressSum                float64
+		started, settled           int
+		failed, succeeded, skipped int
+		errs                       []string
+	)
+	for _, page := range pages {
+		task := page.GetSubTask(name)
+		if task == nil {
+			continue
+		}
+		started++
+		if agg.StartTime.IsZero() || (!task.StartTime.IsZero() && task.StartTime.Before(agg.StartTime)) {
+			agg.StartTime = task.StartTime
+		}
+		if task.EndTime.After(agg.EndTime) {
+			agg.EndTime = task.EndTime
+		}
+		agg.DownloadedSize += task.DownloadedSize
+		agg.TotalSize += task.TotalSize
+		agg.RetryCount += task.RetryCount
+		for _, track := range task.Tracks {
+			track.Label = fmt.Sprintf("P%d %s", page.PID, track.Label)
+			agg.Tracks = append(agg.Tracks, track)
+		}
+
+		switch task.Status {
+		case StatusSucceeded, StatusSkipped, StatusIgnored:
+			settled++
+			progressSum += 100
+			if task.Status == StatusSkipped {
+				skipped++
+			} else {
+				succeeded++
+			}
+		case StatusFailed, StatusFixedFailed:
+			settled++
+			failed++
+			progressSum += task.Progress
+			if task.Error != "" {
+				errs = append(errs, fmt.Sprintf("P%d: %s", page.PID, task.Error))
+			}
+		default:
+			progressSum += task.Progress
+			agg.Speed += task.Speed
+			agg.ETA = max(agg.ETA, task.ETA)
+		}
+	}
+
+	agg.Progress = progressSum / float64(expected)
+	agg.Error = strings.Join(errs, "; ")
+	switch {
+	case settled < expected:
+		if started > 0 {
+			agg.Status = StatusDownloading
+		}
+	case failed > 0:
+		agg.Status = StatusFailed
+	case succeeded > 0:
+		agg.Status = StatusSucceeded
+	case skipped > 0:
+		agg.Status = StatusSkipped
+	}
+	return agg
+}
+
 // IsCompleted 检查是否全部完成
 func (v *VideoProgress) IsCompleted() bool {
 	v.mu.RLock()
@@ -271,6 +365,19 @@ func (t *ProgressTracker) AddVideo(videoID uint, bvid, title string, totalPages
 	return progress
 }
 
+// EnsureVideo 获取视频进度，不存在时创建（多个分P并行下载时避免重复创建）
+func (t *ProgressTracker) EnsureVideo(videoID uint, bvid, title string, totalPages int) *VideoProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if progress, ok := t.videos[videoID]; ok {
+		return progress
+	}
+	progress := NewVideoProgress(videoID, bvid, title, totalPages)
+	t.videos[videoID] = progress
+	return progress
+}
+
 // GetVideo 获取视频进度
 func (t *ProgressTracker) GetVideo(videoID uint) *VideoProgress {
 	t.mu.RLock()
@@ -0,0 +1,141 @@
+package downloader
+
+import (
+	"fmt"
+	"time"
+
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// taskStore 下载队列持久化接口
+// 所有未结束的任务（排队中/运行中）都会写入存储，任务结束后删除
+type taskStore interface {
+	Save(task *DownloadTask) error
+	Delete(taskID string) error
+	Restore() ([]*DownloadTask, error)
+}
+
+// gormTaskStore 基于数据库的队列存储
+type gormTaskStore struct {
+	db *gorm.DB
+}
+
+// newTaskStore 创建队列存储，db 为空时返回 nil（不持久化）
+func newTaskStore(db *gorm.DB) taskStore {
+	if db == nil {
+		return nil
+	}
+	return &gormTaskStore{db: db}
+}
+
+// queueItemUpdateColumns 任务已持久化时需要覆盖的列（除主键、task_id 与 created_at 外的全部列）
+var queueItemUpdateColumns = []string{
+	"task_type", "priority", "video_id", "page_id", "record_id", "url",
+	"output_dir", "retry_count", "max_retries", "paused", "repair", "enqueued_at", "updated_at",
+}
+
+// Save 写入或更新任务
+func (s *gormTaskStore) Save(task *DownloadTask) error {
+	item := buildQueueItem(task)
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		DoUpdates: clause.AssignmentColumns(queueItemUpdateColumns),
+	}).Create(&item).Error
+}
+
+// Delete 删除任务
+func (s *gormTaskStore) Delete(taskID string) error {
+	return s.db.Where("task_id = ?", taskID).Delete(&models.DownloadQueueItem{}).Error
+}
+
+// Restore 读取全部持久化任务并重建为 DownloadTask（按入队时间排序）
+func (s *gormTaskStore) Restore() ([]*DownloadTask, error) {
+	var items []models.DownloadQueueItem
+	if err := s.db.Order("enqueued_at ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("查询持久化队列失败: %w", err)
+	}
+
+	tasks := make([]*DownloadTask, 0, len(items))
+	for _, item := range items {
+		var video models.Video
+		if err := s.db.Preload("Pages").First(&video, item.VideoID).Error; err != nil {
+			utils.Warn("恢复任务 %s 失败，视频 %d 不存在: %v", item.TaskID, item.VideoID, err)
+			s.Delete(item.TaskID)
+			continue
+		}
+
+		var page *models.Page
+		if TaskType(item.TaskType) == TaskTypePage {
+			if item.PageID == nil {
+				s.Delete(item.TaskID)
+				continue
+			}
+			for i := range video.Pages {
+				if video.Pages[i].ID == *item.PageID {
+					page = &video.Pages[i]
+					break
+				}
+			}
+			if page == nil {
+				utils.Warn("恢复任务 %s 失败，分P %d 不存在", item.TaskID, *item.PageID)
+				s.Delete(item.TaskID)
+				continue
+			}
+		}
+
+		tasks = append(tasks, restoreTaskFromItem(item, &video, page))
+	}
+
+	return tasks, nil
+}
+
+// buildQueueItem 将任务转换为持久化条目
+func buildQueueItem(task *DownloadTask) models.DownloadQueueItem {
+	task.mu.RLock()
+	defer task.mu.RUnlock()
+
+	item := models.DownloadQueueItem{
+		TaskID:     task.ID,
+		TaskType:   string(task.Type),
+		Priority:   int(task.Priority),
+		RecordID:   task.RecordID,
+		URL:        task.URL,
+		OutputDir:  task.OutputDir,
+		RetryCount: task.RetryCount,
+		MaxRetries: task.MaxRetries,
+		Repair:     task.Repair,
+		// 全局暂停不跨重启保留，被其中断的任务重启后直接恢复排队
+		Paused:     task.Status == TaskStatusPaused && !task.pausedGlobally,
+		EnqueuedAt: task.CreatedAt,
+		UpdatedAt:  time.Now(),
+	}
+	if task.Video != nil {
+		item.VideoID = task.Video.ID
+	}
+	if task.Page != nil {
+		pageID := task.Page.ID
+		item.PageID = &pageID
+	}
+	return item
+}
+
+// restoreTaskFromItem 根据持久化条目重建任务，保留原任务ID、优先级与入队时间
+func restoreTaskFromItem(item models.DownloadQueueItem, video *models.Video, page *models.Page) *DownloadTask {
+	task := NewDownloadTask(TaskType(item.TaskType), video, page, item.OutputDir)
+	task.ID = item.TaskID
+	task.Priority = TaskPriority(item.Priority)
+	task.RecordID = item.RecordID
+	task.URL = item.URL
+	task.RetryCount = item.RetryCount
+	task.MaxRetries = item.MaxRetries
+	task.Repair = item.Repair
+	task.CreatedAt = item.EnqueuedAt
+	if item.Paused {
+		task.Status = TaskStatusPaused
+	}
+	return task
+}
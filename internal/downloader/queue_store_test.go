@@ -0,0 +1,47 @@
+package downloader
+
+import (
+	"sync"
+	"testing"
+
+	"bili-download/internal/database/models"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestQueueItemUpdateColumnsCoverAllFields(t *testing.T) {
+	s, err := schema.Parse(&models.DownloadQueueItem{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	updated := make(map[string]bool, len(queueItemUpdateColumns))
+	for _, column := range queueItemUpdateColumns {
+		updated[column] = true
+	}
+	// 重复保存同一任务（如普通任务转为修复任务）时，除主键与创建时间外的列都应被覆盖
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.DBName == "id" || field.DBName == "task_id" || field.DBName == "created_at" {
+			continue
+		}
+		if !updated[field.DBName] {
+			t.Errorf("column %q is not updated on conflict", field.DBName)
+		}
+	}
+	if !updated["repair"] {
+		t.Error("expected repair to be updated on conflict")
+	}
+}
+
+func TestQueueItemRoundTripKeepsRepair(t *testing.T) {
+	video := &models.Video{ID: 1}
+	task := NewDownloadTask(TaskTypeVideo, video, nil, "/tmp/out")
+	task.Repair = true
+
+	item := buildQueueItem(task)
+	if !item.Repair {
+		t.Fatal("expected persisted item to keep repair flag")
+	}
+	if restored := restoreTaskFromItem(item, video, nil); !restored.Repair {
+		t.Error("expected restored task to keep repair flag")
+	}
+}
@@ -0,0 +1,580 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"bili-download/internal/config"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// errReorganizeSourceMissing 日志中的源文件与目标文件都不存在
+var errReorganizeSourceMissing = errors.New("源文件不存在")
+
+// ReorganizeMove 单个文件（或视频目录下的子目录）的移动
+type ReorganizeMove struct {
+	PageID *uint  `json:"page_id,omitempty"` // 分P视频文件对应的分P
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// ReorganizePlan 单个视频的重整计划
+type ReorganizePlan struct {
+	VideoID uint             `json:"video_id"`
+	BVid    string           `json:"bvid"`
+	Title   string           `json:"title"`
+	OldDir  string           `json:"old_dir"`
+	NewDir  string           `json:"new_dir"`
+	Moves   []ReorganizeMove `json:"moves"`
+	Skipped string           `json:"skipped,omitempty"` // 无法重整的原因，为空表示可以执行
+}
+
+// ReorganizePreview 媒体库重整试运行结果
+type ReorganizePreview struct {
+	Total     int               `json:"total"`     // 已下载视频总数
+	Unchanged int               `json:"unchanged"` // 已符合当前命名模板的视频数
+	Changed   int               `json:"changed"`   // 需要移动文件的视频数
+	Skipped   int               `json:"skipped"`   // 无法重整的视频数
+	Pending   int64             `json:"pending"`   // 上次中断后尚未完成的日志条目数
+	Plans     []*ReorganizePlan `json:"plans"`     // 需要移动或无法重整的视频
+}
+
+// ReorganizeResult 媒体库重整执行结果
+type ReorganizeResult struct {
+	Resumed int // 从日志继续完成的视频数
+	Moved   int // 完成重整的视频数
+	Skipped int // 无法重整的视频数
+	Failed  int // 移动失败并已回滚的视频数
+}
+
+// Reorganizer 媒体库重整：按当前命名模板移动已下载视频的文件，并同步更新 video.path / page.path
+// 每个视频的移动先写入 reorganize_journal，全部完成后再更新数据库并删除日志，中断后可继续完成
+type Reorganizer struct {
+	cfg *config.Config
+	db  *gorm.DB
+}
+
+// NewReorganizer 创建媒体库重整器
+func NewReorganizer(cfg *config.Config, db *gorm.DB) *Reorganizer {
+	return &Reorganizer{cfg: cfg, db: db}
+}
+
+// Preview 计算所有已下载视频的重整计划，不修改文件与数据库
+func (r *Reorganizer) Preview() (*ReorganizePreview, error) {
+	plans, err := r.plans()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ReorganizePreview{Total: len(plans), Plans: make([]*ReorganizePlan, 0)}
+	for _, plan := range plans {
+		switch {
+		case plan.Skipped != "":
+			preview.Skipped++
+		case len(plan.Moves) > 0:
+			preview.Changed++
+		default:
+			preview.Unchanged++
+			continue
+		}
+		preview.Plans = append(preview.Plans, plan)
+	}
+	r.db.Model(&models.ReorganizeJournal{}).Count(&preview.Pending)
+	return preview, nil
+}
+
+// Run 先完成上次中断的重整，再按当前命名模板重整所有已下载视频
+func (r *Reorganizer) Run(ctx context.Context) (*ReorganizeResult, error) {
+	result := &ReorganizeResult{}
+
+	resumed, err := r.Resume()
+	result.Resumed = resumed
+	if err != nil {
+		return result, err
+	}
+
+	plans, err := r.plans()
+	if err != nil {
+		return result, err
+	}
+
+	for _, plan := range plans {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if plan.Skipped != "" {
+			utils.Warn("媒体库重整: 跳过 %s [%s]: %s", plan.Title, plan.BVid, plan.Skipped)
+			result.Skipped++
+			continue
+		}
+		if len(plan.Moves) == 0 {
+			continue
+		}
+		if err := r.apply(plan); err != nil {
+			utils.Warn("媒体库重整: %s [%s] 失败: %v", plan.Title, plan.BVid, err)
+			result.Failed++
+			continue
+		}
+		utils.Info("媒体库重整: %s [%s] %s -> %s（%d 个文件）", plan.Title, plan.BVid, plan.OldDir, plan.NewDir, len(plan.Moves))
+		result.Moved++
+	}
+	return result, nil
+}
+
+// Resume 按日志继续完成上次中断的重整，返回完成的视频数
+func (r *Reorganizer) Resume() (int, error) {
+	var entries []models.ReorganizeJournal
+	if err := r.db.Order("id").Find(&entries).Error; err != nil {
+		return 0, fmt.Errorf("读取重整日志失败: %w", err)
+	}
+
+	order := make([]uint, 0)
+	grouped := make(map[uint][]models.ReorganizeJournal)
+	for _, entry := range entries {
+		if _, ok := grouped[entry.VideoID]; !ok {
+			order = append(order, entry.VideoID)
+		}
+		grouped[entry.VideoID] = append(grouped[entry.VideoID], entry)
+	}
+
+	resumed := 0
+	for _, videoID := range order {
+		if err := r.execute(videoID, grouped[videoID], false); err != nil {
+			return resumed, fmt.Errorf("继续重整视频 %d 失败: %w", videoID, err)
+		}
+		utils.Info("媒体库重整: 已继续完成视频 %d 的重整", videoID)
+		resumed++
+	}
+	return resumed, nil
+}
+
+// plans 计算所有已下载的B站视频的重整计划
+func (r *Reorganizer) plans() ([]*ReorganizePlan, error) {
+	var videos []models.Video
+	err := r.db.Preload("Pages").
+		Where("download_status <> 0 AND media_kind = ? AND path <> '' AND bvid LIKE ?", "video", "BV%").
+		Order("id").
+		Find(&videos).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询已下载视频失败: %w", err)
+	}
+
+	var queued []uint
+	r.db.Model(&models.DownloadQueueItem{}).Pluck("video_id", &queued)
+	busy := make(map[uint]bool, len(queued))
+	for _, id := range queued {
+		busy[id] = true
+	}
+
+	var journaled []uint
+	r.db.Model(&models.ReorganizeJournal{}).Distinct("video_id").Pluck("video_id", &journaled)
+	for _, id := range journaled {
+		busy[id] = true
+	}
+
+	plans := make([]*ReorganizePlan, 0, len(videos))
+	for i := range videos {
+		video := &videos[i]
+		if !filepath.IsAbs(video.Path) {
+			video.Path = filepath.Join(r.cfg.Paths.DownloadBase, video.Path)
+		}
+
+		if busy[video.ID] {
+			plans = append(plans, &ReorganizePlan{
+				VideoID: video.ID,
+				BVid:    video.BVid,
+				Title:   video.Name,
+				OldDir:  video.Path,
+				NewDir:  video.Path,
+				Skipped: "视频正在下载或存在未完成的重整",
+			})
+			continue
+		}
+
+		sourceName, sourceDir := lookupVideoSource(r.db, video)
+		baseDir := reorganizeBaseDir(video.Path, sourceDir, r.cfg.Paths.DownloadBase)
+		plans = append(plans, PlanVideoReorganize(r.cfg, video, baseDir, sourceName))
+	}
+	return plans, nil
+}
+
+// apply 写入日志后执行单个视频的重整
+func (r *Reorganizer) apply(plan *ReorganizePlan) error {
+	entries := make([]models.ReorganizeJournal, 0, len(plan.Moves)+1)
+	if plan.OldDir != plan.NewDir {
+		entries = append(entries, models.ReorganizeJournal{
+			VideoID:  plan.VideoID,
+			Kind:     models.ReorganizeKindDir,
+			FromPath: plan.OldDir,
+			ToPath:   plan.NewDir,
+			Status:   models.ReorganizeStatusPending,
+		})
+	}
+	for _, move := range plan.Moves {
+		entries = append(entries, models.ReorganizeJournal{
+			VideoID:  plan.VideoID,
+			PageID:   move.PageID,
+			Kind:     models.ReorganizeKindFile,
+			FromPath: move.From,
+			ToPath:   move.To,
+			Status:   models.ReorganizeStatusPending,
+		})
+	}
+
+	if err := r.db.Create(&entries).Error; err != nil {
+		return fmt.Errorf("写入重整日志失败: %w", err)
+	}
+	return r.execute(plan.VideoID, entries, true)
+}
+
+// execute 按日志移动文件并更新数据库
+// rollback 为 true 时移动失败会撤销已完成的移动并删除日志；为 false 时保留日志以便再次继续
+func (r *Reorganizer) execute(videoID uint, entries []models.ReorganizeJournal, rollback bool) error {
+	var dirEntry *models.ReorganizeJournal
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Kind == models.ReorganizeKindDir {
+			dirEntry = entry
+			continue
+		}
+		if entry.Status == models.ReorganizeStatusMoved {
+			continue
+		}
+
+		if err := moveJournaledFile(entry.FromPath, entry.ToPath); err != nil {
+			// 继续中断的重整时文件可能已被手动处理，跳过缺失的文件
+			if !rollback && errors.Is(err, errReorganizeSourceMissing) {
+				utils.Warn("媒体库重整: %v，跳过", err)
+				continue
+			}
+			if rollback {
+				r.rollback(videoID, entries[:i])
+			}
+			return err
+		}
+		entry.Status = models.ReorganizeStatusMoved
+		if err := r.db.Model(entry).Update("status", entry.Status).Error; err != nil {
+			utils.Warn("更新重整日志状态失败: %v", err)
+		}
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if dirEntry != nil {
+			if err := tx.Model(&models.Video{}).Where("id = ?", videoID).Update("path", dirEntry.ToPath).Error; err != nil {
+				return err
+			}
+		}
+		for _, entry := range entries {
+			if entry.PageID == nil {
+				continue
+			}
+			if err := tx.Model(&models.Page{}).Where("id = ?", *entry.PageID).Update("path", entry.ToPath).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("video_id = ?", videoID).Delete(&models.ReorganizeJournal{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("更新数据库失败: %w", err)
+	}
+
+	if dirEntry != nil {
+		removeEmptyDirs(dirEntry.FromPath, dirEntry.ToPath)
+	}
+	return nil
+}
+
+// rollback 撤销已完成的移动并删除日志
+func (r *Reorganizer) rollback(videoID uint, entries []models.ReorganizeJournal) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Kind != models.ReorganizeKindFile || entry.Status != models.ReorganizeStatusMoved {
+			continue
+		}
+		if err := moveFile(entry.ToPath, entry.FromPath); err != nil {
+			utils.Error("回滚重整失败 %s -> %s: %v", entry.ToPath, entry.FromPath, err)
+			return
+		}
+	}
+	for _, entry := range entries {
+		if entry.Kind == models.ReorganizeKindDir {
+			removeEmptyDirs(entry.ToPath, entry.FromPath)
+		}
+	}
+	if err := r.db.Where("video_id = ?", videoID).Delete(&models.ReorganizeJournal{}).Error; err != nil {
+		utils.Warn("删除重整日志失败: %v", err)
+	}
+}
+
+// PlanVideoReorganize 计算视频按当前命名模板重整所需的文件移动，不修改文件
+// video 需预加载 Pages，video.Path 为当前视频目录，baseDir 为视频源目录
+func PlanVideoReorganize(cfg *config.Config, video *models.Video, baseDir, sourceName string) *ReorganizePlan {
+	plan := &ReorganizePlan{
+		VideoID: video.ID,
+		BVid:    video.BVid,
+		Title:   video.Name,
+		OldDir:  video.Path,
+		NewDir:  filepath.Join(baseDir, VideoFolderName(cfg, video, sourceName)),
+		Moves:   make([]ReorganizeMove, 0),
+	}
+
+	if plan.OldDir == "" {
+		plan.Skipped = "未记录视频目录"
+		return plan
+	}
+	dirEntries, err := os.ReadDir(plan.OldDir)
+	if err != nil {
+		plan.Skipped = "视频目录不存在"
+		return plan
+	}
+	if plan.OldDir != plan.NewDir && (isSubPath(plan.OldDir, plan.NewDir) || isSubPath(plan.NewDir, plan.OldDir)) {
+		plan.Skipped = "新旧视频目录存在包含关系"
+		return plan
+	}
+
+	files := make([]string, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	// 定位各分P当前的文件名前缀
+	type pageBase struct {
+		page    *models.Page
+		oldBase string
+		newBase string
+	}
+	bases := make([]pageBase, 0, len(video.Pages))
+	claimed := make(map[string]bool)
+	for i := range video.Pages {
+		page := &video.Pages[i]
+		oldBase := findPageFileBase(cfg, video, page, sourceName, files, claimed)
+		if oldBase == "" {
+			continue
+		}
+		claimed[oldBase] = true
+		bases = append(bases, pageBase{page: page, oldBase: oldBase, newBase: PageFileBaseName(cfg, video, page, sourceName)})
+	}
+
+	targets := make(map[string]bool)
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		newName := name
+		var pageID *uint
+
+		// 同一文件可能匹配多个前缀（如 标题-P1 与 标题-P1-花絮），取最长的前缀
+		var matched *pageBase
+		for i := range bases {
+			if entry.IsDir() || !hasFileBase(name, bases[i].oldBase) {
+				continue
+			}
+			if matched == nil || len(bases[i].oldBase) > len(matched.oldBase) {
+				matched = &bases[i]
+			}
+		}
+		if matched != nil {
+			suffix := name[len(matched.oldBase):]
+			newName = matched.newBase + suffix
+			if isVideoFileExt(suffix) {
+				id := matched.page.ID
+				pageID = &id
+			}
+		}
+
+		from := filepath.Join(plan.OldDir, name)
+		to := filepath.Join(plan.NewDir, newName)
+		if from == to {
+			continue
+		}
+		if targets[to] {
+			plan.Skipped = fmt.Sprintf("多个文件的目标文件名相同: %s", newName)
+			return plan
+		}
+		targets[to] = true
+
+		if info, err := os.Stat(to); err == nil {
+			// 大小写不敏感的文件系统上仅改变大小写时目标即源文件
+			if source, err := os.Stat(from); err != nil || !os.SameFile(info, source) {
+				plan.Skipped = fmt.Sprintf("目标文件已存在: %s", to)
+				return plan
+			}
+		}
+		plan.Moves = append(plan.Moves, ReorganizeMove{PageID: pageID, From: from, To: to})
+	}
+	return plan
+}
+
+// findPageFileBase 在视频目录中定位分P当前的文件名前缀
+// 依次尝试 page.path 记录的文件、默认命名、当前命名模板；单P视频目录中只有一个视频文件时直接使用该文件
+func findPageFileBase(cfg *config.Config, video *models.Video, page *models.Page, sourceName string, files []string, claimed map[string]bool) string {
+	candidates := make([]string, 0, 3)
+	if page.Path != "" {
+		name := filepath.Base(page.Path)
+		candidates = append(candidates, strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	candidates = append(candidates,
+		PageFileBaseName(nil, video, page, sourceName),
+		PageFileBaseName(cfg, video, page, sourceName),
+	)
+
+	for _, candidate := range candidates {
+		if claimed[candidate] {
+			continue
+		}
+		for _, name := range files {
+			if strings.HasPrefix(name, candidate) && isVideoFileExt(name[len(candidate):]) {
+				return candidate
+			}
+		}
+	}
+
+	if !video.SinglePage && len(video.Pages) > 1 {
+		return ""
+	}
+	var found string
+	for _, name := range files {
+		ext := filepath.Ext(name)
+		if !isVideoFileExt(ext) {
+			continue
+		}
+		if found != "" {
+			return ""
+		}
+		found = strings.TrimSuffix(name, ext)
+	}
+	return found
+}
+
+// hasFileBase 文件名是否以给定前缀开头且前缀后紧跟扩展名或 -xxx 后缀（如 -poster.jpg）
+func hasFileBase(name, base string) bool {
+	if !strings.HasPrefix(name, base) || len(name) == len(base) {
+		return false
+	}
+	next := name[len(base)]
+	return next == '.' || next == '-'
+}
+
+// isVideoFileExt 后缀是否为视频文件扩展名
+func isVideoFileExt(suffix string) bool {
+	switch strings.ToLower(suffix) {
+	case ".mp4", ".mkv", ".webm", ".flv", ".avi", ".m4v":
+		return true
+	}
+	return false
+}
+
+// isSubPath child 是否位于 parent 目录内
+func isSubPath(parent, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// reorganizeBaseDir 确定视频目录所在的视频源目录
+// 优先取包含当前视频目录的视频源目录或下载根目录，都不包含时取视频目录的上级目录
+func reorganizeBaseDir(videoDir string, candidates ...string) string {
+	best := ""
+	for _, candidate := range candidates {
+		if candidate == "" || !isSubPath(candidate, videoDir) {
+			continue
+		}
+		if len(candidate) > len(best) {
+			best = candidate
+		}
+	}
+	if best == "" {
+		return filepath.Dir(videoDir)
+	}
+	return filepath.Clean(best)
+}
+
+// removeEmptyDirs 删除重整后留下的空目录，逐级向上直到遇到非空目录或新目录的上级目录
+func removeEmptyDirs(dir, keep string) {
+	for dir != "" && dir != "." && dir != string(filepath.Separator) {
+		if dir == keep || isSubPath(dir, keep) {
+			return
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			return
+		}
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// moveJournaledFile 按日志移动文件，已移动过（源不存在而目标存在）时视为完成
+func moveJournaledFile(from, to string) error {
+	if _, err := os.Lstat(from); os.IsNotExist(err) {
+		if _, err := os.Lstat(to); err == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", errReorganizeSourceMissing, from)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := moveFile(from, to); err != nil {
+		return fmt.Errorf("移动 %s 失败: %w", from, err)
+	}
+	return nil
+}
+
+// moveFile 移动文件，跨设备时先复制到目标目录的临时文件再改名，保证目标文件完整
+func moveFile(from, to string) error {
+	err := os.Rename(from, to)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, statErr := os.Stat(from)
+	if statErr != nil {
+		return statErr
+	}
+	if info.IsDir() {
+		return err
+	}
+
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := to + ".reorganize.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+
+	if err := os.Rename(tmp, to); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	in.Close()
+	return os.Remove(from)
+}
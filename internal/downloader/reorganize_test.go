@@ -0,0 +1,173 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"bili-download/internal/database/models"
+)
+
+func writeReorganizeTestFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func reorganizeMoveTargets(plan *ReorganizePlan) []string {
+	targets := make([]string, 0, len(plan.Moves))
+	for _, move := range plan.Moves {
+		targets = append(targets, filepath.Base(move.To))
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+func TestPlanVideoReorganizeRenamesMultiPageFiles(t *testing.T) {
+	base := t.TempDir()
+	oldDir := filepath.Join(base, "合集")
+	writeReorganizeTestFiles(t, oldDir,
+		"合集-P1.mp4", "合集-P1.nfo", "合集-P1-poster.jpg", "合集-P1.zh-CN.default.ass",
+		"合集-P1-花絮.mkv", "合集-P1-花絮.nfo",
+		"tvshow.nfo",
+	)
+
+	cfg := namingTestConfig("{{upper_name}}/{{title}}", "{{pid}} - {{ptitle}}")
+	video := &models.Video{
+		ID: 1, BVid: "BV1xx411c7mD", Name: "合集", UpperName: "UP", Path: oldDir,
+		Pages: []models.Page{{ID: 11, PID: 1, Name: "P1"}, {ID: 12, PID: 2, Name: "P1-花絮"}},
+	}
+
+	plan := PlanVideoReorganize(cfg, video, base, "")
+	if plan.Skipped != "" {
+		t.Fatalf("unexpected skip: %s", plan.Skipped)
+	}
+	if want := filepath.Join(base, "UP", "合集"); plan.NewDir != want {
+		t.Fatalf("expected new dir %q, got %q", want, plan.NewDir)
+	}
+
+	want := []string{
+		"1 - P1-poster.jpg", "1 - P1.mp4", "1 - P1.nfo", "1 - P1.zh-CN.default.ass",
+		"2 - P1-花絮.mkv", "2 - P1-花絮.nfo",
+		"tvshow.nfo",
+	}
+	sort.Strings(want)
+	got := reorganizeMoveTargets(plan)
+	if len(got) != len(want) {
+		t.Fatalf("expected targets %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected targets %v, got %v", want, got)
+		}
+	}
+
+	for _, move := range plan.Moves {
+		switch filepath.Base(move.To) {
+		case "1 - P1.mp4":
+			if move.PageID == nil || *move.PageID != 11 {
+				t.Fatalf("expected page 11 for %s, got %v", move.To, move.PageID)
+			}
+		case "2 - P1-花絮.mkv":
+			if move.PageID == nil || *move.PageID != 12 {
+				t.Fatalf("expected page 12 for %s, got %v", move.To, move.PageID)
+			}
+		default:
+			if move.PageID != nil {
+				t.Fatalf("expected no page for %s", move.To)
+			}
+		}
+	}
+}
+
+func TestPlanVideoReorganizeUsesRecordedPagePath(t *testing.T) {
+	base := t.TempDir()
+	oldDir := filepath.Join(base, "BV1xx411c7mD")
+	writeReorganizeTestFiles(t, oldDir, "旧名称.mp4", "旧名称-poster.jpg")
+
+	cfg := namingTestConfig("{{title}}", "{{title}}")
+	video := &models.Video{
+		ID: 1, BVid: "BV1xx411c7mD", Name: "新标题", SinglePage: true, Path: oldDir,
+		Pages: []models.Page{{ID: 11, PID: 1, Name: "新标题", Path: filepath.Join(oldDir, "旧名称.mp4")}},
+	}
+
+	plan := PlanVideoReorganize(cfg, video, base, "")
+	got := reorganizeMoveTargets(plan)
+	if len(got) != 2 || got[0] != "新标题-poster.jpg" || got[1] != "新标题.mp4" {
+		t.Fatalf("unexpected targets: %v", got)
+	}
+	if plan.NewDir != filepath.Join(base, "新标题") {
+		t.Fatalf("unexpected new dir: %q", plan.NewDir)
+	}
+}
+
+func TestPlanVideoReorganizeUnchangedAndConflicts(t *testing.T) {
+	base := t.TempDir()
+	oldDir := filepath.Join(base, "标题")
+	writeReorganizeTestFiles(t, oldDir, "标题.mp4", "标题.nfo")
+
+	video := &models.Video{
+		ID: 1, BVid: "BV1xx411c7mD", Name: "标题", SinglePage: true, Path: oldDir,
+		Pages: []models.Page{{ID: 11, PID: 1, Name: "标题"}},
+	}
+
+	plan := PlanVideoReorganize(namingTestConfig("{{title}}", "{{title}}"), video, base, "")
+	if plan.Skipped != "" || len(plan.Moves) != 0 {
+		t.Fatalf("expected unchanged plan, got %+v", plan)
+	}
+
+	writeReorganizeTestFiles(t, filepath.Join(base, "BV1xx411c7mD"), "标题.mp4")
+	plan = PlanVideoReorganize(namingTestConfig("{{bvid}}", "{{title}}"), video, base, "")
+	if plan.Skipped == "" {
+		t.Fatalf("expected conflict to skip plan, got %+v", plan)
+	}
+
+	plan = PlanVideoReorganize(namingTestConfig("{{title}}/{{bvid}}", "{{title}}"), video, base, "")
+	if plan.Skipped == "" {
+		t.Fatalf("expected nested directories to skip plan, got %+v", plan)
+	}
+}
+
+func TestMoveJournaledFileIsIdempotent(t *testing.T) {
+	base := t.TempDir()
+	from := filepath.Join(base, "old", "a.mp4")
+	to := filepath.Join(base, "new", "sub", "a.mp4")
+	writeReorganizeTestFiles(t, filepath.Dir(from), "a.mp4")
+
+	if err := moveJournaledFile(from, to); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if _, err := os.Stat(to); err != nil {
+		t.Fatalf("expected target to exist: %v", err)
+	}
+	// 中断后继续时源已不存在而目标存在，视为已完成
+	if err := moveJournaledFile(from, to); err != nil {
+		t.Fatalf("expected resumed move to succeed, got %v", err)
+	}
+
+	removeEmptyDirs(filepath.Dir(from), filepath.Dir(to))
+	if _, err := os.Stat(filepath.Dir(from)); !os.IsNotExist(err) {
+		t.Fatalf("expected empty old dir to be removed, got %v", err)
+	}
+	if _, err := os.Stat(base); err != nil {
+		t.Fatalf("expected common parent to be kept: %v", err)
+	}
+}
+
+func TestReorganizeBaseDirPrefersContainingSourceDir(t *testing.T) {
+	videoDir := filepath.Join("/data", "fav", "UP", "标题")
+
+	if got := reorganizeBaseDir(videoDir, filepath.Join("/data", "fav"), "/data"); got != filepath.Join("/data", "fav") {
+		t.Fatalf("expected source dir, got %q", got)
+	}
+	if got := reorganizeBaseDir(videoDir, filepath.Join("/other"), ""); got != filepath.Join("/data", "fav", "UP") {
+		t.Fatalf("expected parent dir fallback, got %q", got)
+	}
+}
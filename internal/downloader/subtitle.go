@@ -0,0 +1,251 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+)
+
+// downloadSubtitles 通过B站接口下载分P的全部字幕轨道（含AI字幕），并转换为 SRT/ASS
+// 每个轨道的结果记录在子任务的 Tracks 中，文件名带 Jellyfin 可识别的语言后缀
+func (d *Downloader) downloadSubtitles(ctx context.Context, video *models.Video, page *models.Page, outputDir string, pageProgress *PageProgress) error {
+	pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
+		task.Status = StatusDownloading
+		task.StartTime = time.Now()
+	})
+
+	defer func() {
+		pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
+			task.EndTime = time.Now()
+		})
+		d.tracker.NotifyProgress(video.ID, page.PID, "subtitle", pageProgress.GetSubTask("subtitle"))
+	}()
+
+	metadata, err := d.biliClient.GetDanmakuMetadata(ctx, page.CID, video.BVid)
+	if err != nil {
+		pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
+			task.Status = StatusFailed
+			task.Error = err.Error()
+		})
+		return fmt.Errorf("获取字幕列表失败: %w", err)
+	}
+
+	subtitles := metadata.Subtitle.Subtitles
+	if len(subtitles) == 0 {
+		pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
+			task.Status = StatusSkipped
+			task.Tracks = []models.FileTrack{}
+		})
+		return nil
+	}
+
+	formats := d.config.Download.SubtitleFormats
+	if len(formats) == 0 {
+		formats = []string{"srt"}
+	}
+
+	baseName := d.pageFileBaseName(video, page)
+	usedSuffixes := make(map[string]bool)
+	tracks := make([]models.FileTrack, 0, len(subtitles))
+	var succeeded, failed int
+
+	for _, sub := range subtitles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		track := models.FileTrack{Name: sub.Lan, Label: sub.LanDoc}
+
+		if !subtitleLangAllowed(sub.Lan, d.config.Download.SubtitleLangs) {
+			track.Status = string(StatusSkipped)
+			track.Error = "不在字幕语言白名单中"
+			tracks = append(tracks, track)
+			continue
+		}
+
+		content, err := d.biliClient.GetSubtitleContent(ctx, sub.SubtitleURL)
+		if err != nil {
+			track.Status = string(StatusFailed)
+			track.Error = err.Error()
+			tracks = append(tracks, track)
+			failed++
+			utils.Warn("下载字幕失败 [%s]: %v", sub.Lan, err)
+			continue
+		}
+		if len(content.Body) == 0 {
+			track.Status = string(StatusSkipped)
+			track.Error = "字幕内容为空"
+			tracks = append(tracks, track)
+			continue
+		}
+
+		suffix := subtitleFileSuffix(sub)
+		if usedSuffixes[suffix] {
+			suffix = fmt.Sprintf("%s.%d", suffix, sub.ID)
+		}
+		usedSuffixes[suffix] = true
+
+		if err := d.writeSubtitleFiles(outputDir, baseName+"."+suffix, formats, content.Body, page); err != nil {
+			track.Status = string(StatusFailed)
+			track.Error = err.Error()
+			tracks = append(tracks, track)
+			failed++
+			utils.Warn("写入字幕失败 [%s]: %v", sub.Lan, err)
+			continue
+		}
+
+		track.Status = string(StatusSucceeded)
+		tracks = append(tracks, track)
+		succeeded++
+	}
+
+	pageProgress.UpdateSubTask("subtitle", func(task *SubTaskProgress) {
+		task.Tracks = tracks
+		switch {
+		case failed > 0:
+			task.Status = StatusFailed
+			task.Error = fmt.Sprintf("%d 个字幕轨道下载失败", failed)
+		case succeeded > 0:
+			task.Status = StatusSucceeded
+			task.Progress = 100
+		default:
+			task.Status = StatusSkipped
+		}
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个字幕轨道下载失败", failed)
+	}
+
+	utils.Info("字幕下载完成: %s (共 %d 个轨道)", baseName, succeeded)
+	return nil
+}
+
+// writeSubtitleFiles 按配置的格式写出字幕文件
+func (d *Downloader) writeSubtitleFiles(outputDir, name string, formats []string, lines []bilibili.SubtitleLine, page *models.Page) error {
+	for _, format := range formats {
+		var content string
+		switch format {
+		case "srt":
+			content = subtitleToSRT(lines)
+		case "ass":
+			content = subtitleToASS(lines, d.config.Danmaku.FontName, page.Width, page.Height)
+		default:
+			continue
+		}
+
+		path := filepath.Join(outputDir, name+"."+format)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("写入字幕文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// subtitleLangAllowed 检查字幕语言是否在白名单中（白名单为空时全部允许）
+func subtitleLangAllowed(lan string, whitelist []string) bool {
+	if len(whitelist) == 0 {
+		return true
+	}
+	for _, allowed := range whitelist {
+		if strings.EqualFold(strings.TrimSpace(allowed), lan) {
+			return true
+		}
+	}
+	return false
+}
+
+// subtitleFileSuffix 字幕文件名中的语言后缀
+// 人工字幕直接使用语言代码（如 zh-CN、en-US）；AI 字幕去掉 ai- 前缀并追加 .ai 标记（如 zh-CN.ai）
+func subtitleFileSuffix(sub bilibili.SubtitleLan) string {
+	tag := strings.TrimPrefix(sub.Lan, "ai-")
+	if tag == "zh" {
+		tag = "zh-CN"
+	}
+	if tag == "" {
+		tag = "und"
+	}
+	if sub.IsAI() {
+		return tag + ".ai"
+	}
+	return tag
+}
+
+// subtitleToSRT 将B站 JSON 字幕转换为 SRT 格式
+func subtitleToSRT(lines []bilibili.SubtitleLine) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatSRTTime(line.From),
+			formatSRTTime(line.To),
+			strings.TrimSpace(line.Content))
+	}
+	return sb.String()
+}
+
+// subtitleToASS 将B站 JSON 字幕转换为 ASS 格式
+func subtitleToASS(lines []bilibili.SubtitleLine, fontName string, width, height int) string {
+	if width <= 0 || height <= 0 {
+		width, height = 1920, 1080
+	}
+	if fontName == "" {
+		fontName = "Microsoft YaHei"
+	}
+	fontSize := height / 18
+
+	var sb strings.Builder
+	sb.WriteString("[Script Info]\n")
+	sb.WriteString("ScriptType: v4.00+\n")
+	fmt.Fprintf(&sb, "PlayResX: %d\n", width)
+	fmt.Fprintf(&sb, "PlayResY: %d\n", height)
+	sb.WriteString("WrapStyle: 0\n")
+	sb.WriteString("ScaledBorderAndShadow: yes\n\n")
+
+	sb.WriteString("[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(&sb, "Style: Default,%s,%d,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,20,20,%d,1\n\n",
+		fontName, fontSize, height/20)
+
+	sb.WriteString("[Events]\n")
+	sb.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, line := range lines {
+		text := strings.ReplaceAll(strings.TrimSpace(line.Content), "\n", "\\N")
+		// B站 location 与 ASS 的 \an 对齐方式一致（小键盘布局），2 为默认的底部居中
+		if line.Location > 0 && line.Location <= 9 && line.Location != 2 {
+			text = fmt.Sprintf("{\\an%d}%s", line.Location, text)
+		}
+		fmt.Fprintf(&sb, "Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n",
+			formatASSTime(line.From),
+			formatASSTime(line.To),
+			text)
+	}
+
+	return sb.String()
+}
+
+// formatSRTTime 格式化 SRT 时间（HH:MM:SS,mmm）
+func formatSRTTime(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// formatASSTime 格式化 ASS 时间（H:MM:SS.cc）
+func formatASSTime(seconds float64) string {
+	cs := int64(math.Round(seconds * 100))
+	if cs < 0 {
+		cs = 0
+	}
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
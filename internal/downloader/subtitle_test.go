@@ -0,0 +1,69 @@
+package downloader
+
+import (
+	"strings"
+	"testing"
+
+	"bili-download/internal/bilibili"
+)
+
+func TestSubtitleToSRT(t *testing.T) {
+	lines := []bilibili.SubtitleLine{
+		{From: 0.5, To: 2.25, Location: 2, Content: "第一行"},
+		{From: 3661.001, To: 3662, Location: 2, Content: "第二行"},
+	}
+
+	got := subtitleToSRT(lines)
+	want := "1\n00:00:00,500 --> 00:00:02,250\n第一行\n\n2\n01:01:01,001 --> 01:01:02,000\n第二行\n\n"
+	if got != want {
+		t.Fatalf("unexpected srt output:\n%s", got)
+	}
+}
+
+func TestSubtitleToASSKeepsPositionAndLineBreaks(t *testing.T) {
+	lines := []bilibili.SubtitleLine{
+		{From: 1, To: 2.5, Location: 2, Content: "底部\n换行"},
+		{From: 3, To: 4, Location: 8, Content: "顶部"},
+	}
+
+	got := subtitleToASS(lines, "", 0, 0)
+	if !strings.Contains(got, "PlayResX: 1920") {
+		t.Fatalf("expected default resolution, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,底部\\N换行") {
+		t.Fatalf("expected bottom line with ASS line break, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,{\\an8}顶部") {
+		t.Fatalf("expected top aligned line, got:\n%s", got)
+	}
+}
+
+func TestSubtitleFileSuffix(t *testing.T) {
+	tests := []struct {
+		sub  bilibili.SubtitleLan
+		want string
+	}{
+		{bilibili.SubtitleLan{Lan: "zh-CN"}, "zh-CN"},
+		{bilibili.SubtitleLan{Lan: "en-US"}, "en-US"},
+		{bilibili.SubtitleLan{Lan: "ai-zh", Type: 1}, "zh-CN.ai"},
+		{bilibili.SubtitleLan{Lan: "ai-en"}, "en.ai"},
+	}
+
+	for _, tt := range tests {
+		if got := subtitleFileSuffix(tt.sub); got != tt.want {
+			t.Errorf("subtitleFileSuffix(%q) = %q, want %q", tt.sub.Lan, got, tt.want)
+		}
+	}
+}
+
+func TestSubtitleLangAllowed(t *testing.T) {
+	if !subtitleLangAllowed("ai-zh", nil) {
+		t.Fatal("expected empty whitelist to allow all languages")
+	}
+	if !subtitleLangAllowed("zh-CN", []string{"en-US", "zh-cn"}) {
+		t.Fatal("expected whitelist match to be case-insensitive")
+	}
+	if subtitleLangAllowed("ai-zh", []string{"zh-CN"}) {
+		t.Fatal("expected AI subtitles to require an explicit whitelist entry")
+	}
+}
@@ -3,6 +3,7 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 
@@ -56,6 +57,7 @@ type DownloadTask struct {
 	OutputDir   string             `json:"output_dir"`          // 输出目录
 	RetryCount  int                `json:"retry_count"`         // 重试次数
 	MaxRetries  int                `json:"max_retries"`         // 最大重试次数
+	Repair      bool               `json:"repair,omitempty"`    // 修复模式：只下载磁盘上缺失的文件（重试下载记录时使用）
 	Error       error              `json:"-"`                   // 错误信息
 	ErrorMsg    string             `json:"error_msg"`           // 错误消息（JSON序列化）
 	CreatedAt   time.Time          `json:"created_at"`          // 创建时间
@@ -64,6 +66,10 @@ type DownloadTask struct {
 	CancelFunc  context.CancelFunc `json:"-"`                   // 取消函数
 	Context     context.Context    `json:"-"`                   // 任务上下文
 	mu          sync.RWMutex       `json:"-"`                   // 读写锁
+
+	pauseRequested bool         // 已请求暂停（运行中的任务在退出后转为 paused）
+	pausedGlobally bool         // 由全局暂停开关暂停（全局恢复时一并恢复）
+	completedPages map[int]bool // 已下载完成的分P（PID），暂停恢复与重试时跳过
 }
 
 // NewDownloadTask 创建新的下载任务
@@ -153,6 +159,47 @@ func (t *DownloadTask) Cancel() {
 	t.CompletedAt = time.Now()
 }
 
+// RequestPause 请求暂停任务：取消上下文以终止正在进行的下载（yt-dlp 进程被结束，.part 文件保留）
+func (t *DownloadTask) RequestPause(global bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pauseRequested = true
+	t.pausedGlobally = global
+	if t.CancelFunc != nil {
+		t.CancelFunc()
+	}
+}
+
+// IsPauseRequested 检查任务是否已请求暂停
+func (t *DownloadTask) IsPauseRequested() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pauseRequested
+}
+
+// IsPausedGlobally 检查任务是否由全局暂停开关暂停
+func (t *DownloadTask) IsPausedGlobally() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pausedGlobally
+}
+
+// resetForResume 恢复任务前重置上下文与暂停标记
+func (t *DownloadTask) resetForResume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Context = ctx
+	t.CancelFunc = cancel
+	t.Status = TaskStatusPending
+	t.pauseRequested = false
+	t.pausedGlobally = false
+	t.Error = nil
+	t.ErrorMsg = ""
+}
+
 // IsCancelled 检查任务是否已取消
 func (t *DownloadTask) IsCancelled() bool {
 	select {
@@ -224,8 +271,29 @@ func (t *DownloadTask) Clone() *DownloadTask {
 		OutputDir:  t.OutputDir,
 		RetryCount: t.RetryCount,
 		MaxRetries: t.MaxRetries,
+		Repair:     t.Repair,
 		CreatedAt:  time.Now(),
 		CancelFunc: cancel,
 		Context:    ctx,
+
+		completedPages: maps.Clone(t.completedPages),
 	}
 }
+
+// MarkPageCompleted 记录分P已下载完成
+func (t *DownloadTask) MarkPageCompleted(pid int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.completedPages == nil {
+		t.completedPages = make(map[int]bool)
+	}
+	t.completedPages[pid] = true
+}
+
+// IsPageCompleted 检查分P是否已在本任务（含暂停前与重试前）中下载完成
+func (t *DownloadTask) IsPageCompleted(pid int) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.completedPages[pid]
+}
@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+)
+
+// watchLaterClient 移出稍后再看用到的B站接口
+type watchLaterClient interface {
+	GetVideoDetail(ctx context.Context, bvid string) (*bilibili.VideoDetail, error)
+	AddToFavorite(ctx context.Context, aid, mediaID int64) error
+	DeleteFromWatchLater(ctx context.Context, aid int64) error
+}
+
+// archiveWatchLater 视频下载完成后，按稍后再看视频源的设置将其从账号的稍后再看中移除（可先移入指定收藏夹）
+// 移除成功后记录 archived_at，视频仍视为有效，后续全量扫描不会将其视为从视频源消失
+func (dm *DownloadManager) archiveWatchLater(ctx context.Context, video *models.Video) {
+	if dm.db == nil || dm.biliClient == nil || video.WatchLaterID == nil {
+		return
+	}
+
+	var wl models.WatchLater
+	if err := dm.db.First(&wl, *video.WatchLaterID).Error; err != nil {
+		utils.Warn("加载稍后再看视频源失败: %v", err)
+		return
+	}
+
+	archived, err := archiveWatchLaterItem(ctx, dm.biliClient, &wl, video)
+	if err != nil {
+		utils.Warn("从稍后再看移除视频失败: %s (BV%s) - %v", video.Name, video.BVid, err)
+		return
+	}
+	if !archived {
+		return
+	}
+
+	if err := dm.db.Model(&models.Video{}).Where("id = ?", video.ID).Update("archived_at", video.ArchivedAt).Error; err != nil {
+		utils.Warn("记录视频移出稍后再看时间失败: %v", err)
+	}
+
+	if wl.MoveToFavoriteID != 0 {
+		utils.Info("已将视频 %s (BV%s) 从稍后再看移入收藏夹 %d", video.Name, video.BVid, wl.MoveToFavoriteID)
+	} else {
+		utils.Info("已将视频 %s (BV%s) 从稍后再看移除", video.Name, video.BVid)
+	}
+}
+
+// archiveWatchLaterItem 未开启下载后移除时不做任何操作；移除成功后在 video 上记录 ArchivedAt 并返回 true
+func archiveWatchLaterItem(ctx context.Context, client watchLaterClient, wl *models.WatchLater, video *models.Video) (bool, error) {
+	if !wl.RemoveAfterDownload {
+		return false, nil
+	}
+	if err := removeFromWatchLater(ctx, client, video, wl.MoveToFavoriteID); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	video.ArchivedAt = &now
+	return true, nil
+}
+
+// removeFromWatchLater 从稍后再看移除视频，favoriteID 非 0 时先加入该收藏夹，加入失败则保留在稍后再看中
+func removeFromWatchLater(ctx context.Context, client watchLaterClient, video *models.Video, favoriteID int64) error {
+	// 稍后再看与收藏接口按 aid 操作
+	detail, err := client.GetVideoDetail(ctx, video.BVid)
+	if err != nil {
+		return fmt.Errorf("获取视频 aid 失败: %w", err)
+	}
+
+	if favoriteID != 0 {
+		if err := client.AddToFavorite(ctx, detail.Aid, favoriteID); err != nil {
+			return fmt.Errorf("加入收藏夹 %d 失败: %w", favoriteID, err)
+		}
+	}
+	return client.DeleteFromWatchLater(ctx, detail.Aid)
+}
@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+)
+
+type fakeWatchLaterClient struct {
+	favoriteErr error
+	deleteErr   error
+	calls       []string
+}
+
+func (f *fakeWatchLaterClient) GetVideoDetail(ctx context.Context, bvid string) (*bilibili.VideoDetail, error) {
+	f.calls = append(f.calls, "detail")
+	return &bilibili.VideoDetail{Aid: 42}, nil
+}
+
+func (f *fakeWatchLaterClient) AddToFavorite(ctx context.Context, aid, mediaID int64) error {
+	f.calls = append(f.calls, "favorite")
+	return f.favoriteErr
+}
+
+func (f *fakeWatchLaterClient) DeleteFromWatchLater(ctx context.Context, aid int64) error {
+	f.calls = append(f.calls, "delete")
+	return f.deleteErr
+}
+
+func TestArchiveWatchLaterItem(t *testing.T) {
+	ctx := context.Background()
+
+	// 未开启下载后移除时不调用任何接口
+	client := &fakeWatchLaterClient{}
+	video := &models.Video{BVid: "BV1xx411c7mD"}
+	if archived, err := archiveWatchLaterItem(ctx, client, &models.WatchLater{MoveToFavoriteID: 7}, video); archived || err != nil {
+		t.Fatalf("expected no-op, got archived=%v err=%v", archived, err)
+	}
+	if len(client.calls) != 0 || video.ArchivedAt != nil {
+		t.Fatalf("expected no calls and no archived_at, got %v %v", client.calls, video.ArchivedAt)
+	}
+
+	// 加入收藏夹失败时保留在稍后再看中
+	client = &fakeWatchLaterClient{favoriteErr: errors.New("fav full")}
+	archived, err := archiveWatchLaterItem(ctx, client, &models.WatchLater{RemoveAfterDownload: true, MoveToFavoriteID: 7}, video)
+	if archived || err == nil {
+		t.Fatalf("expected favorite failure, got archived=%v err=%v", archived, err)
+	}
+	if got := strings.Join(client.calls, ","); got != "detail,favorite" {
+		t.Errorf("expected watch-later item to be kept, calls = %s", got)
+	}
+	if video.ArchivedAt != nil {
+		t.Error("archived_at should not be set when the favorite step fails")
+	}
+
+	// 移除失败时不记录 archived_at
+	client = &fakeWatchLaterClient{deleteErr: errors.New("csrf")}
+	if archived, err := archiveWatchLaterItem(ctx, client, &models.WatchLater{RemoveAfterDownload: true}, video); archived || err == nil || video.ArchivedAt != nil {
+		t.Fatalf("expected delete failure without archived_at, got archived=%v err=%v at=%v", archived, err, video.ArchivedAt)
+	}
+
+	// 移入收藏夹并移除成功后才记录 archived_at
+	client = &fakeWatchLaterClient{}
+	archived, err = archiveWatchLaterItem(ctx, client, &models.WatchLater{RemoveAfterDownload: true, MoveToFavoriteID: 7}, video)
+	if !archived || err != nil {
+		t.Fatalf("expected archive to succeed, got archived=%v err=%v", archived, err)
+	}
+	if got := strings.Join(client.calls, ","); got != "detail,favorite,delete" {
+		t.Errorf("calls = %s", got)
+	}
+	if video.ArchivedAt == nil {
+		t.Error("expected archived_at to be set after removal")
+	}
+}
@@ -77,17 +77,19 @@ func (dm *DownloadManager) executeXHSTask(task *DownloadTask) {
 	defer dm.wg.Done()
 
 	if err := dm.concurrency.AcquireVideo(task.Context); err != nil {
+		if task.IsPauseRequested() {
+			dm.finishTask(task)
+			return
+		}
 		task.SetError(err)
 		task.SetStatus(TaskStatusCancelled)
+		dm.forgetTask(task)
 		return
 	}
 	defer dm.concurrency.ReleaseVideo()
 
 	dm.runningTasks.Store(task.ID, task)
-	defer func() {
-		dm.runningTasks.Delete(task.ID)
-		dm.completedTasks.Store(task.ID, task)
-	}()
+	defer dm.finishTask(task)
 
 	task.SetStatus(TaskStatusRunning)
 	dm.emitEvent(ManagerEvent{
@@ -253,6 +255,10 @@ func (dm *DownloadManager) executeXHSTask(task *DownloadTask) {
 	}
 
 	result, err := dl.DownloadNote(task.Context, note, task.OutputDir, progressCb)
+	if err == nil && task.IsPauseRequested() {
+		utils.Info("小红书任务已暂停: %s", task.ID)
+		return
+	}
 	if err != nil {
 		dm.failXHSTask(task, err, notifyLabeled)
 		return
@@ -283,18 +289,16 @@ func (dm *DownloadManager) executeXHSTask(task *DownloadTask) {
 				updates := map[string]interface{}{
 					"file_path":       f.Path,
 					"path":            f.Path,
-					"download_status": 1,
+					"download_status": models.PageStatusVideo,
 					"kind":            string(f.MediaType),
 				}
 				if f.MediaType == xhs.MediaTypeVideo {
 					if probe, err := ProbeVideo(task.Context, f.Path); err != nil {
 						utils.Warn("小红书视频 ffprobe 探测失败: %s, %v", f.Path, err)
 					} else {
-						updates["width"] = probe.Width
-						updates["height"] = probe.Height
-						updates["frame_rate"] = probe.FrameRate
-						updates["quality"] = models.CalcQuality(probe.Width, probe.Height, probe.FrameRate)
-						updates["orientation"] = models.CalcOrientation(probe.Width, probe.Height)
+						for k, v := range probe.PageUpdates() {
+							updates[k] = v
+						}
 					}
 				}
 				dm.db.Model(p).Updates(updates)
@@ -351,6 +355,10 @@ func (dm *DownloadManager) executeXHSTask(task *DownloadTask) {
 }
 
 func (dm *DownloadManager) failXHSTask(task *DownloadTask, err error, notify func(string, string, DownloadStatus, float64, int64, int64)) {
+	if task.IsPauseRequested() {
+		utils.Info("小红书任务已暂停: %s", task.ID)
+		return
+	}
 	utils.Error("小红书下载失败: %v", err)
 	task.SetError(err)
 	task.SetStatus(TaskStatusFailed)
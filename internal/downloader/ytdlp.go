@@ -60,9 +60,8 @@ func (d *YtdlpDownloader) buildCommand(ctx context.Context, opts *DownloadOption
 		args = append(args, "-o", filepath.Join(opts.OutputPath, opts.OutputTemplate))
 	}
 
-	if d.config != nil && d.config.Proxy.IsEnabled() {
-		args = append(args, "--proxy", strings.TrimSpace(d.config.Proxy.URL))
-	}
+	// 代理与带宽限制
+	args = append(args, ytdlpNetworkArgs(d.config)...)
 
 	// Cookies
 	if opts.Cookies != "" {
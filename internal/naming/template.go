@@ -0,0 +1,141 @@
+// Package naming 实现目录与文件命名模板
+// 模板语法为 {{变量}}，支持 {{ truncate 变量 长度 }} 截断，模板中的 / 表示子目录
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// VideoVars 视频名称模板可用的变量
+var VideoVars = []string{
+	"bvid",
+	"title",
+	"upper_name",
+	"upper_mid",
+	"pubtime",
+	"favtime",
+	"ctime",
+	"source_type",
+	"source_name",
+}
+
+// PageVars 分P名称模板额外可用的变量
+var PageVars = []string{
+	"ptitle",
+	"page_title", // ptitle 的别名，兼容早期文档中的写法
+	"pid",
+}
+
+// Vars 模板变量值
+type Vars map[string]string
+
+// pageVarPattern 匹配引用了分P变量的模板动作
+var pageVarPattern = regexp.MustCompile(`\{\{[^}]*\b(ptitle|page_title|pid)\b[^}]*\}\}`)
+
+// Validate 校验模板语法与变量，allowPageVars 为 true 时允许使用分P变量
+func Validate(tmpl string, allowPageVars bool) error {
+	if strings.TrimSpace(tmpl) == "" {
+		return fmt.Errorf("模板不能为空")
+	}
+
+	names := VideoVars
+	if allowPageVars {
+		names = append(append([]string{}, VideoVars...), PageVars...)
+	}
+
+	vars := make(Vars, len(names))
+	for _, name := range names {
+		vars[name] = "x"
+	}
+
+	rendered, err := Render(tmpl, vars)
+	if err != nil {
+		return err
+	}
+	if strings.Trim(rendered, "/ ") == "" {
+		return fmt.Errorf("模板渲染结果为空")
+	}
+	return nil
+}
+
+// Render 渲染模板，变量值应已由调用方处理为合法的文件名
+func Render(tmpl string, vars Vars) (string, error) {
+	funcs := template.FuncMap{
+		"truncate": truncate,
+	}
+	for name, value := range vars {
+		funcs[name] = func() string { return value }
+	}
+
+	t, err := template.New("name").Option("missingkey=error").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("模板解析失败: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, nil); err != nil {
+		return "", fmt.Errorf("模板渲染失败: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// UsesPageVars 模板是否引用了分P变量
+func UsesPageVars(tmpl string) bool {
+	return pageVarPattern.MatchString(tmpl)
+}
+
+// truncate 按字符截断
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if length < 0 || len(runes) <= length {
+		return value
+	}
+	return strings.TrimSpace(string(runes[:length]))
+}
+
+// FormatTime 按 strftime 格式（如 %Y-%m-%d）格式化时间，零值时间返回空字符串
+func FormatTime(t time.Time, format string) string {
+	if t.IsZero() {
+		return ""
+	}
+	if format == "" {
+		format = "%Y-%m-%d"
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			sb.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			fmt.Fprintf(&sb, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&sb, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&sb, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&sb, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&sb, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&sb, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&sb, "%02d", t.Second())
+		case 's':
+			fmt.Fprintf(&sb, "%d", t.Unix())
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(format[i])
+		}
+	}
+	return sb.String()
+}
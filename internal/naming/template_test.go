@@ -0,0 +1,84 @@
+package naming
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderSubstitutesVariables(t *testing.T) {
+	got, err := Render("{{upper_name}}/{{pubtime}} - {{ title }}", Vars{
+		"upper_name": "UP主",
+		"pubtime":    "2024-05-20",
+		"title":      "标题",
+	})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if got != "UP主/2024-05-20 - 标题" {
+		t.Fatalf("unexpected result: %q", got)
+	}
+}
+
+func TestRenderTruncate(t *testing.T) {
+	got, err := Render("{{ truncate title 3 }}", Vars{"title": "一二三四五"})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if got != "一二三" {
+		t.Fatalf("unexpected result: %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		tmpl     string
+		pageVars bool
+		ok       bool
+	}{
+		{"{{title}}", false, true},
+		{"{{upper_name}}/{{ truncate title 50 }}", false, true},
+		{"{{title}}-{{ptitle}}", true, true},
+		{"{{title}}-{{ptitle}}", false, false},
+		{"{{title}}-P{{pid}}-{{page_title}}", true, true},
+		{"{{unknown}}", true, false},
+		{"{{title", false, false},
+		{"", false, false},
+		{"{{ truncate title 0 }}", false, false},
+	}
+
+	for _, tc := range cases {
+		err := Validate(tc.tmpl, tc.pageVars)
+		if (err == nil) != tc.ok {
+			t.Fatalf("Validate(%q, %v) = %v, want ok=%v", tc.tmpl, tc.pageVars, err, tc.ok)
+		}
+	}
+}
+
+func TestUsesPageVars(t *testing.T) {
+	if !UsesPageVars("{{title}} - P{{ pid }}") {
+		t.Fatal("expected pid to be detected")
+	}
+	if !UsesPageVars("{{ truncate ptitle 20 }}") {
+		t.Fatal("expected ptitle to be detected")
+	}
+	if !UsesPageVars("{{title}}-{{page_title}}") {
+		t.Fatal("expected page_title to be detected")
+	}
+	if UsesPageVars("{{title}} pid") {
+		t.Fatal("text outside actions should not count")
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2024, 5, 20, 8, 3, 9, 0, time.UTC)
+
+	if got := FormatTime(ts, "%Y-%m-%d %H:%M:%S"); got != "2024-05-20 08:03:09" {
+		t.Fatalf("unexpected result: %q", got)
+	}
+	if got := FormatTime(ts, "%y%m%d 100%%"); got != "240520 100%" {
+		t.Fatalf("unexpected result: %q", got)
+	}
+	if got := FormatTime(time.Time{}, "%Y"); got != "" {
+		t.Fatalf("expected empty string for zero time, got %q", got)
+	}
+}
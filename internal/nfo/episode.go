@@ -193,6 +193,34 @@ func (g *EpisodeGenerator) SetVideoInfo(codec string, width, height, duration in
 	return g
 }
 
+// AddVideoStream 添加完整的视频流信息
+func (g *EpisodeGenerator) AddVideoStream(stream VideoStream) *EpisodeGenerator {
+	g.ensureStreamDetails()
+	if stream.Aspect == 0 && stream.Width > 0 && stream.Height > 0 {
+		stream.Aspect = float64(stream.Width) / float64(stream.Height)
+	}
+	g.nfo.FileInfo.StreamDetails.Video = append(g.nfo.FileInfo.StreamDetails.Video, stream)
+	return g
+}
+
+// AddAudioStream 添加完整的音频流信息
+func (g *EpisodeGenerator) AddAudioStream(stream AudioStream) *EpisodeGenerator {
+	g.ensureStreamDetails()
+	g.nfo.FileInfo.StreamDetails.Audio = append(g.nfo.FileInfo.StreamDetails.Audio, stream)
+	return g
+}
+
+func (g *EpisodeGenerator) ensureStreamDetails() {
+	if g.nfo.FileInfo == nil {
+		g.nfo.FileInfo = &FileInfo{
+			StreamDetails: &StreamDetails{
+				Video: make([]VideoStream, 0),
+				Audio: make([]AudioStream, 0),
+			},
+		}
+	}
+}
+
 // SetAudioInfo 设置音频信息
 func (g *EpisodeGenerator) SetAudioInfo(codec, language string, channels int) *EpisodeGenerator {
 	if g.nfo.FileInfo == nil {
@@ -56,13 +56,17 @@ type VideoStream struct {
 	Height            int     `xml:"height,omitempty"`
 	DurationInSeconds int     `xml:"durationinseconds,omitempty"`
 	StereoMode        string  `xml:"stereomode,omitempty"`
+	HDRType           string  `xml:"hdrtype,omitempty"`   // hdr10/hlg/dolbyvision
+	Bitrate           int64   `xml:"bitrate,omitempty"`   // bps
+	FrameRate         float32 `xml:"framerate,omitempty"` // 帧率
 }
 
 // AudioStream 音频流
 type AudioStream struct {
-	Codec    string `xml:"codec,omitempty"`
-	Language string `xml:"language,omitempty"`
-	Channels int    `xml:"channels,omitempty"`
+	Codec        string `xml:"codec,omitempty"`
+	Language     string `xml:"language,omitempty"`
+	Channels     int    `xml:"channels,omitempty"`
+	SamplingRate int    `xml:"samplingrate,omitempty"` // Hz
 }
 
 // SubtitleStream 字幕流
@@ -234,6 +238,34 @@ func (g *MovieGenerator) SetVideoInfo(codec string, width, height, duration int)
 	return g
 }
 
+// AddVideoStream 添加完整的视频流信息
+func (g *MovieGenerator) AddVideoStream(stream VideoStream) *MovieGenerator {
+	g.ensureStreamDetails()
+	if stream.Aspect == 0 && stream.Width > 0 && stream.Height > 0 {
+		stream.Aspect = float64(stream.Width) / float64(stream.Height)
+	}
+	g.nfo.FileInfo.StreamDetails.Video = append(g.nfo.FileInfo.StreamDetails.Video, stream)
+	return g
+}
+
+// AddAudioStream 添加完整的音频流信息
+func (g *MovieGenerator) AddAudioStream(stream AudioStream) *MovieGenerator {
+	g.ensureStreamDetails()
+	g.nfo.FileInfo.StreamDetails.Audio = append(g.nfo.FileInfo.StreamDetails.Audio, stream)
+	return g
+}
+
+func (g *MovieGenerator) ensureStreamDetails() {
+	if g.nfo.FileInfo == nil {
+		g.nfo.FileInfo = &FileInfo{
+			StreamDetails: &StreamDetails{
+				Video: make([]VideoStream, 0),
+				Audio: make([]AudioStream, 0),
+			},
+		}
+	}
+}
+
 // SetAudioInfo 设置音频信息
 func (g *MovieGenerator) SetAudioInfo(codec, language string, channels int) *MovieGenerator {
 	if g.nfo.FileInfo == nil {
@@ -0,0 +1,102 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// SeasonNFO 季 NFO（season.nfo，放在季目录中）
+type SeasonNFO struct {
+	XMLName      xml.Name   `xml:"season"`
+	Title        string     `xml:"title"`
+	Plot         string     `xml:"plot,omitempty"`
+	SeasonNumber int        `xml:"seasonnumber"`
+	Premiered    string     `xml:"premiered,omitempty"` // YYYY-MM-DD
+	Year         int        `xml:"year,omitempty"`
+	Thumb        []Thumb    `xml:"thumb,omitempty"`
+	UniqueID     []UniqueID `xml:"uniqueid,omitempty"`
+	DateAdded    string     `xml:"dateadded,omitempty"`
+}
+
+// SeasonGenerator 季 NFO 生成器
+type SeasonGenerator struct {
+	nfo *SeasonNFO
+}
+
+// NewSeasonGenerator 创建季 NFO 生成器
+func NewSeasonGenerator() *SeasonGenerator {
+	return &SeasonGenerator{
+		nfo: &SeasonNFO{
+			Thumb:        make([]Thumb, 0),
+			UniqueID:     make([]UniqueID, 0),
+			SeasonNumber: 1, // 默认第一季
+		},
+	}
+}
+
+// SetTitle 设置标题
+func (g *SeasonGenerator) SetTitle(title string) *SeasonGenerator {
+	g.nfo.Title = title
+	return g
+}
+
+// SetPlot 设置简介
+func (g *SeasonGenerator) SetPlot(plot string) *SeasonGenerator {
+	g.nfo.Plot = plot
+	return g
+}
+
+// SetSeasonNumber 设置季序号
+func (g *SeasonGenerator) SetSeasonNumber(season int) *SeasonGenerator {
+	g.nfo.SeasonNumber = season
+	return g
+}
+
+// SetPremiered 设置首播日期
+func (g *SeasonGenerator) SetPremiered(t time.Time) *SeasonGenerator {
+	g.nfo.Premiered = FormatDate(t, "2006-01-02")
+	g.nfo.Year = t.Year()
+	return g
+}
+
+// SetDateAdded 设置添加日期
+func (g *SeasonGenerator) SetDateAdded(t time.Time) *SeasonGenerator {
+	g.nfo.DateAdded = FormatDate(t, "2006-01-02 15:04:05")
+	return g
+}
+
+// AddThumb 添加缩略图
+func (g *SeasonGenerator) AddThumb(url, aspect string) *SeasonGenerator {
+	thumb := Thumb{
+		URL:    url,
+		Aspect: aspect,
+	}
+	g.nfo.Thumb = append(g.nfo.Thumb, thumb)
+	return g
+}
+
+// AddUniqueID 添加唯一标识
+func (g *SeasonGenerator) AddUniqueID(idType, value string, isDefault bool) *SeasonGenerator {
+	uid := UniqueID{
+		Type:    idType,
+		Value:   value,
+		Default: isDefault,
+	}
+	g.nfo.UniqueID = append(g.nfo.UniqueID, uid)
+	return g
+}
+
+// Generate 生成 NFO
+func (g *SeasonGenerator) Generate() ([]byte, error) {
+	return xml.MarshalIndent(g.nfo, "", "  ")
+}
+
+// WriteToFile 写入文件
+func (g *SeasonGenerator) WriteToFile(filename string) error {
+	return WriteXMLToFile(filename, g.nfo)
+}
+
+// GetNFO 获取 NFO 对象
+func (g *SeasonGenerator) GetNFO() *SeasonNFO {
+	return g.nfo
+}
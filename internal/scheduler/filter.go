@@ -1,39 +1,19 @@
 package scheduler
 
 import (
-	"encoding/json"
 	"strings"
 	"time"
 
 	"bili-download/internal/adapter"
+	"bili-download/internal/config"
 	"bili-download/internal/utils"
 )
 
-// FilterRule 过滤规则
-type FilterRule struct {
-	// 关键词过滤
-	Keywords        []string `json:"keywords"`         // 标题包含（OR关系）
-	ExcludeKeywords []string `json:"exclude_keywords"` // 标题排除（AND关系）
-	KeywordMode     string   `json:"keyword_mode"`     // and / or（keywords之间的关系）
-
-	// 时长过滤
-	MinDuration int `json:"min_duration"` // 最小时长（秒）
-	MaxDuration int `json:"max_duration"` // 最大时长（秒）
-
-	// 时间过滤
-	PubDateAfter  string `json:"pub_date_after"`  // 发布时间晚于（RFC3339格式）
-	PubDateBefore string `json:"pub_date_before"` // 发布时间早于
-	FavDateAfter  string `json:"fav_date_after"`  // 收藏时间晚于
-	FavDateBefore string `json:"fav_date_before"` // 收藏时间早于
-
-	// UP主过滤
-	AllowedUppers []int64 `json:"allowed_uppers"` // UP主白名单
-	BlockedUppers []int64 `json:"blocked_uppers"` // UP主黑名单
-
-	// 其他
-	OnlyOriginal bool `json:"only_original"` // 仅原创
-	MinViews     int  `json:"min_views"`     // 最小播放量
-}
+// FilterRule 过滤规则（定义在配置包中，供配置校验全局规则）
+type FilterRule = config.FilterRule
+
+// copyrightReprint 版权类型：转载
+const copyrightReprint = 2
 
 // FilterEngine 过滤引擎
 type FilterEngine struct {
@@ -47,6 +27,20 @@ func NewFilterEngine(globalRule *FilterRule) *FilterEngine {
 	}
 }
 
+// newFilterEngineFromConfig 根据配置中的全局规则创建过滤引擎
+func newFilterEngineFromConfig(cfg *config.Config) *FilterEngine {
+	if cfg == nil || strings.TrimSpace(cfg.Sync.GlobalRule) == "" {
+		return NewFilterEngine(nil)
+	}
+
+	globalRule, err := ParseRuleFromJSON(cfg.Sync.GlobalRule)
+	if err != nil {
+		utils.Warn("解析全局过滤规则失败，已忽略: %v", err)
+		return NewFilterEngine(nil)
+	}
+	return NewFilterEngine(globalRule)
+}
+
 // ShouldDownload 判断视频是否应该下载
 func (fe *FilterEngine) ShouldDownload(video adapter.VideoInfo, sourceRule *FilterRule) (bool, string) {
 	// 合并规则（视频源规则优先）
@@ -82,26 +76,26 @@ func (fe *FilterEngine) ShouldDownload(video adapter.VideoInfo, sourceRule *Filt
 		return false, "播放量不符合要求"
 	}
 
+	// 7. 检查是否原创（版权未知时放行，由调用方补全版权信息后再次判断）
+	if rule.OnlyOriginal && video.Copyright == copyrightReprint {
+		return false, "非原创视频"
+	}
+
 	// 所有检查通过
 	return true, ""
 }
 
 // ParseRuleFromJSON 从JSON解析规则
 func ParseRuleFromJSON(jsonStr string) (*FilterRule, error) {
-	if jsonStr == "" {
-		return &FilterRule{}, nil
-	}
-
-	var rule FilterRule
-	if err := json.Unmarshal([]byte(jsonStr), &rule); err != nil {
-		return nil, err
-	}
-
-	return &rule, nil
+	return config.ParseFilterRule(jsonStr)
 }
 
 // mergeRules 合并规则（视频源规则优先）
 func (fe *FilterEngine) mergeRules(sourceRule *FilterRule) *FilterRule {
+	if sourceRule == nil && fe.globalRule == nil {
+		return &FilterRule{}
+	}
+
 	if sourceRule == nil {
 		return fe.globalRule
 	}
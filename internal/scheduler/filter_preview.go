@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bili-download/internal/adapter"
+	"bili-download/internal/utils"
+)
+
+// FilterPreviewItem 过滤预览中的单个视频
+type FilterPreviewItem struct {
+	BVid      string    `json:"bvid"`
+	Title     string    `json:"title"`
+	UpperMid  int64     `json:"upper_mid"`
+	UpperName string    `json:"upper_name"`
+	Duration  int       `json:"duration"`
+	PubDate   time.Time `json:"pub_date"`
+	AddTime   time.Time `json:"add_time"`
+	Views     int       `json:"views"`
+	Copyright int       `json:"copyright"`
+	Exists    bool      `json:"exists"` // 是否已存在于该视频源（同步时会跳过）
+	Passed    bool      `json:"passed"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// FilterPreviewResult 过滤预览结果
+type FilterPreviewResult struct {
+	SourceID   string              `json:"source_id"`
+	SourceType string              `json:"source_type"`
+	SourceName string              `json:"source_name"`
+	Rule       *FilterRule         `json:"rule"` // 合并全局规则后的生效规则
+	Total      int                 `json:"total"`
+	Passed     int                 `json:"passed"`
+	Filtered   int                 `json:"filtered"`
+	Items      []FilterPreviewItem `json:"items"`
+}
+
+// PreviewSourceFilter 对视频源当前的扫描结果试运行过滤规则，不创建视频记录也不加入下载队列
+// ruleJSON 为空时使用视频源已保存的规则，否则使用传入的规则（用于保存前调试）
+func (s *Scheduler) PreviewSourceFilter(ctx context.Context, sourceType string, sourceDBID uint, ruleJSON *string, limit int) (*FilterPreviewResult, error) {
+	s.mu.RLock()
+	cfg := s.config
+	s.mu.RUnlock()
+
+	st := NewSyncTask(ctx, "preview", s.db, cfg, s.downloadManager)
+
+	source, err := st.loadVideoSource(sourceType, sourceDBID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleStr := source.Rule
+	if ruleJSON != nil {
+		ruleStr = *ruleJSON
+	}
+	sourceRule, err := ParseRuleFromJSON(ruleStr)
+	if err != nil {
+		return nil, fmt.Errorf("解析过滤规则失败: %w", err)
+	}
+
+	videos, err := source.Adapter.Scan(ctx, &adapter.ScanOptions{Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("扫描视频源失败: %w", err)
+	}
+
+	result := &FilterPreviewResult{
+		SourceID:   source.ID,
+		SourceType: source.Type,
+		SourceName: source.Name,
+		Rule:       st.filterEngine.mergeRules(sourceRule),
+		Total:      len(videos),
+		Items:      make([]FilterPreviewItem, 0, len(videos)),
+	}
+
+	for _, video := range videos {
+		exists, err := st.videoExistsInSource(video.BVid, source.Type, sourceDBID)
+		if err != nil {
+			return nil, fmt.Errorf("查询视频失败: %w", err)
+		}
+
+		passed, reason := st.filterEngine.ShouldDownload(video, sourceRule)
+		// 与同步一致：仅原创规则下列表未返回版权信息时，用视频详情补全后再判断
+		if passed && result.Rule.OnlyOriginal && video.Copyright == 0 && video.Episode == nil {
+			detail, err := st.biliClient.GetVideoDetail(ctx, video.BVid)
+			if err != nil {
+				utils.Warn("过滤预览获取视频详情失败: %s - %v", video.BVid, err)
+			} else {
+				passed, reason = st.recheckWithDetail(&video, detail, sourceRule)
+			}
+		}
+		if passed {
+			result.Passed++
+		} else {
+			result.Filtered++
+		}
+
+		result.Items = append(result.Items, FilterPreviewItem{
+			BVid:      video.BVid,
+			Title:     video.Title,
+			UpperMid:  video.Owner.Mid,
+			UpperName: video.Owner.Name,
+			Duration:  video.Duration,
+			PubDate:   video.PubDate,
+			AddTime:   video.AddTime,
+			Views:     video.Stats.View,
+			Copyright: video.Copyright,
+			Exists:    exists,
+			Passed:    passed,
+			Reason:    reason,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"testing"
+
+	"bili-download/internal/adapter"
+	"bili-download/internal/bilibili"
+)
+
+func TestFilterEngineSourceRuleOverridesGlobalRule(t *testing.T) {
+	engine := NewFilterEngine(&FilterRule{MinDuration: 600, ExcludeKeywords: []string{"直播回放"}})
+	video := adapter.VideoInfo{Title: "周末直播回放", Duration: 120}
+
+	if ok, reason := engine.ShouldDownload(video, nil); ok || reason != "标题包含排除关键词" {
+		t.Fatalf("expected global exclude keyword to reject, got ok=%v reason=%q", ok, reason)
+	}
+
+	sourceRule, err := ParseRuleFromJSON(`{"exclude_keywords":["广告"],"min_duration":60}`)
+	if err != nil {
+		t.Fatalf("parse rule: %v", err)
+	}
+	if ok, reason := engine.ShouldDownload(video, sourceRule); !ok {
+		t.Fatalf("expected source rule to override global rule, got reason %q", reason)
+	}
+}
+
+func TestFilterEngineKeywordModeAndViews(t *testing.T) {
+	engine := NewFilterEngine(nil)
+	video := adapter.VideoInfo{Title: "Go 并发 教程", Stats: adapter.StatsInfo{View: 500}}
+
+	if ok, _ := engine.ShouldDownload(video, &FilterRule{Keywords: []string{"go", "rust"}, KeywordMode: "and"}); ok {
+		t.Fatal("expected and-mode keywords to require every keyword")
+	}
+	if ok, _ := engine.ShouldDownload(video, &FilterRule{Keywords: []string{"go", "rust"}}); !ok {
+		t.Fatal("expected or-mode keywords to accept any keyword")
+	}
+	if ok, reason := engine.ShouldDownload(video, &FilterRule{MinViews: 1000}); ok || reason != "播放量不符合要求" {
+		t.Fatalf("expected min views to reject, got ok=%v reason=%q", ok, reason)
+	}
+}
+
+func TestFilterEngineOnlyOriginal(t *testing.T) {
+	engine := NewFilterEngine(nil)
+	rule := &FilterRule{OnlyOriginal: true}
+
+	if ok, reason := engine.ShouldDownload(adapter.VideoInfo{Copyright: 2}, rule); ok || reason != "非原创视频" {
+		t.Fatalf("expected reprint to be rejected, got ok=%v reason=%q", ok, reason)
+	}
+	if ok, _ := engine.ShouldDownload(adapter.VideoInfo{Copyright: 1}, rule); !ok {
+		t.Fatal("expected original video to pass")
+	}
+	if ok, _ := engine.ShouldDownload(adapter.VideoInfo{}, rule); !ok {
+		t.Fatal("expected unknown copyright to pass until detail is fetched")
+	}
+}
+
+func TestRecheckWithDetailFillsCopyright(t *testing.T) {
+	st := &SyncTask{filterEngine: NewFilterEngine(nil)}
+	rule := &FilterRule{OnlyOriginal: true}
+
+	video := adapter.VideoInfo{BVid: "BV1"}
+	if ok, reason := st.recheckWithDetail(&video, &bilibili.VideoDetail{Copyright: 2}, rule); ok || reason != "非原创视频" {
+		t.Fatalf("expected reprint from detail to be rejected, got ok=%v reason=%q", ok, reason)
+	}
+	if video.Copyright != 2 {
+		t.Errorf("expected copyright filled from detail, got %d", video.Copyright)
+	}
+
+	// 列表已返回版权信息或详情缺失时不覆盖
+	video = adapter.VideoInfo{BVid: "BV2", Copyright: 1}
+	if ok, _ := st.recheckWithDetail(&video, &bilibili.VideoDetail{Copyright: 2}, rule); !ok || video.Copyright != 1 {
+		t.Errorf("expected list copyright to be kept, got %d", video.Copyright)
+	}
+	if ok, _ := st.recheckWithDetail(&adapter.VideoInfo{}, nil, rule); !ok {
+		t.Error("expected missing detail to pass")
+	}
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"bili-download/internal/adapter"
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+)
+
+// LostVideo 从视频源中消失的视频（记录在扫描记录的 Metadata 与 videos_lost 事件中）
+type LostVideo struct {
+	ID         uint   `json:"id"`
+	BVid       string `json:"bvid"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`     // deleted / unavailable / removed
+	Downloaded bool   `json:"downloaded"` // 本地是否已保存
+}
+
+// sourceVideoState 视频源已入库视频的状态
+type sourceVideoState struct {
+	ID             uint
+	BVid           string
+	Name           string
+	Valid          bool
+	DownloadStatus int
+	ArchivedAt     *time.Time
+}
+
+// diffSourceVideos 比对已入库视频与远端列表
+// missing：远端列表中已不存在的有效视频（不含下载后由本程序移出稍后再看的视频）；invalid：远端仍有条目但已失效的有效视频；
+// restored：重新出现在远端的失效视频或已移出稍后再看的视频
+func diffSourceVideos(stored []sourceVideoState, remote []adapter.VideoInfo) (missing, invalid, restored []sourceVideoState) {
+	remoteState := make(map[string]bool, len(remote)) // bvid -> 是否失效
+	for _, v := range remote {
+		remoteState[v.BVid] = remoteState[v.BVid] || v.Invalid
+	}
+
+	for _, v := range stored {
+		isInvalid, ok := remoteState[v.BVid]
+		switch {
+		case !ok && v.Valid && v.ArchivedAt == nil:
+			missing = append(missing, v)
+		case ok && isInvalid && v.Valid:
+			invalid = append(invalid, v)
+		case ok && !isInvalid && (!v.Valid || v.ArchivedAt != nil):
+			restored = append(restored, v)
+		}
+	}
+	return missing, invalid, restored
+}
+
+// classifyLostVideo 按视频详情接口的结果判断消失原因，无法判断（如网络错误）时 ok 为 false
+func classifyLostVideo(detailErr error) (reason string, ok bool) {
+	if detailErr == nil {
+		return models.LostReasonRemoved, true
+	}
+	var biliErr *bilibili.BiliError
+	if !errors.As(detailErr, &biliErr) {
+		return "", false
+	}
+	switch biliErr.Code {
+	case bilibili.CodeVideoBeenDeleted, bilibili.CodeNotFound:
+		return models.LostReasonDeleted, true
+	case bilibili.CodeVideoNotAvailable:
+		return models.LostReasonUnavailable, true
+	default:
+		return "", false
+	}
+}
+
+// detectLostVideos 全量扫描后比对远端列表，标记从视频源消失的视频（保留本地文件），重新出现的视频恢复为有效
+func (st *SyncTask) detectLostVideos(source VideoSourceInfo, remote []adapter.VideoInfo) []LostVideo {
+	// 不完整的列表中缺少的视频未必已消失，据此标记会导致 mirror 保留策略误删本地文件
+	if source.PartialList {
+		utils.Debug("[%s] 视频源 %s 的扫描结果不是完整列表，跳过失效检测", st.ID, source.Name)
+		return nil
+	}
+	sourceDBID := st.getSourceDBID(source)
+	column := sourceForeignKey(source.Type)
+	if sourceDBID == 0 || column == "" {
+		return nil
+	}
+	// 远端列表为空多为接口异常或视频源被设为私密，不据此批量标记失效
+	if len(remote) == 0 {
+		utils.Warn("[%s] 视频源 %s 全量扫描结果为空，跳过失效检测", st.ID, source.Name)
+		return nil
+	}
+
+	var stored []sourceVideoState
+	if err := st.db.Model(&models.Video{}).
+		Select("id, bvid, name, valid, download_status, archived_at").
+		Where(column+" = ?", sourceDBID).
+		Scan(&stored).Error; err != nil {
+		utils.Warn("[%s] 加载视频源 %s 已入库视频失败，跳过失效检测: %v", st.ID, source.Name, err)
+		return nil
+	}
+
+	missing, invalid, restored := diffSourceVideos(stored, remote)
+
+	if len(restored) > 0 {
+		ids := make([]uint, 0, len(restored))
+		for _, v := range restored {
+			ids = append(ids, v.ID)
+		}
+		if err := st.db.Model(&models.Video{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"valid":       true,
+			"lost_reason": "",
+			"lost_at":     nil,
+			"archived_at": nil,
+		}).Error; err != nil {
+			utils.Warn("[%s] 恢复视频源 %s 的失效视频失败: %v", st.ID, source.Name, err)
+		} else {
+			utils.Info("[%s] 视频源 %s 有 %d 个失效视频重新出现，已恢复", st.ID, source.Name, len(restored))
+		}
+	}
+
+	lost := make([]LostVideo, 0, len(missing)+len(invalid))
+	for _, v := range invalid {
+		lost = append(lost, newLostVideo(v, models.LostReasonDeleted))
+	}
+	for _, v := range missing {
+		if st.ctx.Err() != nil {
+			break
+		}
+		_, err := st.biliClient.GetVideoDetail(st.ctx, v.BVid)
+		reason, ok := classifyLostVideo(err)
+		if !ok {
+			utils.Warn("[%s] 无法确认视频 %s (BV%s) 的状态，下次全量扫描时重试: %v", st.ID, v.Name, v.BVid, err)
+			continue
+		}
+		lost = append(lost, newLostVideo(v, reason))
+	}
+
+	now := time.Now()
+	marked := lost[:0]
+	for _, v := range lost {
+		if err := st.db.Model(&models.Video{}).Where("id = ?", v.ID).Updates(map[string]interface{}{
+			"valid":       false,
+			"lost_reason": v.Reason,
+			"lost_at":     now,
+		}).Error; err != nil {
+			utils.Warn("[%s] 标记失效视频失败: %s - %v", st.ID, v.BVid, err)
+			continue
+		}
+		utils.Info("[%s] 视频 %s (BV%s) 已从视频源 %s 消失: %s，本地文件%s", st.ID, v.Name, v.BVid, source.Name,
+			models.LostReasonLabel(v.Reason), map[bool]string{true: "已保存", false: "未下载"}[v.Downloaded])
+		marked = append(marked, v)
+	}
+	return marked
+}
+
+func newLostVideo(v sourceVideoState, reason string) LostVideo {
+	return LostVideo{
+		ID:         v.ID,
+		BVid:       v.BVid,
+		Name:       v.Name,
+		Reason:     reason,
+		Downloaded: v.DownloadStatus != 0,
+	}
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"bili-download/internal/adapter"
+	"bili-download/internal/bilibili"
+	"bili-download/internal/database/models"
+)
+
+func TestDiffSourceVideos(t *testing.T) {
+	archivedAt := time.Now()
+	stored := []sourceVideoState{
+		{ID: 1, BVid: "BV1", Valid: true},                          // 仍在远端
+		{ID: 2, BVid: "BV2", Valid: true},                          // 远端已不存在
+		{ID: 3, BVid: "BV3", Valid: true},                          // 远端条目已失效
+		{ID: 4, BVid: "BV4", Valid: false},                         // 重新出现
+		{ID: 5, BVid: "BV5", Valid: false},                         // 仍然消失，不重复上报
+		{ID: 7, BVid: "BV7", Valid: true, ArchivedAt: &archivedAt}, // 下载后移出稍后再看，不视为消失
+		{ID: 8, BVid: "BV8", Valid: true, ArchivedAt: &archivedAt}, // 重新加入稍后再看
+	}
+	remote := []adapter.VideoInfo{
+		{BVid: "BV1"},
+		{BVid: "BV3", Invalid: true},
+		{BVid: "BV4"},
+		{BVid: "BV6"},
+		{BVid: "BV8"},
+	}
+
+	missing, invalid, restored := diffSourceVideos(stored, remote)
+	if len(missing) != 1 || missing[0].ID != 2 {
+		t.Fatalf("expected BV2 missing, got %+v", missing)
+	}
+	if len(invalid) != 1 || invalid[0].ID != 3 {
+		t.Fatalf("expected BV3 invalid, got %+v", invalid)
+	}
+	if len(restored) != 2 || restored[0].ID != 4 || restored[1].ID != 8 {
+		t.Fatalf("expected BV4 and BV8 restored, got %+v", restored)
+	}
+}
+
+func TestClassifyLostVideo(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		reason string
+		ok     bool
+	}{
+		{"still accessible", nil, models.LostReasonRemoved, true},
+		{"deleted", &bilibili.BiliError{Code: bilibili.CodeVideoBeenDeleted}, models.LostReasonDeleted, true},
+		{"not found", fmt.Errorf("wrapped: %w", &bilibili.BiliError{Code: bilibili.CodeNotFound}), models.LostReasonDeleted, true},
+		{"unavailable", &bilibili.BiliError{Code: bilibili.CodeVideoNotAvailable}, models.LostReasonUnavailable, true},
+		{"risk control", &bilibili.BiliError{Code: bilibili.CodeRiskControl}, "", false},
+		{"network", errors.New("connection reset"), "", false},
+	}
+	for _, tc := range cases {
+		reason, ok := classifyLostVideo(tc.err)
+		if reason != tc.reason || ok != tc.ok {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", tc.name, reason, ok, tc.reason, tc.ok)
+		}
+	}
+}
+
+func TestDetectLostVideosSkipsDynamicFeed(t *testing.T) {
+	// 动态流缺少已入库的 BV2（删除了动态或合作投稿），不应被标记为消失；
+	// SyncTask 未设置数据库，若未跳过检测会在查询已入库视频时出错
+	st := &SyncTask{ID: "test"}
+	source := st.submissionSource(models.Submission{ID: 1, UpperID: 42, Name: "UP", UseDynamicAPI: true})
+	if !source.PartialList {
+		t.Fatal("expected dynamic API submission to be marked as a partial list")
+	}
+	remote := []adapter.VideoInfo{{BVid: "BV1"}}
+	if lost := st.detectLostVideos(source, remote); lost != nil {
+		t.Fatalf("expected no lost videos for a dynamic feed, got %+v", lost)
+	}
+
+	if st.submissionSource(models.Submission{ID: 1, UpperID: 42}).PartialList {
+		t.Error("archive list scans should still run lost detection")
+	}
+}
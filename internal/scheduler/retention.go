@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"time"
+
+	"bili-download/internal/adapter"
+	"bili-download/internal/database/models"
+	"bili-download/internal/utils"
+)
+
+// retentionGuard 同步时按视频源保留策略跳过不应入库的视频，避免下载后又被清理
+type retentionGuard struct {
+	pruned map[string]struct{} // 已按保留策略清理的视频
+	cutoff time.Time           // max_age 策略下早于此时间加入视频源的视频不再入库
+}
+
+// newRetentionGuard 加载视频源已按 keep_last/max_age 清理的视频
+// 按 mirror 清理的视频已从远端消失，重新出现时照常入库
+func (st *SyncTask) newRetentionGuard(source VideoSourceInfo, sourceDBID uint, now time.Time) *retentionGuard {
+	guard := &retentionGuard{pruned: make(map[string]struct{})}
+	if source.RetentionMode == models.RetentionModeMaxAge && source.RetentionValue > 0 {
+		guard.cutoff = now.AddDate(0, 0, -source.RetentionValue)
+	}
+
+	var bvids []string
+	if err := st.db.Model(&models.PrunedVideo{}).
+		Where("source_type = ? AND source_id = ? AND mode <> ?", source.Type, sourceDBID, models.RetentionModeMirror).
+		Pluck("bvid", &bvids).Error; err != nil {
+		utils.Warn("[%s] 加载视频源 %s 的清理记录失败: %v", st.ID, source.Name, err)
+	}
+	for _, bvid := range bvids {
+		guard.pruned[bvid] = struct{}{}
+	}
+	return guard
+}
+
+// skip 判断新视频是否因保留策略跳过，返回跳过原因
+func (g *retentionGuard) skip(video adapter.VideoInfo) (string, bool) {
+	if _, ok := g.pruned[video.BVid]; ok {
+		return "已按保留策略清理", true
+	}
+	if !g.cutoff.IsZero() && !video.AddTime.IsZero() && video.AddTime.Before(g.cutoff) {
+		return "超出保留天数", true
+	}
+	return "", false
+}
+
+// isPruned 视频是否已按保留策略清理（增量扫描时视为已知视频）
+func (g *retentionGuard) isPruned(bvid string) bool {
+	_, ok := g.pruned[bvid]
+	return ok
+}
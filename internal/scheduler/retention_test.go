@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"bili-download/internal/adapter"
+)
+
+func TestRetentionGuardSkip(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	guard := &retentionGuard{
+		pruned: map[string]struct{}{"BVpruned": {}},
+		cutoff: now.AddDate(0, 0, -7),
+	}
+
+	tests := []struct {
+		video adapter.VideoInfo
+		want  bool
+	}{
+		{adapter.VideoInfo{BVid: "BVpruned", AddTime: now}, true},
+		{adapter.VideoInfo{BVid: "BVold", AddTime: now.AddDate(0, 0, -8)}, true},
+		{adapter.VideoInfo{BVid: "BVnew", AddTime: now.AddDate(0, 0, -1)}, false},
+		{adapter.VideoInfo{BVid: "BVnotime"}, false},
+	}
+	for _, tt := range tests {
+		if _, got := guard.skip(tt.video); got != tt.want {
+			t.Errorf("skip(%s) = %v, want %v", tt.video.BVid, got, tt.want)
+		}
+	}
+
+	// 未设置 max_age 时只跳过已清理的视频
+	guard.cutoff = time.Time{}
+	if _, got := guard.skip(adapter.VideoInfo{BVid: "BVold", AddTime: now.AddDate(-1, 0, 0)}); got {
+		t.Error("expected old video to be kept without max_age policy")
+	}
+	if !guard.isPruned("BVpruned") || guard.isPruned("BVnew") {
+		t.Error("isPruned mismatch")
+	}
+}
@@ -32,6 +32,11 @@ type Scheduler struct {
 	lastRunAt     *time.Time
 	nextRunAt     *time.Time
 	currentSyncID string
+	// 取消当前同步任务
+	syncCancel context.CancelFunc
+
+	// 各视频源下次同步时间（key 为视频源ID，如 fav_123）
+	sourceNextRun map[string]time.Time
 
 	// 事件处理
 	eventHandlers []EventHandler
@@ -43,7 +48,9 @@ type SchedulerStatus struct {
 	LastRunAt     *time.Time `json:"last_run_at"`
 	NextRunAt     *time.Time `json:"next_run_at"`
 	CurrentSyncID string     `json:"current_sync_id"`
-	Interval      int        `json:"interval"` // 秒
+	Interval      int        `json:"interval"`    // 默认同步间隔（秒），未单独设置间隔的视频源使用
+	Cron          string     `json:"cron"`        // 默认同步 cron 表达式，设置后替代 interval
+	QuietHours    bool       `json:"quiet_hours"` // 当前是否处于静默时段
 }
 
 // EventType 事件类型
@@ -57,6 +64,7 @@ const (
 	EventSyncFailed        EventType = "sync_failed"
 	EventSourceScanned     EventType = "source_scanned"
 	EventCredentialInvalid EventType = "credential_invalid"
+	EventVideosLost        EventType = "videos_lost" // 全量扫描发现视频从视频源消失
 	EventError             EventType = "error"
 )
 
@@ -85,6 +93,7 @@ func NewScheduler(cfg *config.Config, db *gorm.DB, dm *downloader.DownloadManage
 		downloadManager: dm,
 		ctx:             ctx,
 		cancelFunc:      cancel,
+		sourceNextRun:   make(map[string]time.Time),
 		eventHandlers:   make([]EventHandler, 0),
 	}
 
@@ -165,17 +174,15 @@ func (s *Scheduler) Start() error {
 		}
 	}()
 
-	// 初始化定时器
+	// 初始化定时器：定期检查各视频源是否到期，到期的视频源才会被同步
 	utils.Debug("[Scheduler] 初始化定时器...")
-	interval := time.Duration(s.config.Sync.Interval) * time.Second
-	s.ticker = time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+	s.cancelFunc = cancel
+	s.ticker = time.NewTicker(scheduleCheckInterval)
 	s.running = true
-	utils.Debug("[Scheduler] 定时器已初始化，间隔: %v", interval)
-
-	// 计算下次运行时间
-	nextRun := time.Now().Add(interval)
-	s.nextRunAt = &nextRun
-	utils.Debug("[Scheduler] 下次运行时间: %v", nextRun)
+	s.sourceNextRun = make(map[string]time.Time)
+	utils.Debug("[Scheduler] 定时器已初始化，检查间隔: %v", scheduleCheckInterval)
 
 	// 异步更新数据库状态，避免阻塞
 	utils.Debug("[Scheduler] 启动异步数据库状态更新")
@@ -223,16 +230,21 @@ func (s *Scheduler) Stop() error {
 
 	utils.Info("[Scheduler] 正在停止调度器...")
 
-	// 停止定时器
+	// 停止定时器并退出调度循环
 	if s.ticker != nil {
 		utils.Debug("[Scheduler] 停止定时器")
 		s.ticker.Stop()
 	}
+	if s.cancelFunc != nil {
+		s.cancelFunc()
+	}
 
 	// 取消当前同步任务（如果有）
 	if s.currentSyncID != "" {
-		utils.Warn("[Scheduler] 当前有同步任务运行: %s", s.currentSyncID)
-		// TODO: 实现取消逻辑
+		utils.Warn("[Scheduler] 取消当前同步任务: %s", s.currentSyncID)
+		if s.syncCancel != nil {
+			s.syncCancel()
+		}
 	}
 
 	s.running = false
@@ -272,39 +284,20 @@ func (s *Scheduler) UpdateConfig(cfg *config.Config) {
 	defer s.mu.Unlock()
 
 	oldInterval := s.config.Sync.Interval
+	oldCron := s.config.Sync.Cron
 	s.config = cfg
 
-	// 如果调度器正在运行且同步间隔发生变化，需要重启调度循环
-	if s.running && oldInterval != cfg.Sync.Interval {
-		utils.Info("同步间隔已从 %d 秒更改为 %d 秒，正在重启调度器...", oldInterval, cfg.Sync.Interval)
-
-		// 取消旧的上下文以停止调度循环
-		if s.cancelFunc != nil {
-			s.cancelFunc()
+	// 默认同步计划变化后，清空已推算的视频源计划，下次检查时按新计划与最后扫描时间重新推算
+	if oldInterval != cfg.Sync.Interval || oldCron != cfg.Sync.Cron {
+		if oldInterval != cfg.Sync.Interval {
+			utils.Info("同步间隔已从 %d 秒更改为 %d 秒，正在重新计算各视频源的同步计划...", oldInterval, cfg.Sync.Interval)
 		}
-
-		// 停止旧的ticker
-		if s.ticker != nil {
-			s.ticker.Stop()
+		if oldCron != cfg.Sync.Cron {
+			utils.Info("同步 cron 已从 %q 更改为 %q，正在重新计算各视频源的同步计划...", oldCron, cfg.Sync.Cron)
 		}
-
-		// 创建新的上下文
-		ctx, cancel := context.WithCancel(context.Background())
-		s.ctx = ctx
-		s.cancelFunc = cancel
-
-		// 创建新的ticker
-		interval := time.Duration(cfg.Sync.Interval) * time.Second
-		s.ticker = time.NewTicker(interval)
-
-		// 更新下次运行时间
-		nextRun := time.Now().Add(interval)
-		s.nextRunAt = &nextRun
-
-		// 启动新的调度循环
-		go s.runScheduleLoop()
-
-		utils.Info("调度器已重启，新的同步间隔: %d 秒，下次运行时间: %v", cfg.Sync.Interval, nextRun)
+		s.sourceNextRun = make(map[string]time.Time)
+		s.refreshNextRunAtLocked()
+		utils.Info("同步计划已更新，新的同步间隔: %d 秒，下次检查时按新计划安排各视频源", cfg.Sync.Interval)
 	}
 
 	utils.Info("调度器配置已更新")
@@ -333,6 +326,7 @@ func (s *Scheduler) TriggerManual() (string, error) {
 
 	// 设置当前同步ID
 	s.currentSyncID = syncID
+	s.syncCancel = syncTask.Cancel
 	s.mu.Unlock()
 	utils.Debug("[TriggerManual] 锁已释放")
 
@@ -364,10 +358,15 @@ func (s *Scheduler) TriggerManual() (string, error) {
 		utils.Debug("[TriggerManual] 清除当前同步ID")
 		s.mu.Lock()
 		s.currentSyncID = ""
+		s.syncCancel = nil
 		now := time.Now()
 		s.lastRunAt = &now
 		s.mu.Unlock()
 		utils.Debug("[TriggerManual] 当前同步ID已清除")
+		syncTask.Cancel()
+
+		// 手动同步覆盖了全部视频源，各视频源的计划从现在重新开始
+		s.markSourcesScheduled(syncTask.sources, now)
 
 		if err != nil {
 			utils.Error("[TriggerManual] 同步任务执行失败: %v", err)
@@ -423,6 +422,8 @@ func (s *Scheduler) GetStatus() *SchedulerStatus {
 		NextRunAt:     s.nextRunAt,
 		CurrentSyncID: s.currentSyncID,
 		Interval:      s.config.Sync.Interval,
+		Cron:          s.config.Sync.Cron,
+		QuietHours:    s.config.Sync.QuietHours.Contains(time.Now()),
 	}
 }
 
@@ -437,16 +438,21 @@ func (s *Scheduler) OnEvent(handler EventHandler) {
 func (s *Scheduler) runScheduleLoop() {
 	utils.Info("[runScheduleLoop] 调度循环已启动")
 
+	s.mu.RLock()
+	ticker := s.ticker
+	ctx := s.ctx
+	s.mu.RUnlock()
+
 	for {
 		utils.Debug("[runScheduleLoop] 等待事件...")
 		select {
-		case <-s.ticker.C:
-			// 定时触发
-			utils.Info("[runScheduleLoop] 定时器触发，开始同步...")
+		case <-ticker.C:
+			// 定时检查到期的视频源
+			utils.Debug("[runScheduleLoop] 定时器触发，检查到期视频源...")
 			s.performSync("auto")
-			utils.Debug("[runScheduleLoop] 定时同步完成，继续等待...")
+			utils.Debug("[runScheduleLoop] 定时检查完成，继续等待...")
 
-		case <-s.ctx.Done():
+		case <-ctx.Done():
 			// 上下文取消
 			utils.Info("[runScheduleLoop] 调度循环收到退出信号")
 			return
@@ -454,9 +460,9 @@ func (s *Scheduler) runScheduleLoop() {
 	}
 }
 
-// performSync 执行同步
+// performSync 同步已到期的视频源
 func (s *Scheduler) performSync(triggerType string) {
-	utils.Info("[performSync] 方法被调用，触发类型: %s", triggerType)
+	utils.Debug("[performSync] 检查到期视频源，触发类型: %s", triggerType)
 
 	utils.Debug("[performSync] 尝试获取锁...")
 	s.mu.Lock()
@@ -465,57 +471,79 @@ func (s *Scheduler) performSync(triggerType string) {
 	// 检查是否已有同步任务在运行
 	if s.currentSyncID != "" {
 		s.mu.Unlock()
-		utils.Warn("[performSync] 同步任务已在运行，跳过本次触发")
+		utils.Debug("[performSync] 同步任务已在运行，跳过本次检查")
 		return
 	}
+	cfg := s.config
 	s.mu.Unlock()
 	utils.Debug("[performSync] 锁已释放")
 
-	// 执行同步任务
-	utils.Debug("[performSync] 创建上下文")
-	ctx := context.Background()
+	// 静默时段内不触发自动同步，到期的视频源在静默时段结束后的首次检查中同步
+	if triggerType == "auto" && cfg.Sync.QuietHours.Contains(time.Now()) {
+		utils.Debug("[performSync] 处于静默时段（%s-%s），跳过本次检查", cfg.Sync.QuietHours.Start, cfg.Sync.QuietHours.End)
+		return
+	}
+
+	syncTask := NewSyncTask(context.Background(), triggerType, s.db, cfg, s.downloadManager).withScheduler(s)
+
+	sources, err := syncTask.loadVideoSources()
+	if err != nil {
+		utils.Error("[performSync] 加载视频源失败: %v", err)
+		s.emitEvent(Event{
+			Type: EventSyncFailed,
+			Data: map[string]interface{}{
+				"error": err.Error(),
+			},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	due := s.selectDueSources(sources, time.Now())
+	if len(due) == 0 {
+		utils.Debug("[performSync] 没有到期的视频源")
+		return
+	}
+
+	utils.Info("[performSync] 方法被调用，触发类型: %s", triggerType)
+	utils.Info("[performSync] %d/%d 个视频源已到期，开始同步", len(due), len(sources))
 	utils.Debug("[performSync] 调用 executeSync")
-	syncID, err := s.executeSync(ctx, triggerType)
+	syncID, err := s.executeSync(syncTask.withSources(due))
 	utils.Debug("[performSync] executeSync 返回，同步ID: %s, 错误: %v", syncID, err)
-
 	if err != nil {
 		utils.Error("[performSync] 同步任务执行失败: %v", err)
 		utils.Debug("[performSync] 发送同步失败事件")
 		s.emitEvent(Event{
 			Type: EventSyncFailed,
 			Data: map[string]interface{}{
-				"error": err.Error(),
+				"sync_id": syncID,
+				"error":   err.Error(),
 			},
 			Timestamp: time.Now(),
 		})
 	}
 
-	// 更新下次运行时间
+	// 按各视频源的计划推算下次同步时间（失败的视频源同样等到下一周期再重试）
 	utils.Debug("[performSync] 更新下次运行时间")
-	s.mu.Lock()
-	interval := time.Duration(s.config.Sync.Interval) * time.Second
-	nextRun := time.Now().Add(interval)
-	s.nextRunAt = &nextRun
-	s.mu.Unlock()
-	utils.Debug("[performSync] 下次运行时间已更新: %v", nextRun)
+	s.markSourcesScheduled(due, time.Now())
+	if nextRun := s.GetStatus().NextRunAt; nextRun != nil {
+		utils.Debug("[performSync] 下次运行时间已更新: %v", *nextRun)
+	}
 
 	utils.Info("[performSync] 同步任务完成: %s", syncID)
 }
 
-// executeSync 执行同步逻辑
-func (s *Scheduler) executeSync(ctx context.Context, triggerType string) (string, error) {
-	utils.Info("[executeSync] 方法被调用，触发类型: %s", triggerType)
-
-	// 创建同步任务
-	utils.Debug("[executeSync] 创建同步任务...")
-	syncTask := NewSyncTask(ctx, triggerType, s.db, s.config, s.downloadManager).withScheduler(s)
-	utils.Debug("[executeSync] 同步任务已创建: %s", syncTask.ID)
+// executeSync 执行同步任务
+func (s *Scheduler) executeSync(syncTask *SyncTask) (string, error) {
+	utils.Info("[executeSync] 方法被调用，触发类型: %s", syncTask.TriggerType)
+	utils.Debug("[executeSync] 同步任务: %s，视频源数: %d", syncTask.ID, len(syncTask.sources))
 
 	// 设置当前同步ID
 	utils.Debug("[executeSync] 尝试获取锁...")
 	s.mu.Lock()
 	utils.Debug("[executeSync] 锁已获取")
 	s.currentSyncID = syncTask.ID
+	s.syncCancel = syncTask.Cancel
 	now := time.Now()
 	s.lastRunAt = &now
 	s.mu.Unlock()
@@ -527,7 +555,7 @@ func (s *Scheduler) executeSync(ctx context.Context, triggerType string) (string
 		Type: EventSyncStarted,
 		Data: map[string]interface{}{
 			"sync_id":      syncTask.ID,
-			"trigger_type": triggerType,
+			"trigger_type": syncTask.TriggerType,
 		},
 		Timestamp: time.Now(),
 	})
@@ -542,7 +570,9 @@ func (s *Scheduler) executeSync(ctx context.Context, triggerType string) (string
 	utils.Debug("[executeSync] 清除当前同步ID")
 	s.mu.Lock()
 	s.currentSyncID = ""
+	s.syncCancel = nil
 	s.mu.Unlock()
+	syncTask.Cancel()
 	utils.Debug("[executeSync] 当前同步ID已清除")
 
 	if err != nil {
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"bili-download/internal/cron"
+	"bili-download/internal/utils"
+)
+
+// scheduleCheckInterval 调度循环检查到期视频源的间隔
+var scheduleCheckInterval = 30 * time.Second
+
+// minSourceScanInterval 视频源同步间隔下限（秒），避免过于频繁地请求B站接口
+const minSourceScanInterval = 60
+
+// sourceSchedule 视频源同步计划：设置了 cron 表达式时优先使用，否则按固定间隔
+type sourceSchedule struct {
+	interval time.Duration
+	cron     *cron.Schedule
+}
+
+// next 计算 from 之后的下一次同步时间
+func (s sourceSchedule) next(from time.Time) time.Time {
+	if s.cron != nil {
+		if next := s.cron.Next(from); !next.IsZero() {
+			return next
+		}
+	}
+	return from.Add(s.interval)
+}
+
+// SourceScheduleStatus 视频源同步计划状态
+type SourceScheduleStatus struct {
+	SourceID   string     `json:"source_id"`
+	SourceType string     `json:"source_type"`
+	SourceName string     `json:"source_name"`
+	Interval   int        `json:"interval"` // 生效的同步间隔（秒）
+	Cron       string     `json:"cron,omitempty"`
+	LastScanAt *time.Time `json:"last_scan_at"`
+	NextRunAt  *time.Time `json:"next_run_at"`
+}
+
+// ValidateSourceSchedule 校验视频源的同步间隔与 cron 表达式
+func ValidateSourceSchedule(interval int, cronExpr string) error {
+	if interval < 0 || (interval > 0 && interval < minSourceScanInterval) {
+		return fmt.Errorf("同步间隔必须为 0（使用全局间隔）或不小于 %d 秒", minSourceScanInterval)
+	}
+	if cronExpr != "" {
+		if _, err := cron.Parse(cronExpr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSourceSchedule 根据视频源配置与全局同步配置得到同步计划
+// 优先级：视频源 cron > 视频源间隔 > 全局 cron > 全局间隔
+func (s *Scheduler) resolveSourceSchedule(source VideoSourceInfo) sourceSchedule {
+	s.mu.RLock()
+	globalInterval := s.config.Sync.Interval
+	globalCron := s.config.Sync.Cron
+	s.mu.RUnlock()
+
+	interval := source.ScanInterval
+	if interval <= 0 {
+		interval = globalInterval
+	}
+	schedule := sourceSchedule{interval: time.Duration(interval) * time.Second}
+
+	cronExpr := source.ScanCron
+	if cronExpr == "" && source.ScanInterval <= 0 {
+		cronExpr = globalCron
+	}
+	if cronExpr != "" {
+		parsed, err := cron.Parse(cronExpr)
+		if err != nil {
+			utils.Warn("视频源 %s 的 cron 表达式无效，改用同步间隔: %v", source.Name, err)
+		} else {
+			schedule.cron = parsed
+		}
+	}
+
+	return schedule
+}
+
+// sourceNextRunLocked 获取视频源下次同步时间，首次遇到的视频源根据最后扫描时间推算（调用方需持有锁）
+// 从未扫描过的视频源立即到期
+func (s *Scheduler) sourceNextRunLocked(source VideoSourceInfo, schedule sourceSchedule, now time.Time) time.Time {
+	if next, ok := s.sourceNextRun[source.ID]; ok {
+		return next
+	}
+
+	next := now
+	if source.LastScanAt != nil {
+		next = schedule.next(*source.LastScanAt)
+	}
+	s.sourceNextRun[source.ID] = next
+	return next
+}
+
+// selectDueSources 从全部视频源中挑出已到期的视频源，同时清理已删除视频源的计划
+func (s *Scheduler) selectDueSources(sources []VideoSourceInfo, now time.Time) []VideoSourceInfo {
+	schedules := make([]sourceSchedule, len(sources))
+	for i, source := range sources {
+		schedules[i] = s.resolveSourceSchedule(source)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	present := make(map[string]bool, len(sources))
+	due := make([]VideoSourceInfo, 0)
+	for i, source := range sources {
+		present[source.ID] = true
+		if !s.sourceNextRunLocked(source, schedules[i], now).After(now) {
+			due = append(due, source)
+		}
+	}
+
+	for id := range s.sourceNextRun {
+		if !present[id] {
+			delete(s.sourceNextRun, id)
+		}
+	}
+
+	s.refreshNextRunAtLocked()
+	return due
+}
+
+// markSourcesScheduled 视频源同步后按各自计划推算下次同步时间
+func (s *Scheduler) markSourcesScheduled(sources []VideoSourceInfo, finishedAt time.Time) {
+	schedules := make([]sourceSchedule, len(sources))
+	for i, source := range sources {
+		schedules[i] = s.resolveSourceSchedule(source)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, source := range sources {
+		s.sourceNextRun[source.ID] = schedules[i].next(finishedAt)
+	}
+	s.refreshNextRunAtLocked()
+}
+
+// refreshNextRunAtLocked 调度器的下次运行时间取所有视频源中最早的一个（调用方需持有锁）
+func (s *Scheduler) refreshNextRunAtLocked() {
+	if !s.running {
+		s.nextRunAt = nil
+		return
+	}
+
+	var earliest *time.Time
+	for _, next := range s.sourceNextRun {
+		if earliest == nil || next.Before(*earliest) {
+			n := next
+			earliest = &n
+		}
+	}
+	s.nextRunAt = earliest
+}
+
+// ResetSourceSchedules 清空已记录的视频源计划，下次检查时根据最新配置与最后扫描时间重新推算
+func (s *Scheduler) ResetSourceSchedules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sourceNextRun = make(map[string]time.Time)
+}
+
+// GetSourceSchedules 获取所有已启用视频源的同步计划
+func (s *Scheduler) GetSourceSchedules() ([]SourceScheduleStatus, error) {
+	s.mu.RLock()
+	cfg := s.config
+	s.mu.RUnlock()
+
+	st := NewSyncTask(context.Background(), "preview", s.db, cfg, s.downloadManager)
+	sources, err := st.loadVideoSources()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]SourceScheduleStatus, 0, len(sources))
+	for _, source := range sources {
+		schedule := s.resolveSourceSchedule(source)
+
+		s.mu.Lock()
+		next := s.sourceNextRunLocked(source, schedule, now)
+		s.mu.Unlock()
+
+		status := SourceScheduleStatus{
+			SourceID:   source.ID,
+			SourceType: source.Type,
+			SourceName: source.Name,
+			Interval:   int(schedule.interval / time.Second),
+			LastScanAt: source.LastScanAt,
+			NextRunAt:  &next,
+		}
+		if schedule.cron != nil {
+			status.Cron = schedule.cron.String()
+		}
+		result = append(result, status)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].NextRunAt.Before(*result[j].NextRunAt)
+	})
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+)
+
+func TestSelectDueSourcesUsesPerSourceSchedules(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+	lastScan := now.Add(-15 * time.Minute)
+
+	s := &Scheduler{
+		config:        &config.Config{Sync: config.SyncConfig{Interval: 3600}},
+		running:       true,
+		sourceNextRun: make(map[string]time.Time),
+	}
+
+	sources := []VideoSourceInfo{
+		{ID: "sub_1", Name: "fast", ScanInterval: 600, LastScanAt: &lastScan},
+		{ID: "fav_1", Name: "default", LastScanAt: &lastScan},
+		{ID: "fav_2", Name: "nightly", ScanCron: "0 3 * * *", LastScanAt: &lastScan},
+		{ID: "col_1", Name: "never scanned"},
+	}
+
+	due := s.selectDueSources(sources, now)
+	if len(due) != 2 || due[0].ID != "sub_1" || due[1].ID != "col_1" {
+		t.Fatalf("expected sub_1 and col_1 to be due, got %+v", due)
+	}
+
+	wantNightly := time.Date(2024, 3, 16, 3, 0, 0, 0, time.Local)
+	if got := s.sourceNextRun["fav_2"]; !got.Equal(wantNightly) {
+		t.Fatalf("expected cron source to run at %v, got %v", wantNightly, got)
+	}
+
+	s.markSourcesScheduled(due, now)
+	if got := s.sourceNextRun["sub_1"]; !got.Equal(now.Add(10 * time.Minute)) {
+		t.Fatalf("expected fast source to run again in 10 minutes, got %v", got)
+	}
+	if s.nextRunAt == nil || !s.nextRunAt.Equal(now.Add(10*time.Minute)) {
+		t.Fatalf("expected scheduler next run to be the earliest source, got %v", s.nextRunAt)
+	}
+
+	// 删除的视频源不再保留计划
+	if due := s.selectDueSources(sources[:1], now.Add(time.Minute)); len(due) != 0 {
+		t.Fatalf("expected no sources to be due, got %+v", due)
+	}
+	if _, ok := s.sourceNextRun["fav_2"]; ok {
+		t.Fatal("expected removed source schedule to be pruned")
+	}
+}
+
+func TestValidateSourceSchedule(t *testing.T) {
+	if err := ValidateSourceSchedule(0, ""); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+	if err := ValidateSourceSchedule(30, ""); err == nil {
+		t.Fatal("expected too short interval to be rejected")
+	}
+	if err := ValidateSourceSchedule(0, "0 3 * *"); err == nil {
+		t.Fatal("expected malformed cron to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"bili-download/internal/utils"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +38,9 @@ type SyncTask struct {
 	VideosQueued   int
 	TasksCreated   int
 
+	// 本次同步的视频源（为空时加载全部已启用的视频源）
+	sources []VideoSourceInfo
+
 	// 详细信息
 	SourceScans []*models.VideoSourceScan
 	Errors      []TaskError
@@ -45,10 +50,12 @@ type SyncTask struct {
 
 	// 依赖
 	ctx             context.Context
+	cancel          context.CancelFunc
 	db              *gorm.DB
 	config          *config.Config
 	downloadManager *downloader.DownloadManager
 	biliClient      *bilibili.Client
+	filterEngine    *FilterEngine
 	scheduler       *Scheduler
 }
 
@@ -63,17 +70,28 @@ type TaskError struct {
 // VideoSourceInfo 视频源信息
 type VideoSourceInfo struct {
 	ID         string
-	Type       string // favorite / submission / collection / watch_later
+	Type       string // favorite / submission / collection / watch_later / bangumi
 	Name       string
 	Path       string
 	Priority   int
 	Rule       string
 	LastScanAt *time.Time
-	Adapter    adapter.VideoSource
+	// 最后全量扫描时间，超过全量扫描间隔时本次扫描不使用增量
+	LastFullScanAt *time.Time
+	// 同步计划：ScanCron 优先，其次 ScanInterval（秒），均未设置时使用全局同步间隔
+	ScanInterval int
+	ScanCron     string
+	// 保留策略：同步完成后由清理任务执行，同步时跳过已清理或超出保留天数的视频
+	RetentionMode  string
+	RetentionValue int
+	// PartialList 全量扫描也拿不到完整的远端列表（如UP主投稿的动态接口），不做失效检测
+	PartialList bool
+	Adapter     adapter.VideoSource
 }
 
 // NewSyncTask 创建同步任务
 func NewSyncTask(ctx context.Context, triggerType string, db *gorm.DB, cfg *config.Config, dm *downloader.DownloadManager) *SyncTask {
+	ctx, cancel := context.WithCancel(ctx)
 	return &SyncTask{
 		ID:              fmt.Sprintf("sync-%s-%s", time.Now().Format("20060102-150405"), uuid.New().String()[:8]),
 		TriggerType:     triggerType,
@@ -82,23 +100,36 @@ func NewSyncTask(ctx context.Context, triggerType string, db *gorm.DB, cfg *conf
 		SourceScans:     make([]*models.VideoSourceScan, 0),
 		Errors:          make([]TaskError, 0),
 		ctx:             ctx,
+		cancel:          cancel,
 		db:              db,
 		config:          cfg,
 		downloadManager: dm,
 		biliClient:      bilibili.NewClient(cfg),
+		filterEngine:    newFilterEngineFromConfig(cfg),
 	}
 }
 
+// Cancel 取消同步任务，中断进行中的 B 站接口请求与扫描
+func (st *SyncTask) Cancel() {
+	st.cancel()
+}
+
 // withScheduler 注入 Scheduler 引用，用于事件发送
 func (st *SyncTask) withScheduler(s *Scheduler) *SyncTask {
 	st.scheduler = s
 	return st
 }
 
+// withSources 指定本次同步的视频源（仅同步到期的视频源）
+func (st *SyncTask) withSources(sources []VideoSourceInfo) *SyncTask {
+	st.sources = sources
+	return st
+}
+
 // isBiliSource 判断是否为 B 站类型视频源
 func isBiliSource(sourceType string) bool {
 	switch sourceType {
-	case "favorite", "submission", "collection", "watch_later":
+	case "favorite", "submission", "collection", "watch_later", "bangumi":
 		return true
 	}
 	return false
@@ -110,7 +141,7 @@ func (st *SyncTask) notifyCredentialInvalidOnce(source VideoSourceInfo, scanErr
 		return
 	}
 	// 二次确认：调用 ValidateCredential 直接判定凭据状态
-	if err := st.biliClient.ValidateCredential(); err == nil {
+	if err := st.biliClient.ValidateCredential(st.ctx); err == nil {
 		return
 	}
 	st.credentialInvalidNotified = true
@@ -133,10 +164,15 @@ func (st *SyncTask) notifyCredentialInvalidOnce(source VideoSourceInfo, scanErr
 func (st *SyncTask) Execute() error {
 	utils.Info("[%s] 开始执行同步任务", st.ID)
 
-	// 1. 加载所有启用的视频源
-	sources, err := st.loadVideoSources()
-	if err != nil {
-		return fmt.Errorf("加载视频源失败: %w", err)
+	// 1. 加载视频源（未指定时加载所有启用的视频源）
+	sources := st.sources
+	if sources == nil {
+		loaded, err := st.loadVideoSources()
+		if err != nil {
+			return fmt.Errorf("加载视频源失败: %w", err)
+		}
+		sources = loaded
+		st.sources = loaded
 	}
 
 	st.SourcesTotal = len(sources)
@@ -146,13 +182,16 @@ func (st *SyncTask) Execute() error {
 	for _, source := range sources {
 		select {
 		case <-st.ctx.Done():
-			utils.Warn("[%s] 同步任务被取消", st.ID)
-			st.Status = "cancelled"
-			return st.saveToDatabase()
+			return st.cancelled()
 
 		default:
 			// 扫描视频源
 			scanResult, err := st.scanVideoSource(source)
+			if err != nil && st.ctx.Err() != nil {
+				// 扫描中途被取消，不计入视频源失败
+				utils.Warn("[%s] 视频源 %s 扫描被中断", st.ID, source.Name)
+				return st.cancelled()
+			}
 			if err != nil {
 				utils.Error("[%s] 扫描视频源失败: %s - %v", st.ID, source.Name, err)
 				st.SourcesFailed++
@@ -173,6 +212,9 @@ func (st *SyncTask) Execute() error {
 				st.SourceScans = append(st.SourceScans, scanResult)
 				// 更新视频源健康状态
 				st.updateSourceHealth(source.ID, source.Type, true, "")
+				if scanResult.FullScan {
+					st.markFullScanned(source.ID, source.Type, scanResult.ScannedAt)
+				}
 			}
 		}
 	}
@@ -189,6 +231,15 @@ func (st *SyncTask) Execute() error {
 	return st.saveToDatabase()
 }
 
+// cancelled 标记同步任务已取消并保存
+func (st *SyncTask) cancelled() error {
+	utils.Warn("[%s] 同步任务被取消", st.ID)
+	endTime := time.Now()
+	st.EndAt = &endTime
+	st.Status = "cancelled"
+	return st.saveToDatabase()
+}
+
 // loadVideoSources 加载所有启用的视频源
 func (st *SyncTask) loadVideoSources() ([]VideoSourceInfo, error) {
 	sources := make([]VideoSourceInfo, 0)
@@ -200,26 +251,7 @@ func (st *SyncTask) loadVideoSources() ([]VideoSourceInfo, error) {
 	}
 
 	for _, fav := range favorites {
-		favConfig := &adapter.FavoriteConfig{
-			SourceConfig: adapter.SourceConfig{
-				Type:    adapter.SourceTypeFavorite,
-				ID:      fmt.Sprintf("fav_%d", fav.FID),
-				Name:    fav.Name,
-				Enabled: fav.Enabled,
-			},
-			MediaID: fmt.Sprintf("%d", fav.FID),
-		}
-		favAdapter := adapter.NewFavoriteAdapter(st.biliClient, favConfig)
-		sources = append(sources, VideoSourceInfo{
-			ID:         fmt.Sprintf("fav_%d", fav.FID),
-			Type:       "favorite",
-			Name:       fav.Name,
-			Path:       fav.Path,
-			Priority:   fav.Priority,
-			Rule:       fav.Rule,
-			LastScanAt: fav.LastScanAt,
-			Adapter:    favAdapter,
-		})
+		sources = append(sources, st.favoriteSource(fav))
 	}
 
 	// 2. 加载UP主投稿
@@ -229,26 +261,7 @@ func (st *SyncTask) loadVideoSources() ([]VideoSourceInfo, error) {
 	}
 
 	for _, sub := range submissions {
-		subConfig := &adapter.SubmissionConfig{
-			SourceConfig: adapter.SourceConfig{
-				Type:    adapter.SourceTypeSubmission,
-				ID:      fmt.Sprintf("sub_%d", sub.UpperID),
-				Name:    sub.Name,
-				Enabled: sub.Enabled,
-			},
-			Mid: fmt.Sprintf("%d", sub.UpperID),
-		}
-		subAdapter := adapter.NewSubmissionAdapter(st.biliClient, subConfig)
-		sources = append(sources, VideoSourceInfo{
-			ID:         fmt.Sprintf("sub_%d", sub.UpperID),
-			Type:       "submission",
-			Name:       sub.Name,
-			Path:       sub.Path,
-			Priority:   sub.Priority,
-			Rule:       sub.Rule,
-			LastScanAt: sub.LastScanAt,
-			Adapter:    subAdapter,
-		})
+		sources = append(sources, st.submissionSource(sub))
 	}
 
 	// 3. 加载合集
@@ -258,28 +271,7 @@ func (st *SyncTask) loadVideoSources() ([]VideoSourceInfo, error) {
 	}
 
 	for _, col := range collections {
-		colConfig := &adapter.CollectionConfig{
-			SourceConfig: adapter.SourceConfig{
-				Type:    adapter.SourceTypeCollection,
-				ID:      fmt.Sprintf("col_%d", col.CID),
-				Name:    col.Name,
-				Enabled: col.Enabled,
-			},
-			Mid:            "", // 合集可能不需要 Mid，或者需要从其他地方获取
-			SeasonID:       fmt.Sprintf("%d", col.CID),
-			CollectionType: col.CType,
-		}
-		colAdapter := adapter.NewCollectionAdapter(st.biliClient, colConfig)
-		sources = append(sources, VideoSourceInfo{
-			ID:         fmt.Sprintf("col_%d", col.CID),
-			Type:       "collection",
-			Name:       col.Name,
-			Path:       col.Path,
-			Priority:   col.Priority,
-			Rule:       col.Rule,
-			LastScanAt: col.LastScanAt,
-			Adapter:    colAdapter,
-		})
+		sources = append(sources, st.collectionSource(col))
 	}
 
 	// 4. 加载稍后再看
@@ -289,30 +281,209 @@ func (st *SyncTask) loadVideoSources() ([]VideoSourceInfo, error) {
 	}
 
 	for _, wl := range watchLaters {
-		wlConfig := &adapter.WatchLaterConfig{
-			SourceConfig: adapter.SourceConfig{
-				Type:    adapter.SourceTypeWatchLater,
-				ID:      fmt.Sprintf("wl_%d", wl.ID),
-				Name:    wl.Name,
-				Enabled: wl.Enabled,
-			},
-		}
-		wlAdapter := adapter.NewWatchLaterAdapter(st.biliClient, wlConfig)
-		sources = append(sources, VideoSourceInfo{
-			ID:         fmt.Sprintf("wl_%d", wl.ID),
-			Type:       "watch_later",
-			Name:       wl.Name,
-			Path:       wl.Path,
-			Priority:   wl.Priority,
-			Rule:       wl.Rule,
-			LastScanAt: wl.LastScanAt,
-			Adapter:    wlAdapter,
-		})
+		sources = append(sources, st.watchLaterSource(wl))
+	}
+
+	// 5. 加载番剧
+	var bangumis []models.Bangumi
+	if err := st.db.Where("enabled = ?", true).Order("priority DESC, id ASC").Find(&bangumis).Error; err != nil {
+		return nil, fmt.Errorf("查询番剧失败: %w", err)
+	}
+
+	for _, bgm := range bangumis {
+		sources = append(sources, st.bangumiSource(bgm))
 	}
 
 	return sources, nil
 }
 
+// loadVideoSource 按类型和数据库ID加载单个视频源（不检查启用状态）
+func (st *SyncTask) loadVideoSource(sourceType string, id uint) (VideoSourceInfo, error) {
+	switch sourceType {
+	case "favorite":
+		var fav models.Favorite
+		if err := st.db.First(&fav, id).Error; err != nil {
+			return VideoSourceInfo{}, fmt.Errorf("查询收藏夹失败: %w", err)
+		}
+		return st.favoriteSource(fav), nil
+	case "submission":
+		var sub models.Submission
+		if err := st.db.First(&sub, id).Error; err != nil {
+			return VideoSourceInfo{}, fmt.Errorf("查询UP主投稿失败: %w", err)
+		}
+		return st.submissionSource(sub), nil
+	case "collection":
+		var col models.Collection
+		if err := st.db.First(&col, id).Error; err != nil {
+			return VideoSourceInfo{}, fmt.Errorf("查询合集失败: %w", err)
+		}
+		return st.collectionSource(col), nil
+	case "watch_later":
+		var wl models.WatchLater
+		if err := st.db.First(&wl, id).Error; err != nil {
+			return VideoSourceInfo{}, fmt.Errorf("查询稍后再看失败: %w", err)
+		}
+		return st.watchLaterSource(wl), nil
+	case "bangumi":
+		var bgm models.Bangumi
+		if err := st.db.First(&bgm, id).Error; err != nil {
+			return VideoSourceInfo{}, fmt.Errorf("查询番剧失败: %w", err)
+		}
+		return st.bangumiSource(bgm), nil
+	}
+	return VideoSourceInfo{}, fmt.Errorf("不支持的视频源类型: %s", sourceType)
+}
+
+// favoriteSource 构建收藏夹视频源
+func (st *SyncTask) favoriteSource(fav models.Favorite) VideoSourceInfo {
+	favConfig := &adapter.FavoriteConfig{
+		SourceConfig: adapter.SourceConfig{
+			Type:         adapter.SourceTypeFavorite,
+			ID:           fmt.Sprintf("fav_%d", fav.FID),
+			Name:         fav.Name,
+			Enabled:      fav.Enabled,
+			ScanInterval: fav.ScanInterval,
+		},
+		MediaID: fmt.Sprintf("%d", fav.FID),
+	}
+	return VideoSourceInfo{
+		ID:             fmt.Sprintf("fav_%d", fav.FID),
+		Type:           "favorite",
+		Name:           fav.Name,
+		Path:           fav.Path,
+		Priority:       fav.Priority,
+		Rule:           fav.Rule,
+		LastScanAt:     fav.LastScanAt,
+		LastFullScanAt: fav.LastFullScanAt,
+		ScanInterval:   fav.ScanInterval,
+		ScanCron:       fav.ScanCron,
+		RetentionMode:  fav.RetentionMode,
+		RetentionValue: fav.RetentionValue,
+		Adapter:        adapter.NewFavoriteAdapter(st.biliClient, favConfig),
+	}
+}
+
+// submissionSource 构建UP主投稿视频源
+func (st *SyncTask) submissionSource(sub models.Submission) VideoSourceInfo {
+	subConfig := &adapter.SubmissionConfig{
+		SourceConfig: adapter.SourceConfig{
+			Type:         adapter.SourceTypeSubmission,
+			ID:           fmt.Sprintf("sub_%d", sub.UpperID),
+			Name:         sub.Name,
+			Enabled:      sub.Enabled,
+			ScanInterval: sub.ScanInterval,
+		},
+		Mid:           fmt.Sprintf("%d", sub.UpperID),
+		UseDynamicAPI: sub.UseDynamicAPI,
+	}
+	return VideoSourceInfo{
+		ID:             fmt.Sprintf("sub_%d", sub.UpperID),
+		Type:           "submission",
+		Name:           sub.Name,
+		Path:           sub.Path,
+		Priority:       sub.Priority,
+		Rule:           sub.Rule,
+		LastScanAt:     sub.LastScanAt,
+		LastFullScanAt: sub.LastFullScanAt,
+		ScanInterval:   sub.ScanInterval,
+		ScanCron:       sub.ScanCron,
+		RetentionMode:  sub.RetentionMode,
+		RetentionValue: sub.RetentionValue,
+		// 动态流不包含删除了动态的投稿与合作投稿
+		PartialList: sub.UseDynamicAPI,
+		Adapter:     adapter.NewSubmissionAdapter(st.biliClient, subConfig),
+	}
+}
+
+// collectionSource 构建合集视频源
+func (st *SyncTask) collectionSource(col models.Collection) VideoSourceInfo {
+	colConfig := &adapter.CollectionConfig{
+		SourceConfig: adapter.SourceConfig{
+			Type:         adapter.SourceTypeCollection,
+			ID:           fmt.Sprintf("col_%d", col.CID),
+			Name:         col.Name,
+			Enabled:      col.Enabled,
+			ScanInterval: col.ScanInterval,
+		},
+		Mid:            "", // 合集可能不需要 Mid，或者需要从其他地方获取
+		SeasonID:       fmt.Sprintf("%d", col.CID),
+		CollectionType: col.CType,
+	}
+	return VideoSourceInfo{
+		ID:             fmt.Sprintf("col_%d", col.CID),
+		Type:           "collection",
+		Name:           col.Name,
+		Path:           col.Path,
+		Priority:       col.Priority,
+		Rule:           col.Rule,
+		LastScanAt:     col.LastScanAt,
+		LastFullScanAt: col.LastFullScanAt,
+		ScanInterval:   col.ScanInterval,
+		ScanCron:       col.ScanCron,
+		RetentionMode:  col.RetentionMode,
+		RetentionValue: col.RetentionValue,
+		Adapter:        adapter.NewCollectionAdapter(st.biliClient, colConfig),
+	}
+}
+
+// watchLaterSource 构建稍后再看视频源
+func (st *SyncTask) watchLaterSource(wl models.WatchLater) VideoSourceInfo {
+	wlConfig := &adapter.WatchLaterConfig{
+		SourceConfig: adapter.SourceConfig{
+			Type:         adapter.SourceTypeWatchLater,
+			ID:           fmt.Sprintf("wl_%d", wl.ID),
+			Name:         wl.Name,
+			Enabled:      wl.Enabled,
+			ScanInterval: wl.ScanInterval,
+		},
+	}
+	return VideoSourceInfo{
+		ID:             fmt.Sprintf("wl_%d", wl.ID),
+		Type:           "watch_later",
+		Name:           wl.Name,
+		Path:           wl.Path,
+		Priority:       wl.Priority,
+		Rule:           wl.Rule,
+		LastScanAt:     wl.LastScanAt,
+		LastFullScanAt: wl.LastFullScanAt,
+		ScanInterval:   wl.ScanInterval,
+		ScanCron:       wl.ScanCron,
+		RetentionMode:  wl.RetentionMode,
+		RetentionValue: wl.RetentionValue,
+		Adapter:        adapter.NewWatchLaterAdapter(st.biliClient, wlConfig),
+	}
+}
+
+// bangumiSource 构建番剧视频源
+func (st *SyncTask) bangumiSource(bgm models.Bangumi) VideoSourceInfo {
+	bgmConfig := &adapter.BangumiConfig{
+		SourceConfig: adapter.SourceConfig{
+			Type:         adapter.SourceTypeBangumi,
+			ID:           fmt.Sprintf("bgm_%d", bgm.SeasonID),
+			Name:         bgm.Name,
+			Enabled:      bgm.Enabled,
+			ScanInterval: bgm.ScanInterval,
+		},
+		SeasonID:     fmt.Sprintf("%d", bgm.SeasonID),
+		SeasonNumber: bgm.SeasonNumber,
+	}
+	return VideoSourceInfo{
+		ID:             fmt.Sprintf("bgm_%d", bgm.SeasonID),
+		Type:           "bangumi",
+		Name:           bgm.Name,
+		Path:           bgm.Path,
+		Priority:       bgm.Priority,
+		Rule:           bgm.Rule,
+		LastScanAt:     bgm.LastScanAt,
+		LastFullScanAt: bgm.LastFullScanAt,
+		ScanInterval:   bgm.ScanInterval,
+		ScanCron:       bgm.ScanCron,
+		RetentionMode:  bgm.RetentionMode,
+		RetentionValue: bgm.RetentionValue,
+		Adapter:        adapter.NewBangumiAdapter(st.biliClient, bgmConfig),
+	}
+}
+
 // scanVideoSource 扫描单个视频源
 func (st *SyncTask) scanVideoSource(source VideoSourceInfo) (*models.VideoSourceScan, error) {
 	startTime := time.Now()
@@ -331,10 +502,16 @@ func (st *SyncTask) scanVideoSource(source VideoSourceInfo) (*models.VideoSource
 		Limit: 0, // 不限制扫描数量
 	}
 
-	// 如果有上次扫描时间，使用增量扫描
-	if source.LastScanAt != nil {
+	guard := st.newRetentionGuard(source, st.getSourceDBID(source), startTime)
+
+	// 增量扫描：遇到上次扫描时间之前或已入库的视频即停止翻页
+	scanResult.FullScan = st.fullScanDue(source, startTime)
+	if !scanResult.FullScan {
 		scanOpts.OnlyNew = true
 		scanOpts.LastScanTime = *source.LastScanAt
+		scanOpts.IsKnown = st.knownVideoChecker(source, guard)
+	} else {
+		utils.Info("[%s] 视频源 %s 执行全量扫描", st.ID, source.Name)
 	}
 
 	videos, err := source.Adapter.Scan(st.ctx, scanOpts)
@@ -350,7 +527,7 @@ func (st *SyncTask) scanVideoSource(source VideoSourceInfo) (*models.VideoSource
 	utils.Info("[%s] 视频源 %s 发现 %d 个视频", st.ID, source.Name, len(videos))
 
 	// 处理视频
-	newCount, queuedCount, err := st.processVideos(videos, source)
+	newCount, queuedCount, filtered, err := st.processVideos(videos, source, guard)
 	if err != nil {
 		scanResult.Success = false
 		scanResult.ErrorMessage = err.Error()
@@ -359,6 +536,28 @@ func (st *SyncTask) scanVideoSource(source VideoSourceInfo) (*models.VideoSource
 
 	scanResult.VideosNew = newCount
 	scanResult.VideosQueued = queuedCount
+	scanResult.VideosFiltered = len(filtered)
+
+	// 全量扫描拿到了完整的远端列表，据此检测从视频源消失的视频
+	var lost []LostVideo
+	if scanResult.FullScan {
+		lost = st.detectLostVideos(source, videos)
+		scanResult.VideosLost = len(lost)
+	}
+
+	metadata := map[string]interface{}{}
+	if len(filtered) > 0 {
+		metadata["filtered_videos"] = filtered
+	}
+	if len(lost) > 0 {
+		metadata["lost_videos"] = lost
+		st.notifyVideosLost(source, lost)
+	}
+	if len(metadata) > 0 {
+		if data, err := json.Marshal(metadata); err == nil {
+			scanResult.Metadata = datatypes.JSON(data)
+		}
+	}
 	scanResult.DurationMs = int(time.Since(startTime).Milliseconds())
 
 	st.VideosNew += newCount
@@ -370,112 +569,240 @@ func (st *SyncTask) scanVideoSource(source VideoSourceInfo) (*models.VideoSource
 	return scanResult, nil
 }
 
+// notifyVideosLost 上报视频源中有视频消失的事件
+func (st *SyncTask) notifyVideosLost(source VideoSourceInfo, lost []LostVideo) {
+	if st.scheduler == nil {
+		return
+	}
+	st.scheduler.EmitEvent(Event{
+		Type: EventVideosLost,
+		Data: map[string]interface{}{
+			"source_id":   source.ID,
+			"source_name": source.Name,
+			"source_type": source.Type,
+			"sync_id":     st.ID,
+			"videos":      lost,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// fullScanDue 判断视频源本次是否需要全量扫描：从未扫描过，或距上次全量扫描已超过全量扫描间隔
+func (st *SyncTask) fullScanDue(source VideoSourceInfo, now time.Time) bool {
+	if source.LastScanAt == nil {
+		return true
+	}
+	days := st.config.Sync.FullScanDays
+	if days <= 0 {
+		return false
+	}
+	return source.LastFullScanAt == nil || now.Sub(*source.LastFullScanAt) >= time.Duration(days)*24*time.Hour
+}
+
+// knownVideoChecker 加载视频源已入库的视频，返回增量扫描用的判断函数
+// 加载失败时返回 nil，此时仅按上次扫描时间截止
+func (st *SyncTask) knownVideoChecker(source VideoSourceInfo, guard *retentionGuard) func(string) bool {
+	sourceDBID := st.getSourceDBID(source)
+	column := sourceForeignKey(source.Type)
+	if sourceDBID == 0 || column == "" {
+		return nil
+	}
+
+	var bvids []string
+	if err := st.db.Model(&models.Video{}).Where(column+" = ?", sourceDBID).Pluck("bvid", &bvids).Error; err != nil {
+		utils.Warn("[%s] 加载视频源 %s 已入库视频失败，仅按上次扫描时间增量扫描: %v", st.ID, source.Name, err)
+		return nil
+	}
+
+	known := make(map[string]struct{}, len(bvids))
+	for _, bvid := range bvids {
+		known[bvid] = struct{}{}
+	}
+	return func(bvid string) bool {
+		_, ok := known[bvid]
+		// 已按保留策略清理的视频同样视为已知
+		return ok || guard.isPruned(bvid)
+	}
+}
+
+// sourceForeignKey 视频源类型对应的 video 表外键列
+func sourceForeignKey(sourceType string) string {
+	switch sourceType {
+	case "favorite":
+		return "favorite_id"
+	case "submission":
+		return "submission_id"
+	case "collection":
+		return "collection_id"
+	case "watch_later":
+		return "watch_later_id"
+	case "bangumi":
+		return "bangumi_id"
+	}
+	return ""
+}
+
+// FilteredVideo 被过滤规则拒绝的视频（记录在 VideoSourceScan.Metadata 中）
+type FilteredVideo struct {
+	BVid   string `json:"bvid"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
 // processVideos 处理视频列表
-func (st *SyncTask) processVideos(videos []adapter.VideoInfo, source VideoSourceInfo) (newCount, queuedCount int, err error) {
+func (st *SyncTask) processVideos(videos []adapter.VideoInfo, source VideoSourceInfo, guard *retentionGuard) (newCount, queuedCount int, filtered []FilteredVideo, err error) {
 	// 获取视频源的数据库ID
 	sourceDBID := st.getSourceDBID(source)
 	if sourceDBID == 0 {
-		return 0, 0, fmt.Errorf("无法获取视频源数据库ID: %s", source.ID)
+		return 0, 0, nil, fmt.Errorf("无法获取视频源数据库ID: %s", source.ID)
+	}
+
+	// 解析视频源过滤规则，解析失败时仅使用全局规则
+	sourceRule, ruleErr := ParseRuleFromJSON(source.Rule)
+	if ruleErr != nil {
+		utils.Warn("[%s] 视频源 %s 的过滤规则解析失败，仅使用全局规则: %v", st.ID, source.Name, ruleErr)
+		sourceRule = nil
 	}
 
 	for _, video := range videos {
+		if err := st.ctx.Err(); err != nil {
+			return newCount, queuedCount, filtered, err
+		}
+
 		// 检查视频是否已存在于当前视频源
-		var existingVideo models.Video
-		query := st.db.Where("bvid = ?", video.BVid)
+		exists, err := st.videoExistsInSource(video.BVid, source.Type, sourceDBID)
+		if err != nil {
+			utils.Error("[%s] 查询视频失败: %s - %v", st.ID, video.BVid, err)
+			continue
+		}
+		if exists {
+			// 视频已存在于当前视频源，跳过
+			continue
+		}
 
-		// 根据视频源类型添加关联条件
-		switch source.Type {
-		case "favorite":
-			query = query.Where("favorite_id = ?", sourceDBID)
-		case "submission":
-			query = query.Where("submission_id = ?", sourceDBID)
-		case "collection":
-			query = query.Where("collection_id = ?", sourceDBID)
-		case "watch_later":
-			query = query.Where("watch_later_id = ?", sourceDBID)
+		// 视频源中已失效的条目（如收藏夹中被删除的视频）无法下载，不再入库
+		if video.Invalid {
+			st.VideosFiltered++
+			filtered = append(filtered, FilteredVideo{BVid: video.BVid, Title: video.Title, Reason: "视频已失效"})
+			continue
 		}
 
-		result := query.First(&existingVideo)
+		// 已按保留策略清理或超出保留天数的视频不再入库，避免下载后又被清理
+		if reason, skip := guard.skip(video); skip {
+			st.VideosFiltered++
+			filtered = append(filtered, FilteredVideo{BVid: video.BVid, Title: video.Title, Reason: reason})
+			continue
+		}
 
-		if result.Error == gorm.ErrRecordNotFound {
-			// 当前视频源中不存在此视频
-			utils.Info("[%s] 发现新视频: %s (BV%s)", st.ID, video.Title, video.BVid)
+		// 当前视频源中不存在此视频
+		utils.Info("[%s] 发现新视频: %s (BV%s)", st.ID, video.Title, video.BVid)
 
-			// 判断是否应该下载
-			if !st.shouldDownloadVideo(&video) {
-				utils.Debug("[%s] 视频被过滤: %s", st.ID, video.Title)
-				st.VideosFiltered++
-				continue
-			}
-
-			// 获取视频详情以获取Pages信息
-			if detail, err := st.biliClient.GetVideoDetail(video.BVid); err == nil {
-				pages := make([]adapter.PageInfo, 0, len(detail.Pages))
-				for _, p := range detail.Pages {
-					pages = append(pages, adapter.PageInfo{
-						CID:      p.CID,
-						Page:     p.Page,
-						Part:     p.Part,
-						Duration: p.Duration,
-						Width:    p.Dimension.Width,
-						Height:   p.Dimension.Height,
-					})
-				}
-				video.Pages = pages
-			}
-			time.Sleep(300 * time.Millisecond)
+		// 仅扫描模式只记录发现的新视频，不入库也不计入过滤
+		if st.config.Sync.ScanOnly {
+			continue
+		}
 
-			// 创建视频记录
-			newVideo := st.createVideoModel(video, source)
-			utils.Debug("[%s] 创建视频模型: %s, Pages: %d", st.ID, newVideo.Name, len(newVideo.Pages))
+		// 判断是否应该下载
+		if ok, reason := st.shouldDownloadVideo(&video, sourceRule); !ok {
+			utils.Debug("[%s] 视频被过滤: %s (%s)", st.ID, video.Title, reason)
+			st.VideosFiltered++
+			filtered = append(filtered, FilteredVideo{BVid: video.BVid, Title: video.Title, Reason: reason})
+			continue
+		}
 
-			// 使用 FullSaveAssociations 确保 Pages 也被保存
-			if err := st.db.Session(&gorm.Session{FullSaveAssociations: true}).Create(&newVideo).Error; err != nil {
-				utils.Error("[%s] 创建视频记录失败: %s - %v", st.ID, video.Title, err)
-				continue
+		// 获取视频详情以获取Pages信息（番剧剧集的分P已由适配器填充）
+		var detail *bilibili.VideoDetail
+		var detailErr error
+		if video.Episode == nil {
+			detail, detailErr = st.biliClient.GetVideoDetail(st.ctx, video.BVid)
+		}
+		if detail != nil && detailErr == nil {
+			pages := make([]adapter.PageInfo, 0, len(detail.Pages))
+			for _, p := range detail.Pages {
+				pages = append(pages, adapter.PageInfo{
+					CID:      p.CID,
+					Page:     p.Page,
+					Part:     p.Part,
+					Duration: p.Duration,
+					Width:    p.Dimension.Width,
+					Height:   p.Dimension.Height,
+				})
 			}
-			utils.Info("[%s] 视频记录创建成功: %s (ID: %d)", st.ID, newVideo.Name, newVideo.ID)
-			newCount++
+			video.Pages = pages
 
-			// 重新从数据库加载视频和它的Pages，确保关联数据完整
-			var videoWithPages models.Video
-			if err := st.db.Preload("Pages").First(&videoWithPages, newVideo.ID).Error; err != nil {
-				utils.Error("[%s] 加载视频Pages失败: %s - %v", st.ID, video.Title, err)
+			if ok, reason := st.recheckWithDetail(&video, detail, sourceRule); !ok {
+				utils.Debug("[%s] 视频被过滤: %s (%s)", st.ID, video.Title, reason)
+				st.VideosFiltered++
+				filtered = append(filtered, FilteredVideo{BVid: video.BVid, Title: video.Title, Reason: reason})
 				continue
 			}
-			utils.Debug("[%s] 加载视频完整数据: %s, Pages: %d", st.ID, videoWithPages.Name, len(videoWithPages.Pages))
+		}
 
-			// 创建下载任务
-			// 构建完整的基础目录：下载基础路径 + 视频源相对路径
-			baseDir := filepath.Join(st.config.Paths.DownloadBase, source.Path)
-			utils.Debug("[%s] 下载基础目录: %s", st.ID, baseDir)
+		// 创建视频记录
+		newVideo := st.createVideoModel(video, source)
+		utils.Debug("[%s] 创建视频模型: %s, Pages: %d", st.ID, newVideo.Name, len(newVideo.Pages))
 
-			if err := st.createDownloadTask(&videoWithPages, baseDir); err != nil {
-				utils.Error("[%s] 创建下载任务失败: %s - %v", st.ID, video.Title, err)
-				continue
-			}
+		// 使用 FullSaveAssociations 确保 Pages 也被保存
+		if err := st.db.Session(&gorm.Session{FullSaveAssociations: true}).Create(&newVideo).Error; err != nil {
+			utils.Error("[%s] 创建视频记录失败: %s - %v", st.ID, video.Title, err)
+			continue
+		}
+		utils.Info("[%s] 视频记录创建成功: %s (ID: %d)", st.ID, newVideo.Name, newVideo.ID)
+		newCount++
+
+		// 重新从数据库加载视频和它的Pages，确保关联数据完整
+		var videoWithPages models.Video
+		if err := st.db.Preload("Pages").First(&videoWithPages, newVideo.ID).Error; err != nil {
+			utils.Error("[%s] 加载视频Pages失败: %s - %v", st.ID, video.Title, err)
+			continue
+		}
+		utils.Debug("[%s] 加载视频完整数据: %s, Pages: %d", st.ID, videoWithPages.Name, len(videoWithPages.Pages))
 
-			queuedCount++
-		} else if result.Error != nil {
-			utils.Error("[%s] 查询视频失败: %s - %v", st.ID, video.BVid, result.Error)
+		// 创建下载任务
+		// 构建完整的基础目录：下载基础路径 + 视频源相对路径
+		baseDir := filepath.Join(st.config.Paths.DownloadBase, source.Path)
+		utils.Debug("[%s] 下载基础目录: %s", st.ID, baseDir)
+
+		if err := st.createDownloadTask(&videoWithPages, baseDir); err != nil {
+			utils.Error("[%s] 创建下载任务失败: %s - %v", st.ID, video.Title, err)
+			continue
 		}
-		// 视频已存在于当前视频源，跳过
+
+		queuedCount++
 	}
 
-	return newCount, queuedCount, nil
+	return newCount, queuedCount, filtered, nil
 }
 
-// shouldDownloadVideo 判断视频是否应该下载
-func (st *SyncTask) shouldDownloadVideo(video *adapter.VideoInfo) bool {
-	// 检查是否在配置的扫描模式下
-	if st.config.Sync.ScanOnly {
-		return false
+// videoExistsInSource 检查视频是否已存在于指定视频源
+func (st *SyncTask) videoExistsInSource(bvid, sourceType string, sourceDBID uint) (bool, error) {
+	query := st.db.Model(&models.Video{}).Where("bvid = ?", bvid)
+
+	// 根据视频源类型添加关联条件
+	if column := sourceForeignKey(sourceType); column != "" {
+		query = query.Where(column+" = ?", sourceDBID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
 	}
+	return count > 0, nil
+}
 
-	// 可以在这里集成过滤引擎
-	// TODO: 集成 FilterEngine
+// shouldDownloadVideo 判断视频是否应该下载，返回拒绝原因
+func (st *SyncTask) shouldDownloadVideo(video *adapter.VideoInfo, sourceRule *FilterRule) (bool, string) {
+	return st.filterEngine.ShouldDownload(*video, sourceRule)
+}
 
-	return true
+// recheckWithDetail 列表接口未返回版权信息时，用视频详情补全后重新判断（仅原创规则依赖此字段）
+// 同步与过滤预览共用，无需补全时直接放行
+func (st *SyncTask) recheckWithDetail(video *adapter.VideoInfo, detail *bilibili.VideoDetail, sourceRule *FilterRule) (bool, string) {
+	if detail == nil || video.Copyright != 0 || detail.Copyright == 0 {
+		return true, ""
+	}
+	video.Copyright = detail.Copyright
+	return st.shouldDownloadVideo(video, sourceRule)
 }
 
 // createVideoModel 创建视频模型
@@ -499,6 +826,11 @@ func (st *SyncTask) createVideoModel(video adapter.VideoInfo, source VideoSource
 		DownloadStatus: 0,
 		Path:           "", // 不在这里设置Path，由PrepareAndAddVideoTask统一设置
 	}
+	if video.Episode != nil {
+		newVideo.EpID = video.Episode.EpID
+		newVideo.SeasonNumber = video.Episode.SeasonNumber
+		newVideo.EpisodeNumber = video.Episode.EpisodeNumber
+	}
 
 	// 创建视频的所有分P
 	pages := make([]models.Page, 0, len(video.Pages))
@@ -546,6 +878,12 @@ func (st *SyncTask) createVideoModel(video adapter.VideoInfo, source VideoSource
 		if err := st.db.First(&wl).Error; err == nil {
 			newVideo.WatchLaterID = &wl.ID
 		}
+	case "bangumi":
+		// 从数据库查询 SeasonID 对应的 ID
+		var bgm models.Bangumi
+		if err := st.db.Where("season_id = ?", extractIDFromSourceID(source.ID)).First(&bgm).Error; err == nil {
+			newVideo.BangumiID = &bgm.ID
+		}
 	}
 
 	return newVideo
@@ -586,6 +924,11 @@ func (st *SyncTask) getSourceDBID(source VideoSourceInfo) uint {
 		if err := st.db.First(&wl).Error; err == nil {
 			return wl.ID
 		}
+	case "bangumi":
+		var bgm models.Bangumi
+		if err := st.db.Where("season_id = ?", numericID).First(&bgm).Error; err == nil {
+			return bgm.ID
+		}
 	}
 
 	return 0
@@ -647,9 +990,34 @@ func (st *SyncTask) getSourcePriority(video *models.Video) int {
 			return wl.Priority
 		}
 	}
+	if video.BangumiID != nil {
+		var bgm models.Bangumi
+		if err := st.db.First(&bgm, *video.BangumiID).Error; err == nil {
+			return bgm.Priority
+		}
+	}
 	return 0 // 默认优先级
 }
 
+// markFullScanned 记录视频源的全量扫描时间
+func (st *SyncTask) markFullScanned(sourceID, sourceType string, scannedAt time.Time) {
+	numericID := extractIDFromSourceID(sourceID)
+	update := map[string]interface{}{"last_full_scan_at": scannedAt}
+
+	switch sourceType {
+	case "favorite":
+		st.db.Model(&models.Favorite{}).Where("f_id = ?", numericID).Updates(update)
+	case "submission":
+		st.db.Model(&models.Submission{}).Where("upper_id = ?", numericID).Updates(update)
+	case "collection":
+		st.db.Model(&models.Collection{}).Where("c_id = ?", numericID).Updates(update)
+	case "watch_later":
+		st.db.Model(&models.WatchLater{}).Where("id = ?", numericID).Updates(update)
+	case "bangumi":
+		st.db.Model(&models.Bangumi{}).Where("season_id = ?", numericID).Updates(update)
+	}
+}
+
 // updateSourceHealth 更新视频源健康状态
 func (st *SyncTask) updateSourceHealth(sourceID, sourceType string, success bool, errorMsg string) {
 	now := time.Now()
@@ -674,6 +1042,8 @@ func (st *SyncTask) updateSourceHealth(sourceID, sourceType string, success bool
 			st.db.Model(&models.Collection{}).Where("c_id = ?", numericID).Updates(updates)
 		case "watch_later":
 			st.db.Model(&models.WatchLater{}).Updates(updates)
+		case "bangumi":
+			st.db.Model(&models.Bangumi{}).Where("season_id = ?", numericID).Updates(updates)
 		}
 
 		utils.Debug("[%s] 视频源 %s 健康状态已更新为 healthy", st.ID, sourceID)
@@ -704,6 +1074,11 @@ func (st *SyncTask) updateSourceHealth(sourceID, sourceType string, success bool
 			if err := st.db.First(&wl).Error; err == nil {
 				currentFailures = wl.ConsecutiveFailures
 			}
+		case "bangumi":
+			var bgm models.Bangumi
+			if err := st.db.Where("season_id = ?", numericID).First(&bgm).Error; err == nil {
+				currentFailures = bgm.ConsecutiveFailures
+			}
 		}
 
 		// 增加失败次数
@@ -741,6 +1116,8 @@ func (st *SyncTask) updateSourceHealth(sourceID, sourceType string, success bool
 			st.db.Model(&models.Collection{}).Where("c_id = ?", numericID).Updates(updates)
 		case "watch_later":
 			st.db.Model(&models.WatchLater{}).Updates(updates)
+		case "bangumi":
+			st.db.Model(&models.Bangumi{}).Where("season_id = ?", numericID).Updates(updates)
 		}
 
 		utils.Debug("[%s] 视频源 %s 健康状态已更新为 %s (连续失败: %d)", st.ID, sourceID, healthStatus, currentFailures)
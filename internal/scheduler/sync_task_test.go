@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"bili-download/internal/config"
+)
+
+func TestFullScanDue(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.Local)
+	lastScan := now.Add(-time.Hour)
+	recentFull := now.Add(-3 * 24 * time.Hour)
+	staleFull := now.Add(-8 * 24 * time.Hour)
+
+	st := &SyncTask{config: &config.Config{Sync: config.SyncConfig{FullScanDays: 7}}}
+
+	cases := []struct {
+		name   string
+		source VideoSourceInfo
+		want   bool
+	}{
+		{"never scanned", VideoSourceInfo{}, true},
+		{"never fully scanned", VideoSourceInfo{LastScanAt: &lastScan}, true},
+		{"recent full scan", VideoSourceInfo{LastScanAt: &lastScan, LastFullScanAt: &recentFull}, false},
+		{"stale full scan", VideoSourceInfo{LastScanAt: &lastScan, LastFullScanAt: &staleFull}, true},
+	}
+	for _, tc := range cases {
+		if got := st.fullScanDue(tc.source, now); got != tc.want {
+			t.Fatalf("%s: fullScanDue = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// 关闭周期全量扫描后，只有从未扫描过的视频源全量扫描
+	st.config.Sync.FullScanDays = 0
+	if st.fullScanDue(VideoSourceInfo{LastScanAt: &lastScan}, now) {
+		t.Fatal("expected incremental scan when periodic full scans are disabled")
+	}
+	if !st.fullScanDue(VideoSourceInfo{}, now) {
+		t.Fatal("expected first scan to be a full scan")
+	}
+}
@@ -99,6 +99,7 @@ type URLDownloadVideo struct {
 	WatchLaterID   *uint             `json:"watch_later_id,omitempty"`
 	CollectionID   *uint             `json:"collection_id,omitempty"`
 	SubmissionID   *uint             `json:"submission_id,omitempty"`
+	BangumiID      *uint             `json:"bangumi_id,omitempty"`
 	Pages          []URLDownloadPage `json:"pages,omitempty"`
 	CreatedAt      time.Time         `json:"created_at"`
 }
@@ -147,7 +148,7 @@ func (s *URLDownloadService) Submit(ctx context.Context, req URLDownloadRequest)
 	return s.submitYtdlp(ctx, req)
 }
 
-func (s *URLDownloadService) submitBilibili(_ context.Context, req URLDownloadRequest) (*URLDownloadResult, error) {
+func (s *URLDownloadService) submitBilibili(ctx context.Context, req URLDownloadRequest) (*URLDownloadResult, error) {
 	bvid, err := s.biliClient.ParseVideoURL(req.URL)
 	if err != nil {
 		return nil, &URLDownloadError{
@@ -172,7 +173,7 @@ func (s *URLDownloadService) submitBilibili(_ context.Context, req URLDownloadRe
 		return newURLDownloadResult(task, &existingVideo, URLDownloadSourceTypeBilibili, URLDownloadOutcomeExistingVideo), nil
 	}
 
-	videoDetail, err := s.biliClient.GetVideoDetail(bvid)
+	videoDetail, err := s.biliClient.GetVideoDetail(ctx, bvid)
 	if err != nil {
 		return nil, &URLDownloadError{
 			Type:    URLDownloadErrorTypeInternal,
@@ -198,7 +199,7 @@ func (s *URLDownloadService) submitBilibili(_ context.Context, req URLDownloadRe
 		ShouldDownload: true,
 	}
 
-	videoTags, err := s.biliClient.GetVideoTags(bvid)
+	videoTags, err := s.biliClient.GetVideoTags(ctx, bvid)
 	if err == nil && len(videoTags) > 0 {
 		tags := make([]string, len(videoTags))
 		for i, tag := range videoTags {
@@ -560,6 +561,7 @@ func (r *URLDownloadResult) APIVideo() models.Video {
 		WatchLaterID:   r.Video.WatchLaterID,
 		CollectionID:   r.Video.CollectionID,
 		SubmissionID:   r.Video.SubmissionID,
+		BangumiID:      r.Video.BangumiID,
 		CreatedAt:      r.Video.CreatedAt,
 	}
 
@@ -636,6 +638,7 @@ func toURLDownloadVideo(video *models.Video) URLDownloadVideo {
 		WatchLaterID:   video.WatchLaterID,
 		CollectionID:   video.CollectionID,
 		SubmissionID:   video.SubmissionID,
+		BangumiID:      video.BangumiID,
 		CreatedAt:      video.CreatedAt,
 	}
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	"bili-download/internal/bandwidth"
 	"bili-download/internal/utils"
 )
 
@@ -20,6 +21,7 @@ type Downloader struct {
 	concurrent       int  // 单笔记内并发下载数（0=串行）
 	enableLivePhoto  bool // 是否合成 Live Photo（默认 true）
 	keepLivePhotoSrc bool // 合成 Live Photo 后是否保留原始图+视频文件（默认 false）
+	limiter          *bandwidth.Limiter
 }
 
 // NewDownloader 创建下载器
@@ -31,6 +33,7 @@ func NewDownloader(httpClient *http.Client) *Downloader {
 		httpClient:      httpClient,
 		concurrent:      4,
 		enableLivePhoto: true,
+		limiter:         bandwidth.Shared(),
 	}
 }
 
@@ -315,6 +318,7 @@ func (d *Downloader) downloadFile(ctx context.Context, url, dst, displayName str
 		return "", 0, fmt.Errorf("创建文件失败: %w", err)
 	}
 
+	body := d.limiter.Reader(ctx, resp.Body)
 	total := resp.ContentLength
 	written := int64(0)
 	buf := make([]byte, 32*1024)
@@ -326,7 +330,7 @@ func (d *Downloader) downloadFile(ctx context.Context, url, dst, displayName str
 			return "", 0, ctx.Err()
 		default:
 		}
-		n, readErr := resp.Body.Read(buf)
+		n, readErr := body.Read(buf)
 		if n > 0 {
 			if _, werr := f.Write(buf[:n]); werr != nil {
 				f.Close()
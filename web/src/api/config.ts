@@ -1,5 +1,5 @@
 import { http } from '@/utils/request'
-import type { Config } from '@/types'
+import type { Config, TemplatePreviewResult } from '@/types'
 
 // 获取配置
 export const getConfig = () => {
@@ -20,6 +20,11 @@ export const validateConfig = (data: Partial<Config>) => {
   return http.post<{ valid: boolean; errors?: string[] }>('/config/validate', data)
 }
 
+// 命名模板预览（字段留空时使用当前配置）
+export const previewTemplate = (data: Partial<Config['template']>) => {
+  return http.post<TemplatePreviewResult>('/config/template/preview', data)
+}
+
 // 验证B站认证信息
 export const validateBilibiliCredential = () => {
   return http.post<{
@@ -1,4 +1,5 @@
 import { http } from '@/utils/request'
+import type { ReorganizePreview } from '@/types'
 
 export const refreshViewCounts = () => {
   return http.post<{ total: number; updated: number; failed: number; message: string }>('/maintenance/refresh-view-counts')
@@ -15,3 +16,11 @@ export const backfillQuality = () => {
 export const reparsePageMetadata = () => {
   return http.post<{ total: number; message: string }>('/maintenance/reparse-page-metadata')
 }
+
+export const previewReorganize = () => {
+  return http.get<ReorganizePreview>('/maintenance/reorganize/preview')
+}
+
+export const reorganizeLibrary = () => {
+  return http.post<{ total: number; pending: number; message: string }>('/maintenance/reorganize')
+}
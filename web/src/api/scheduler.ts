@@ -62,3 +62,23 @@ export const getTasks = (params?: {
 }) => {
   return http.get<Task[]>('/scheduler/tasks', { params })
 }
+
+// 暂停任务
+export const pauseTask = (taskId: string) => {
+  return http.post(`/scheduler/tasks/${taskId}/pause`)
+}
+
+// 继续任务
+export const resumeTask = (taskId: string) => {
+  return http.post(`/scheduler/tasks/${taskId}/resume`)
+}
+
+// 暂停全部下载（全局开关）
+export const pauseAllTasks = () => {
+  return http.post<{ paused_all: boolean; paused_count: number }>('/scheduler/tasks/pause-all')
+}
+
+// 继续全部下载（关闭全局开关）
+export const resumeAllTasks = () => {
+  return http.post<{ paused_all: boolean; resumed_count: number }>('/scheduler/tasks/resume-all')
+}
@@ -1,5 +1,5 @@
 import { http } from '@/utils/request'
-import type { VideoSource, PageParams, PageResponse } from '@/types'
+import type { VideoSource, RetentionResult, PageParams, PageResponse } from '@/types'
 
 // 获取视频源列表
 export const getVideoSources = (params?: PageParams) => {
@@ -35,3 +35,12 @@ export const toggleVideoSource = (id: number, enabled: boolean, type: string) =>
 export const scanVideoSource = (id: number, type: string) => {
   return http.post(`/video_sources/${id}/scan?type=${type}`)
 }
+
+// 执行或试运行保留策略（dry_run 时可传入未保存的策略）
+export const runSourceRetention = (id: number, type: string, data: {
+  dry_run: boolean
+  retention_mode?: string
+  retention_value?: number
+}) => {
+  return http.post<RetentionResult>(`/video_sources/${id}/retention?type=${type}`, data)
+}
@@ -25,6 +25,11 @@ export const getVideoPages = (id: number) => {
   return http.get<Page[]>(`/videos/${id}/pages`)
 }
 
+// 更新视频（如标记保留）
+export const updateVideo = (id: number, data: Partial<Video>) => {
+  return http.put<Video>(`/videos/${id}`, data)
+}
+
 // 删除视频
 export const deleteVideo = (id: number) => {
   return http.delete(`/videos/${id}`)
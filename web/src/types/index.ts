@@ -7,7 +7,7 @@ export interface User {
 }
 
 // 视频源类型
-export type VideoSourceType = 'favorite' | 'watch_later' | 'collection' | 'submission'
+export type VideoSourceType = 'favorite' | 'watch_later' | 'collection' | 'submission' | 'bangumi'
 
 // 视频源接口
 export interface VideoSource {
@@ -25,9 +25,44 @@ export interface VideoSource {
   mid?: string // UP主ID/合集UP主ID
   upper_id?: number // UP主ID
   upper_face?: string // UP主头像
-  season_id?: string // 合集ID
+  season_id?: string // 合集ID/番剧季度ID
   series_id?: string // 系列ID
   collection_type?: string // 合集类型
+  use_dynamic_api?: boolean // UP主投稿：通过空间动态接口扫描
+  season_number?: number // 番剧：季序号
+  finished?: boolean // 番剧：是否已完结
+  remove_after_download?: boolean // 稍后再看：下载完成后从稍后再看移除
+  move_to_favorite_id?: number // 稍后再看：移除前加入的收藏夹ID，0 表示直接移除
+  // 同步计划
+  scan_interval?: number // 同步间隔（秒），0 表示使用全局同步间隔
+  scan_cron?: string // 同步 cron 表达式，设置后优先于同步间隔
+  // 保留策略
+  retention_mode?: '' | 'keep_last' | 'max_age' | 'mirror'
+  retention_value?: number // keep_last 为保留数量，max_age 为保留天数
+  download_engine?: '' | 'ytdlp' | 'native' // 下载引擎，空表示使用全局配置
+  post_process?: '' | 'none' | 'mkv' | 'h264_1080p' | 'audio_m4a' // 下载后处理方案，空表示使用全局配置
+}
+
+// 保留策略执行结果
+export interface RetentionResult {
+  source_type: string
+  source_id: number
+  source_name: string
+  mode: string
+  value: number
+  dry_run: boolean
+  total: number
+  kept: number // 标记保留而跳过
+  busy: number // 下载中推迟
+  candidates: {
+    id: number
+    bvid: string
+    name: string
+    reason: string
+    downloaded: boolean
+  }[]
+  pruned: number
+  failed: number
 }
 
 // 视频信息
@@ -48,7 +83,11 @@ export interface Video {
   ctime: string
   single_page: boolean
   valid: boolean
+  lost_reason?: 'deleted' | 'unavailable' | 'removed' | ''
+  lost_at?: string
+  archived_at?: string // 下载完成后由本程序移出稍后再看的时间
   should_download: boolean
+  keep?: boolean // 标记保留，不受视频源保留策略清理
   download_status: number
   path: string
   media_kind?: 'video' | 'gallery'
@@ -56,10 +95,19 @@ export interface Video {
   watch_later_id?: number
   collection_id?: number
   submission_id?: number
+  bangumi_id?: number
+  ep_id?: number // 番剧剧集ID
+  season_number?: number
+  episode_number?: number
   created_at: string
   pages?: Page[]
   max_quality?: number
   max_quality_label?: string
+  video_codecs?: string[]
+  hdr_format?: string
+  dolby_vision?: boolean
+  audio_codecs?: string[]
+  audio_types?: string[]
 }
 
 // 分P信息
@@ -75,8 +123,16 @@ export interface Page {
   frame_rate?: number
   quality?: number
   orientation?: number
+  video_codec?: string
+  video_bitrate?: number
+  hdr_format?: string
+  dolby_vision?: boolean
+  audio_codec?: string
+  audio_channels?: number
+  audio_sample_rate?: number
+  audio_type?: 'standard' | 'dolby' | 'dolby_atmos' | 'hires' | ''
   image: string
-  download_status: number
+  download_status: number // 位标志：1 视频 2 封面 4 弹幕 8 字幕 16 NFO
   path: string
   kind?: 'video' | 'image' | 'live_photo'
   file_path?: string
@@ -108,7 +164,14 @@ export interface Config {
   }
   sync: {
     interval: number
+    cron: string
     scan_only: boolean
+    full_scan_days: number
+    quiet_hours: {
+      enabled: boolean
+      start: string
+      end: string
+    }
   }
   paths: {
     download_base: string
@@ -136,11 +199,20 @@ export interface Config {
     cdn_sort: boolean
   }
   download: {
+    engine: 'ytdlp' | 'native'
+    post_process: '' | 'mkv' | 'h264_1080p' | 'audio_m4a'
+    post_process_workers: number
     skip_poster: boolean
     skip_video_nfo: boolean
     skip_upper: boolean
     skip_danmaku: boolean
     skip_subtitle: boolean
+    subtitle_langs: string[]
+    subtitle_formats: string[]
+    bandwidth: {
+      limit: number
+      schedule: BandwidthScheduleRule[]
+    }
   }
   danmaku: {
     duration: number
@@ -166,10 +238,17 @@ export interface Config {
     rate_limit: {
       duration_ms: number
       limit: number
+      wbi: RateLimitRule
+      playurl: RateLimitRule
+      danmaku: RateLimitRule
     }
     nfo_time_type: string
     ytdlp_extra_args: string[]
     max_retry_count: number
+    api_retry: {
+      max_retries: number
+      backoff_ms: number
+    }
   }
   logging: {
     level: string
@@ -290,6 +369,59 @@ export interface Task {
   completed_at?: string
 }
 
+// 命名模板预览
+export interface TemplatePreviewItem {
+  bvid: string
+  title: string
+  sample: boolean
+  folder: string
+  files: string[]
+}
+
+export interface TemplatePreviewResult {
+  template: Config['template']
+  items: TemplatePreviewItem[]
+}
+
+// 媒体库重整
+export interface ReorganizeMove {
+  page_id?: number
+  from: string
+  to: string
+}
+
+export interface ReorganizePlan {
+  video_id: number
+  bvid: string
+  title: string
+  old_dir: string
+  new_dir: string
+  moves: ReorganizeMove[]
+  skipped?: string
+}
+
+export interface ReorganizePreview {
+  total: number
+  unchanged: number
+  changed: number
+  skipped: number
+  pending: number
+  plans: ReorganizePlan[]
+}
+
+// 单类接口的请求预算，均为 0 时沿用默认预算
+export interface RateLimitRule {
+  duration_ms: number
+  limit: number
+}
+
+// 按时段覆盖的带宽上限
+export interface BandwidthScheduleRule {
+  start: string
+  end: string
+  limit: number // KB/s，0 表示不限速
+}
+
 // 调度器状态
 export interface SchedulerStatus {
   is_running: boolean
@@ -297,6 +429,8 @@ export interface SchedulerStatus {
   next_run_at: string | null
   current_sync_id: string
   interval: number
+  cron: string
+  quiet_hours: boolean
 }
 
 // 同步日志
@@ -339,6 +473,7 @@ export interface VideoSourceScan {
   videos_new: number
   videos_filtered: number
   videos_queued: number
+  videos_lost?: number
   created_at: string
 }
 
@@ -360,9 +495,12 @@ export interface TasksSummary {
   pending: number
   queued: number
   running: number
+  paused: number
   completed: number
   failed: number
   total: number
+  paused_all: boolean
+  quiet_hours: boolean
 }
 
 
@@ -390,6 +528,14 @@ export interface ApiResponse<T = any> {
   data: T
 }
 
+// 文件子轨道状态（如字幕各语言轨道）
+export interface FileTrack {
+  name: string
+  label: string
+  status: string
+  error?: string
+}
+
 // 下载记录文件详情
 export interface FileDetail {
   name: string
@@ -397,6 +543,7 @@ export interface FileDetail {
   status: 'pending' | 'downloading' | 'completed' | 'failed' | 'skipped'
   size: number
   progress: number
+  tracks?: FileTrack[]
 }
 
 // 下载记录